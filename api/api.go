@@ -0,0 +1,12 @@
+// Package api embeds the OpenAPI document generated from the routes and
+// models. Regenerate it after changing either with:
+//
+//	go generate ./api
+package api
+
+import _ "embed"
+
+//go:generate go run ../cmd/openapi -o openapi.json
+
+//go:embed openapi.json
+var Spec []byte
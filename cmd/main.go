@@ -1,14 +1,35 @@
 package main
 
 import (
+	"context"
 	"log"
 	"github.com/gin-gonic/gin"
+	"net"
 	"os"
-	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/togzhanzhakhani/projects/api"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/changes"
+	"github.com/togzhanzhakhani/projects/internal/graph"
 	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/jobs"
+	"github.com/togzhanzhakhani/projects/internal/openapi"
+	"github.com/togzhanzhakhani/projects/internal/password"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/ratelimit"
+	"github.com/togzhanzhakhani/projects/internal/recurrence"
+	"github.com/togzhanzhakhani/projects/internal/invite"
+	"github.com/togzhanzhakhani/projects/internal/reminders"
 	"github.com/togzhanzhakhani/projects/pkg/database"
 	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+	"github.com/togzhanzhakhani/projects/internal/rpc"
+	"github.com/togzhanzhakhani/projects/internal/tracker"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -20,77 +41,179 @@ func main() {
 	}
 	defer sqlDB.Close()
 
-	router := gin.Default()
-	
+	router := gin.New()
+	router.Use(gin.Logger(), gin.CustomRecovery(problem.Recover), problem.Trace())
+
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
 	projectRepo := repository.NewProjectRepository(db)
-	
-	userHandler := handlers.NewUserHandler(userRepo)
-	taskHandler := handlers.NewTaskHandler(taskRepo)
-	projectHandler := handlers.NewProjectHandler(projectRepo)
-	
-	userRoutes := router.Group("/users")
-	{
-		userRoutes.GET("/", userHandler.GetAllUsers)
-		userRoutes.POST("/", userHandler.CreateUser)
-		userRoutes.GET("/:id", userHandler.GetUserByID)
-		userRoutes.PUT("/:id", userHandler.UpdateUser)
-		userRoutes.DELETE("/:id", userHandler.DeleteUser)
-		userRoutes.GET("/:id/tasks", userHandler.GetTasksByUserID)
-		userRoutes.GET("/search", func(c *gin.Context) {
-			if name := c.Query("name"); name != "" {
-				userHandler.SearchUsersByName(c)
-			} else if email := c.Query("email"); email != "" {
-				userHandler.SearchUsersByEmail(c)
-			} else {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'name' or 'email' is required"})
-			}
-		})
+	reminderRepo := repository.NewReminderRepository(db)
+	recurringTaskRepo := repository.NewRecurringTaskRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	feedTokenRepo := repository.NewFeedTokenRepository(db)
+	importRepo := repository.NewImportRepository(db)
+	exportRepo := repository.NewExportRepository(db)
+	archiveRepo := repository.NewArchiveRepository(db)
+	trackerRepo := repository.NewTrackerRepository(db)
+	commitRepo := repository.NewCommitRepository(db)
+	graphRepo := repository.NewGraphRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	projectMemberRepo := repository.NewProjectMemberRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	authRepo := repository.NewAuthRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	validation.RegisterRules(repository.NewValidationRepository(db))
+
+	scheduler := reminders.NewScheduler(taskRepo, reminderRepo, reminders.LogNotifier{})
+	if err := scheduler.LoadConfig(); err != nil {
+		log.Fatalf("Invalid reminder configuration: %v", err)
 	}
-	
-	taskRoutes := router.Group("/tasks")
-	{
-		taskRoutes.GET("/", taskHandler.GetAllTasks)
-		taskRoutes.POST("/", taskHandler.CreateTask)
-		taskRoutes.GET("/:id", taskHandler.GetTaskByID)
-		taskRoutes.PUT("/:id", taskHandler.UpdateTask)
-		taskRoutes.DELETE("/:id", func(c *gin.Context) {
-			if title := c.Query("title"); title != "" {
-				taskHandler.SearchTasksByTitle(c)
-			} else if status := c.Query("status"); status != "" {
-				taskHandler.SearchTasksByStatus(c)
-			} else if priority := c.Query("priority"); priority != "" {
-				taskHandler.SearchTasksByPriority(c)
-			} else if assignee := c.Query("assignee"); assignee != "" {
-				taskHandler.SearchTasksByAssignee(c)
-			} else if project := c.Query("project"); project != "" {
-				taskHandler.SearchTasksByProject(c)
-			} else {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Missing query parameter"})
-			}
-		})
+	generator := recurrence.NewGenerator(recurringTaskRepo)
+	if err := generator.LoadConfig(); err != nil {
+		log.Fatalf("Invalid recurrence configuration: %v", err)
 	}
 
-	projectRoutes := router.Group("/projects")
-	{
-		projectRoutes.GET("/", projectHandler.GetAllProjects)
-		projectRoutes.POST("/", projectHandler.CreateProject)
-		projectRoutes.GET("/:id", projectHandler.GetProjectByID)
-		projectRoutes.PUT("/:id", projectHandler.UpdateProject)
-		projectRoutes.DELETE("/:id", projectHandler.DeleteProject)
-		projectRoutes.GET("/:id/tasks", projectHandler.GetTasksByProjectID)
-		projectRoutes.GET("/search", func(c *gin.Context) {
-			if title := c.Query("title"); title != "" {
-				projectHandler.SearchProjectsByTitle(c)
-			} else if manager := c.Query("manager"); manager != "" {
-				projectHandler.SearchProjectsByManagerID(c)
-			} else {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Missing query parameter"})
-			}
+	jobManager := jobs.NewManager(jobRepo, jobs.NewAdvisoryLocker(sqlDB))
+	registerJob(jobManager, jobs.Job{
+		Name:     "task-reminders",
+		Schedule: getEnv("REMINDER_SCHEDULE", "* * * * *"),
+		Run: func(ctx context.Context) error {
+			return scheduler.RunOnce(time.Now())
+		},
+	})
+	registerJob(jobManager, jobs.Job{
+		Name:     "recurring-tasks",
+		Schedule: getEnv("RECURRENCE_SCHEDULE", "@hourly"),
+		Run: func(ctx context.Context) error {
+			_, err := generator.RunOnce(time.Now())
+			return err
+		},
+	})
+
+	// Rate limits are kept in memory unless RATE_LIMIT_STORE is postgres,
+	// which shares them between replicas.
+	rateLimits, err := ratelimit.LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid rate limit configuration: %v", err)
+	}
+	var rateLimitStore ratelimit.Store
+	switch store := getEnv("RATE_LIMIT_STORE", "memory"); store {
+	case "memory":
+		rateLimitStore = ratelimit.NewMemoryStore()
+	case "postgres":
+		postgresStore := ratelimit.NewPostgresStore(sqlDB)
+		rateLimitStore = postgresStore
+		registerJob(jobManager, jobs.Job{
+			Name:     "rate-limit-prune",
+			Schedule: getEnv("RATE_LIMIT_PRUNE_SCHEDULE", "@hourly"),
+			Run: func(ctx context.Context) error {
+				_, err := postgresStore.Prune(ctx, rateLimits.Longest()+time.Hour)
+				return err
+			},
 		})
+	default:
+		log.Fatalf("Invalid RATE_LIMIT_STORE %q", store)
+	}
+	limiter := ratelimit.NewLimiter(rateLimits, rateLimitStore)
+	// Client IPs are only taken from X-Forwarded-For behind the proxies in
+	// TRUSTED_PROXIES; otherwise it would pick the IP bucket of a request.
+	if err := router.SetTrustedProxies(rateLimits.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go jobManager.Start(ctx)
+
+	taskChanges := changes.NewBroker()
+	go changes.NewListener(sqlDB, taskChanges).Start(ctx)
+
+	// Callers log in for a session token, or send an API token, which the
+	// gRPC API does not take as it cannot check its scopes. The X-User-ID
+	// header lets anyone act as any user, so it is only trusted when
+	// TRUST_USER_HEADER is true, for deployments behind an authenticating
+	// proxy that sets it.
+	authHandler := handlers.NewAuthHandler(authRepo, userRepo, password.LogResetSender{})
+	if err := authHandler.LoadConfig(); err != nil {
+		log.Fatalf("Invalid auth configuration: %v", err)
+	}
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenRepo)
+	if err := apiTokenHandler.LoadConfig(); err != nil {
+		log.Fatalf("Invalid API token configuration: %v", err)
+	}
+	identify := auth.Any(auth.BearerScoped(apiTokenHandler.Authenticate), auth.Bearer(authHandler.Authenticate))
+	identifyCall := rpc.BearerMetadata(authHandler.Authenticate)
+	trustHeader, err := strconv.ParseBool(getEnv("TRUST_USER_HEADER", "false"))
+	if err != nil {
+		log.Fatalf("Invalid TRUST_USER_HEADER %q", os.Getenv("TRUST_USER_HEADER"))
+	}
+	if trustHeader {
+		identify = auth.Any(identify, auth.Header)
+		identifyCall = rpc.AnyMetadata(identifyCall, rpc.UserMetadata)
+	}
+
+	grpcServer := grpc.NewServer(append(rpc.RateLimit(limiter), rpc.Scope(identifyCall, workspaceRepo)...)...)
+	rpc.Register(grpcServer, rpc.Services{
+		User:    rpc.NewUserService(userRepo),
+		Project: rpc.NewProjectService(projectRepo),
+		Task:    rpc.NewTaskService(taskRepo, taskChanges),
+	})
+	listener, err := net.Listen("tcp", ":"+getEnv("GRPC_PORT", "9090"))
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC: %v", err)
 	}
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+	defer grpcServer.GracefulStop()
 	
+	spec, err := openapi.Load(api.Spec)
+	if err != nil {
+		log.Fatalf("Failed to load OpenAPI document: %v", err)
+	}
+	router.Use(openapi.NewRequestValidator(spec).Middleware())
+
+	invitationHandler := handlers.NewInvitationHandler(invitationRepo, userRepo, invite.LogSender{})
+	invitationHandler.Policy = authHandler.Policy
+	if ttl := os.Getenv("INVITATION_TTL"); ttl != "" {
+		if invitationHandler.TTL, err = time.ParseDuration(ttl); err != nil || invitationHandler.TTL <= 0 {
+			log.Fatalf("Invalid INVITATION_TTL %q", ttl)
+		}
+	}
+
+	scope := []gin.HandlerFunc{workspace.Middleware(workspaceRepo)}
+	if rls, _ := strconv.ParseBool(os.Getenv("WORKSPACE_RLS")); rls {
+		scope = append(scope, workspace.RowLevelSecurity(db))
+	}
+	routes.Register(router, routes.Handlers{
+		Workspace:     handlers.NewWorkspaceHandler(workspaceRepo, invitationHandler),
+		User:          handlers.NewUserHandler(userRepo),
+		Task:          handlers.NewTaskHandler(taskRepo),
+		Project:       handlers.NewProjectHandler(projectRepo),
+		ProjectMember: handlers.NewProjectMemberHandler(projectMemberRepo),
+		Team:          handlers.NewTeamHandler(teamRepo),
+		Invitation:    invitationHandler,
+		Auth:          authHandler,
+		APIToken:      apiTokenHandler,
+		RecurringTask: handlers.NewRecurringTaskHandler(recurringTaskRepo),
+		Job:           handlers.NewJobHandler(jobManager),
+		Calendar:      handlers.NewCalendarHandler(userRepo, projectRepo, feedTokenRepo),
+		Import:        handlers.NewImportHandler(importRepo),
+		Export:        handlers.NewExportHandler(exportRepo),
+		Archive:       handlers.NewArchiveHandler(archiveRepo),
+		TrackerImport: handlers.NewTrackerImportHandler(tracker.NewImporter(trackerRepo)),
+		Webhook:       handlers.NewWebhookHandler(commitRepo, workspaceRepo),
+		GraphQL:       handlers.NewGraphQLHandler(graph.NewResolver(graphRepo)),
+		Docs:          handlers.NewDocsHandler(api.Spec),
+		Authenticate:  []gin.HandlerFunc{auth.Middleware(identify)},
+		Scope:         scope,
+		RateLimitIP:   []gin.HandlerFunc{limiter.IPMiddleware()},
+		RateLimit:     []gin.HandlerFunc{limiter.Middleware()},
+	})
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -100,3 +223,16 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+func registerJob(manager *jobs.Manager, job jobs.Job) {
+	if err := manager.Register(job); err != nil {
+		log.Fatalf("Failed to register job: %v", err)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,41 @@
+// Command openapi writes the OpenAPI document of the API, generated from the
+// routes and models. It is run by go generate ./api.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/openapi"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+)
+
+func main() {
+	output := flag.String("o", "", "output file (default: standard output)")
+	flag.Parse()
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	routes.Register(router, routes.Handlers{})
+
+	doc, err := openapi.Generate(router.Routes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,17 @@
+// Command pmctl is a command-line client for the API. Run pmctl help for
+// its commands.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/togzhanzhakhani/projects/internal/cli"
+)
+
+func main() {
+	if err := cli.NewRootCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
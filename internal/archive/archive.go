@@ -0,0 +1,215 @@
+// Package archive reads and writes portable project archives: zip files with a
+// JSON manifest and one JSON file per kind of record.
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+const (
+	// Format identifies project archives in the manifest.
+	Format = "project-archive"
+	// Version is the archive layout written by this code. Archives with a
+	// higher version are rejected; older versions must stay readable.
+	Version = 1
+
+	manifestFile       = "manifest.json"
+	projectFile        = "project.json"
+	tasksFile          = "tasks.json"
+	usersFile          = "users.json"
+	recurringTasksFile = "recurring_tasks.json"
+
+	// maxFileSize bounds a single decompressed archive member.
+	maxFileSize = 256 << 20
+)
+
+// Manifest describes the archive. Files maps every member to its SHA-256
+// checksum, which is verified on restore.
+type Manifest struct {
+	Format    string            `json:"format"`
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	ProjectID int               `json:"project_id"`
+	Counts    map[string]int    `json:"counts"`
+	Files     map[string]string `json:"files"`
+}
+
+// Archive is a project with everything that belongs to or is referenced by it.
+// Comments and attachments are not stored by the API yet, so archives do not
+// contain them.
+type Archive struct {
+	Manifest       Manifest
+	Project        models.Project
+	Tasks          []models.Task
+	Users          []models.User
+	RecurringTasks []models.RecurringTask
+}
+
+// Write writes a as a zip archive, filling in its manifest.
+func Write(w io.Writer, a *Archive) error {
+	// Empty lists are written as [] rather than null.
+	if a.Tasks == nil {
+		a.Tasks = []models.Task{}
+	}
+	if a.Users == nil {
+		a.Users = []models.User{}
+	}
+	if a.RecurringTasks == nil {
+		a.RecurringTasks = []models.RecurringTask{}
+	}
+
+	members := []struct {
+		name  string
+		value interface{}
+	}{
+		{projectFile, a.Project},
+		{tasksFile, a.Tasks},
+		{usersFile, a.Users},
+		{recurringTasksFile, a.RecurringTasks},
+	}
+
+	a.Manifest = Manifest{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		ProjectID: a.Project.ID,
+		Counts: map[string]int{
+			"tasks":           len(a.Tasks),
+			"users":           len(a.Users),
+			"recurring_tasks": len(a.RecurringTasks),
+		},
+		Files: make(map[string]string),
+	}
+
+	zw := zip.NewWriter(w)
+	for _, member := range members {
+		data, err := json.MarshalIndent(member.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		a.Manifest.Files[member.name] = hex.EncodeToString(sum[:])
+		if err := writeMember(zw, member.name, data); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := json.MarshalIndent(a.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeMember(zw, manifestFile, manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeMember(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// Read parses and verifies an archive.
+func Read(data []byte) (*Archive, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("not a zip archive")
+	}
+
+	members := make(map[string][]byte)
+	for _, f := range zr.File {
+		if f.UncompressedSize64 > maxFileSize {
+			return nil, fmt.Errorf("%s is too large", f.Name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(r, maxFileSize))
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		members[f.Name] = content
+	}
+
+	a := &Archive{}
+	manifest, ok := members[manifestFile]
+	if !ok {
+		return nil, errors.New("manifest.json is missing")
+	}
+	if err := json.Unmarshal(manifest, &a.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest.json: %v", err)
+	}
+	if a.Manifest.Format != Format {
+		return nil, fmt.Errorf("unknown archive format %q", a.Manifest.Format)
+	}
+	if a.Manifest.Version < 1 || a.Manifest.Version > Version {
+		return nil, fmt.Errorf("unsupported archive version %d", a.Manifest.Version)
+	}
+
+	targets := map[string]interface{}{
+		projectFile:        &a.Project,
+		tasksFile:          &a.Tasks,
+		usersFile:          &a.Users,
+		recurringTasksFile: &a.RecurringTasks,
+	}
+	for name, target := range targets {
+		content, ok := members[name]
+		if !ok {
+			if name == projectFile {
+				return nil, errors.New("project.json is missing")
+			}
+			continue
+		}
+		sum := sha256.Sum256(content)
+		if expected, ok := a.Manifest.Files[name]; ok && expected != hex.EncodeToString(sum[:]) {
+			return nil, fmt.Errorf("checksum mismatch for %s", name)
+		}
+		if err := json.Unmarshal(content, target); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", name, err)
+		}
+	}
+	return a, nil
+}
+
+// Validate checks that every user referenced by the project, its tasks and
+// its recurring task templates is included in the archive.
+func (a *Archive) Validate() error {
+	users := make(map[int]bool, len(a.Users))
+	for _, user := range a.Users {
+		if user.Email == "" {
+			return fmt.Errorf("user %d has no email", user.ID)
+		}
+		users[int(user.ID)] = true
+	}
+
+	if !users[a.Project.ManagerID] {
+		return fmt.Errorf("manager %d is missing from the archive", a.Project.ManagerID)
+	}
+	for _, task := range a.Tasks {
+		if !users[task.AssigneeID] {
+			return fmt.Errorf("assignee %d of task %d is missing from the archive", task.AssigneeID, task.ID)
+		}
+	}
+	for _, template := range a.RecurringTasks {
+		if !users[template.AssigneeID] {
+			return fmt.Errorf("assignee %d of recurring task %d is missing from the archive", template.AssigneeID, template.ID)
+		}
+	}
+	return nil
+}
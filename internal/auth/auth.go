@@ -0,0 +1,113 @@
+// Package auth identifies the caller of a request. Handlers and
+// repositories find the caller's user ID in the request context, whichever
+// way the caller was identified.
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+// UserHeader carries the caller's user ID when the API runs behind a proxy
+// that authenticates requests, see Header.
+const UserHeader = "X-User-ID"
+
+// Identify returns the user ID of the caller of a request, and false if the
+// request does not identify one.
+type Identify func(c *gin.Context) (uint, bool)
+
+type userKey struct{}
+
+// NewContext returns a copy of ctx carrying the caller's user ID.
+func NewContext(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserID returns the caller's user ID carried by ctx.
+func UserID(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(userKey{}).(uint)
+	return id, ok
+}
+
+type verifiedKey struct{}
+
+// NewVerifiedContext returns a copy of ctx whose caller was identified by a
+// credential the API checked, such as a session or API token.
+func NewVerifiedContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verifiedKey{}, true)
+}
+
+// Verified reports whether the caller of ctx was identified by a credential
+// the API checked, rather than by a header taken on trust.
+func Verified(ctx context.Context) bool {
+	verified, _ := ctx.Value(verifiedKey{}).(bool)
+	return verified
+}
+
+// Header identifies the caller by the UserHeader header. The header is taken
+// on trust, so it is only fit for deployments where a proxy in front of the
+// API authenticates the caller and sets it.
+func Header(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.GetHeader(UserHeader), 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// BearerToken returns the bearer token of the Authorization header, and
+// false if there is none.
+func BearerToken(c *gin.Context) (string, bool) {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// Bearer identifies the caller by the bearer token of the Authorization
+// header, looked up by lookup. The caller is verified, see Verified.
+func Bearer(lookup func(token string) (uint, bool)) Identify {
+	return func(c *gin.Context) (uint, bool) {
+		token, ok := BearerToken(c)
+		if !ok {
+			return 0, false
+		}
+		userID, ok := lookup(token)
+		if !ok {
+			return 0, false
+		}
+		c.Request = c.Request.WithContext(NewVerifiedContext(c.Request.Context()))
+		return userID, true
+	}
+}
+
+// Any identifies the caller by the first of identify that does.
+func Any(identify ...Identify) Identify {
+	return func(c *gin.Context) (uint, bool) {
+		for _, id := range identify {
+			if userID, ok := id(c); ok {
+				return userID, true
+			}
+		}
+		return 0, false
+	}
+}
+
+// Middleware rejects requests whose caller cannot be identified, and puts
+// the caller's user ID in the context of the others.
+func Middleware(identify Identify) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := identify(c)
+		if !ok {
+			problem.Write(c, problem.Unauthorized("Authentication is required"))
+			return
+		}
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), userID))
+		c.Next()
+	}
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+// Scope levels. Each grants the ones before it: admin grants write, write
+// grants read.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var levels = map[string]int{ScopeRead: 1, ScopeWrite: 2, ScopeAdmin: 3}
+
+// Resources are what scopes grant access to, as resource:level.
+var Resources = []string{"users", "projects", "tasks", "recurring-tasks", "teams", "invitations", "workspaces", "import", "export", "graphql"}
+
+// ValidScope reports whether scope is a known resource:level.
+func ValidScope(scope string) bool {
+	resource, level, ok := strings.Cut(scope, ":")
+	if !ok || levels[level] == 0 {
+		return false
+	}
+	for _, r := range Resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// Grants reports whether scopes grant scope.
+func Grants(scopes []string, scope string) bool {
+	resource, level, _ := strings.Cut(scope, ":")
+	for _, s := range scopes {
+		r, l, _ := strings.Cut(s, ":")
+		if r == resource && levels[l] >= levels[level] {
+			return true
+		}
+	}
+	return false
+}
+
+type scopesKey struct{}
+
+// NewScopedContext returns a copy of ctx whose caller may only do what
+// scopes grant.
+func NewScopedContext(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// Scopes returns the scopes the caller is limited to, and false if the
+// caller is not limited, as users with sessions are not.
+func Scopes(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(scopesKey{}).([]string)
+	return scopes, ok
+}
+
+// Allows reports whether the caller of ctx may do what scope grants.
+func Allows(ctx context.Context, scope string) bool {
+	scopes, limited := Scopes(ctx)
+	return !limited || Grants(scopes, scope)
+}
+
+// BearerScoped identifies the caller by a bearer token that grants only
+// some scopes, such as an API token, looked up by lookup. The scopes are
+// put in the request's context for RequireScope, and the caller is
+// verified.
+func BearerScoped(lookup func(token string) (uint, []string, bool)) Identify {
+	return func(c *gin.Context) (uint, bool) {
+		token, ok := BearerToken(c)
+		if !ok {
+			return 0, false
+		}
+		userID, scopes, ok := lookup(token)
+		if !ok {
+			return 0, false
+		}
+		c.Request = c.Request.WithContext(NewVerifiedContext(NewScopedContext(c.Request.Context(), scopes)))
+		return userID, true
+	}
+}
+
+// RequireScope rejects the requests of limited callers whose scopes do not
+// grant resource:read, for GET and HEAD requests, or resource:write.
+func RequireScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		level := ScopeWrite
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			level = ScopeRead
+		}
+		requireScope(c, resource+":"+level)
+	}
+}
+
+// RequireAdminScope rejects the requests of limited callers whose scopes do
+// not grant resource:admin.
+func RequireAdminScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requireScope(c, resource+":"+ScopeAdmin)
+	}
+}
+
+func requireScope(c *gin.Context, scope string) {
+	if !Allows(c.Request.Context(), scope) {
+		problem.Write(c, problem.Forbidden("The token lacks the "+scope+" scope"))
+		return
+	}
+	c.Next()
+}
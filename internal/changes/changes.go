@@ -0,0 +1,73 @@
+// Package changes delivers a feed of task changes. A trigger on the tasks
+// table notifies a Postgres channel of every insert, update and delete, so
+// the feed sees the writes of every replica and of every API; a Listener
+// forwards the notifications to the subscribers of a Broker.
+package changes
+
+import (
+	"sync"
+)
+
+// Channel is the Postgres notification channel of task changes.
+const Channel = "task_changes"
+
+// Kinds of changes, as the trigger reports them.
+const (
+	Inserted = "insert"
+	Updated  = "update"
+	Deleted  = "delete"
+)
+
+// TaskChange identifies a changed task. The task itself is not part of the
+// notification, whose payload is limited in size; subscribers load it.
+type TaskChange struct {
+	Op          string `json:"op"`
+	ID          int    `json:"id"`
+	ProjectID   int    `json:"project_id"`
+	AssigneeID  int    `json:"assignee_id"`
+	WorkspaceID uint   `json:"workspace_id"`
+}
+
+// Broker fans changes out to subscribers.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[chan TaskChange]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[chan TaskChange]struct{})}
+}
+
+// Subscribe returns a channel of the changes published from now on, and a
+// function that ends the subscription. A subscriber that falls more than
+// buffer changes behind is dropped: its channel is closed, so that it does
+// not miss changes silently.
+func (b *Broker) Subscribe(buffer int) (<-chan TaskChange, func()) {
+	ch := make(chan TaskChange, buffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish sends change to every subscriber without blocking.
+func (b *Broker) Publish(change TaskChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- change:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
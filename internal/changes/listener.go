@@ -0,0 +1,77 @@
+package changes
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Listener publishes the notifications of Channel to a Broker.
+type Listener struct {
+	DB     *sql.DB
+	Broker *Broker
+	// RetryInterval is the wait before listening again after the connection
+	// is lost. Changes made in the meantime are not delivered.
+	RetryInterval time.Duration
+}
+
+func NewListener(db *sql.DB, broker *Broker) *Listener {
+	return &Listener{DB: db, Broker: broker, RetryInterval: 5 * time.Second}
+}
+
+// Start listens until ctx is done.
+func (l *Listener) Start(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Error listening for task changes: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.RetryInterval):
+		}
+	}
+}
+
+// listen holds a connection of the pool for as long as it listens, since
+// notifications are delivered to the session that issued LISTEN.
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := l.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unsupported driver connection %T", driverConn)
+		}
+		pgConn := stdlibConn.Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+Channel); err != nil {
+			return err
+		}
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				// The session is still listening, or was closed by the
+				// cancellation; either way it must not go back to the pool.
+				return fmt.Errorf("%w: %v", driver.ErrBadConn, err)
+			}
+			var change TaskChange
+			if err := json.Unmarshal([]byte(notification.Payload), &change); err != nil {
+				log.Printf("Invalid task change notification %q: %v", notification.Payload, err)
+				continue
+			}
+			l.Broker.Publish(change)
+		}
+	})
+}
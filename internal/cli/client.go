@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+type apiClient struct {
+	baseURL string
+	token   string
+	// user and workspace are sent in the X-User-ID and X-Workspace-ID
+	// headers when not empty.
+	user      string
+	workspace string
+	http      *http.Client
+}
+
+func newAPIClient(baseURL, token string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	Status  int
+	Problem problem.Problem
+}
+
+func (e *apiError) Error() string {
+	var b strings.Builder
+	detail := e.Problem.Detail
+	if detail == "" {
+		detail = e.Problem.Title
+	}
+	if detail == "" {
+		detail = http.StatusText(e.Status)
+	}
+	fmt.Fprintf(&b, "%s (HTTP %d", detail, e.Status)
+	if e.Problem.Code != "" {
+		fmt.Fprintf(&b, ", %s", e.Problem.Code)
+	}
+	b.WriteString(")")
+	for _, fieldError := range e.Problem.Errors {
+		fmt.Fprintf(&b, "\n  %s: %s", fieldError.Field, fieldError.Message)
+	}
+	return b.String()
+}
+
+// do sends body as JSON and decodes the response into out, unless either is
+// nil.
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.user != "" {
+		req.Header.Set("X-User-ID", c.user)
+	}
+	if c.workspace != "" {
+		req.Header.Set("X-Workspace-ID", c.workspace)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		apiErr := &apiError{Status: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr.Problem)
+		return apiErr
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultBaseURL is used when neither a flag, the environment nor the
+// profile sets the base URL.
+const defaultBaseURL = "http://localhost:8080"
+
+// Config is the pmctl config file.
+type Config struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// Profile is a named API endpoint, the token and user to use with it and
+// the workspace to work in.
+type Profile struct {
+	BaseURL   string `yaml:"base_url,omitempty"`
+	Token     string `yaml:"token,omitempty"`
+	User      string `yaml:"user,omitempty"`
+	Workspace string `yaml:"workspace,omitempty"`
+}
+
+func (opts *options) configFile() (string, error) {
+	if opts.configPath != "" {
+		return opts.configPath, nil
+	}
+	if path := os.Getenv("PMCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pmctl", "config.yaml"), nil
+}
+
+// loadConfig reads the config file. A missing file is an empty config.
+func (opts *options) loadConfig() (*Config, string, error) {
+	path, err := opts.configFile()
+	if err != nil {
+		return nil, "", err
+	}
+	config := &Config{Profiles: make(map[string]*Profile)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, path, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, "", fmt.Errorf("read %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]*Profile)
+	}
+	return config, path, nil
+}
+
+// saveConfig writes the config file, readable only by the user as it holds
+// tokens.
+func saveConfig(path string, config *Config) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// client returns the API client of the selected profile. Flags override
+// the environment, which overrides the profile.
+func (opts *options) client() (*apiClient, error) {
+	config, _, err := opts.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	name := opts.profile
+	if name == "" {
+		name = config.CurrentProfile
+	}
+	profile := &Profile{}
+	if name != "" {
+		var ok bool
+		if profile, ok = config.Profiles[name]; !ok {
+			return nil, fmt.Errorf("profile %q does not exist", name)
+		}
+	}
+
+	baseURL := firstNonEmpty(opts.baseURL, os.Getenv("PMCTL_BASE_URL"), profile.BaseURL, defaultBaseURL)
+	token := firstNonEmpty(opts.token, os.Getenv("PMCTL_TOKEN"), profile.Token)
+	client := newAPIClient(baseURL, token)
+	client.user = firstNonEmpty(opts.user, os.Getenv("PMCTL_USER"), profile.User)
+	client.workspace = firstNonEmpty(opts.workspace, os.Getenv("PMCTL_WORKSPACE"), profile.Workspace)
+	return client, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func (opts *options) completeProfiles(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	config, _, err := opts.loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return profileNames(config), cobra.ShellCompDirectiveNoFileComp
+}
+
+func profileNames(config *Config) []string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newConfigCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage connection profiles",
+	}
+
+	var baseURL, token, user, workspace string
+	setProfile := &cobra.Command{
+		Use:   "set-profile NAME",
+		Short: "Create or change a profile; the first profile becomes the current one",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, path, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+			profile, ok := config.Profiles[args[0]]
+			if !ok {
+				profile = &Profile{}
+				config.Profiles[args[0]] = profile
+			}
+			if cmd.Flags().Changed("base-url") {
+				profile.BaseURL = strings.TrimRight(baseURL, "/")
+			}
+			if cmd.Flags().Changed("token") {
+				profile.Token = token
+			}
+			if cmd.Flags().Changed("user") {
+				profile.User = user
+			}
+			if cmd.Flags().Changed("workspace") {
+				profile.Workspace = workspace
+			}
+			if config.CurrentProfile == "" {
+				config.CurrentProfile = args[0]
+			}
+			return saveConfig(path, config)
+		},
+	}
+	// These shadow the global flags of the same name, which would otherwise
+	// apply to this command's own request.
+	setProfile.Flags().StringVar(&baseURL, "base-url", "", "API base URL")
+	setProfile.Flags().StringVar(&token, "token", "", "API token")
+	setProfile.Flags().StringVar(&user, "user", "", "user ID to act as")
+	setProfile.Flags().StringVarP(&workspace, "workspace", "w", "", "workspace ID")
+
+	useProfile := &cobra.Command{
+		Use:               "use-profile NAME",
+		Short:             "Make a profile the current one",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: opts.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, path, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := config.Profiles[args[0]]; !ok {
+				return fmt.Errorf("profile %q does not exist", args[0])
+			}
+			config.CurrentProfile = args[0]
+			return saveConfig(path, config)
+		},
+	}
+
+	deleteProfile := &cobra.Command{
+		Use:               "delete-profile NAME",
+		Short:             "Delete a profile",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: opts.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, path, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := config.Profiles[args[0]]; !ok {
+				return fmt.Errorf("profile %q does not exist", args[0])
+			}
+			delete(config.Profiles, args[0])
+			if config.CurrentProfile == args[0] {
+				config.CurrentProfile = ""
+			}
+			return saveConfig(path, config)
+		},
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List profiles; tokens are not shown",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, _, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CURRENT\tNAME\tBASE URL\tWORKSPACE\tTOKEN")
+			for _, name := range profileNames(config) {
+				profile := config.Profiles[name]
+				current, hasToken := "", "no"
+				if name == config.CurrentProfile {
+					current = "*"
+				}
+				if profile.Token != "" {
+					hasToken = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", current, name, profile.BaseURL, profile.Workspace, hasToken)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.AddCommand(setProfile, useProfile, deleteProfile, list)
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// formats are the values of --output.
+var formats = []string{"table", "json", "yaml"}
+
+// table is the table form of a result.
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// render writes value in the selected output format. rows is only called for
+// table output.
+func (opts *options) render(w io.Writer, value interface{}, rows func() table) error {
+	switch opts.output {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case "yaml":
+		return writeYAML(w, value)
+	case "table", "":
+		t := rows()
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q, want one of %s", opts.output, strings.Join(formats, ", "))
+	}
+}
+
+// writeYAML writes value as YAML with the field names and order of its JSON
+// encoding, which is what the API documents.
+func writeYAML(w io.Writer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	// JSON is YAML, but in flow style; decoding into a node keeps the key
+	// order, and clearing the style turns it into block style.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	clearStyle(&node)
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func clearStyle(node *yaml.Node) {
+	// The encoder still quotes strings that would read as another type.
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
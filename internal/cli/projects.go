@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+func newProjectsCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "projects",
+		Aliases: []string{"project"},
+		Short:   "List and show projects and their members",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List projects",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var projects []models.Project
+			if err := client.do(cmd.Context(), http.MethodGet, "/projects/", nil, &projects); err != nil {
+				return err
+			}
+			return opts.render(cmd.OutOrStdout(), projects, func() table { return projectTable(projects) })
+		},
+	}
+
+	show := &cobra.Command{
+		Use:   "show ID",
+		Short: "Show a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := strconv.Atoi(args[0]); err != nil {
+				return fmt.Errorf("invalid project ID %q", args[0])
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var project models.Project
+			if err := client.do(cmd.Context(), http.MethodGet, "/projects/"+args[0], nil, &project); err != nil {
+				return err
+			}
+			projects := []models.Project{project}
+			return opts.render(cmd.OutOrStdout(), project, func() table { return projectTable(projects) })
+		},
+	}
+
+	members := &cobra.Command{
+		Use:   "members ID",
+		Short: "List the members of a project and their roles",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := strconv.Atoi(args[0]); err != nil {
+				return fmt.Errorf("invalid project ID %q", args[0])
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var members []projectMember
+			if err := client.do(cmd.Context(), http.MethodGet, "/projects/"+args[0]+"/members", nil, &members); err != nil {
+				return err
+			}
+			return opts.render(cmd.OutOrStdout(), members, func() table { return projectMemberTable(members) })
+		},
+	}
+
+	cmd.AddCommand(list, show, members)
+	return cmd
+}
+
+// projectMember is a project membership with the member's name and email.
+type projectMember struct {
+	models.ProjectMember
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func projectMemberTable(members []projectMember) table {
+	t := table{headers: []string{"USER", "NAME", "EMAIL", "ROLE"}}
+	for _, member := range members {
+		t.rows = append(t.rows, []string{
+			strconv.FormatUint(uint64(member.UserID), 10),
+			member.Name,
+			member.Email,
+			member.Role,
+		})
+	}
+	return t
+}
+
+func projectTable(projects []models.Project) table {
+	t := table{headers: []string{"ID", "NAME", "START", "END", "MANAGER", "DESCRIPTION"}}
+	for _, project := range projects {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(project.ID),
+			project.Name,
+			project.StartDate.Format(dates.DateLayout),
+			project.EndDate.Format(dates.DateLayout),
+			strconv.Itoa(project.ManagerID),
+			project.Description,
+		})
+	}
+	return t
+}
@@ -0,0 +1,55 @@
+// Package cli implements pmctl, the command-line client of the API.
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// options are the global flags.
+type options struct {
+	configPath string
+	profile    string
+	baseURL    string
+	token      string
+	user       string
+	workspace  string
+	output     string
+}
+
+// NewRootCommand returns the pmctl command.
+func NewRootCommand() *cobra.Command {
+	opts := &options{}
+	root := &cobra.Command{
+		Use:           "pmctl",
+		Short:         "Command-line client for the task management API",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.configPath, "config", "", "config file (default $PMCTL_CONFIG or pmctl/config.yaml in the user config directory)")
+	flags.StringVarP(&opts.profile, "profile", "p", "", "config profile to use (default the current profile)")
+	flags.StringVar(&opts.baseURL, "base-url", "", "API base URL, overriding the profile and $PMCTL_BASE_URL")
+	flags.StringVar(&opts.token, "token", "", "API token, overriding the profile and $PMCTL_TOKEN")
+	flags.StringVar(&opts.user, "user", "", "user ID to act as, overriding the profile and $PMCTL_USER")
+	flags.StringVarP(&opts.workspace, "workspace", "w", "", "workspace ID, overriding the profile and $PMCTL_WORKSPACE (default the default workspace)")
+	flags.StringVarP(&opts.output, "output", "o", "table", "output format: table, json or yaml")
+	_ = root.RegisterFlagCompletionFunc("output", fixedCompletions(formats...))
+	_ = root.RegisterFlagCompletionFunc("profile", opts.completeProfiles)
+
+	root.AddCommand(
+		newTasksCommand(opts),
+		newProjectsCommand(opts),
+		newUsersCommand(opts),
+		newWorkspacesCommand(opts),
+		newTokensCommand(opts),
+		newConfigCommand(opts),
+	)
+	return root
+}
+
+func fixedCompletions(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,421 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	taskStatuses   = []string{"todo", "in_progress", "done"}
+	taskPriorities = []string{"low", "medium", "high"}
+)
+
+func newTasksCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tasks",
+		Aliases: []string{"task"},
+		Short:   "List, create and update tasks",
+	}
+	cmd.AddCommand(
+		newTasksListCommand(opts),
+		newTasksCreateCommand(opts),
+		newTasksUpdateCommand(opts),
+		newTasksTransitionCommand(opts),
+	)
+	return cmd
+}
+
+func newTasksListCommand(opts *options) *cobra.Command {
+	var filter struct {
+		status, priority, title string
+		assignee, project       int
+	}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tasks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var tasks []models.Task
+			if err := client.do(cmd.Context(), http.MethodGet, "/tasks/", nil, &tasks); err != nil {
+				return err
+			}
+
+			matched := make([]models.Task, 0, len(tasks))
+			for _, task := range tasks {
+				switch {
+				case filter.status != "" && task.Status != filter.status,
+					filter.priority != "" && task.Priority != filter.priority,
+					filter.title != "" && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(filter.title)),
+					filter.assignee != 0 && task.AssigneeID != filter.assignee,
+					filter.project != 0 && task.ProjectID != filter.project:
+					continue
+				}
+				matched = append(matched, task)
+			}
+			return opts.render(cmd.OutOrStdout(), matched, func() table { return taskTable(matched) })
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&filter.status, "status", "", "only tasks with this status")
+	flags.StringVar(&filter.priority, "priority", "", "only tasks with this priority")
+	flags.StringVar(&filter.title, "title", "", "only tasks whose title contains this text")
+	flags.IntVar(&filter.assignee, "assignee", 0, "only tasks assigned to this user ID")
+	flags.IntVar(&filter.project, "project", 0, "only tasks of this project ID")
+	_ = cmd.RegisterFlagCompletionFunc("status", fixedCompletions(taskStatuses...))
+	_ = cmd.RegisterFlagCompletionFunc("priority", fixedCompletions(taskPriorities...))
+	return cmd
+}
+
+// taskFields are the flags that set task fields, keyed by the JSON field of
+// the request body.
+type taskFields struct {
+	title, description, priority, status string
+	assignee, project                    int
+	created, completed, due, dueTimezone string
+}
+
+// register adds the task flags to cmd. Only flags of commands that create
+// tasks have defaults.
+func (f *taskFields) register(cmd *cobra.Command, create bool) {
+	priority, status, created := "", "", ""
+	if create {
+		priority, status, created = "medium", "todo", time.Now().Format(dates.DateLayout)
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&f.title, "title", "", "task title")
+	flags.StringVar(&f.description, "description", "", "task description")
+	flags.StringVar(&f.priority, "priority", priority, "priority: low, medium or high")
+	flags.StringVar(&f.status, "status", status, "status: todo, in_progress or done")
+	flags.IntVar(&f.assignee, "assignee", 0, "assignee user ID")
+	flags.IntVar(&f.project, "project", 0, "project ID")
+	flags.StringVar(&f.created, "created", created, "creation date (YYYY-MM-DD)")
+	flags.StringVar(&f.completed, "completed", "", "completion date (YYYY-MM-DD), after the creation date")
+	flags.StringVar(&f.due, "due", "", "due date: a date, a local time or an RFC 3339 timestamp")
+	flags.StringVar(&f.dueTimezone, "due-timezone", "", "IANA time zone of a local due date")
+	_ = cmd.RegisterFlagCompletionFunc("status", fixedCompletions(taskStatuses...))
+	_ = cmd.RegisterFlagCompletionFunc("priority", fixedCompletions(taskPriorities...))
+}
+
+// body returns the fields to send. Unless all is set, only the flags given
+// on the command line are included.
+func (f *taskFields) body(cmd *cobra.Command, all bool) map[string]interface{} {
+	values := []struct {
+		flag, field string
+		value       interface{}
+	}{
+		{"title", "title", f.title},
+		{"description", "description", f.description},
+		{"priority", "priority", f.priority},
+		{"status", "status", f.status},
+		{"assignee", "assignee_id", f.assignee},
+		{"project", "project_id", f.project},
+		{"created", "created_at", f.created},
+		{"completed", "completed_at", f.completed},
+		{"due", "due_date", f.due},
+		{"due-timezone", "due_timezone", f.dueTimezone},
+	}
+	body := make(map[string]interface{})
+	for _, v := range values {
+		if all || cmd.Flags().Changed(v.flag) {
+			body[v.field] = v.value
+		}
+	}
+	return body
+}
+
+// changed reports whether any task flag was given.
+func (f *taskFields) changed(cmd *cobra.Command) bool {
+	return len(f.body(cmd, false)) > 0
+}
+
+func newTasksCreateCommand(opts *options) *cobra.Command {
+	var fields taskFields
+	var file string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a task, or every task in a file",
+		Long: `Create a task from the flags, or with --file every task in a JSON or YAML
+file. The file holds a task object, a list of them or several YAML documents,
+with the fields of the POST /tasks request body. Use "-" to read standard input.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records := []map[string]interface{}{fields.body(cmd, true)}
+			if file != "" {
+				if fields.changed(cmd) {
+					return errors.New("task flags cannot be combined with --file")
+				}
+				var err error
+				if records, err = readRecords(cmd.InOrStdin(), file); err != nil {
+					return err
+				}
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			created := make([]models.Task, 0, len(records))
+			for i, record := range records {
+				var task models.Task
+				if err = client.do(cmd.Context(), http.MethodPost, "/tasks/", record, &task); err != nil {
+					err = recordError(len(records), i, err)
+					break
+				}
+				created = append(created, task)
+			}
+			if len(created) > 0 {
+				if renderErr := opts.render(cmd.OutOrStdout(), created, func() table { return taskTable(created) }); renderErr != nil {
+					return renderErr
+				}
+			}
+			return err
+		},
+	}
+	fields.register(cmd, true)
+	cmd.Flags().StringVarP(&file, "file", "f", "", `JSON or YAML file of tasks to create, "-" for standard input`)
+	return cmd
+}
+
+func newTasksUpdateCommand(opts *options) *cobra.Command {
+	var fields taskFields
+	var file string
+	cmd := &cobra.Command{
+		Use:   "update [ID]",
+		Short: "Change fields of a task, or of every task in a file",
+		Long: `Change the fields given as flags of task ID, leaving the others as they are.
+With --file, update every task in a JSON or YAML file instead; each record
+needs an "id" and holds the fields to change.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var records []map[string]interface{}
+			switch {
+			case file != "" && (len(args) > 0 || fields.changed(cmd)):
+				return errors.New("an ID and task flags cannot be combined with --file")
+			case file != "":
+				var err error
+				if records, err = readRecords(cmd.InOrStdin(), file); err != nil {
+					return err
+				}
+			case len(args) == 0:
+				return errors.New("a task ID or --file is required")
+			default:
+				record := fields.body(cmd, false)
+				record["id"] = args[0]
+				records = append(records, record)
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			updated := make([]models.Task, 0, len(records))
+			for i, record := range records {
+				var task *models.Task
+				if task, err = updateTask(cmd, client, record); err != nil {
+					err = recordError(len(records), i, err)
+					break
+				}
+				updated = append(updated, *task)
+			}
+			if len(updated) > 0 {
+				if renderErr := opts.render(cmd.OutOrStdout(), updated, func() table { return taskTable(updated) }); renderErr != nil {
+					return renderErr
+				}
+			}
+			return err
+		},
+	}
+	fields.register(cmd, false)
+	cmd.Flags().StringVarP(&file, "file", "f", "", `JSON or YAML file of task changes, "-" for standard input`)
+	return cmd
+}
+
+func newTasksTransitionCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "transition ID STATUS",
+		Short: "Move a task to another status",
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return taskStatuses, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			task, err := updateTask(cmd, client, map[string]interface{}{"id": args[0], "status": args[1]})
+			if err != nil {
+				return err
+			}
+			tasks := []models.Task{*task}
+			return opts.render(cmd.OutOrStdout(), task, func() table { return taskTable(tasks) })
+		},
+	}
+}
+
+// updateTask applies changes to a task. The API only replaces whole tasks,
+// so the current task is fetched and the changes laid over it.
+func updateTask(cmd *cobra.Command, client *apiClient, changes map[string]interface{}) (*models.Task, error) {
+	id, err := recordID(changes["id"])
+	if err != nil {
+		return nil, err
+	}
+	path := "/tasks/" + strconv.Itoa(id)
+
+	var current models.Task
+	if err := client.do(cmd.Context(), http.MethodGet, path, nil, &current); err != nil {
+		return nil, err
+	}
+	body := taskBody(current)
+	for field, value := range changes {
+		if field != "id" {
+			body[field] = value
+		}
+	}
+
+	var task models.Task
+	if err := client.do(cmd.Context(), http.MethodPut, path, body, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// taskBody returns the request body that recreates task as it is.
+func taskBody(task models.Task) map[string]interface{} {
+	body := map[string]interface{}{
+		"title":        task.Title,
+		"description":  task.Description,
+		"priority":     task.Priority,
+		"status":       task.Status,
+		"assignee_id":  task.AssigneeID,
+		"project_id":   task.ProjectID,
+		"created_at":   task.CreatedAt.Format(dates.DateLayout),
+		"completed_at": task.CompletedAt.Format(dates.DateLayout),
+	}
+	if task.DueDate != nil {
+		body["due_date"] = task.DueDate.Format(time.RFC3339)
+		body["due_timezone"] = task.DueTimezone
+	}
+	return body
+}
+
+func recordID(value interface{}) (int, error) {
+	switch id := value.(type) {
+	case int:
+		return id, nil
+	case float64:
+		return int(id), nil
+	case string:
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return 0, fmt.Errorf("invalid task ID %q", id)
+		}
+		return n, nil
+	case nil:
+		return 0, errors.New(`record has no "id"`)
+	default:
+		return 0, fmt.Errorf("invalid task ID %v", id)
+	}
+}
+
+// recordError names the failed record when there are several.
+func recordError(count, i int, err error) error {
+	if count == 1 {
+		return err
+	}
+	return fmt.Errorf("record %d: %w", i+1, err)
+}
+
+// readRecords reads the objects in a JSON or YAML file, or standard input
+// for "-". A document may hold one object or a list of them.
+func readRecords(stdin io.Reader, path string) ([]map[string]interface{}, error) {
+	reader := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	var records []map[string]interface{}
+	decoder := yaml.NewDecoder(reader)
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		items, ok := doc.([]interface{})
+		if !ok {
+			items = []interface{}{doc}
+		}
+		for _, item := range items {
+			record, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("read %s: record %d is not an object", path, len(records)+1)
+			}
+			for field, value := range record {
+				// YAML reads unquoted dates as timestamps; the API wants them
+				// back as text.
+				if t, ok := value.(time.Time); ok {
+					record[field] = formatTime(t)
+				}
+			}
+			records = append(records, record)
+		}
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("read %s: no records", path)
+	}
+	return records, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.Location() == time.UTC && t.Equal(t.Truncate(24*time.Hour)) {
+		return t.Format(dates.DateLayout)
+	}
+	return t.Format(time.RFC3339)
+}
+
+func taskTable(tasks []models.Task) table {
+	t := table{headers: []string{"ID", "TITLE", "STATUS", "PRIORITY", "ASSIGNEE", "PROJECT", "DUE"}}
+	for _, task := range tasks {
+		due := ""
+		if task.DueDate != nil {
+			due = task.DueDate.Format(time.RFC3339)
+		}
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(task.ID),
+			task.Title,
+			task.Status,
+			task.Priority,
+			strconv.Itoa(task.AssigneeID),
+			strconv.Itoa(task.ProjectID),
+			due,
+		})
+	}
+	return t
+}
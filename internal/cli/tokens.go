@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+// createdToken is a created API token with the token itself.
+type createdToken struct {
+	models.APIToken
+	Token string `json:"token"`
+}
+
+func newTokensCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tokens",
+		Aliases: []string{"token"},
+		Short:   "Manage your personal API tokens",
+		Long:    "Manage your personal API tokens. These commands need a session token or user; API tokens cannot manage API tokens.",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List your API tokens that are not revoked",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var tokens []models.APIToken
+			if err := client.do(cmd.Context(), http.MethodGet, "/me/tokens", nil, &tokens); err != nil {
+				return err
+			}
+			return opts.render(cmd.OutOrStdout(), tokens, func() table { return tokenTable(tokens) })
+		},
+	}
+
+	var scopes []string
+	var expires time.Duration
+	create := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create an API token",
+		Long: `Create an API token with the scopes given, such as tasks:read or
+projects:admin. The token is printed only once; keep it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input := map[string]interface{}{"name": args[0], "scopes": scopes}
+			if expires != 0 {
+				input["expires_at"] = time.Now().Add(expires).UTC().Format(time.RFC3339)
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var created createdToken
+			if err := client.do(cmd.Context(), http.MethodPost, "/me/tokens", input, &created); err != nil {
+				return err
+			}
+			return opts.render(cmd.OutOrStdout(), created, func() table {
+				t := tokenTable([]models.APIToken{created.APIToken})
+				t.headers = append(t.headers, "TOKEN")
+				t.rows[0] = append(t.rows[0], created.Token)
+				return t
+			})
+		},
+	}
+	create.Flags().StringSliceVar(&scopes, "scope", nil, "scope to grant, as resource:level; repeat or separate with commas")
+	create.Flags().DurationVar(&expires, "expires", 0, "how long the token lasts, such as 720h (default the server's)")
+	_ = create.MarkFlagRequired("scope")
+
+	revoke := &cobra.Command{
+		Use:   "revoke ID",
+		Short: "Revoke an API token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := strconv.Atoi(args[0]); err != nil {
+				return fmt.Errorf("invalid token ID %q", args[0])
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			return client.do(cmd.Context(), http.MethodDelete, "/me/tokens/"+args[0], nil, nil)
+		},
+	}
+
+	cmd.AddCommand(list, create, revoke)
+	return cmd
+}
+
+func tokenTable(tokens []models.APIToken) table {
+	t := table{headers: []string{"ID", "NAME", "PREFIX", "SCOPES", "EXPIRES", "LAST USED"}}
+	for _, token := range tokens {
+		lastUsed := "never"
+		if token.LastUsedAt != nil {
+			lastUsed = token.LastUsedAt.Format(dates.DateLayout)
+		}
+		t.rows = append(t.rows, []string{
+			strconv.FormatUint(uint64(token.ID), 10),
+			token.Name,
+			token.Prefix,
+			strings.Join(token.Scopes, ","),
+			token.ExpiresAt.Format(dates.DateLayout),
+			lastUsed,
+		})
+	}
+	return t
+}
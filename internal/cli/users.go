@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+func newUsersCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "users",
+		Aliases: []string{"user"},
+		Short:   "Search and invite users",
+	}
+
+	var name, email string
+	search := &cobra.Command{
+		Use:   "search",
+		Short: "Search users by name or email",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			switch {
+			case name != "" && email != "":
+				return errors.New("search by either --name or --email")
+			case name != "":
+				query.Set("name", name)
+			case email != "":
+				query.Set("email", email)
+			default:
+				return errors.New("--name or --email is required")
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var users []models.User
+			if err := client.do(cmd.Context(), http.MethodGet, "/users/search?"+query.Encode(), nil, &users); err != nil {
+				return err
+			}
+			return opts.render(cmd.OutOrStdout(), users, func() table { return userTable(users) })
+		},
+	}
+	search.Flags().StringVar(&name, "name", "", "name to search for")
+	search.Flags().StringVar(&email, "email", "", "email to search for")
+
+	var role, projectRole string
+	var projectID int
+	invite := &cobra.Command{
+		Use:   "invite EMAIL",
+		Short: "Invite someone to the workspace",
+		Long:  "Invite someone to the workspace. The invitation token is delivered to the email address.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input := map[string]interface{}{"email": args[0], "role": role}
+			if projectID != 0 {
+				input["project_id"] = projectID
+				if projectRole != "" {
+					input["project_role"] = projectRole
+				}
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var invitation models.Invitation
+			if err := client.do(cmd.Context(), http.MethodPost, "/invitations/", input, &invitation); err != nil {
+				return err
+			}
+			return opts.render(cmd.OutOrStdout(), invitation, func() table { return invitationTable(invitation) })
+		},
+	}
+	invite.Flags().StringVar(&role, "role", "developer", "role of the invited user: admin, manager or developer")
+	invite.Flags().IntVar(&projectID, "project", 0, "ID of a project the invited user joins")
+	invite.Flags().StringVar(&projectRole, "project-role", "", "role in the project, contributor by default")
+
+	cmd.AddCommand(search, invite)
+	return cmd
+}
+
+func invitationTable(invitation models.Invitation) table {
+	return table{
+		headers: []string{"ID", "EMAIL", "ROLE", "EXPIRES"},
+		rows: [][]string{{
+			strconv.FormatUint(uint64(invitation.ID), 10),
+			invitation.Email,
+			invitation.Role,
+			invitation.ExpiresAt.Format(dates.DateLayout),
+		}},
+	}
+}
+
+func userTable(users []models.User) table {
+	t := table{headers: []string{"ID", "NAME", "EMAIL", "ROLE", "REGISTERED"}}
+	for _, user := range users {
+		t.rows = append(t.rows, []string{
+			strconv.FormatUint(uint64(user.ID), 10),
+			user.Name,
+			user.Email,
+			user.Role,
+			user.RegistrationDate.Format(dates.DateLayout),
+		})
+	}
+	return t
+}
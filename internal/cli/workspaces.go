@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+// userWorkspace is a workspace with the caller's role in it.
+type userWorkspace struct {
+	models.Workspace
+	Role string `json:"role"`
+}
+
+func newWorkspacesCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "workspaces",
+		Aliases: []string{"workspace"},
+		Short:   "List your workspaces",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the workspaces you are a member of; select one with --workspace",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var workspaces []userWorkspace
+			if err := client.do(cmd.Context(), http.MethodGet, "/workspaces/", nil, &workspaces); err != nil {
+				return err
+			}
+			return opts.render(cmd.OutOrStdout(), workspaces, func() table { return workspaceTable(workspaces) })
+		},
+	}
+
+	cmd.AddCommand(list)
+	return cmd
+}
+
+func workspaceTable(workspaces []userWorkspace) table {
+	t := table{headers: []string{"ID", "NAME", "SLUG", "ROLE"}}
+	for _, ws := range workspaces {
+		t.rows = append(t.rows, []string{
+			strconv.FormatUint(uint64(ws.ID), 10),
+			ws.Name,
+			ws.Slug,
+			ws.Role,
+		})
+	}
+	return t
+}
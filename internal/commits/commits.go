@@ -0,0 +1,142 @@
+// Package commits reads push webhooks and finds the task references in their
+// commit messages.
+package commits
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Push is a push event, reduced to what is common to GitHub and GitLab.
+type Push struct {
+	Repository    string
+	Ref           string
+	DefaultBranch string
+	Commits       []Commit
+}
+
+// OnDefaultBranch reports whether the push updated the repository's default
+// branch. It is true when the payload does not name a default branch.
+func (p *Push) OnDefaultBranch() bool {
+	return p.DefaultBranch == "" || p.Ref == "refs/heads/"+p.DefaultBranch
+}
+
+type Commit struct {
+	SHA         string
+	Message     string
+	URL         string
+	Author      string
+	AuthorEmail string
+	Timestamp   time.Time
+}
+
+type pushPayload struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		FullName      string `json:"full_name"`
+		Name          string `json:"name"`
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		DefaultBranch     string `json:"default_branch"`
+	} `json:"project"`
+	Commits []struct {
+		ID        string    `json:"id"`
+		Message   string    `json:"message"`
+		URL       string    `json:"url"`
+		Timestamp time.Time `json:"timestamp"`
+		Author    struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"author"`
+	} `json:"commits"`
+}
+
+// ParsePush reads a GitHub or GitLab push webhook payload.
+func ParsePush(data []byte) (*Push, error) {
+	var payload pushPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, errors.New("invalid push payload: " + err.Error())
+	}
+	if payload.Ref == "" {
+		return nil, errors.New("invalid push payload: missing ref")
+	}
+
+	push := &Push{
+		Ref:           payload.Ref,
+		Repository:    firstNonEmpty(payload.Repository.FullName, payload.Project.PathWithNamespace, payload.Repository.Name),
+		DefaultBranch: firstNonEmpty(payload.Repository.DefaultBranch, payload.Project.DefaultBranch),
+	}
+	for _, c := range payload.Commits {
+		push.Commits = append(push.Commits, Commit{
+			SHA:         c.ID,
+			Message:     c.Message,
+			URL:         c.URL,
+			Author:      c.Author.Name,
+			AuthorEmail: c.Author.Email,
+			Timestamp:   c.Timestamp,
+		})
+	}
+	return push, nil
+}
+
+// Reference is a task mentioned in a commit message.
+type Reference struct {
+	TaskID int
+	Closes bool
+}
+
+var (
+	// closingRef matches a closing keyword followed by one or more task
+	// references: "fixes #1", "Closes: #2, #3 and #4".
+	closingRef = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\b:?\s*(#\d+(?:\s*(?:,|\band\b)\s*#\d+)*)`)
+	taskRef    = regexp.MustCompile(`(?:^|[^\w&/])#(\d+)\b`)
+)
+
+// ParseReferences returns the tasks referenced in a commit message, ordered by
+// task ID. A task is closed if any of its references follows a closing
+// keyword.
+func ParseReferences(message string) []Reference {
+	closes := make(map[int]bool)
+	for _, match := range closingRef.FindAllStringSubmatch(message, -1) {
+		for _, id := range taskIDs(match[1]) {
+			closes[id] = true
+		}
+	}
+
+	seen := make(map[int]bool)
+	var refs []Reference
+	for _, id := range taskIDs(message) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		refs = append(refs, Reference{TaskID: id, Closes: closes[id]})
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].TaskID < refs[j].TaskID })
+	return refs
+}
+
+func taskIDs(text string) []int {
+	var ids []int
+	for _, match := range taskRef.FindAllStringSubmatch(text, -1) {
+		if id, err := strconv.Atoi(match[1]); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+// Package dates parses the date and time formats accepted by the API.
+package dates
+
+import "time"
+
+// DateLayout is the plain calendar date format used for start, end, created
+// and completed dates.
+const DateLayout = "2006-01-02"
+
+// ParseLocal accepts an RFC 3339 timestamp, a local "2006-01-02T15:04" time or
+// a plain date. Local values are interpreted in timezone (UTC when empty);
+// dateOnly reports whether value was a plain date.
+func ParseLocal(value, timezone string) (t time.Time, dateOnly bool, err error) {
+	loc := time.UTC
+	if timezone != "" {
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return time.Time{}, false, err
+		}
+	}
+
+	if t, err = time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err = time.ParseInLocation("2006-01-02T15:04", value, loc); err == nil {
+		return t, false, nil
+	}
+	t, err = time.ParseInLocation(DateLayout, value, loc)
+	return t, true, err
+}
+
+// ParseDue parses a due date like ParseLocal, except that a plain date means
+// the end of that day.
+func ParseDue(value, timezone string) (*time.Time, error) {
+	t, dateOnly, err := ParseLocal(value, timezone)
+	if err != nil {
+		return nil, err
+	}
+	if dateOnly {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return &t, nil
+}
+
+// ParseBefore parses an upper bound for due dates: an RFC 3339 timestamp, or
+// a plain date, which includes everything due up to the end of that day
+// (UTC).
+func ParseBefore(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,148 @@
+// Package export writes tabular data as CSV, NDJSON or XLSX, one row at a
+// time, so that large exports never have to be held in memory.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+// Writer writes rows of values matching the columns it was created with.
+// Values may be strings, integers, time.Time, *time.Time or nil.
+type Writer interface {
+	WriteRow(values []interface{}) error
+	// Close flushes buffered output and completes the file.
+	Close() error
+}
+
+// NewWriter returns a writer for the given format, which must be one of
+// FormatCSV, FormatNDJSON or FormatXLSX.
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w), columns: columns}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// ContentType returns the media type of the format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+// IsSupported reports whether format is a known export format.
+func IsSupported(format string) bool {
+	return format == FormatCSV || format == FormatNDJSON || format == FormatXLSX
+}
+
+// flushEvery is the number of rows after which buffered CSV output is pushed
+// to the client.
+const flushEvery = 500
+
+type csvWriter struct {
+	writer *csv.Writer
+	rows   int
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatText(value)
+	}
+	if err := cw.writer.Write(record); err != nil {
+		return err
+	}
+	cw.rows++
+	if cw.rows%flushEvery == 0 {
+		cw.writer.Flush()
+	}
+	return cw.writer.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+	columns []string
+}
+
+func (nw *ndjsonWriter) WriteRow(values []interface{}) error {
+	object := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		if t, ok := value.(*time.Time); ok {
+			if t == nil {
+				value = nil
+			} else {
+				value = *t
+			}
+		}
+		object[nw.columns[i]] = value
+	}
+	return nw.encoder.Encode(object)
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+// formatText renders a value for text formats: times as RFC 3339, or as a
+// plain date when they are midnight UTC.
+func formatText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.UTC().Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatText(*v)
+	}
+	return fmt.Sprint(value)
+}
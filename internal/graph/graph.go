@@ -0,0 +1,230 @@
+// Package graph is the GraphQL API over users, projects and tasks. Related
+// records are fetched through per-request loaders, so a query for a list of
+// tasks with their assignees and projects costs one query per level rather
+// than one per task.
+package graph
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/togzhanzhakhani/projects/internal/graphql"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+)
+
+// Resolver resolves the fields of the schema against the repository.
+type Resolver struct {
+	Repo   repository.GraphRepository
+	schema *graphql.Schema
+}
+
+func NewResolver(repo repository.GraphRepository) *Resolver {
+	r := &Resolver{Repo: repo}
+	schema, err := r.buildSchema()
+	if err != nil {
+		panic(err)
+	}
+	r.schema = schema
+	return r
+}
+
+// Schema returns the schema, which prints as SDL.
+func (r *Resolver) Schema() *graphql.Schema {
+	return r.schema
+}
+
+// Execute runs a parsed GraphQL request in the workspace of ctx. Error
+// messages are in the language of trans.
+func (r *Resolver) Execute(ctx context.Context, trans ut.Translator, doc *graphql.Document) *graphql.Response {
+	state := &request{ctx: ctx, repo: r.Repo.WithContext(ctx), trans: trans}
+	state.reset()
+	return doc.Execute(context.WithValue(ctx, requestKey{}, state), r.schema)
+}
+
+type requestKey struct{}
+
+// request is the state of one GraphQL request.
+type request struct {
+	ctx   context.Context
+	repo  repository.GraphRepository
+	trans ut.Translator
+
+	users             *graphql.Loader
+	projects          *graphql.Loader
+	tasks             *graphql.Loader
+	projectsByManager *graphql.Loader
+	tasksByAssignee   map[repository.TaskFilter]*graphql.Loader
+	tasksByProject    map[repository.TaskFilter]*graphql.Loader
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// reset discards the loaded records. Mutations call it after writing, so
+// the fields they select see the change.
+func (req *request) reset() {
+	req.users = graphql.NewLoader(req.loadUsers)
+	req.projects = graphql.NewLoader(req.loadProjects)
+	req.tasks = graphql.NewLoader(req.loadTasks)
+	req.projectsByManager = graphql.NewLoader(req.loadProjectsByManager)
+	req.tasksByAssignee = make(map[repository.TaskFilter]*graphql.Loader)
+	req.tasksByProject = make(map[repository.TaskFilter]*graphql.Loader)
+}
+
+func (req *request) loadUsers(ids []int) (map[int]interface{}, error) {
+	users, err := req.repo.GetUsersByIDs(ids)
+	if err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to retrieve users"))
+	}
+	values := make(map[int]interface{}, len(users))
+	for _, user := range users {
+		values[int(user.ID)] = user
+	}
+	return values, nil
+}
+
+func (req *request) loadProjects(ids []int) (map[int]interface{}, error) {
+	projects, err := req.repo.GetProjectsByIDs(ids)
+	if err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to retrieve projects"))
+	}
+	values := make(map[int]interface{}, len(projects))
+	for _, project := range projects {
+		values[project.ID] = project
+	}
+	return values, nil
+}
+
+func (req *request) loadTasks(ids []int) (map[int]interface{}, error) {
+	tasks, err := req.repo.GetTasksByIDs(ids)
+	if err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to fetch tasks"))
+	}
+	values := make(map[int]interface{}, len(tasks))
+	for _, task := range tasks {
+		values[task.ID] = task
+	}
+	return values, nil
+}
+
+func (req *request) loadProjectsByManager(managerIDs []int) (map[int]interface{}, error) {
+	projects, err := req.repo.GetProjectsByManagers(managerIDs)
+	if err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to retrieve projects"))
+	}
+	grouped := make(map[int][]models.Project)
+	for _, project := range projects {
+		grouped[project.ManagerID] = append(grouped[project.ManagerID], project)
+	}
+	values := make(map[int]interface{}, len(grouped))
+	for id, projects := range grouped {
+		values[id] = projects
+	}
+	return values, nil
+}
+
+// assigneeTaskLoader returns the loader of the tasks matching filter by
+// assignee. Each filter used in a request gets its own loader.
+func (req *request) assigneeTaskLoader(filter repository.TaskFilter) *graphql.Loader {
+	loader, ok := req.tasksByAssignee[filter]
+	if !ok {
+		loader = graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			tasks, err := req.repo.GetTasksByAssignees(ids, filter)
+			if err != nil {
+				return nil, req.fail(problem.FromError(err, "Failed to fetch tasks"))
+			}
+			return groupTasks(tasks, func(task models.Task) int { return task.AssigneeID }), nil
+		})
+		req.tasksByAssignee[filter] = loader
+	}
+	return loader
+}
+
+// projectTaskLoader returns the loader of the tasks matching filter by project.
+func (req *request) projectTaskLoader(filter repository.TaskFilter) *graphql.Loader {
+	loader, ok := req.tasksByProject[filter]
+	if !ok {
+		loader = graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			tasks, err := req.repo.GetTasksByProjects(ids, filter)
+			if err != nil {
+				return nil, req.fail(problem.FromError(err, "Failed to fetch tasks"))
+			}
+			return groupTasks(tasks, func(task models.Task) int { return task.ProjectID }), nil
+		})
+		req.tasksByProject[filter] = loader
+	}
+	return loader
+}
+
+func groupTasks(tasks []models.Task, key func(models.Task) int) map[int]interface{} {
+	grouped := make(map[int][]models.Task)
+	for _, task := range tasks {
+		grouped[key(task)] = append(grouped[key(task)], task)
+	}
+	values := make(map[int]interface{}, len(grouped))
+	for id, tasks := range grouped {
+		values[id] = tasks
+	}
+	return values
+}
+
+// fail converts a problem into the error of a field. The message is the
+// problem's detail, and the code and invalid fields are extensions.
+func (req *request) fail(p *problem.Problem) error {
+	p.Localize(req.trans)
+	if p.Status >= 500 {
+		log.Printf("graphql: %v", p)
+	}
+	extensions := map[string]interface{}{"code": p.Code}
+	if len(p.Errors) > 0 {
+		errs := make([]problem.FieldError, len(p.Errors))
+		for i, fieldError := range p.Errors {
+			fieldError.Field = camelCase(fieldError.Field)
+			errs[i] = fieldError
+		}
+		extensions["errors"] = errs
+	}
+	return &graphql.Error{Message: p.Detail, Extensions: extensions}
+}
+
+// validate runs the model's validation rules, as the REST handlers do.
+func (req *request) validate(obj interface{}) error {
+	fieldErrors, err := validation.ValidateFields(req.ctx, req.trans, obj)
+	if err != nil {
+		return req.fail(problem.FromError(err, "Failed to validate request"))
+	}
+	if len(fieldErrors) > 0 {
+		return req.fail(problem.Validation(fieldErrors))
+	}
+	return nil
+}
+
+// parseID converts an ID argument to the integer key of a resource, such as
+// "user".
+func (req *request) parseID(value interface{}, resource string) (int, error) {
+	s, _ := value.(string)
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 {
+		return 0, req.fail(problem.BadRequest("Invalid " + resource + " ID"))
+	}
+	return id, nil
+}
+
+// camelCase converts the JSON name of a field, as the validation rules
+// report it, to the name of the GraphQL field.
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
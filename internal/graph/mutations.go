@@ -0,0 +1,252 @@
+package graph
+
+import (
+	"errors"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/graphql"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// The mutations validate and write records like the REST handlers, and
+// report the same problems as errors with the problem code as extension.
+
+func (r *Resolver) createUser(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if !workspace.Administers(req.ctx) {
+		return nil, req.fail(problem.Forbidden("Only workspace admins may create users; invite them instead"))
+	}
+	var user models.User
+	setUser(&user, p.Args["input"].(map[string]interface{}))
+	if err := req.validate(&user); err != nil {
+		return nil, err
+	}
+	if err := r.Repo.CreateUser(&user); err != nil {
+		return nil, req.fail(validation.MapError(req.trans, err, "Failed to create user"))
+	}
+	req.reset()
+	return user, nil
+}
+
+func (r *Resolver) updateUser(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	record, _, err := req.existing(req.users, p.Args, "user")
+	if err != nil {
+		return nil, err
+	}
+	user := record.(models.User)
+	role, email := user.Role, user.Email
+	setUser(&user, p.Args["input"].(map[string]interface{}))
+	if err := req.validate(&user); err != nil {
+		return nil, err
+	}
+	if p := workspace.AuthorizeRoleChange(req.ctx, user.ID, role, user.Role); p != nil {
+		return nil, req.fail(p)
+	}
+	if p := workspace.AuthorizeEmailChange(email, user.Email); p != nil {
+		return nil, req.fail(p)
+	}
+	if err := r.Repo.UpdateUser(&user); err != nil {
+		return nil, req.fail(validation.MapError(req.trans, err, "Failed to update user"))
+	}
+	req.reset()
+	return user, nil
+}
+
+func setUser(user *models.User, input map[string]interface{}) {
+	user.Name = stringArg(input, "name")
+	user.Email = stringArg(input, "email")
+	user.Role = stringArg(input, "role")
+}
+
+func (r *Resolver) deleteUser(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if !workspace.Administers(req.ctx) {
+		return nil, req.fail(problem.Forbidden("Only workspace admins may delete users"))
+	}
+	_, id, err := req.existing(req.users, p.Args, "user")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Repo.DeleteUser(id); err != nil {
+		if errors.Is(err, repository.ErrLastAdmin) {
+			return nil, req.fail(problem.Conflict("A workspace needs at least one admin"))
+		}
+		return nil, req.fail(problem.FromError(err, "Failed to delete user"))
+	}
+	req.reset()
+	return true, nil
+}
+
+func (r *Resolver) createProject(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	var project models.Project
+	if err := req.setProject(&project, p.Args["input"].(map[string]interface{})); err != nil {
+		return nil, err
+	}
+	if err := req.validate(&project); err != nil {
+		return nil, err
+	}
+	if err := r.Repo.CreateProject(&project); err != nil {
+		return nil, req.fail(validation.MapError(req.trans, err, "Failed to create project"))
+	}
+	req.reset()
+	return project, nil
+}
+
+func (r *Resolver) updateProject(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	record, _, err := req.existing(req.projects, p.Args, "project")
+	if err != nil {
+		return nil, err
+	}
+	project := record.(models.Project)
+	if err := req.setProject(&project, p.Args["input"].(map[string]interface{})); err != nil {
+		return nil, err
+	}
+	if err := req.validate(&project); err != nil {
+		return nil, err
+	}
+	if err := r.Repo.UpdateProject(&project); err != nil {
+		return nil, req.fail(validation.MapError(req.trans, err, "Failed to update project"))
+	}
+	req.reset()
+	return project, nil
+}
+
+func (req *request) setProject(project *models.Project, input map[string]interface{}) error {
+	managerID, err := req.parseID(input["managerId"], "manager")
+	if err != nil {
+		return err
+	}
+	project.Name = stringArg(input, "name")
+	project.Description = stringArg(input, "description")
+	project.StartDate = input["startDate"].(time.Time)
+	project.EndDate = input["endDate"].(time.Time)
+	project.ManagerID = managerID
+	return nil
+}
+
+func (r *Resolver) deleteProject(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	_, id, err := req.existing(req.projects, p.Args, "project")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Repo.DeleteProject(id); err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to delete project"))
+	}
+	req.reset()
+	return true, nil
+}
+
+func (r *Resolver) createTask(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	var task models.Task
+	if err := req.setTask(&task, p.Args["input"].(map[string]interface{})); err != nil {
+		return nil, err
+	}
+	if err := req.checkTask(&task); err != nil {
+		return nil, err
+	}
+	if err := r.Repo.CreateTask(&task); err != nil {
+		return nil, req.fail(validation.MapError(req.trans, err, "Failed to create task"))
+	}
+	req.reset()
+	return task, nil
+}
+
+// updateTask replaces the fields of the input. Labels and the recurrence of
+// the task are kept.
+func (r *Resolver) updateTask(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	record, _, err := req.existing(req.tasks, p.Args, "task")
+	if err != nil {
+		return nil, err
+	}
+	task := record.(models.Task)
+	if err := req.setTask(&task, p.Args["input"].(map[string]interface{})); err != nil {
+		return nil, err
+	}
+	if err := req.checkTask(&task); err != nil {
+		return nil, err
+	}
+	if err := r.Repo.UpdateTask(&task); err != nil {
+		return nil, req.fail(validation.MapError(req.trans, err, "Failed to update task"))
+	}
+	req.reset()
+	return task, nil
+}
+
+func (req *request) setTask(task *models.Task, input map[string]interface{}) error {
+	assigneeID, err := req.parseID(input["assigneeId"], "assignee")
+	if err != nil {
+		return err
+	}
+	projectID, err := req.parseID(input["projectId"], "project")
+	if err != nil {
+		return err
+	}
+	var dueDate *time.Time
+	if value := stringArg(input, "dueDate"); value != "" {
+		dueDate, err = dates.ParseDue(value, stringArg(input, "dueTimezone"))
+		if err != nil {
+			return req.fail(problem.BadRequest("Invalid dueDate format"))
+		}
+	}
+
+	task.Title = stringArg(input, "title")
+	task.Description = stringArg(input, "description")
+	task.Priority = stringArg(input, "priority")
+	task.Status = stringArg(input, "status")
+	task.AssigneeID = assigneeID
+	task.ProjectID = projectID
+	task.CreatedAt = input["createdAt"].(time.Time)
+	task.CompletedAt = input["completedAt"].(time.Time)
+	task.DueDate = dueDate
+	task.DueTimezone = stringArg(input, "dueTimezone")
+	return nil
+}
+
+// checkTask validates the task and checks that it is due by the end of its
+// project.
+func (req *request) checkTask(task *models.Task) error {
+	if err := req.validate(task); err != nil {
+		return err
+	}
+	if task.DueDate == nil {
+		return nil
+	}
+	record, err := req.projects.Load(task.ProjectID)()
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return req.fail(problem.Lookup(gorm.ErrRecordNotFound, "project"))
+	}
+	project := record.(models.Project)
+	// The project end date is a calendar day, so anything due on that day is still in time.
+	if !task.DueDate.Before(project.EndDate.AddDate(0, 0, 1)) {
+		return req.fail(problem.BadRequest("Due date must not be after the project end date"))
+	}
+	return nil
+}
+
+func (r *Resolver) deleteTask(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	_, id, err := req.existing(req.tasks, p.Args, "task")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Repo.DeleteTask(id); err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to delete task"))
+	}
+	req.reset()
+	return true, nil
+}
@@ -0,0 +1,234 @@
+package graph
+
+import (
+	"github.com/togzhanzhakhani/projects/internal/graphql"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"gorm.io/gorm"
+)
+
+func stringArg(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// idArg reads an optional ID argument, 0 when it is not given.
+func (req *request) idArg(args map[string]interface{}, name, resource string) (int, error) {
+	if _, ok := args[name]; !ok {
+		return 0, nil
+	}
+	return req.parseID(args[name], resource)
+}
+
+func (req *request) page(args map[string]interface{}) (repository.Page, error) {
+	limit, _ := args["limit"].(int)
+	offset, _ := args["offset"].(int)
+	if limit < 0 || offset < 0 {
+		return repository.Page{}, req.fail(problem.BadRequest("limit and offset must not be negative"))
+	}
+	return repository.Page{Limit: limit, Offset: offset}, nil
+}
+
+// window returns the bounds of page within a list of n items.
+func window(n int, page repository.Page) (int, int) {
+	from, to := page.Offset, n
+	if from > n {
+		from = n
+	}
+	if page.Limit > 0 && from+page.Limit < n {
+		to = from + page.Limit
+	}
+	return from, to
+}
+
+// pagedTasks applies page to the tasks a loader loads for one parent.
+func pagedTasks(thunk graphql.Thunk, page repository.Page) graphql.Thunk {
+	return func() (interface{}, error) {
+		value, err := thunk()
+		tasks, _ := value.([]models.Task)
+		from, to := window(len(tasks), page)
+		return tasks[from:to], err
+	}
+}
+
+func pagedProjects(thunk graphql.Thunk, page repository.Page) graphql.Thunk {
+	return func() (interface{}, error) {
+		value, err := thunk()
+		projects, _ := value.([]models.Project)
+		from, to := window(len(projects), page)
+		return projects[from:to], err
+	}
+}
+
+func (r *Resolver) user(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	id, err := req.parseID(p.Args["id"], "user")
+	if err != nil {
+		return nil, err
+	}
+	return req.users.Load(id), nil
+}
+
+func (r *Resolver) users(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	page, err := req.page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	filter := repository.UserFilter{
+		Name:  stringArg(p.Args, "name"),
+		Email: stringArg(p.Args, "email"),
+		Role:  stringArg(p.Args, "role"),
+	}
+	users, err := r.Repo.FindUsers(filter, page)
+	if err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to retrieve users"))
+	}
+	return users, nil
+}
+
+func (r *Resolver) project(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	id, err := req.parseID(p.Args["id"], "project")
+	if err != nil {
+		return nil, err
+	}
+	return req.projects.Load(id), nil
+}
+
+func (r *Resolver) projects(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	page, err := req.page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	managerID, err := req.idArg(p.Args, "managerId", "manager")
+	if err != nil {
+		return nil, err
+	}
+	projects, err := r.Repo.FindProjects(repository.ProjectFilter{Name: stringArg(p.Args, "name"), ManagerID: managerID}, page)
+	if err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to retrieve projects"))
+	}
+	return projects, nil
+}
+
+func (r *Resolver) task(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	id, err := req.parseID(p.Args["id"], "task")
+	if err != nil {
+		return nil, err
+	}
+	return req.tasks.Load(id), nil
+}
+
+func (r *Resolver) tasks(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	page, err := req.page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := req.taskFilter(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := r.Repo.FindTasks(filter, page)
+	if err != nil {
+		return nil, req.fail(problem.FromError(err, "Failed to fetch tasks"))
+	}
+	return tasks, nil
+}
+
+func (req *request) taskFilter(args map[string]interface{}) (repository.TaskFilter, error) {
+	filter := repository.TaskFilter{
+		Title:    stringArg(args, "title"),
+		Status:   stringArg(args, "status"),
+		Priority: stringArg(args, "priority"),
+	}
+	assigneeID, err := req.idArg(args, "assigneeId", "assignee")
+	if err != nil {
+		return filter, err
+	}
+	projectID, err := req.idArg(args, "projectId", "project")
+	if err != nil {
+		return filter, err
+	}
+	filter.AssigneeID = uint(assigneeID)
+	filter.ProjectID = uint(projectID)
+	return filter, nil
+}
+
+func (r *Resolver) userTasks(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	page, err := req.page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := req.taskFilter(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	user := p.Source.(models.User)
+	return pagedTasks(req.assigneeTaskLoader(filter).Load(int(user.ID)), page), nil
+}
+
+func (r *Resolver) managedProjects(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	page, err := req.page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	user := p.Source.(models.User)
+	return pagedProjects(req.projectsByManager.Load(int(user.ID)), page), nil
+}
+
+func (r *Resolver) projectManager(p graphql.ResolveParams) (interface{}, error) {
+	return requestFrom(p.Context).users.Load(p.Source.(models.Project).ManagerID), nil
+}
+
+func (r *Resolver) projectTasks(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	page, err := req.page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := req.taskFilter(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	project := p.Source.(models.Project)
+	return pagedTasks(req.projectTaskLoader(filter).Load(project.ID), page), nil
+}
+
+func (r *Resolver) taskAssignee(p graphql.ResolveParams) (interface{}, error) {
+	return requestFrom(p.Context).users.Load(p.Source.(models.Task).AssigneeID), nil
+}
+
+func (r *Resolver) taskProject(p graphql.ResolveParams) (interface{}, error) {
+	return requestFrom(p.Context).projects.Load(p.Source.(models.Task).ProjectID), nil
+}
+
+func (r *Resolver) dueTimezone(p graphql.ResolveParams) (interface{}, error) {
+	if timezone := p.Source.(models.Task).DueTimezone; timezone != "" {
+		return timezone, nil
+	}
+	return nil, nil
+}
+
+// existing loads the record with the ID argument through loader, failing
+// with "<Resource> not found" if there is none.
+func (req *request) existing(loader *graphql.Loader, args map[string]interface{}, resource string) (interface{}, int, error) {
+	id, err := req.parseID(args["id"], resource)
+	if err != nil {
+		return nil, 0, err
+	}
+	record, err := loader.Load(id)()
+	if err != nil {
+		return nil, 0, err
+	}
+	if record == nil {
+		return nil, 0, req.fail(problem.Lookup(gorm.ErrRecordNotFound, resource))
+	}
+	return record, id, nil
+}
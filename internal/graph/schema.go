@@ -0,0 +1,217 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/graphql"
+)
+
+// Date is a calendar day, the type of the dates the REST API takes as
+// 2006-01-02.
+var Date = &graphql.Scalar{
+	Name:        "Date",
+	Description: "A calendar day, as 2006-01-02.",
+	Serialize: func(value interface{}) (interface{}, error) {
+		if t, ok := timeValue(value); ok {
+			return t.Format("2006-01-02"), nil
+		}
+		return nil, fmt.Errorf("Date cannot represent value: %v", value)
+	},
+	ParseValue: func(value interface{}) (interface{}, error) {
+		s, _ := value.(string)
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, fmt.Errorf("Date must be written as 2006-01-02: %v", value)
+		}
+		return t, nil
+	},
+}
+
+// DateTime is an RFC 3339 timestamp.
+var DateTime = &graphql.Scalar{
+	Name:        "DateTime",
+	Description: "An RFC 3339 timestamp.",
+	Serialize: func(value interface{}) (interface{}, error) {
+		if t, ok := timeValue(value); ok {
+			return t.Format(time.RFC3339), nil
+		}
+		return nil, fmt.Errorf("DateTime cannot represent value: %v", value)
+	},
+	ParseValue: func(value interface{}) (interface{}, error) {
+		s, _ := value.(string)
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("DateTime must be an RFC 3339 timestamp: %v", value)
+		}
+		return t, nil
+	},
+}
+
+func timeValue(value interface{}) (time.Time, bool) {
+	switch t := value.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		return *t, t != nil
+	}
+	return time.Time{}, false
+}
+
+func enum(name string, values ...string) *graphql.Enum {
+	e := &graphql.Enum{Name: name}
+	for _, value := range values {
+		e.Values = append(e.Values, &graphql.EnumValue{Name: value})
+	}
+	return e
+}
+
+var (
+	role     = enum("Role", "admin", "manager", "developer")
+	priority = enum("Priority", "low", "medium", "high")
+	status   = enum("Status", "todo", "in_progress", "done")
+)
+
+func required(t graphql.Type) graphql.Type {
+	return graphql.NonNull(t)
+}
+
+func listOf(t graphql.Type) graphql.Type {
+	return graphql.NonNull(graphql.List(graphql.NonNull(t)))
+}
+
+// pageArgs are the pagination arguments of every list.
+var pageArgs = []*graphql.Argument{
+	{Name: "limit", Type: graphql.Int, Description: "Return at most this many items."},
+	{Name: "offset", Type: graphql.Int, Description: "Skip this many items."},
+}
+
+func withPage(args ...*graphql.Argument) []*graphql.Argument {
+	return append(args, pageArgs...)
+}
+
+func (r *Resolver) buildSchema() (*graphql.Schema, error) {
+	user := &graphql.Object{Name: "User"}
+	project := &graphql.Object{Name: "Project"}
+	task := &graphql.Object{Name: "Task"}
+
+	user.Fields = []*graphql.Field{
+		{Name: "id", Type: required(graphql.ID)},
+		{Name: "name", Type: required(graphql.String)},
+		{Name: "email", Type: required(graphql.String)},
+		{Name: "role", Type: required(role)},
+		{Name: "registrationDate", Type: required(DateTime)},
+		{Name: "tasks", Type: listOf(task), Description: "Tasks assigned to the user.", Resolve: r.userTasks,
+			Args: withPage(
+				&graphql.Argument{Name: "status", Type: status},
+				&graphql.Argument{Name: "priority", Type: priority},
+			)},
+		{Name: "managedProjects", Type: listOf(project), Description: "Projects the user manages.", Resolve: r.managedProjects,
+			Args: withPage()},
+	}
+	project.Fields = []*graphql.Field{
+		{Name: "id", Type: required(graphql.ID)},
+		{Name: "name", Type: required(graphql.String)},
+		{Name: "description", Type: required(graphql.String)},
+		{Name: "startDate", Type: required(Date)},
+		{Name: "endDate", Type: required(Date)},
+		{Name: "managerId", Type: required(graphql.ID)},
+		{Name: "manager", Type: user, Resolve: r.projectManager},
+		{Name: "tasks", Type: listOf(task), Resolve: r.projectTasks,
+			Args: withPage(
+				&graphql.Argument{Name: "status", Type: status},
+				&graphql.Argument{Name: "priority", Type: priority},
+				&graphql.Argument{Name: "assigneeId", Type: graphql.ID},
+			)},
+	}
+	task.Fields = []*graphql.Field{
+		{Name: "id", Type: required(graphql.ID)},
+		{Name: "title", Type: required(graphql.String)},
+		{Name: "description", Type: required(graphql.String)},
+		{Name: "priority", Type: required(priority)},
+		{Name: "status", Type: required(status)},
+		{Name: "assigneeId", Type: required(graphql.ID)},
+		{Name: "projectId", Type: required(graphql.ID)},
+		{Name: "createdAt", Type: required(Date)},
+		{Name: "completedAt", Type: required(Date)},
+		{Name: "dueDate", Type: DateTime},
+		{Name: "dueTimezone", Type: graphql.String, Resolve: r.dueTimezone},
+		{Name: "labels", Type: listOf(graphql.String)},
+		{Name: "assignee", Type: user, Resolve: r.taskAssignee},
+		{Name: "project", Type: project, Resolve: r.taskProject},
+	}
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{Name: "user", Type: user, Resolve: r.user,
+			Args: []*graphql.Argument{{Name: "id", Type: required(graphql.ID)}}},
+		{Name: "users", Type: listOf(user), Resolve: r.users,
+			Description: "Users ordered by ID. name and email match substrings.",
+			Args: withPage(
+				&graphql.Argument{Name: "name", Type: graphql.String},
+				&graphql.Argument{Name: "email", Type: graphql.String},
+				&graphql.Argument{Name: "role", Type: role},
+			)},
+		{Name: "project", Type: project, Resolve: r.project,
+			Args: []*graphql.Argument{{Name: "id", Type: required(graphql.ID)}}},
+		{Name: "projects", Type: listOf(project), Resolve: r.projects,
+			Description: "Projects ordered by ID. name matches substrings.",
+			Args: withPage(
+				&graphql.Argument{Name: "name", Type: graphql.String},
+				&graphql.Argument{Name: "managerId", Type: graphql.ID},
+			)},
+		{Name: "task", Type: task, Resolve: r.task,
+			Args: []*graphql.Argument{{Name: "id", Type: required(graphql.ID)}}},
+		{Name: "tasks", Type: listOf(task), Resolve: r.tasks,
+			Description: "Tasks ordered by ID. title matches substrings.",
+			Args: withPage(
+				&graphql.Argument{Name: "title", Type: graphql.String},
+				&graphql.Argument{Name: "status", Type: status},
+				&graphql.Argument{Name: "priority", Type: priority},
+				&graphql.Argument{Name: "assigneeId", Type: graphql.ID},
+				&graphql.Argument{Name: "projectId", Type: graphql.ID},
+			)},
+	}}
+
+	userInput := &graphql.InputObject{Name: "UserInput", Fields: []*graphql.Argument{
+		{Name: "name", Type: required(graphql.String)},
+		{Name: "email", Type: required(graphql.String)},
+		{Name: "role", Type: required(role)},
+	}}
+	projectInput := &graphql.InputObject{Name: "ProjectInput", Fields: []*graphql.Argument{
+		{Name: "name", Type: required(graphql.String)},
+		{Name: "description", Type: required(graphql.String)},
+		{Name: "startDate", Type: required(Date)},
+		{Name: "endDate", Type: required(Date)},
+		{Name: "managerId", Type: required(graphql.ID)},
+	}}
+	taskInput := &graphql.InputObject{Name: "TaskInput", Fields: []*graphql.Argument{
+		{Name: "title", Type: required(graphql.String)},
+		{Name: "description", Type: required(graphql.String)},
+		{Name: "priority", Type: required(priority)},
+		{Name: "status", Type: required(status)},
+		{Name: "assigneeId", Type: required(graphql.ID)},
+		{Name: "projectId", Type: required(graphql.ID)},
+		{Name: "createdAt", Type: required(Date)},
+		{Name: "completedAt", Type: required(Date)},
+		{Name: "dueDate", Type: graphql.String, Description: "A date (2006-01-02), local time or RFC 3339 timestamp."},
+		{Name: "dueTimezone", Type: graphql.String, Description: "IANA time zone of a due date without an offset."},
+	}}
+
+	id := &graphql.Argument{Name: "id", Type: required(graphql.ID)}
+	input := func(t *graphql.InputObject) *graphql.Argument {
+		return &graphql.Argument{Name: "input", Type: required(t)}
+	}
+	mutation := &graphql.Object{Name: "Mutation", Fields: []*graphql.Field{
+		{Name: "createUser", Type: required(user), Resolve: r.createUser, Args: []*graphql.Argument{input(userInput)}},
+		{Name: "updateUser", Type: required(user), Resolve: r.updateUser, Args: []*graphql.Argument{id, input(userInput)}},
+		{Name: "deleteUser", Type: required(graphql.Boolean), Resolve: r.deleteUser, Args: []*graphql.Argument{id}},
+		{Name: "createProject", Type: required(project), Resolve: r.createProject, Args: []*graphql.Argument{input(projectInput)}},
+		{Name: "updateProject", Type: required(project), Resolve: r.updateProject, Args: []*graphql.Argument{id, input(projectInput)}},
+		{Name: "deleteProject", Type: required(graphql.Boolean), Resolve: r.deleteProject, Args: []*graphql.Argument{id}},
+		{Name: "createTask", Type: required(task), Resolve: r.createTask, Args: []*graphql.Argument{input(taskInput)}},
+		{Name: "updateTask", Type: required(task), Resolve: r.updateTask, Args: []*graphql.Argument{id, input(taskInput)}},
+		{Name: "deleteTask", Type: required(graphql.Boolean), Resolve: r.deleteTask, Args: []*graphql.Argument{id}},
+	}}
+
+	return graphql.NewSchema(query, mutation)
+}
@@ -0,0 +1,371 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+type executor struct {
+	ctx       context.Context
+	schema    *Schema
+	fragments map[string]*fragment
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// node is an object or list in the response. Fields are written to their
+// slots as they complete, level by level, so a null that propagates from a
+// non-null field may reach an object whose other fields are already set:
+// the node is then marked null instead.
+type node struct {
+	parent *node
+	// nullable tells whether the node's slot in its parent may be null.
+	nullable bool
+	null     bool
+	list     bool
+	keys     []string
+	values   []interface{}
+}
+
+// fail replaces the node with null because one of its non-null values is
+// null, propagating to the parent when the node is non-null itself.
+func (n *node) fail() {
+	for ; n != nil; n = n.parent {
+		n.null = true
+		if n.nullable {
+			return
+		}
+	}
+}
+
+func (n *node) MarshalJSON() ([]byte, error) {
+	if n.null {
+		return []byte("null"), nil
+	}
+	var b bytes.Buffer
+	if n.list {
+		b.WriteByte('[')
+	} else {
+		b.WriteByte('{')
+	}
+	for i, value := range n.values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if !n.list {
+			key, _ := json.Marshal(n.keys[i])
+			b.Write(key)
+			b.WriteByte(':')
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(data)
+	}
+	if n.list {
+		b.WriteByte(']')
+	} else {
+		b.WriteByte('}')
+	}
+	return b.Bytes(), nil
+}
+
+// collectedField is the fields of a selection set sharing a response key,
+// whose sub-selections are merged.
+type collectedField struct {
+	key    string
+	def    *Field
+	fields []*field
+}
+
+func (f *collectedField) selections() []selection {
+	var selections []selection
+	for _, field := range f.fields {
+		selections = append(selections, field.selections...)
+	}
+	return selections
+}
+
+// pending is an object whose fields are yet to be executed.
+type pending struct {
+	node   *node
+	source interface{}
+	path   []interface{}
+}
+
+func (e *executor) execute(op *operation) interface{} {
+	root := e.schema.query
+	if op.kind == "mutation" {
+		root = e.schema.mutation
+	}
+	data := &node{nullable: true}
+	items := []pending{{node: data}}
+	fields := e.collectFields(root, op.selections, nil, make(map[string]bool))
+	if op.kind == "mutation" {
+		// Mutations run one after the other, each with its sub-selections.
+		for _, f := range fields {
+			e.executeFields(root, []*collectedField{f}, items)
+		}
+	} else {
+		e.executeFields(root, fields, items)
+	}
+	if data.null {
+		return nil
+	}
+	return data
+}
+
+// collectFields flattens fragments and drops skipped selections, grouping
+// the fields by response key in the order they first appear.
+func (e *executor) collectFields(obj *Object, selections []selection, fields []*collectedField, visited map[string]bool) []*collectedField {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if e.skipped(sel.directives) {
+				continue
+			}
+			key := sel.key()
+			var group *collectedField
+			for _, f := range fields {
+				if f.key == key {
+					group = f
+					break
+				}
+			}
+			if group == nil {
+				group = &collectedField{key: key, def: obj.field(sel.name)}
+				if sel.name == typenameField.Name {
+					group.def = typenameField
+				}
+				fields = append(fields, group)
+			}
+			group.fields = append(group.fields, sel)
+		case *inlineFragment:
+			if e.skipped(sel.directives) || sel.typeCondition != "" && sel.typeCondition != obj.Name {
+				continue
+			}
+			fields = e.collectFields(obj, sel.selections, fields, visited)
+		case *fragmentSpread:
+			if e.skipped(sel.directives) || visited[sel.name] {
+				continue
+			}
+			visited[sel.name] = true
+			frag := e.fragments[sel.name]
+			if frag.typeCondition != obj.Name {
+				continue
+			}
+			fields = e.collectFields(obj, frag.selections, fields, visited)
+		}
+	}
+	return fields
+}
+
+func (e *executor) skipped(directives []*directive) bool {
+	for _, d := range directives {
+		args, err := e.argumentValues(directiveArgs[d.name], d.arguments)
+		if err != nil {
+			continue
+		}
+		if d.name == "skip" && args["if"] == true || d.name == "include" && args["if"] == false {
+			return true
+		}
+	}
+	return false
+}
+
+// typenameField is the meta field naming the type of an object.
+var typenameField = &Field{Name: "__typename", Type: NonNull(String)}
+
+// resolved is the value of one field of one object, before completion.
+type resolved struct {
+	item  pending
+	field *collectedField
+	index int
+	value interface{}
+	err   error
+}
+
+// executeFields resolves the fields for every object in items, then forces
+// the thunks they returned, and completes the values. The objects in the
+// values are executed together, per field, at the next level.
+func (e *executor) executeFields(obj *Object, fields []*collectedField, items []pending) {
+	var results []*resolved
+	for _, item := range items {
+		if item.node.null {
+			continue
+		}
+		for _, f := range fields {
+			r := &resolved{item: item, field: f, index: len(item.node.keys)}
+			item.node.keys = append(item.node.keys, f.key)
+			item.node.values = append(item.node.values, nil)
+			r.value, r.err = e.resolve(obj, f, item.source)
+			results = append(results, r)
+		}
+	}
+
+	for _, r := range results {
+		for r.err == nil {
+			thunk, ok := r.value.(Thunk)
+			if !ok {
+				break
+			}
+			r.value, r.err = thunk()
+		}
+	}
+
+	children := make([][]pending, len(fields))
+	for _, r := range results {
+		path := appendPath(r.item.path, r.field.key)
+		if r.err != nil {
+			e.fieldError(r.field, path, r.err)
+			if _, required := r.field.def.Type.(*nonNull); required {
+				r.item.node.fail()
+			}
+			continue
+		}
+		var batch []pending
+		e.complete(r.field, r.field.def.Type, r.value, r.item.node, r.index, path, &batch)
+		for i, f := range fields {
+			if f == r.field {
+				children[i] = append(children[i], batch...)
+			}
+		}
+	}
+
+	for i, f := range fields {
+		if len(children[i]) > 0 {
+			child := named(f.def.Type).(*Object)
+			e.executeFields(child, e.collectFields(child, f.selections(), nil, make(map[string]bool)), children[i])
+		}
+	}
+}
+
+func (e *executor) resolve(obj *Object, f *collectedField, source interface{}) (interface{}, error) {
+	if f.def == typenameField {
+		return obj.Name, nil
+	}
+	args, err := e.argumentValues(f.def.Args, f.fields[0].arguments)
+	if err != nil {
+		return nil, err
+	}
+	if f.def.Resolve == nil {
+		return defaultResolve(source, f.def.Name), nil
+	}
+	return f.def.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+}
+
+// complete stores the value of a field, or list item, in slot index of
+// parent. Objects are added to batch to be executed at the next level.
+func (e *executor) complete(f *collectedField, t Type, value interface{}, parent *node, index int, path []interface{}, batch *[]pending) {
+	nn, required := t.(*nonNull)
+	if required {
+		t = nn.ofType
+	}
+	// A nil slice is an empty list rather than null.
+	if isNil(value) && reflect.ValueOf(value).Kind() != reflect.Slice {
+		if required {
+			e.fieldError(f, path, &Error{Message: "Cannot return null for non-nullable field."})
+			parent.fail()
+		}
+		return
+	}
+
+	switch t := t.(type) {
+	case *list:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			e.fieldError(f, path, &Error{Message: "Expected a list for field of type " + t.String() + "."})
+			if required {
+				parent.fail()
+			}
+			return
+		}
+		child := &node{parent: parent, nullable: !required, list: true, values: make([]interface{}, items.Len())}
+		parent.values[index] = child
+		for i := 0; i < items.Len(); i++ {
+			e.complete(f, t.ofType, items.Index(i).Interface(), child, i, appendPath(path, i), batch)
+		}
+	case *Object:
+		child := &node{parent: parent, nullable: !required}
+		parent.values[index] = child
+		*batch = append(*batch, pending{node: child, source: value, path: path})
+	default:
+		serialized, err := serialize(t, value)
+		if err != nil {
+			e.fieldError(f, path, err)
+			if required {
+				parent.fail()
+			}
+			return
+		}
+		parent.values[index] = serialized
+	}
+}
+
+func serialize(t Type, value interface{}) (interface{}, error) {
+	switch t := t.(type) {
+	case *Enum:
+		if v := reflect.ValueOf(value); v.Kind() == reflect.String && t.has(v.String()) {
+			return v.String(), nil
+		}
+		return nil, &Error{Message: "Enum \"" + t.Name + "\" cannot represent value: " + inputString(value)}
+	case *Scalar:
+		return t.Serialize(value)
+	}
+	return nil, &Error{Message: "Cannot serialize value of type " + t.String() + "."}
+}
+
+func (e *executor) fieldError(f *collectedField, path []interface{}, err error) {
+	gqlErr := &Error{Message: err.Error()}
+	if original, ok := err.(*Error); ok {
+		gqlErr.Message = original.Message
+		gqlErr.Extensions = original.Extensions
+	}
+	gqlErr.Locations = []Location{f.fields[0].loc}
+	gqlErr.Path = path
+	e.errors = append(e.errors, gqlErr)
+}
+
+// defaultResolve reads a field from a map or struct source.
+func defaultResolve(source interface{}, name string) interface{} {
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if value := v.MapIndex(reflect.ValueOf(name)); value.IsValid() {
+			return value.Interface()
+		}
+	case reflect.Struct:
+		if value := v.FieldByNameFunc(func(field string) bool { return strings.EqualFold(field, name) }); value.IsValid() {
+			return value.Interface()
+		}
+	}
+	return nil
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+func appendPath(path []interface{}, key interface{}) []interface{} {
+	next := make([]interface{}, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, key)
+}
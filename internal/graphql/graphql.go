@@ -0,0 +1,170 @@
+// Package graphql executes GraphQL requests against a schema defined in Go.
+//
+// It implements the parts of the specification the API uses: queries and
+// mutations with variables, aliases, fragments and the @skip and @include
+// directives. Introspection is limited to __typename; the schema is
+// published as SDL by Schema.String instead.
+//
+// Fields are executed breadth first: a field is resolved for every object at
+// one level of the response before any of their children. A resolver may
+// return a Thunk instead of a value, and thunks are only forced once the
+// whole level has been resolved, so a Loader can fetch the values requested
+// by all of them with one query.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Request is the body of a GraphQL HTTP request.
+type Request struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is only present once execution
+// has started; requests that fail to parse or validate only have Errors.
+type Response struct {
+	Data   interface{}
+	Errors []*Error
+
+	executed bool
+}
+
+func (r *Response) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{}, 2)
+	if len(r.Errors) > 0 {
+		body["errors"] = r.Errors
+	}
+	if r.executed {
+		body["data"] = r.Data
+	}
+	return json.Marshal(body)
+}
+
+// Location is a position in the request document. Line and column start at 1.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a GraphQL error. Resolvers return one to add extensions, such as
+// a machine-readable code, to the error reported for their field; any other
+// error is reported with its Error text.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(loc Location, format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}}
+}
+
+func syntaxError(loc Location, format string, args ...interface{}) *Error {
+	return newError(loc, "Syntax Error: "+format, args...)
+}
+
+// Limits on requests, so that a single request cannot cost the server more
+// than a page of the API does: the query may have at most MaxQuerySize
+// bytes, and its fields may nest at most MaxDepth levels deep, counting the
+// fields of the fragments they spread.
+const (
+	MaxQuerySize = 16 << 10
+	MaxDepth     = 10
+)
+
+// Document is a parsed request, ready to be executed. Parsing it first lets
+// callers look at its operation without parsing the query twice.
+type Document struct {
+	req Request
+	doc *document
+	op  *operation
+	// err is the error parsing the query or picking its operation, which
+	// the response reports.
+	err *Error
+}
+
+// Parse parses the query of a request and picks the operation it runs.
+// Errors are reported when the document is executed.
+func Parse(req Request) *Document {
+	d := &Document{req: req}
+	if len(req.Query) > MaxQuerySize {
+		d.err = &Error{Message: fmt.Sprintf("Query is longer than %d bytes.", MaxQuerySize)}
+		return d
+	}
+	doc, err := parse(req.Query)
+	if err != nil {
+		d.err = err.(*Error)
+		return d
+	}
+	d.doc = doc
+	if d.op, err = doc.operation(req.OperationName); err != nil {
+		d.err = err.(*Error)
+	}
+	return d
+}
+
+// Kind returns whether the operation of the document is a query, mutation
+// or subscription. Documents that fail to parse report their errors when
+// executed.
+func (d *Document) Kind() (string, bool) {
+	if d.op == nil {
+		return "", false
+	}
+	return d.op.kind, true
+}
+
+// Execute validates and runs the document.
+func (d *Document) Execute(ctx context.Context, schema *Schema) *Response {
+	if d.doc == nil {
+		return &Response{Errors: []*Error{d.err}}
+	}
+	if errs := validate(schema, d.doc); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	if d.err != nil {
+		return &Response{Errors: []*Error{d.err}}
+	}
+
+	e := &executor{ctx: ctx, schema: schema, fragments: d.doc.fragments}
+	if errs := e.coerceVariables(d.op.variables, d.req.Variables); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	data := e.execute(d.op)
+	return &Response{Data: data, Errors: e.errors, executed: true}
+}
+
+// Execute parses, validates and runs a request.
+func Execute(ctx context.Context, schema *Schema, req Request) *Response {
+	return Parse(req).Execute(ctx, schema)
+}
+
+// operation picks the operation to run: the one named, or the only one.
+func (doc *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", name)}
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
@@ -0,0 +1,65 @@
+package graphql
+
+// BatchFunc loads the values of keys at once. Keys missing from the result
+// load as nil.
+type BatchFunc func(keys []int) (map[int]interface{}, error)
+
+// Loader batches and caches loads by key for one request. Load only queues
+// the key; the first of the returned thunks to be forced loads every key
+// queued until then with one call to the batch function. Resolvers that
+// return the thunk for a field therefore share one call per level of the
+// response.
+//
+// A Loader is not safe for concurrent use, which the executor does not need.
+type Loader struct {
+	batch  BatchFunc
+	queue  []int
+	queued map[int]bool
+	values map[int]interface{}
+	errs   map[int]error
+}
+
+func NewLoader(batch BatchFunc) *Loader {
+	return &Loader{
+		batch:  batch,
+		queued: make(map[int]bool),
+		values: make(map[int]interface{}),
+		errs:   make(map[int]error),
+	}
+}
+
+// Load returns a thunk for the value of key.
+func (l *Loader) Load(key int) Thunk {
+	if _, loaded := l.values[key]; !loaded && l.errs[key] == nil && !l.queued[key] {
+		l.queued[key] = true
+		l.queue = append(l.queue, key)
+	}
+	return func() (interface{}, error) {
+		if l.queued[key] {
+			l.dispatch()
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+// Prime stores a value that is already known, such as a record just written.
+func (l *Loader) Prime(key int, value interface{}) {
+	delete(l.errs, key)
+	l.values[key] = value
+}
+
+func (l *Loader) dispatch() {
+	keys := l.queue
+	l.queue = nil
+	for _, key := range keys {
+		delete(l.queued, key)
+	}
+	values, err := l.batch(keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.values[key] = values[key]
+	}
+}
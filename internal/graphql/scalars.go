@@ -0,0 +1,165 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// enumLiteral is an unquoted name written in the document, which only an
+// enum accepts. Variables carry enum values as strings.
+type enumLiteral string
+
+// The built-in scalars.
+var (
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "A signed 32-bit integer.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			n, ok := toInt(value)
+			if !ok || n < math.MinInt32 || n > math.MaxInt32 {
+				return nil, fmt.Errorf("Int cannot represent value: %v", value)
+			}
+			return n, nil
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			n, ok := inputInt(value)
+			if !ok || n < math.MinInt32 || n > math.MaxInt32 {
+				return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %s", inputString(value))
+			}
+			return int(n), nil
+		},
+	}
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "A double-precision floating-point number.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			v := reflect.ValueOf(value)
+			switch v.Kind() {
+			case reflect.Float32, reflect.Float64:
+				return v.Float(), nil
+			}
+			if n, ok := toInt(value); ok {
+				return float64(n), nil
+			}
+			return nil, fmt.Errorf("Float cannot represent value: %v", value)
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case json.Number:
+				if f, err := v.Float64(); err == nil {
+					return f, nil
+				}
+			case float64:
+				return v, nil
+			}
+			return nil, fmt.Errorf("Float cannot represent non numeric value: %s", inputString(value))
+		},
+	}
+	String = &Scalar{
+		Name:        "String",
+		Description: "A UTF-8 string.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+				return v.String(), nil
+			}
+			return nil, fmt.Errorf("String cannot represent value: %v", value)
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+			return nil, fmt.Errorf("String cannot represent a non string value: %s", inputString(value))
+		},
+	}
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "true or false.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if v := reflect.ValueOf(value); v.Kind() == reflect.Bool {
+				return v.Bool(), nil
+			}
+			return nil, fmt.Errorf("Boolean cannot represent value: %v", value)
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %s", inputString(value))
+		},
+	}
+	// ID is serialized as a string. It accepts strings and integers and
+	// passes them to resolvers as strings.
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "A unique identifier, serialized as a string.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+				return v.String(), nil
+			}
+			if n, ok := toInt(value); ok {
+				return strconv.FormatInt(n, 10), nil
+			}
+			return nil, fmt.Errorf("ID cannot represent value: %v", value)
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+			if n, ok := inputInt(value); ok {
+				return strconv.FormatInt(n, 10), nil
+			}
+			return nil, fmt.Errorf("ID cannot represent value: %s", inputString(value))
+		},
+	}
+)
+
+// toInt converts a resolved value of any integer kind.
+func toInt(value interface{}) (int64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v.Uint()), true
+	}
+	return 0, false
+}
+
+// inputInt converts an integer input value: a literal, or a JSON number
+// decoded with or without UseNumber.
+func inputInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := strconv.ParseInt(string(v), 10, 64)
+		return n, err == nil
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return toInt(value)
+}
+
+// inputString prints an input value for error messages.
+func inputString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return quote(v)
+	case enumLiteral:
+		return string(v)
+	case nil:
+		return "null"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,367 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Type is a GraphQL type: a *Scalar, *Enum, *Object or *InputObject, or a
+// list or non-null wrapper of one.
+type Type interface {
+	String() string
+}
+
+// ResolveParams is passed to a field's resolver. Source is the value of the
+// object the field belongs to, nil for root fields. Args holds the coerced
+// arguments; optional arguments that were not given are absent.
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{}
+	Args    map[string]interface{}
+}
+
+// ResolveFunc produces the value of a field: a Go value of the field's type,
+// or a Thunk producing it.
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// Thunk is a deferred value. The executor forces it after resolving the
+// field for every object at the same level.
+type Thunk func() (interface{}, error)
+
+// Scalar is a leaf type. Serialize converts a resolved value into its JSON
+// representation; ParseValue converts an input value, as decoded from JSON
+// variables or a literal, into the Go value resolvers receive.
+type Scalar struct {
+	Name        string
+	Description string
+	Serialize   func(value interface{}) (interface{}, error)
+	ParseValue  func(value interface{}) (interface{}, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Enum is a leaf type with a fixed set of string values. Resolvers return
+// and receive the values as strings.
+type Enum struct {
+	Name        string
+	Description string
+	Values      []*EnumValue
+}
+
+type EnumValue struct {
+	Name        string
+	Description string
+}
+
+func (e *Enum) String() string { return e.Name }
+
+func (e *Enum) has(value string) bool {
+	for _, v := range e.Values {
+		if v.Name == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Object is an output type with fields. Fields may be appended after the
+// object is created, to define types that refer to each other.
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (o *Object) String() string { return o.Name }
+
+func (o *Object) field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field is a field of an object. Without Resolve the field is read from the
+// source value: a map key equal to the name, or a struct field whose name
+// matches it ignoring case.
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	Resolve     ResolveFunc
+}
+
+// Argument is an argument of a field, or a field of an input object. Default
+// is used when the argument is not given.
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     interface{}
+}
+
+func argumentNamed(args []*Argument, name string) *Argument {
+	for _, arg := range args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// InputObject is an input type with fields, passed to resolvers as a
+// map[string]interface{}.
+type InputObject struct {
+	Name        string
+	Description string
+	Fields      []*Argument
+}
+
+func (o *InputObject) String() string { return o.Name }
+
+type list struct {
+	ofType Type
+}
+
+func (l *list) String() string { return "[" + l.ofType.String() + "]" }
+
+type nonNull struct {
+	ofType Type
+}
+
+func (n *nonNull) String() string { return n.ofType.String() + "!" }
+
+// List is the type of a list of values of t.
+func List(t Type) Type {
+	return &list{ofType: t}
+}
+
+// NonNull is t without null.
+func NonNull(t Type) Type {
+	return &nonNull{ofType: t}
+}
+
+// named unwraps lists and non-null types.
+func named(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *list:
+			t = w.ofType
+		case *nonNull:
+			t = w.ofType
+		default:
+			return t
+		}
+	}
+}
+
+func isLeaf(t Type) bool {
+	switch named(t).(type) {
+	case *Scalar, *Enum:
+		return true
+	}
+	return false
+}
+
+func isInput(t Type) bool {
+	switch named(t).(type) {
+	case *Scalar, *Enum, *InputObject:
+		return true
+	}
+	return false
+}
+
+// Schema is a set of types with a root query and, optionally, mutation type.
+type Schema struct {
+	query    *Object
+	mutation *Object
+	types    map[string]Type
+}
+
+// NewSchema collects the types reachable from the root types and checks
+// that their names are unique and that fields and arguments have types of
+// the right kind.
+func NewSchema(query, mutation *Object) (*Schema, error) {
+	s := &Schema{query: query, mutation: mutation, types: make(map[string]Type)}
+	for _, scalar := range []*Scalar{Int, Float, String, Boolean, ID} {
+		s.types[scalar.Name] = scalar
+	}
+	roots := []Type{query}
+	if mutation != nil {
+		roots = append(roots, mutation)
+	}
+	for _, root := range roots {
+		if err := s.add(root); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) add(t Type) error {
+	t = named(t)
+	if t == nil {
+		return fmt.Errorf("graphql: missing type")
+	}
+	name := t.String()
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: two types named %s", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+
+	switch t := t.(type) {
+	case *Object:
+		for _, f := range t.Fields {
+			if f.Type == nil {
+				return fmt.Errorf("graphql: field %s.%s has no type", name, f.Name)
+			}
+			if _, ok := named(f.Type).(*InputObject); ok {
+				return fmt.Errorf("graphql: field %s.%s has input type %s", name, f.Name, f.Type)
+			}
+			if err := s.add(f.Type); err != nil {
+				return err
+			}
+			if err := s.addArguments(name+"."+f.Name, f.Args); err != nil {
+				return err
+			}
+		}
+	case *InputObject:
+		return s.addArguments(name, t.Fields)
+	}
+	return nil
+}
+
+func (s *Schema) addArguments(owner string, args []*Argument) error {
+	for _, arg := range args {
+		if arg.Type == nil || !isInput(arg.Type) {
+			return fmt.Errorf("graphql: %s(%s) must have an input type", owner, arg.Name)
+		}
+		if err := s.add(arg.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inputType resolves a type written in a variable definition.
+func (s *Schema) inputType(ref *typeRef) Type {
+	var t Type
+	if ref.elem != nil {
+		elem := s.inputType(ref.elem)
+		if elem == nil {
+			return nil
+		}
+		t = List(elem)
+	} else {
+		t = s.types[ref.name]
+		if t == nil || !isInput(t) {
+			return nil
+		}
+	}
+	if ref.nonNull {
+		t = NonNull(t)
+	}
+	return t
+}
+
+// String prints the schema in the GraphQL schema definition language.
+func (s *Schema) String() string {
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + s.query.Name + "\n")
+	if s.mutation != nil {
+		b.WriteString("  mutation: " + s.mutation.Name + "\n")
+	}
+	b.WriteString("}\n")
+
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			if t == Int || t == Float || t == String || t == Boolean || t == ID {
+				continue
+			}
+			b.WriteString("\n")
+			writeDescription(&b, "", t.Description)
+			b.WriteString("scalar " + t.Name + "\n")
+		case *Enum:
+			b.WriteString("\n")
+			writeDescription(&b, "", t.Description)
+			b.WriteString("enum " + t.Name + " {\n")
+			for _, v := range t.Values {
+				writeDescription(&b, "  ", v.Description)
+				b.WriteString("  " + v.Name + "\n")
+			}
+			b.WriteString("}\n")
+		case *Object:
+			b.WriteString("\n")
+			writeDescription(&b, "", t.Description)
+			b.WriteString("type " + t.Name + " {\n")
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				b.WriteString("  " + f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, len(f.Args))
+					for i, arg := range f.Args {
+						args[i] = formatArgument(arg)
+					}
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + f.Type.String() + "\n")
+			}
+			b.WriteString("}\n")
+		case *InputObject:
+			b.WriteString("\n")
+			writeDescription(&b, "", t.Description)
+			b.WriteString("input " + t.Name + " {\n")
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				b.WriteString("  " + formatArgument(f) + "\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, "\n") {
+		b.WriteString(indent + quote(description) + "\n")
+		return
+	}
+	b.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(description, "\n") {
+		b.WriteString(indent + strings.ReplaceAll(line, `"""`, `\"""`) + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+}
+
+func formatArgument(arg *Argument) string {
+	s := arg.Name + ": " + arg.Type.String()
+	if arg.Default != nil {
+		if str, ok := arg.Default.(string); ok {
+			if _, isEnum := named(arg.Type).(*Enum); isEnum {
+				s += " = " + str
+			} else {
+				s += " = " + quote(str)
+			}
+		} else {
+			s += fmt.Sprintf(" = %v", arg.Default)
+		}
+	}
+	return s
+}
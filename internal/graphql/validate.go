@@ -0,0 +1,314 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// validate checks a document against the schema before anything is
+// executed: that the operations and fragments are well formed, the fields
+// and arguments exist, leaf fields have no selections and object fields
+// have some, the variables used are defined and the fields nest at most
+// MaxDepth levels deep. Argument values are checked when they are coerced
+// during execution.
+func validate(schema *Schema, doc *document) []*Error {
+	v := &validator{schema: schema, doc: doc}
+	v.operations()
+	v.fragments()
+	for _, op := range doc.operations {
+		v.operation(op)
+	}
+	if len(v.errs) == 0 {
+		depths := make(map[string]int)
+		for _, op := range doc.operations {
+			if depth := v.depth(op.selections, depths); depth > MaxDepth {
+				v.report(op.loc, "Operation is nested %d levels deep, more than the maximum of %d.", depth, MaxDepth)
+			}
+		}
+	}
+	return v.errs
+}
+
+type validator struct {
+	schema *Schema
+	doc    *document
+	errs   []*Error
+}
+
+func (v *validator) report(loc Location, format string, args ...interface{}) {
+	v.errs = append(v.errs, newError(loc, format, args...))
+}
+
+func (v *validator) operations() {
+	names := make(map[string]bool)
+	for _, op := range v.doc.operations {
+		if op.name == "" && len(v.doc.operations) > 1 {
+			v.report(op.loc, "This anonymous operation must be the only defined operation.")
+		}
+		if op.name != "" {
+			if names[op.name] {
+				v.report(op.loc, "There can be only one operation named %q.", op.name)
+			}
+			names[op.name] = true
+		}
+	}
+}
+
+// fragments checks the type conditions of fragments and that no fragment
+// spreads itself, directly or through others.
+func (v *validator) fragments() {
+	for _, frag := range v.doc.fragments {
+		if _, ok := v.schema.types[frag.typeCondition].(*Object); !ok {
+			v.report(frag.loc, "Fragment %q cannot condition on non composite type %q.", frag.name, frag.typeCondition)
+		}
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(frag *fragment) bool
+	visit = func(frag *fragment) bool {
+		switch state[frag.name] {
+		case visiting:
+			v.report(frag.loc, "Cannot spread fragment %q within itself.", frag.name)
+			return false
+		case done:
+			return true
+		}
+		state[frag.name] = visiting
+		ok := true
+		walkSpreads(frag.selections, func(spread *fragmentSpread) {
+			if next := v.doc.fragments[spread.name]; next != nil && ok {
+				ok = visit(next)
+			}
+		})
+		state[frag.name] = done
+		return ok
+	}
+	for _, frag := range v.doc.fragments {
+		visit(frag)
+	}
+}
+
+// depth returns how many levels deep the fields of selections nest,
+// including those of the fragments they spread. The depths of fragments are
+// memoized, so each is walked once; it runs once the document is valid, so
+// no fragment spreads itself.
+func (v *validator) depth(selections []selection, depths map[string]int) int {
+	max := 0
+	for _, sel := range selections {
+		d := 0
+		switch sel := sel.(type) {
+		case *field:
+			d = 1 + v.depth(sel.selections, depths)
+		case *inlineFragment:
+			d = v.depth(sel.selections, depths)
+		case *fragmentSpread:
+			var ok bool
+			if d, ok = depths[sel.name]; !ok {
+				d = v.depth(v.doc.fragments[sel.name].selections, depths)
+				depths[sel.name] = d
+			}
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+func walkSpreads(selections []selection, fn func(*fragmentSpread)) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			walkSpreads(sel.selections, fn)
+		case *inlineFragment:
+			walkSpreads(sel.selections, fn)
+		case *fragmentSpread:
+			fn(sel)
+		}
+	}
+}
+
+func (v *validator) operation(op *operation) {
+	var root *Object
+	switch op.kind {
+	case "query":
+		root = v.schema.query
+	case "mutation":
+		root = v.schema.mutation
+		if root == nil {
+			v.report(op.loc, "Schema is not configured for mutations.")
+			return
+		}
+	default:
+		v.report(op.loc, "Schema is not configured for %ss.", op.kind)
+		return
+	}
+
+	defined := make(map[string]bool)
+	for _, def := range op.variables {
+		if defined[def.name] {
+			v.report(def.loc, "There can be only one variable named \"$%s\".", def.name)
+		}
+		defined[def.name] = true
+		if v.schema.inputType(def.typ) == nil {
+			v.report(def.loc, "Variable \"$%s\" cannot be non-input type %q.", def.name, def.typ)
+		}
+	}
+
+	s := &selectionValidator{validator: v, variables: defined, op: op, fragments: make(map[string]bool)}
+	s.directives(op.directives)
+	s.selections(root, op.selections)
+}
+
+// selectionValidator walks the selections of one operation, including the
+// fragments it spreads.
+type selectionValidator struct {
+	*validator
+	op        *operation
+	variables map[string]bool
+	fragments map[string]bool
+}
+
+func (s *selectionValidator) selections(parent *Object, selections []selection) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			s.field(parent, sel)
+		case *inlineFragment:
+			s.directives(sel.directives)
+			if sel.typeCondition != "" && !s.condition(parent, sel.typeCondition, sel.loc, "") {
+				continue
+			}
+			s.selections(parent, sel.selections)
+		case *fragmentSpread:
+			s.directives(sel.directives)
+			frag := s.doc.fragments[sel.name]
+			if frag == nil {
+				s.report(sel.loc, "Unknown fragment %q.", sel.name)
+				continue
+			}
+			if !s.condition(parent, frag.typeCondition, sel.loc, sel.name) {
+				continue
+			}
+			// Each fragment is checked once per operation, which also stops
+			// at fragments that spread themselves.
+			if s.fragments[sel.name] {
+				continue
+			}
+			s.fragments[sel.name] = true
+			s.directives(frag.directives)
+			s.selections(parent, frag.selections)
+		}
+	}
+}
+
+// condition checks that a fragment on typeName can be spread in parent. The
+// schema has no interfaces or unions, so the types must be the same.
+func (s *selectionValidator) condition(parent *Object, typeName string, loc Location, fragmentName string) bool {
+	if _, ok := s.schema.types[typeName].(*Object); !ok {
+		if fragmentName == "" {
+			s.report(loc, "Fragment cannot condition on non composite type %q.", typeName)
+		}
+		return false
+	}
+	if typeName == parent.Name {
+		return true
+	}
+	if fragmentName != "" {
+		s.report(loc, "Fragment %q cannot be spread here as objects of type %q can never be of type %q.", fragmentName, parent.Name, typeName)
+	} else {
+		s.report(loc, "Fragment cannot be spread here as objects of type %q can never be of type %q.", parent.Name, typeName)
+	}
+	return false
+}
+
+func (s *selectionValidator) field(parent *Object, f *field) {
+	s.directives(f.directives)
+	if f.name == "__typename" {
+		s.arguments(f.name, nil, f.arguments)
+		if len(f.selections) > 0 {
+			s.report(f.loc, "Field \"__typename\" must not have a selection since type \"String!\" has no subfields.")
+		}
+		return
+	}
+
+	def := parent.field(f.name)
+	if def == nil {
+		s.report(f.loc, "Cannot query field %q on type %q.", f.name, parent.Name)
+		return
+	}
+	s.arguments(parent.Name+"."+f.name, def.Args, f.arguments)
+
+	switch t := named(def.Type).(type) {
+	case *Object:
+		if len(f.selections) == 0 {
+			s.report(f.loc, "Field %q of type %q must have a selection of subfields. Did you mean \"%s { ... }\"?", f.name, def.Type, f.name)
+			return
+		}
+		s.selections(t, f.selections)
+	default:
+		if len(f.selections) > 0 {
+			s.report(f.loc, "Field %q must not have a selection since type %q has no subfields.", f.name, def.Type)
+		}
+	}
+}
+
+func (s *selectionValidator) arguments(owner string, defs []*Argument, args []*argument) {
+	given := make(map[string]bool)
+	for _, arg := range args {
+		if given[arg.name] {
+			s.report(arg.loc, "There can be only one argument named %q.", arg.name)
+		}
+		given[arg.name] = true
+		if argumentNamed(defs, arg.name) == nil {
+			s.report(arg.loc, "Unknown argument %q on %s.", arg.name, owner)
+		}
+		s.variablesIn(arg.value)
+	}
+	for _, def := range defs {
+		if _, required := def.Type.(*nonNull); required && def.Default == nil && !given[def.Name] {
+			s.report(s.op.loc, "%s argument %q of type %q is required, but it was not provided.", owner, def.Name, def.Type)
+		}
+	}
+}
+
+func (s *selectionValidator) directives(directives []*directive) {
+	for _, d := range directives {
+		def, ok := directiveArgs[d.name]
+		if !ok {
+			s.report(d.loc, "Unknown directive \"@%s\".", d.name)
+			continue
+		}
+		s.arguments("@"+d.name, def, d.arguments)
+	}
+}
+
+func (s *selectionValidator) variablesIn(v *value) {
+	switch v.kind {
+	case valueVariable:
+		if !s.variables[v.raw] {
+			msg := fmt.Sprintf("Variable \"$%s\" is not defined", v.raw)
+			if s.op.name != "" {
+				msg += fmt.Sprintf(" by operation %q", s.op.name)
+			}
+			s.report(v.loc, "%s.", msg)
+		}
+	case valueList:
+		for _, item := range v.items {
+			s.variablesIn(item)
+		}
+	case valueObject:
+		for _, f := range v.fields {
+			s.variablesIn(f.value)
+		}
+	}
+}
+
+// directiveArgs are the arguments of the supported directives.
+var directiveArgs = map[string][]*Argument{
+	"skip":    {{Name: "if", Type: NonNull(Boolean)}},
+	"include": {{Name: "if", Type: NonNull(Boolean)}},
+}
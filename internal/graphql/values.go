@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// coerceInput converts an input value decoded from JSON variables, or a
+// literal converted by literalInput, to the Go value of type t.
+func coerceInput(t Type, value interface{}) (interface{}, error) {
+	if nn, ok := t.(*nonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("Expected non-nullable type %q not to be null.", t)
+		}
+		return coerceInput(nn.ofType, value)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *list:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice {
+			item, err := coerceInput(t.ofType, value)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		coerced := make([]interface{}, items.Len())
+		for i := range coerced {
+			item, err := coerceInput(t.ofType, items.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("at index %d: %v", i, err)
+			}
+			coerced[i] = item
+		}
+		return coerced, nil
+	case *InputObject:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected type %q to be an object.", t.Name)
+		}
+		for name := range fields {
+			if argumentNamed(t.Fields, name) == nil {
+				return nil, fmt.Errorf("Field %q is not defined by type %q.", name, t.Name)
+			}
+		}
+		coerced := make(map[string]interface{}, len(t.Fields))
+		for _, f := range t.Fields {
+			v, given := fields[f.Name]
+			if !given {
+				if f.Default != nil {
+					coerced[f.Name] = f.Default
+				} else if _, required := f.Type.(*nonNull); required {
+					return nil, fmt.Errorf("Field %q of required type %q was not provided.", f.Name, f.Type)
+				}
+				continue
+			}
+			item, err := coerceInput(f.Type, v)
+			if err != nil {
+				return nil, fmt.Errorf("at %q: %v", f.Name, err)
+			}
+			coerced[f.Name] = item
+		}
+		return coerced, nil
+	case *Enum:
+		var name string
+		switch v := value.(type) {
+		case string:
+			name = v
+		case enumLiteral:
+			name = string(v)
+		}
+		if name == "" || !t.has(name) {
+			return nil, fmt.Errorf("Value %s does not exist in %q enum.", inputString(value), t.Name)
+		}
+		return name, nil
+	case *Scalar:
+		if _, ok := value.(enumLiteral); ok {
+			return nil, fmt.Errorf("%s cannot represent value: %s", t.Name, inputString(value))
+		}
+		return t.ParseValue(value)
+	}
+	return nil, fmt.Errorf("%q is not an input type.", t)
+}
+
+// literalInput converts a literal to the representation of decoded JSON,
+// with numbers as json.Number and enum values as enumLiteral. Variables are
+// looked up in variables, which hold raw JSON values; absent variables are
+// left out of lists and objects, and reported as not given.
+func literalInput(v *value, variables map[string]interface{}) (interface{}, bool) {
+	switch v.kind {
+	case valueVariable:
+		value, given := variables[v.raw]
+		return value, given
+	case valueInt, valueFloat:
+		return json.Number(v.raw), true
+	case valueString:
+		return v.raw, true
+	case valueBoolean:
+		return v.raw == "true", true
+	case valueNull:
+		return nil, true
+	case valueEnum:
+		return enumLiteral(v.raw), true
+	case valueList:
+		items := make([]interface{}, 0, len(v.items))
+		for _, item := range v.items {
+			value, given := literalInput(item, variables)
+			if !given {
+				value = nil
+			}
+			items = append(items, value)
+		}
+		return items, true
+	default:
+		fields := make(map[string]interface{}, len(v.fields))
+		for _, f := range v.fields {
+			if value, given := literalInput(f.value, variables); given {
+				fields[f.name] = value
+			}
+		}
+		return fields, true
+	}
+}
+
+// argumentValues coerces the arguments given to a field or directive.
+// Arguments that are not given take their default, or are left out.
+func (e *executor) argumentValues(defs []*Argument, args []*argument) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(defs))
+	for _, def := range defs {
+		var given bool
+		var value interface{}
+		for _, arg := range args {
+			if arg.name == def.Name {
+				value, given = literalInput(arg.value, e.variables)
+				break
+			}
+		}
+		if !given {
+			if def.Default != nil {
+				values[def.Name] = def.Default
+			} else if _, required := def.Type.(*nonNull); required {
+				return nil, fmt.Errorf("Argument %q of required type %q was not provided.", def.Name, def.Type)
+			}
+			continue
+		}
+		coerced, err := coerceInput(def.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("Argument %q has invalid value: %v", def.Name, err)
+		}
+		values[def.Name] = coerced
+	}
+	return values, nil
+}
+
+// coerceVariables checks the variables against the operation's definitions.
+// The raw values are kept: they are coerced with the arguments they are
+// used in.
+func (e *executor) coerceVariables(defs []*variableDefinition, inputs map[string]interface{}) []*Error {
+	e.variables = make(map[string]interface{}, len(defs))
+	var errs []*Error
+	for _, def := range defs {
+		t := e.schema.inputType(def.typ)
+		value, given := inputs[def.name]
+		if !given && def.defaultValue != nil {
+			value, given = literalInput(def.defaultValue, nil)
+		}
+		if !given {
+			if _, required := t.(*nonNull); required {
+				errs = append(errs, newError(def.loc, "Variable \"$%s\" of required type %q was not provided.", def.name, t))
+			}
+			continue
+		}
+		if _, err := coerceInput(t, value); err != nil {
+			errs = append(errs, newError(def.loc, "Variable \"$%s\" got invalid value %s; %v", def.name, inputString(value), err))
+			continue
+		}
+		e.variables[def.name] = value
+	}
+	return errs
+}
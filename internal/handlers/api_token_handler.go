@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+)
+
+// apiTokenPrefix starts every API token, so that they are told apart from
+// session tokens, and found by secret scanners.
+const apiTokenPrefix = "pat_"
+
+// APITokenHandler serves the API tokens of the caller at /me/tokens.
+type APITokenHandler struct {
+	APITokenRepo repository.APITokenRepository
+	// TTL is how long tokens created without an expiry last, MaxTTL the
+	// longest expiry a token may be created with.
+	TTL    time.Duration
+	MaxTTL time.Duration
+}
+
+func NewAPITokenHandler(repo repository.APITokenRepository) *APITokenHandler {
+	return &APITokenHandler{APITokenRepo: repo, TTL: 90 * 24 * time.Hour, MaxTTL: 365 * 24 * time.Hour}
+}
+
+// LoadConfig overrides the TTLs with API_TOKEN_TTL and API_TOKEN_MAX_TTL.
+func (th *APITokenHandler) LoadConfig() error {
+	for name, d := range map[string]*time.Duration{"API_TOKEN_TTL": &th.TTL, "API_TOKEN_MAX_TTL": &th.MaxTTL} {
+		if v := os.Getenv(name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("invalid %s %q", name, v)
+			}
+			*d = parsed
+		}
+	}
+	if th.TTL > th.MaxTTL {
+		return fmt.Errorf("API_TOKEN_TTL %s exceeds API_TOKEN_MAX_TTL %s", th.TTL, th.MaxTTL)
+	}
+	return nil
+}
+
+// tokens returns the handler's repository with the request's context.
+func (th *APITokenHandler) tokens(c *gin.Context) repository.APITokenRepository {
+	return th.APITokenRepo.WithContext(c.Request.Context())
+}
+
+// Authenticate returns the user and scopes of an active API token, for
+// auth.BearerScoped, and records that it was used.
+func (th *APITokenHandler) Authenticate(token string) (uint, []string, bool) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return 0, nil, false
+	}
+	apiToken, err := th.APITokenRepo.FindActiveAPIToken(hashSecret(token))
+	if err != nil {
+		return 0, nil, false
+	}
+	if err := th.APITokenRepo.TouchAPIToken(apiToken); err != nil {
+		log.Printf("Error recording use of API token %d: %v", apiToken.ID, err)
+	}
+	return apiToken.UserID, apiToken.Scopes, true
+}
+
+func (th *APITokenHandler) GetTokens(c *gin.Context) {
+	userID, ok := th.caller(c)
+	if !ok {
+		return
+	}
+	tokens, err := th.tokens(c).GetAPITokensByUser(userID)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve API tokens"))
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreatedAPIToken is the body of a created token, the only one that
+// carries the token itself.
+type CreatedAPIToken struct {
+	models.APIToken
+	Token string `json:"token"`
+}
+
+// CreateToken creates an API token of the caller, which expires after TTL
+// unless the input says when.
+func (th *APITokenHandler) CreateToken(c *gin.Context) {
+	userID, ok := th.caller(c)
+	if !ok {
+		return
+	}
+	var input struct {
+		Name      string     `json:"name" validate:"required,max=100"`
+		Scopes    []string   `json:"scopes" validate:"required,min=1,dive,scope"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+	now := time.Now()
+	expiresAt := now.Add(th.TTL)
+	if input.ExpiresAt != nil {
+		expiresAt = *input.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(th.MaxTTL)) {
+		problem.Write(c, problem.Validation([]problem.FieldError{{
+			Field:   "expires_at",
+			Message: fmt.Sprintf("Tokens must expire within %s", th.MaxTTL),
+		}}))
+		return
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create API token"))
+		return
+	}
+	secret = apiTokenPrefix + secret
+	token := models.APIToken{
+		UserID:    userID,
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		Prefix:    secret[:len(apiTokenPrefix)+6],
+		TokenHash: hashSecret(secret),
+		ExpiresAt: expiresAt,
+	}
+	if err := th.tokens(c).CreateAPIToken(&token); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create API token"))
+		return
+	}
+	c.JSON(http.StatusCreated, CreatedAPIToken{APIToken: token, Token: secret})
+}
+
+func (th *APITokenHandler) RevokeToken(c *gin.Context) {
+	userID, ok := th.caller(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid token ID"))
+		return
+	}
+	if err := th.tokens(c).RevokeAPIToken(uint(id), userID); err != nil {
+		problem.Write(c, problem.Lookup(err, "api_token"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// caller returns the caller's user ID. API tokens may not manage API
+// tokens, lest a token with few scopes create one with more.
+func (th *APITokenHandler) caller(c *gin.Context) (uint, bool) {
+	if _, limited := auth.Scopes(c.Request.Context()); limited {
+		problem.Write(c, problem.Forbidden("API tokens cannot manage API tokens; log in instead"))
+		return 0, false
+	}
+	userID, _ := auth.UserID(c.Request.Context())
+	return userID, true
+}
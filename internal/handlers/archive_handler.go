@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/archive"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+const maxArchiveSize = 64 << 20
+
+type ArchiveHandler struct {
+	ArchiveRepo repository.ArchiveRepository
+}
+
+func NewArchiveHandler(archiveRepo repository.ArchiveRepository) *ArchiveHandler {
+	return &ArchiveHandler{ArchiveRepo: archiveRepo}
+}
+
+// archives returns the handler's repository scoped to the request's workspace.
+func (ah *ArchiveHandler) archives(c *gin.Context) repository.ArchiveRepository {
+	return ah.ArchiveRepo.WithContext(c.Request.Context())
+}
+
+// ExportProjectArchive downloads a project with its tasks, recurring task
+// templates and referenced users as a zip archive.
+func (ah *ArchiveHandler) ExportProjectArchive(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid project ID"))
+		return
+	}
+
+	a, err := ah.archives(c).LoadProjectArchive(uint(id))
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "project"))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, a); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create project archive"))
+		return
+	}
+
+	filename := fmt.Sprintf("project-%d-%s.zip", id, time.Now().Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// ImportProjectArchive restores an archive as a new project. The archive is
+// sent as the request body or as the "file" field of a multipart form.
+func (ah *ArchiveHandler) ImportProjectArchive(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveSize)
+
+	var data []byte
+	var err error
+	if mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, ferr := c.Request.FormFile("file")
+		if ferr != nil {
+			problem.Write(c, problem.BadRequest("Form field 'file' is required"))
+			return
+		}
+		defer file.Close()
+		data, err = io.ReadAll(file)
+	} else {
+		data, err = io.ReadAll(c.Request.Body)
+	}
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Failed to read archive"))
+		return
+	}
+
+	a, err := archive.Read(data)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid archive: "+err.Error()))
+		return
+	}
+	if err := a.Validate(); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid archive: "+err.Error()))
+		return
+	}
+
+	result, err := ah.archives(c).RestoreProjectArchive(a)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to restore project archive"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/oidc"
+	"github.com/togzhanzhakhani/projects/internal/password"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// AuthHandler logs users in with their passwords, or with single sign-on,
+// and resets forgotten passwords. A login opens a session, whose token the caller sends as a bearer
+// token; see Authenticate.
+type AuthHandler struct {
+	AuthRepo    repository.AuthRepository
+	UserRepo    repository.UserRepository
+	ResetSender password.ResetSender
+	Policy      password.Policy
+	Lockout     password.Lockout
+	// SessionTTL is how long a session lasts, ResetTTL how long a password
+	// reset can be confirmed.
+	SessionTTL time.Duration
+	ResetTTL   time.Duration
+	// OIDC is the identity provider of single sign-on, nil when it is off.
+	OIDC *oidc.Provider
+}
+
+func NewAuthHandler(ar repository.AuthRepository, ur repository.UserRepository, sender password.ResetSender) *AuthHandler {
+	return &AuthHandler{
+		AuthRepo:    ar,
+		UserRepo:    ur,
+		ResetSender: sender,
+		Policy:      password.DefaultPolicy,
+		Lockout:     password.DefaultLockout,
+		SessionTTL:  24 * time.Hour,
+		ResetTTL:    time.Hour,
+	}
+}
+
+// LoadConfig overrides the password policy and the lockout, see their
+// LoadConfig, and the TTLs with SESSION_TTL and PASSWORD_RESET_TTL. It turns
+// single sign-on on when oidc.Config.LoadConfig finds an issuer.
+func (ah *AuthHandler) LoadConfig() error {
+	if err := ah.Policy.LoadConfig(); err != nil {
+		return err
+	}
+	if err := ah.Lockout.LoadConfig(); err != nil {
+		return err
+	}
+	for name, d := range map[string]*time.Duration{"SESSION_TTL": &ah.SessionTTL, "PASSWORD_RESET_TTL": &ah.ResetTTL} {
+		if v := os.Getenv(name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("invalid %s %q", name, v)
+			}
+			*d = parsed
+		}
+	}
+	config := oidc.DefaultConfig
+	if err := config.LoadConfig(); err != nil {
+		return err
+	}
+	if config.Enabled() {
+		ah.OIDC = oidc.NewProvider(config)
+	}
+	return nil
+}
+
+// auth returns the handler's repository with the request's context.
+func (ah *AuthHandler) auth(c *gin.Context) repository.AuthRepository {
+	return ah.AuthRepo.WithContext(c.Request.Context())
+}
+
+// LoginResponse is the body of a successful login.
+type LoginResponse struct {
+	Token     string      `json:"token"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	User      models.User `json:"user"`
+}
+
+// Authenticate returns the user of an active session token, for
+// auth.Bearer.
+func (ah *AuthHandler) Authenticate(token string) (uint, bool) {
+	session, err := ah.AuthRepo.FindActiveSession(hashSecret(token))
+	if err != nil {
+		return 0, false
+	}
+	return session.UserID, true
+}
+
+// Login checks an email and password and opens a session. Unknown emails,
+// users without a password and wrong passwords all get the same 401.
+// Consecutive failures lock the account, see password.Lockout.
+func (ah *AuthHandler) Login(c *gin.Context) {
+	var input struct {
+		Email    string `json:"email" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+
+	user, err := ah.auth(c).FindUserByEmail(input.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.FromError(err, "Failed to log in"))
+		return
+	}
+	var credential *models.Credential
+	var ok bool
+	if user != nil {
+		credential, ok, err = ah.attempt(c, user.ID, input.Password)
+		if errors.Is(err, repository.ErrLocked) {
+			ah.record(c, user, input.Email, models.AuthLoginFailed)
+			writeLocked(c, credential)
+			return
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			problem.Write(c, problem.FromError(err, "Failed to log in"))
+			return
+		}
+	}
+	if credential == nil {
+		password.VerifyDecoy(input.Password)
+		ah.record(c, user, input.Email, models.AuthLoginFailed)
+		problem.Write(c, problem.Unauthorized("Invalid email or password"))
+		return
+	}
+	if !ok {
+		ah.record(c, user, input.Email, models.AuthLoginFailed)
+		if credential.FailedAttempts >= ah.Lockout.Threshold {
+			ah.record(c, user, input.Email, models.AuthLockedOut)
+		}
+		problem.Write(c, problem.Unauthorized("Invalid email or password"))
+		return
+	}
+
+	if password.NeedsRehash(credential.PasswordHash) {
+		if hash, err := password.Hash(input.Password); err == nil {
+			if err := ah.auth(c).SetPassword(user.ID, hash); err != nil {
+				log.Printf("Error rehashing password of user %d: %v", user.ID, err)
+			}
+		}
+	}
+	ah.openSession(c, user, models.AuthLoginSucceeded)
+}
+
+// openSession opens a session of a user who logged in, and answers with its
+// token.
+func (ah *AuthHandler) openSession(c *gin.Context, user *models.User, kind string) {
+	token, err := newSecret()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to log in"))
+		return
+	}
+	session := models.Session{UserID: user.ID, TokenHash: hashSecret(token), ExpiresAt: time.Now().Add(ah.SessionTTL)}
+	if err := ah.auth(c).CreateSession(&session); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to log in"))
+		return
+	}
+	ah.record(c, user, user.Email, kind)
+	c.JSON(http.StatusOK, LoginResponse{Token: token, ExpiresAt: session.ExpiresAt, User: *user})
+}
+
+// Logout ends the session whose token authenticated the request.
+func (ah *AuthHandler) Logout(c *gin.Context) {
+	token, ok := auth.BearerToken(c)
+	if _, limited := auth.Scopes(c.Request.Context()); !ok || limited {
+		problem.Write(c, problem.BadRequest("Only sessions can be logged out"))
+		return
+	}
+	if err := ah.auth(c).RevokeSession(hashSecret(token)); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to log out"))
+		return
+	}
+	userID, _ := auth.UserID(c.Request.Context())
+	ah.record(c, &models.User{ID: userID}, "", models.AuthLogout)
+	c.Status(http.StatusNoContent)
+}
+
+// RequestPasswordReset sends a reset token to the user with the email. It
+// answers the same whether there is such a user or not.
+func (ah *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+
+	accepted := gin.H{"message": "If the email belongs to a user, a password reset was sent to it"}
+	user, err := ah.auth(c).FindUserByEmail(input.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusAccepted, accepted)
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to reset password"))
+		return
+	}
+	token, err := newSecret()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to reset password"))
+		return
+	}
+	reset := models.PasswordReset{UserID: user.ID, TokenHash: hashSecret(token), ExpiresAt: time.Now().Add(ah.ResetTTL)}
+	if err := ah.auth(c).CreatePasswordReset(&reset); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to reset password"))
+		return
+	}
+	if err := ah.ResetSender.SendReset(password.ResetMessage{User: *user, Token: token, ExpiresAt: reset.ExpiresAt}); err != nil {
+		log.Printf("Error sending password reset %d: %v", reset.ID, err)
+	}
+	ah.record(c, user, user.Email, models.AuthResetRequested)
+	c.JSON(http.StatusAccepted, accepted)
+}
+
+// ConfirmPasswordReset sets a new password with a reset token. It unlocks
+// the account and ends the user's sessions.
+func (ah *AuthHandler) ConfirmPasswordReset(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) || !checkPasswordPolicy(c, ah.Policy, input.Password) {
+		return
+	}
+	hash, err := password.Hash(input.Password)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to reset password"))
+		return
+	}
+	reset, err := ah.auth(c).ResetPassword(hashSecret(input.Token), hash)
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "password_reset"))
+		return
+	}
+	ah.record(c, &models.User{ID: reset.UserID}, "", models.AuthPasswordReset)
+	c.Status(http.StatusNoContent)
+}
+
+// ChangeEmail changes the email of the caller, which password resets are
+// sent to, once they confirm it with their password. Wrong passwords count
+// towards the lockout as failed logins do.
+func (ah *AuthHandler) ChangeEmail(c *gin.Context) {
+	var input struct {
+		ID       uint   `json:"-"`
+		Email    string `json:"email" validate:"required,email,unique_email"`
+		Password string `json:"password" validate:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	input.ID, _ = auth.UserID(c.Request.Context())
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+
+	user := &models.User{ID: input.ID}
+	credential, ok, err := ah.attempt(c, user.ID, input.Password)
+	if errors.Is(err, repository.ErrLocked) {
+		writeLocked(c, credential)
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.Forbidden("Users without a password change their email with their identity provider"))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to change email"))
+		return
+	}
+	if !ok {
+		ah.record(c, user, "", models.AuthLoginFailed)
+		if credential.FailedAttempts >= ah.Lockout.Threshold {
+			ah.record(c, user, "", models.AuthLockedOut)
+		}
+		problem.Write(c, problem.Unauthorized("Invalid password"))
+		return
+	}
+
+	if err := ah.auth(c).SetEmail(user.ID, input.Email); err != nil {
+		problem.Write(c, validation.FromError(c, err, "Failed to change email"))
+		return
+	}
+	ah.record(c, user, input.Email, models.AuthEmailChanged)
+	c.Status(http.StatusNoContent)
+}
+
+// GetAuthEvents lists the latest auth events of a user of the workspace, to
+// the user and to workspace admins.
+func (ah *AuthHandler) GetAuthEvents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid user ID"))
+		return
+	}
+	if _, err := ah.UserRepo.WithContext(c.Request.Context()).GetUserByID(uint(id)); err != nil {
+		problem.Write(c, problem.Lookup(err, "user"))
+		return
+	}
+	callerID, _ := auth.UserID(c.Request.Context())
+	if callerID != uint(id) && !workspace.Administers(c.Request.Context()) {
+		problem.Write(c, problem.Forbidden("Only workspace admins may see the auth events of other users"))
+		return
+	}
+	events, err := ah.auth(c).GetAuthEvents(uint(id))
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve auth events"))
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// checkPasswordPolicy reports the ways a new password breaks policy as
+// validation errors of the password field.
+func checkPasswordPolicy(c *gin.Context, policy password.Policy, newPassword string) bool {
+	violations := policy.Check(i18n.Translator(c), newPassword)
+	if len(violations) == 0 {
+		return true
+	}
+	errs := make([]problem.FieldError, len(violations))
+	for i, message := range violations {
+		errs[i] = problem.FieldError{Field: "password", Message: message}
+	}
+	problem.Write(c, problem.Validation(errs))
+	return false
+}
+
+// attempt checks the password of a user while counting failures towards
+// the lockout; see repository.AuthRepository.AttemptLogin.
+func (ah *AuthHandler) attempt(c *gin.Context, userID uint, plain string) (*models.Credential, bool, error) {
+	return ah.auth(c).AttemptLogin(userID, func(hash string) (bool, error) {
+		err := password.Verify(hash, plain)
+		if errors.Is(err, password.ErrMismatch) {
+			return false, nil
+		}
+		return err == nil, err
+	}, ah.Lockout.Duration)
+}
+
+// writeLocked answers for a locked account, with the time left in
+// Retry-After.
+func writeLocked(c *gin.Context, credential *models.Credential) {
+	left := time.Until(*credential.LockedUntil)
+	c.Header("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(left.Seconds())))))
+	problem.Write(c, problem.RateLimited("Too many failed logins; the account is locked for now"))
+}
+
+// record writes an auth event. A failure to do so is logged rather than
+// failing the request.
+func (ah *AuthHandler) record(c *gin.Context, user *models.User, email, kind string) {
+	event := models.AuthEvent{Email: email, Kind: kind, IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	if user != nil {
+		event.UserID = &user.ID
+	}
+	if err := ah.auth(c).RecordEvent(&event); err != nil {
+		log.Printf("Error recording auth event %s: %v", kind, err)
+	}
+}
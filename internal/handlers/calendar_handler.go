@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/ical"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+)
+
+const calendarUIDDomain = "task-management-api"
+
+type CalendarHandler struct {
+	UserRepo      repository.UserRepository
+	ProjectRepo   *repository.ProjectRepository
+	FeedTokenRepo repository.FeedTokenRepository
+}
+
+func NewCalendarHandler(userRepo repository.UserRepository, projectRepo *repository.ProjectRepository, feedTokenRepo repository.FeedTokenRepository) *CalendarHandler {
+	return &CalendarHandler{
+		UserRepo:      userRepo,
+		ProjectRepo:   projectRepo,
+		FeedTokenRepo: feedTokenRepo,
+	}
+}
+
+// The feeds are read in the workspace of their token, see authorizeFeed;
+// everything else is scoped to the request's workspace.
+func (ch *CalendarHandler) users(c *gin.Context) repository.UserRepository {
+	return ch.UserRepo.WithContext(c.Request.Context())
+}
+
+func (ch *CalendarHandler) projects(c *gin.Context) *repository.ProjectRepository {
+	return ch.ProjectRepo.WithContext(c.Request.Context())
+}
+
+func (ch *CalendarHandler) tokens(c *gin.Context) repository.FeedTokenRepository {
+	return ch.FeedTokenRepo.WithContext(c.Request.Context())
+}
+
+// GetUserCalendar serves the tasks assigned to a user and the projects they
+// manage.
+func (ch *CalendarHandler) GetUserCalendar(c *gin.Context) {
+	id, ctx, ok := ch.authorizeFeed(c, models.FeedOwnerUser)
+	if !ok {
+		return
+	}
+
+	users := ch.UserRepo.WithContext(ctx)
+	user, err := users.GetUserByID(id)
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "user"))
+		return
+	}
+
+	tasks, err := users.GetTasksByUserID(id)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve tasks"))
+		return
+	}
+
+	projects, err := ch.ProjectRepo.WithContext(ctx).SearchProjectsByManagerID(id)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve projects"))
+		return
+	}
+
+	ch.writeCalendar(c, "Tasks of "+user.Name, projects, tasks)
+}
+
+// GetProjectCalendar serves a project's start and end dates and its tasks.
+func (ch *CalendarHandler) GetProjectCalendar(c *gin.Context) {
+	id, ctx, ok := ch.authorizeFeed(c, models.FeedOwnerProject)
+	if !ok {
+		return
+	}
+
+	projects := ch.ProjectRepo.WithContext(ctx)
+	project, err := projects.GetProjectByID(id)
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "project"))
+		return
+	}
+
+	tasks, err := projects.GetTasksByProjectID(id)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve tasks for project"))
+		return
+	}
+
+	ch.writeCalendar(c, project.Name, []models.Project{*project}, tasks)
+}
+
+// authorizeFeed checks the token query parameter against the feed in the
+// path and returns the owner ID, with a context scoped to the token's
+// workspace for reading the feed.
+func (ch *CalendarHandler) authorizeFeed(c *gin.Context, ownerType string) (uint, context.Context, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid "+ownerType+" ID"))
+		return 0, nil, false
+	}
+
+	token := c.Query("token")
+	if token == "" {
+		problem.Write(c, problem.Unauthorized("Query parameter 'token' is required"))
+		return 0, nil, false
+	}
+
+	feedToken, err := ch.FeedTokenRepo.FindActiveFeedToken(hashSecret(token))
+	if err != nil || feedToken.OwnerType != ownerType || feedToken.OwnerID != uint(id) {
+		problem.Write(c, problem.Unauthorized("Invalid or revoked feed token"))
+		return 0, nil, false
+	}
+
+	ctx := workspace.NewContext(c.Request.Context(), models.Membership{WorkspaceID: feedToken.WorkspaceID, Role: models.WorkspaceMember})
+	return uint(id), ctx, true
+}
+
+func (ch *CalendarHandler) writeCalendar(c *gin.Context, name string, projects []models.Project, tasks []models.Task) {
+	asEvents := c.DefaultQuery("tasks", "todo") == "event"
+	now := time.Now()
+
+	cal := ical.Calendar{ProdID: "-//togzhanzhakhani//Task Management API//EN", Name: name}
+	for _, project := range projects {
+		cal.Components = append(cal.Components, projectEvent(project, now))
+	}
+	for _, task := range tasks {
+		if asEvents {
+			if task.DueDate != nil {
+				cal.Components = append(cal.Components, taskEvent(task, now))
+			}
+		} else {
+			cal.Components = append(cal.Components, taskTodo(task, now))
+		}
+	}
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Status(http.StatusOK)
+	if _, err := cal.WriteTo(c.Writer); err != nil {
+		log.Printf("Error writing calendar: %v", err)
+	}
+}
+
+// projectEvent is an all-day event spanning the project; DTEND is exclusive.
+func projectEvent(project models.Project, now time.Time) ical.Component {
+	event := ical.Component{Name: "VEVENT"}
+	event.Raw("UID", ical.UID("project", project.ID, calendarUIDDomain))
+	event.DateTime("DTSTAMP", now)
+	event.Text("SUMMARY", project.Name)
+	event.Text("DESCRIPTION", project.Description)
+	event.Date("DTSTART", project.StartDate)
+	event.Date("DTEND", project.EndDate.AddDate(0, 0, 1))
+	event.Raw("TRANSP", "TRANSPARENT")
+	return event
+}
+
+func taskTodo(task models.Task, now time.Time) ical.Component {
+	todo := ical.Component{Name: "VTODO"}
+	todo.Raw("UID", ical.UID("task", task.ID, calendarUIDDomain))
+	todo.DateTime("DTSTAMP", now)
+	todo.Text("SUMMARY", task.Title)
+	todo.Text("DESCRIPTION", task.Description)
+	if !task.CreatedAt.IsZero() {
+		todo.DateTime("CREATED", task.CreatedAt)
+	}
+	if task.DueDate != nil {
+		todo.DateTime("DUE", *task.DueDate)
+	}
+	todo.Raw("PRIORITY", icalPriority(task.Priority))
+	todo.Raw("STATUS", icalTodoStatus(task.Status))
+	if task.Status == "done" && !task.CompletedAt.IsZero() {
+		todo.DateTime("COMPLETED", task.CompletedAt)
+	}
+	return todo
+}
+
+// taskEvent shows a task as a zero-length event at its due date, for calendar
+// apps that ignore VTODO.
+func taskEvent(task models.Task, now time.Time) ical.Component {
+	event := ical.Component{Name: "VEVENT"}
+	event.Raw("UID", ical.UID("task", task.ID, calendarUIDDomain))
+	event.DateTime("DTSTAMP", now)
+	event.Text("SUMMARY", task.Title)
+	event.Text("DESCRIPTION", task.Description)
+	event.DateTime("DTSTART", *task.DueDate)
+	event.Raw("PRIORITY", icalPriority(task.Priority))
+	event.Raw("TRANSP", "TRANSPARENT")
+	return event
+}
+
+func icalPriority(priority string) string {
+	switch priority {
+	case "high":
+		return "1"
+	case "medium":
+		return "5"
+	case "low":
+		return "9"
+	}
+	return "0"
+}
+
+func icalTodoStatus(status string) string {
+	switch status {
+	case "in_progress":
+		return "IN-PROCESS"
+	case "done":
+		return "COMPLETED"
+	}
+	return "NEEDS-ACTION"
+}
+
+func (ch *CalendarHandler) CreateUserFeedToken(c *gin.Context) {
+	ch.createFeedToken(c, models.FeedOwnerUser)
+}
+
+func (ch *CalendarHandler) CreateProjectFeedToken(c *gin.Context) {
+	ch.createFeedToken(c, models.FeedOwnerProject)
+}
+
+func (ch *CalendarHandler) GetUserFeedTokens(c *gin.Context) {
+	ch.getFeedTokens(c, models.FeedOwnerUser)
+}
+
+func (ch *CalendarHandler) GetProjectFeedTokens(c *gin.Context) {
+	ch.getFeedTokens(c, models.FeedOwnerProject)
+}
+
+func (ch *CalendarHandler) RevokeUserFeedToken(c *gin.Context) {
+	ch.revokeFeedToken(c, models.FeedOwnerUser)
+}
+
+func (ch *CalendarHandler) RevokeProjectFeedToken(c *gin.Context) {
+	ch.revokeFeedToken(c, models.FeedOwnerProject)
+}
+
+// authorizeOwner checks that the owner of feeds exists in the request's
+// workspace and that the caller may manage its feed tokens: those of a user
+// are managed by the user and workspace admins, those of a project by its
+// workspace's members.
+func (ch *CalendarHandler) authorizeOwner(c *gin.Context, ownerType string, id uint) bool {
+	var err error
+	if ownerType == models.FeedOwnerUser {
+		_, err = ch.users(c).GetUserByID(id)
+	} else {
+		_, err = ch.projects(c).GetProjectByID(id)
+	}
+	if err != nil {
+		problem.Write(c, problem.NotFound(ownerNotFound(ownerType)))
+		return false
+	}
+	if ownerType == models.FeedOwnerUser {
+		ctx := c.Request.Context()
+		if callerID, _ := auth.UserID(ctx); callerID != id && !workspace.Administers(ctx) {
+			problem.Write(c, problem.Forbidden("Only the user and workspace admins may manage the user's feeds"))
+			return false
+		}
+	}
+	return true
+}
+
+// createFeedToken issues a new feed token. The secret is only returned here.
+func (ch *CalendarHandler) createFeedToken(c *gin.Context, ownerType string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid "+ownerType+" ID"))
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			problem.Write(c, problem.BadRequest("Invalid input"))
+			return
+		}
+	}
+
+	if !ch.authorizeOwner(c, ownerType, uint(id)) {
+		return
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create feed token"))
+		return
+	}
+
+	token := models.FeedToken{
+		OwnerType: ownerType,
+		OwnerID:   uint(id),
+		Name:      input.Name,
+		TokenHash: hashSecret(secret),
+	}
+	if err := ch.tokens(c).CreateFeedToken(&token); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create feed token"))
+		return
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         token.ID,
+		"name":       token.Name,
+		"created_at": token.CreatedAt,
+		"token":      secret,
+		"url":        fmt.Sprintf("%s://%s/%ss/%d/calendar.ics?token=%s", scheme, c.Request.Host, ownerType, id, secret),
+	})
+}
+
+func (ch *CalendarHandler) getFeedTokens(c *gin.Context, ownerType string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid "+ownerType+" ID"))
+		return
+	}
+
+	if !ch.authorizeOwner(c, ownerType, uint(id)) {
+		return
+	}
+
+	tokens, err := ch.tokens(c).GetFeedTokensByOwner(ownerType, uint(id))
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve feed tokens"))
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (ch *CalendarHandler) revokeFeedToken(c *gin.Context, ownerType string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid "+ownerType+" ID"))
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("tokenId"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid feed token ID"))
+		return
+	}
+
+	if !ch.authorizeOwner(c, ownerType, uint(id)) {
+		return
+	}
+
+	token, err := ch.tokens(c).GetFeedTokenByID(uint(tokenID))
+	if err != nil || token.OwnerType != ownerType || token.OwnerID != uint(id) {
+		problem.Write(c, problem.NotFound("Feed token not found"))
+		return
+	}
+
+	if err := ch.tokens(c).RevokeFeedToken(token.ID); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to revoke feed token"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func ownerNotFound(ownerType string) string {
+	if ownerType == models.FeedOwnerUser {
+		return "User not found"
+	}
+	return "Project not found"
+}
+
+// newSecret returns a random token for a URL or a message, see hashSecret.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashSecret returns the hash of a token that is stored in its place.
+func hashSecret(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
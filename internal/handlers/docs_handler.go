@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const redocPage = `<!DOCTYPE html>
+<html>
+<head>
+<title>Task Management API</title>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+<redoc spec-url="/openapi.json"></redoc>
+<script src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+type DocsHandler struct {
+	Spec []byte
+}
+
+func NewDocsHandler(spec []byte) *DocsHandler {
+	return &DocsHandler{Spec: spec}
+}
+
+// GetSpec serves the OpenAPI document.
+func (dh *DocsHandler) GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", dh.Spec)
+}
+
+// GetDocs serves a Redoc page that renders the OpenAPI document.
+func (dh *DocsHandler) GetDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(redocPage))
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/export"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+var taskExportColumns = []string{
+	"id", "title", "description", "priority", "status",
+	"assignee_id", "assignee_name", "project_id", "project_name",
+	"created_at", "completed_at", "due_date",
+}
+
+var projectExportColumns = []string{
+	"id", "name", "description", "start_date", "end_date",
+	"manager_id", "manager_name", "task_count",
+}
+
+type ExportHandler struct {
+	ExportRepo repository.ExportRepository
+}
+
+func NewExportHandler(exportRepo repository.ExportRepository) *ExportHandler {
+	return &ExportHandler{ExportRepo: exportRepo}
+}
+
+// ExportTasks streams the tasks matching the search parameters (title,
+// status, priority, assignee, project).
+func (eh *ExportHandler) ExportTasks(c *gin.Context) {
+	filter := repository.TaskFilter{
+		Title:    c.Query("title"),
+		Status:   c.Query("status"),
+		Priority: c.Query("priority"),
+	}
+	if assignee := c.Query("assignee"); assignee != "" {
+		id, err := strconv.ParseUint(assignee, 10, 32)
+		if err != nil {
+			problem.Write(c, problem.BadRequest("Invalid assignee ID"))
+			return
+		}
+		filter.AssigneeID = uint(id)
+	}
+	if project := c.Query("project"); project != "" {
+		id, err := strconv.ParseUint(project, 10, 32)
+		if err != nil {
+			problem.Write(c, problem.BadRequest("Invalid project ID"))
+			return
+		}
+		filter.ProjectID = uint(id)
+	}
+
+	eh.streamTasks(c, "tasks", filter)
+}
+
+// ExportProjectTasks streams the tasks of one project.
+func (eh *ExportHandler) ExportProjectTasks(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid project ID"))
+		return
+	}
+
+	eh.streamTasks(c, fmt.Sprintf("project-%d-tasks", id), repository.TaskFilter{ProjectID: uint(id)})
+}
+
+func (eh *ExportHandler) streamTasks(c *gin.Context, name string, filter repository.TaskFilter) {
+	writer, ok := startExport(c, name, taskExportColumns)
+	if !ok {
+		return
+	}
+
+	err := eh.ExportRepo.StreamTasks(c.Request.Context(), filter, func(row repository.TaskExportRow) error {
+		return writer.WriteRow([]interface{}{
+			row.ID, row.Title, row.Description, row.Priority, row.Status,
+			row.AssigneeID, row.AssigneeName, row.ProjectID, row.ProjectName,
+			row.CreatedAt, row.CompletedAt, row.DueDate,
+		})
+	})
+	finishExport(writer, name, err)
+}
+
+// ExportProjects streams all projects.
+func (eh *ExportHandler) ExportProjects(c *gin.Context) {
+	writer, ok := startExport(c, "projects", projectExportColumns)
+	if !ok {
+		return
+	}
+
+	err := eh.ExportRepo.StreamProjects(c.Request.Context(), func(row repository.ProjectExportRow) error {
+		return writer.WriteRow([]interface{}{
+			row.ID, row.Name, row.Description, row.StartDate, row.EndDate,
+			row.ManagerID, row.ManagerName, row.TaskCount,
+		})
+	})
+	finishExport(writer, "projects", err)
+}
+
+// startExport validates the format and writes the response headers. Once it
+// returns, the status is committed: errors while streaming can only be logged.
+func startExport(c *gin.Context, name string, columns []string) (export.Writer, bool) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+	if !export.IsSupported(format) {
+		problem.Write(c, problem.BadRequest("Query parameter 'format' must be one of: csv, ndjson, xlsx"))
+		return nil, false
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer, columns)
+	if err != nil {
+		log.Printf("Error starting %s export: %v", name, err)
+		return nil, false
+	}
+	return writer, true
+}
+
+func finishExport(writer export.Writer, name string, err error) {
+	if err != nil {
+		log.Printf("Error exporting %s: %v", name, err)
+	}
+	if err := writer.Close(); err != nil {
+		log.Printf("Error finishing %s export: %v", name, err)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/graph"
+	"github.com/togzhanzhakhani/projects/internal/graphql"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+type GraphQLHandler struct {
+	Resolver *graph.Resolver
+}
+
+func NewGraphQLHandler(resolver *graph.Resolver) *GraphQLHandler {
+	return &GraphQLHandler{Resolver: resolver}
+}
+
+// Query executes a GraphQL request. Errors of the query itself are reported
+// in the GraphQL response with status 200; only a body that is not a
+// GraphQL request is a problem.
+func (gh *GraphQLHandler) Query(c *gin.Context) {
+	var req graphql.Request
+	if err := c.ShouldBindJSON(&req); err != nil || req.Query == "" {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	// API tokens need graphql:read to query and graphql:write to mutate.
+	doc := graphql.Parse(req)
+	if kind, ok := doc.Kind(); ok {
+		scope := "graphql:" + auth.ScopeRead
+		if kind == "mutation" {
+			scope = "graphql:" + auth.ScopeWrite
+		}
+		if !auth.Allows(c.Request.Context(), scope) {
+			problem.Write(c, problem.Forbidden("The token lacks the "+scope+" scope"))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gh.Resolver.Execute(c.Request.Context(), i18n.Translator(c), doc))
+}
+
+// GetSchema serves the GraphQL schema in the schema definition language.
+func (gh *GraphQLHandler) GetSchema(c *gin.Context) {
+	c.String(http.StatusOK, gh.Resolver.Schema().String())
+}
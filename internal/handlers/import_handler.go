@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/importer"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+const maxImportSize = 10 << 20
+
+type ImportHandler struct {
+	ImportRepo repository.ImportRepository
+}
+
+func NewImportHandler(importRepo repository.ImportRepository) *ImportHandler {
+	return &ImportHandler{ImportRepo: importRepo}
+}
+
+// imports returns the handler's repository scoped to the request's workspace.
+func (ih *ImportHandler) imports(c *gin.Context) repository.ImportRepository {
+	return ih.ImportRepo.WithContext(c.Request.Context())
+}
+
+type importReport struct {
+	Entity      string              `json:"entity"`
+	Format      string              `json:"format"`
+	DryRun      bool                `json:"dry_run"`
+	TotalRows   int                 `json:"total_rows"`
+	ValidRows   int                 `json:"valid_rows"`
+	InvalidRows int                 `json:"invalid_rows"`
+	CreatedRows int                 `json:"created_rows"`
+	Errors      []importer.RowError `json:"errors"`
+	Job         *models.ImportJob   `json:"job,omitempty"`
+}
+
+// Import creates users, projects or tasks from a CSV or NDJSON file.
+//
+// With dry_run=true nothing is written and the per-row validation errors are
+// returned. In the default atomic mode all rows are created in one transaction,
+// and nothing is created if any row is invalid. In batch mode valid rows are
+// committed batch_size at a time and invalid rows are skipped; if a batch
+// fails, the import can be resumed by posting the same file with job_id.
+func (ih *ImportHandler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	data, format, mapping, err := readImportInput(c)
+	if err != nil {
+		problem.Write(c, problem.BadRequest(err.Error()))
+		return
+	}
+
+	entity := c.Query("entity")
+	if entity == "" {
+		entity = c.PostForm("entity")
+	}
+	if entity != importer.EntityUsers && entity != importer.EntityProjects && entity != importer.EntityTasks {
+		problem.Write(c, problem.BadRequest("Query parameter 'entity' must be one of: users, projects, tasks"))
+		return
+	}
+
+	mode := c.DefaultQuery("mode", "atomic")
+	if mode != "atomic" && mode != "batch" {
+		problem.Write(c, problem.BadRequest("Query parameter 'mode' must be 'atomic' or 'batch'"))
+		return
+	}
+
+	batchSize, err := strconv.Atoi(c.DefaultQuery("batch_size", "100"))
+	if err != nil || batchSize < 1 || batchSize > 1000 {
+		problem.Write(c, problem.BadRequest("Query parameter 'batch_size' must be between 1 and 1000"))
+		return
+	}
+
+	rows, err := importer.Read(format, bytes.NewReader(data), mapping)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid "+format+" input: "+err.Error()))
+		return
+	}
+
+	records, rowErrors, err := importer.Build(entity, rows, ih.imports(c))
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to validate import"))
+		return
+	}
+
+	report := importReport{
+		Entity:      entity,
+		Format:      format,
+		DryRun:      c.Query("dry_run") == "true",
+		TotalRows:   len(rows),
+		ValidRows:   len(records),
+		InvalidRows: len(rowErrors),
+		Errors:      rowErrors,
+	}
+	if report.Errors == nil {
+		report.Errors = []importer.RowError{}
+	}
+
+	if report.DryRun {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	if mode == "batch" {
+		ih.importInBatches(c, report, data, rows, records, batchSize)
+		return
+	}
+
+	if len(rowErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	if err := ih.imports(c).CreateAll(recordModels(records)); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to import "+entity))
+		return
+	}
+
+	report.CreatedRows = len(records)
+	c.JSON(http.StatusCreated, report)
+}
+
+func (ih *ImportHandler) importInBatches(c *gin.Context, report importReport, data []byte, rows []importer.Row, records []importer.Record, batchSize int) {
+	checksum := sha256.Sum256(data)
+
+	var job *models.ImportJob
+	if jobID := c.Query("job_id"); jobID != "" {
+		var err error
+		job, err = ih.imports(c).GetImportJob(jobID)
+		if err != nil {
+			problem.Write(c, problem.Lookup(err, "import job"))
+			return
+		}
+		if job.Entity != report.Entity || job.Checksum != hex.EncodeToString(checksum[:]) {
+			problem.Write(c, problem.Conflict("Import job was started with a different file"))
+			return
+		}
+		if job.Status == models.ImportJobCompleted {
+			report.Job = job
+			c.JSON(http.StatusOK, report)
+			return
+		}
+	} else {
+		job = &models.ImportJob{
+			ID:        newImportJobID(),
+			Entity:    report.Entity,
+			Format:    report.Format,
+			Checksum:  hex.EncodeToString(checksum[:]),
+			TotalRows: len(rows),
+		}
+		if err := ih.imports(c).CreateImportJob(job); err != nil {
+			problem.Write(c, problem.FromError(err, "Failed to create import job"))
+			return
+		}
+	}
+
+	valid := make(map[int]interface{}, len(records))
+	for _, record := range records {
+		valid[record.Line] = record.Model
+	}
+
+	job.Status = models.ImportJobRunning
+	job.Error = ""
+	for start := job.ProcessedRows; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var batch []interface{}
+		for _, row := range rows[start:end] {
+			if model, ok := valid[row.Line]; ok {
+				batch = append(batch, model)
+			}
+		}
+
+		if err := ih.imports(c).CreateAll(batch); err != nil {
+			log.Printf("Error importing batch of import job %s: %v", job.ID, err)
+			job.Status = models.ImportJobFailed
+			job.Error = err.Error()
+			if err := ih.imports(c).UpdateImportJob(job); err != nil {
+				log.Printf("Error updating import job %s: %v", job.ID, err)
+			}
+			report.Job = job
+			c.JSON(http.StatusInternalServerError, report)
+			return
+		}
+
+		job.ProcessedRows = end
+		job.CreatedRows += len(batch)
+		job.InvalidRows += (end - start) - len(batch)
+		if end == len(rows) {
+			job.Status = models.ImportJobCompleted
+		}
+		if err := ih.imports(c).UpdateImportJob(job); err != nil {
+			problem.Write(c, problem.FromError(err, "Failed to update import job"))
+			return
+		}
+	}
+	if len(rows) == 0 {
+		job.Status = models.ImportJobCompleted
+		if err := ih.imports(c).UpdateImportJob(job); err != nil {
+			log.Printf("Error updating import job %s: %v", job.ID, err)
+		}
+	}
+
+	report.CreatedRows = job.CreatedRows
+	report.Job = job
+	c.JSON(http.StatusCreated, report)
+}
+
+func (ih *ImportHandler) GetImportJob(c *gin.Context) {
+	job, err := ih.imports(c).GetImportJob(c.Param("id"))
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "import job"))
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// readImportInput returns the file, its format and the column mapping, from
+// either a multipart form (fields "file", "format" and "mapping") or the raw
+// request body (query parameters "format" and "map").
+func readImportInput(c *gin.Context) ([]byte, string, map[string]string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	var data []byte
+	format := c.Query("format")
+	mapping, err := importer.ParseMapping(c.Query("map"))
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if mediaType == "multipart/form-data" {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			return nil, "", nil, errors.New("Form field 'file' is required")
+		}
+		defer file.Close()
+		if data, err = io.ReadAll(file); err != nil {
+			return nil, "", nil, errors.New("Failed to read file")
+		}
+		if format == "" {
+			format = c.PostForm("format")
+		}
+		if format == "" {
+			format = formatFromName(header.Filename)
+		}
+		if value := c.PostForm("mapping"); value != "" {
+			if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+				return nil, "", nil, errors.New("Form field 'mapping' must be a JSON object")
+			}
+		}
+	} else {
+		if data, err = io.ReadAll(c.Request.Body); err != nil {
+			return nil, "", nil, errors.New("Failed to read request body")
+		}
+		if format == "" {
+			format = formatFromMediaType(mediaType)
+		}
+	}
+
+	if format != importer.FormatCSV && format != importer.FormatNDJSON {
+		return nil, "", nil, errors.New("Format must be 'csv' or 'ndjson'")
+	}
+	return data, format, mapping, nil
+}
+
+func formatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return importer.FormatCSV
+	case ".ndjson", ".jsonl":
+		return importer.FormatNDJSON
+	}
+	return ""
+}
+
+func formatFromMediaType(mediaType string) string {
+	switch mediaType {
+	case "text/csv":
+		return importer.FormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return importer.FormatNDJSON
+	}
+	return ""
+}
+
+func recordModels(records []importer.Record) []interface{} {
+	values := make([]interface{}, len(records))
+	for i, record := range records {
+		values[i] = record.Model
+	}
+	return values
+}
+
+func newImportJobID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
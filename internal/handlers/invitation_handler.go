@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/invite"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/password"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// InvitationHandler serves the invitations of a workspace. Workspace admins
+// and users with the admin role invite with any role, managers everyone
+// but admins. Invitations are accepted by whoever holds their token.
+type InvitationHandler struct {
+	InvitationRepo repository.InvitationRepository
+	UserRepo       repository.UserRepository
+	Sender         invite.Sender
+	// Policy is what the passwords of new users must satisfy.
+	Policy password.Policy
+	// TTL is how long an invitation can be accepted after it was sent.
+	TTL time.Duration
+}
+
+func NewInvitationHandler(ir repository.InvitationRepository, ur repository.UserRepository, sender invite.Sender) *InvitationHandler {
+	return &InvitationHandler{InvitationRepo: ir, UserRepo: ur, Sender: sender, Policy: password.DefaultPolicy, TTL: 7 * 24 * time.Hour}
+}
+
+// invitations returns the handler's repository scoped to the request's
+// workspace.
+func (ih *InvitationHandler) invitations(c *gin.Context) repository.InvitationRepository {
+	return ih.InvitationRepo.WithContext(c.Request.Context())
+}
+
+func (ih *InvitationHandler) GetPendingInvitations(c *gin.Context) {
+	if !ih.authorize(c, "developer") {
+		return
+	}
+	invitations, err := ih.invitations(c).GetPendingInvitations()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve invitations"))
+		return
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// CreateInvitation invites an email address to the workspace, and to one of
+// its projects if the invitation names one.
+func (ih *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var invitation models.Invitation
+	if err := c.ShouldBindJSON(&invitation); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	invitation.Email = strings.ToLower(strings.TrimSpace(invitation.Email))
+	if invitation.ProjectID != nil && invitation.ProjectRole == "" {
+		invitation.ProjectRole = models.ProjectContributor
+	}
+	if invitation.ProjectID == nil {
+		invitation.ProjectRole = ""
+	}
+	// Workspace roles are given by the members route of workspace admins.
+	invitation.WorkspaceRole = ""
+	if !validation.ValidateStruct(c, &invitation) {
+		return
+	}
+	if !ih.authorize(c, invitation.Role) {
+		return
+	}
+	if !ih.issue(c, ih.invitations(c), &invitation) {
+		return
+	}
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// issue stores invitation with a new token, as sent by the caller, and
+// sends it. It writes the problem and returns false if that fails.
+func (ih *InvitationHandler) issue(c *gin.Context, invitations repository.InvitationRepository, invitation *models.Invitation) bool {
+	token, err := newSecret()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create invitation"))
+		return false
+	}
+	invitation.ID = 0
+	invitation.InvitedBy, _ = auth.UserID(c.Request.Context())
+	invitation.TokenHash = hashSecret(token)
+	invitation.SentAt = time.Now()
+	invitation.ExpiresAt = invitation.SentAt.Add(ih.TTL)
+	invitation.UserID, invitation.AcceptedAt, invitation.RevokedAt = nil, nil, nil
+	if err := invitations.CreateInvitation(invitation); err != nil {
+		problem.Write(c, validation.FromError(c, err, "Failed to create invitation"))
+		return false
+	}
+	ih.send(*invitation, token)
+	return true
+}
+
+// ResendInvitation sends a pending invitation again with a new token, which
+// replaces the one sent before, and a new expiry.
+func (ih *InvitationHandler) ResendInvitation(c *gin.Context) {
+	invitation, ok := ih.invitation(c)
+	if !ok || !ih.authorize(c, invitation.Role) {
+		return
+	}
+	token, err := newSecret()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to resend invitation"))
+		return
+	}
+	invitation.TokenHash = hashSecret(token)
+	invitation.SentAt = time.Now()
+	invitation.ExpiresAt = invitation.SentAt.Add(ih.TTL)
+	if err := ih.invitations(c).RenewInvitation(invitation.ID, invitation.TokenHash, invitation.ExpiresAt); err != nil {
+		problem.Write(c, invitationError(err, "Failed to resend invitation"))
+		return
+	}
+	ih.send(*invitation, token)
+	c.JSON(http.StatusOK, invitation)
+}
+
+func (ih *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	invitation, ok := ih.invitation(c)
+	if !ok || !ih.authorize(c, invitation.Role) {
+		return
+	}
+	if err := ih.invitations(c).RevokeInvitation(invitation.ID); err != nil {
+		problem.Write(c, invitationError(err, "Failed to revoke invitation"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation redeems an invitation token. Whoever has no account yet
+// gets one, with the invitation's email and role and the name and password
+// they choose; users who have one join the workspace with it as it is.
+func (ih *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" validate:"required"`
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+	invitation, err := ih.InvitationRepo.FindPendingInvitation(hashSecret(input.Token))
+	if err == nil && !invitation.ExpiresAt.After(time.Now()) {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "invitation"))
+		return
+	}
+
+	user, err := ih.UserRepo.FindByEmail(invitation.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.FromError(err, "Failed to accept invitation"))
+		return
+	}
+	var passwordHash string
+	if user == nil {
+		newUser := struct {
+			Name     string `validate:"required"`
+			Password string `validate:"required"`
+		}{input.Name, input.Password}
+		if !validation.ValidateStruct(c, &newUser) || !checkPasswordPolicy(c, ih.Policy, input.Password) {
+			return
+		}
+		passwordHash, err = password.Hash(input.Password)
+		if err != nil {
+			problem.Write(c, problem.FromError(err, "Failed to accept invitation"))
+			return
+		}
+		user = &models.User{Name: input.Name, Email: invitation.Email, Role: invitation.Role}
+	}
+	if err := ih.InvitationRepo.AcceptInvitation(invitation, user, passwordHash); err != nil {
+		if errors.Is(err, repository.ErrInvitationClosed) {
+			problem.Write(c, problem.Lookup(gorm.ErrRecordNotFound, "invitation"))
+			return
+		}
+		problem.Write(c, validation.FromError(c, err, "Failed to accept invitation"))
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+// invitation parses the invitation in the path and looks it up.
+func (ih *InvitationHandler) invitation(c *gin.Context) (*models.Invitation, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid invitation ID"))
+		return nil, false
+	}
+	invitation, err := ih.invitations(c).GetInvitationByID(uint(id))
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "invitation"))
+		return nil, false
+	}
+	return invitation, true
+}
+
+// authorize checks that the caller may manage invitations with role.
+func (ih *InvitationHandler) authorize(c *gin.Context, role string) bool {
+	ctx := c.Request.Context()
+	if membership, _ := workspace.FromContext(ctx); membership.Role == models.WorkspaceAdmin {
+		return true
+	}
+	if userID, ok := auth.UserID(ctx); ok {
+		caller, err := ih.UserRepo.WithContext(ctx).GetUserByID(userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			problem.Write(c, problem.FromError(err, "Failed to retrieve user"))
+			return false
+		}
+		if err == nil && (caller.Role == "admin" || caller.Role == "manager" && role != "admin") {
+			return true
+		}
+	}
+	if role == "admin" {
+		problem.Write(c, problem.Forbidden("Only admins may invite admins"))
+	} else {
+		problem.Write(c, problem.Forbidden("Only admins and managers may invite users"))
+	}
+	return false
+}
+
+// send delivers an invitation. The invitation is kept when that fails, as
+// it can be resent.
+func (ih *InvitationHandler) send(invitation models.Invitation, token string) {
+	if err := ih.Sender.Send(invite.Message{Invitation: invitation, Token: token}); err != nil {
+		log.Printf("Error sending invitation %d: %v", invitation.ID, err)
+	}
+}
+
+func invitationError(err error, detail string) *problem.Problem {
+	if errors.Is(err, repository.ErrInvitationClosed) {
+		return problem.Conflict("The invitation was already accepted or revoked")
+	}
+	return problem.FromError(err, detail)
+}
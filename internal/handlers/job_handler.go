@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/jobs"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+type JobHandler struct {
+	Manager *jobs.Manager
+}
+
+func NewJobHandler(manager *jobs.Manager) *JobHandler {
+	return &JobHandler{Manager: manager}
+}
+
+func (jh *JobHandler) GetJobs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		problem.Write(c, problem.BadRequest("Query parameter 'limit' must be between 1 and 100"))
+		return
+	}
+
+	statuses, err := jh.Manager.Statuses(limit)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve jobs"))
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
@@ -1,13 +1,13 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
 	"github.com/togzhanzhakhani/projects/internal/repository"
 	"github.com/togzhanzhakhani/projects/internal/validation"
 )
@@ -20,11 +20,15 @@ func NewProjectHandler(pr *repository.ProjectRepository) *ProjectHandler {
 	return &ProjectHandler{ProjectRepo: pr}
 }
 
+// projects returns the handler's repository scoped to the request's workspace.
+func (ph *ProjectHandler) projects(c *gin.Context) *repository.ProjectRepository {
+	return ph.ProjectRepo.WithContext(c.Request.Context())
+}
+
 func (ph *ProjectHandler) GetAllProjects(c *gin.Context) {
-	projects, err := ph.ProjectRepo.GetAllProjects()
+	projects, err := ph.projects(c).GetAllProjects()
 	if err != nil {
-		log.Printf("Error retrieving projects: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve projects"})
+		problem.Write(c, problem.FromError(err, "Failed to retrieve projects"))
 		return
 	}
 	c.JSON(http.StatusOK, projects)
@@ -35,23 +39,23 @@ func (ph *ProjectHandler) processProject(c *gin.Context, id uint, isUpdate bool)
 		Description string `json:"description" validate:"required,max=100"`
 		StartDate   string `json:"start_date" validate:"required"`
 		EndDate     string `json:"end_date" validate:"required,gtfield=StartDate"`
-		ManagerID   int    `json:"manager_id" validate:"required,gt=0,manager-exists"`
+		ManagerID   int    `json:"manager_id" validate:"required,gt=0"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		problem.Write(c, problem.BadRequest("Invalid input"))
 		return
 	}
 
 	startDate, err := time.Parse("2006-01-02", input.StartDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+		problem.Write(c, problem.BadRequest("Invalid start date format"))
 		return
 	}
 
 	endDate, err := time.Parse("2006-01-02", input.EndDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+		problem.Write(c, problem.BadRequest("Invalid end date format"))
 		return
 	}
 
@@ -67,16 +71,11 @@ func (ph *ProjectHandler) processProject(c *gin.Context, id uint, isUpdate bool)
 		return
 	}
 
-	if !ph.ProjectRepo.UserExists(project.ManagerID) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Manager does not exist"})
-		return
-	}
-
 	if isUpdate {
 		project.ID = int(id)
-		err = ph.ProjectRepo.UpdateProject(&project)
+		err = ph.projects(c).UpdateProject(&project)
 	} else {
-		err = ph.ProjectRepo.CreateProject(&project)
+		err = ph.projects(c).CreateProject(&project)
 	}
 
 	if err != nil {
@@ -86,8 +85,7 @@ func (ph *ProjectHandler) processProject(c *gin.Context, id uint, isUpdate bool)
 		} else {
 			errMsg = "Failed to create project"
 		}
-		log.Printf("Error %s: %v", errMsg, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		problem.Write(c, validation.FromError(c, err, errMsg))
 		return
 	}
 
@@ -101,7 +99,7 @@ func (ph *ProjectHandler) processProject(c *gin.Context, id uint, isUpdate bool)
 func (ph *ProjectHandler) UpdateProject(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		problem.Write(c, problem.BadRequest("Invalid project ID"))
 		return
 	}
 
@@ -115,13 +113,13 @@ func (ph *ProjectHandler) CreateProject(c *gin.Context) {
 func (ph *ProjectHandler) GetProjectByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		problem.Write(c, problem.BadRequest("Invalid project ID"))
 		return
 	}
 
-	project, err := ph.ProjectRepo.GetProjectByID(uint(id))
+	project, err := ph.projects(c).GetProjectByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		problem.Write(c, problem.Lookup(err, "project"))
 		return
 	}
 
@@ -131,25 +129,23 @@ func (ph *ProjectHandler) GetProjectByID(c *gin.Context) {
 func (ph *ProjectHandler) DeleteProject(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		problem.Write(c, problem.BadRequest("Invalid project ID"))
 		return
 	}
 
-	project, err := ph.ProjectRepo.GetProjectByID(uint(id))
+	project, err := ph.projects(c).GetProjectByID(uint(id))
 	if err != nil {
-		log.Printf("Error retrieving project: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
+		problem.Write(c, problem.FromError(err, "Failed to retrieve project"))
 		return
 	}
 
 	if project == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		problem.Write(c, problem.NotFound("Project not found"))
 		return
 	}
 
-	if err := ph.ProjectRepo.DeleteProject(uint(id)); err != nil {
-		log.Printf("Error deleting project: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
+	if err := ph.projects(c).DeleteProject(uint(id)); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to delete project"))
 		return
 	}
 
@@ -159,18 +155,13 @@ func (ph *ProjectHandler) DeleteProject(c *gin.Context) {
 func (ph *ProjectHandler) GetTasksByProjectID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		problem.Write(c, problem.BadRequest("Invalid project ID"))
 		return
 	}
 
-	tasks, err := ph.ProjectRepo.GetTasksByProjectID(uint(id))
+	tasks, err := ph.projects(c).GetTasksByProjectID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks for project"})
-		return
-	}
-
-	if len(tasks) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "No tasks found"})
+		problem.Write(c, problem.FromError(err, "Failed to retrieve tasks for project"))
 		return
 	}
 
@@ -180,18 +171,13 @@ func (ph *ProjectHandler) GetTasksByProjectID(c *gin.Context) {
 func (ph *ProjectHandler) SearchProjectsByTitle(c *gin.Context) {
 	title := c.Query("title")
 	if title == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing title parameter"})
+		problem.Write(c, problem.BadRequest("Missing title parameter"))
 		return
 	}
 
-	projects, err := ph.ProjectRepo.SearchProjectsByTitle(title)
+	projects, err := ph.projects(c).SearchProjectsByTitle(title)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search projects by title"})
-		return
-	}
-
-	if len(projects) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "No projects found with the given title"})
+		problem.Write(c, problem.FromError(err, "Failed to search projects by title"))
 		return
 	}
 
@@ -201,18 +187,13 @@ func (ph *ProjectHandler) SearchProjectsByTitle(c *gin.Context) {
 func (ph *ProjectHandler) SearchProjectsByManagerID(c *gin.Context) {
 	managerID, err := strconv.ParseUint(c.Query("manager"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid manager ID"})
+		problem.Write(c, problem.BadRequest("Invalid manager ID"))
 		return
 	}
 
-	projects, err := ph.ProjectRepo.SearchProjectsByManagerID(uint(managerID))
+	projects, err := ph.projects(c).SearchProjectsByManagerID(uint(managerID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search projects by manager ID"})
-		return
-	}
-
-	if len(projects) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "No projects found"})
+		problem.Write(c, problem.FromError(err, "Failed to search projects by manager ID"))
 		return
 	}
 
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// ProjectMemberHandler serves the members of projects. Workspace admins and
+// project owners manage every member, maintainers every member but the
+// owners.
+type ProjectMemberHandler struct {
+	MemberRepo repository.ProjectMemberRepository
+}
+
+func NewProjectMemberHandler(mr repository.ProjectMemberRepository) *ProjectMemberHandler {
+	return &ProjectMemberHandler{MemberRepo: mr}
+}
+
+// members returns the handler's repository scoped to the request's workspace.
+func (mh *ProjectMemberHandler) members(c *gin.Context) repository.ProjectMemberRepository {
+	return mh.MemberRepo.WithContext(c.Request.Context())
+}
+
+// projectRoleInput is the role of a project member. The field is not called
+// Role so that its messages are not those of the user's role.
+type projectRoleInput struct {
+	ProjectRole string `json:"role" validate:"required,oneof=owner maintainer contributor viewer"`
+}
+
+func (mh *ProjectMemberHandler) GetMembers(c *gin.Context) {
+	projectID, ok := mh.project(c)
+	if !ok {
+		return
+	}
+	members, err := mh.members(c).GetProjectMembers(projectID)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve project members"))
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember adds a user of the workspace to the project, or changes the role
+// of a member.
+func (mh *ProjectMemberHandler) AddMember(c *gin.Context) {
+	projectID, ok := mh.project(c)
+	if !ok {
+		return
+	}
+	var input struct {
+		UserID int `json:"user_id" validate:"required,gt=0,user_exists"`
+		projectRoleInput
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+
+	member := models.ProjectMember{ProjectID: projectID, UserID: uint(input.UserID), Role: input.ProjectRole}
+	// Demoting an owner is managing an owner.
+	role := member.Role
+	existing, err := mh.members(c).GetProjectMember(projectID, member.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve project member"))
+		return
+	}
+	if existing != nil && existing.Role == models.ProjectOwner {
+		role = models.ProjectOwner
+	}
+	if !mh.authorize(c, projectID, role) {
+		return
+	}
+	if err := mh.members(c).AddProjectMember(&member); err != nil {
+		problem.Write(c, projectMemberError(err, "Failed to add project member"))
+		return
+	}
+	c.JSON(http.StatusCreated, member)
+}
+
+// AddTeam adds the members of a team to the project.
+func (mh *ProjectMemberHandler) AddTeam(c *gin.Context) {
+	projectID, ok := mh.project(c)
+	if !ok {
+		return
+	}
+	var input struct {
+		TeamID uint `json:"team_id" validate:"required,gt=0"`
+		projectRoleInput
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+	if !mh.authorize(c, projectID, input.ProjectRole) {
+		return
+	}
+
+	added, err := mh.members(c).AddProjectTeam(projectID, input.TeamID, input.ProjectRole)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.Lookup(err, "team"))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to add team"))
+		return
+	}
+	if added == nil {
+		added = []models.ProjectMember{}
+	}
+	c.JSON(http.StatusCreated, added)
+}
+
+func (mh *ProjectMemberHandler) RemoveMember(c *gin.Context) {
+	projectID, ok := mh.project(c)
+	if !ok {
+		return
+	}
+	userID, ok := memberID(c)
+	if !ok {
+		return
+	}
+	member, err := mh.members(c).GetProjectMember(projectID, userID)
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "member"))
+		return
+	}
+	if !mh.authorize(c, projectID, member.Role) {
+		return
+	}
+	if err := mh.members(c).RemoveProjectMember(projectID, userID); err != nil {
+		problem.Write(c, projectMemberError(err, "Failed to remove project member"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// project parses the project in the path and checks that it exists.
+func (mh *ProjectMemberHandler) project(c *gin.Context) (int, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid project ID"))
+		return 0, false
+	}
+	if _, err := mh.members(c).GetProject(int(id)); err != nil {
+		problem.Write(c, problem.Lookup(err, "project"))
+		return 0, false
+	}
+	return int(id), true
+}
+
+// authorize checks that the caller may manage project members with role.
+func (mh *ProjectMemberHandler) authorize(c *gin.Context, projectID int, role string) bool {
+	if membership, _ := workspace.FromContext(c.Request.Context()); membership.Role == models.WorkspaceAdmin {
+		return true
+	}
+	userID, _ := auth.UserID(c.Request.Context())
+	caller, err := mh.members(c).GetProjectMember(projectID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve project member"))
+		return false
+	}
+	if caller != nil {
+		switch {
+		case caller.Role == models.ProjectOwner:
+			return true
+		case caller.Role == models.ProjectMaintainer && role != models.ProjectOwner:
+			return true
+		}
+	}
+	if role == models.ProjectOwner {
+		problem.Write(c, problem.Forbidden("Only project owners may manage owners"))
+	} else {
+		problem.Write(c, problem.Forbidden("Only project owners and maintainers may manage members"))
+	}
+	return false
+}
+
+func projectMemberError(err error, detail string) *problem.Problem {
+	if errors.Is(err, repository.ErrLastOwner) {
+		return problem.Conflict("A project needs at least one owner")
+	}
+	return problem.FromError(err, detail)
+}
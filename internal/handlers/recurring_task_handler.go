@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/recurrence"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+)
+
+type RecurringTaskHandler struct {
+	RecurringTaskRepo repository.RecurringTaskRepository
+}
+
+func NewRecurringTaskHandler(repo repository.RecurringTaskRepository) *RecurringTaskHandler {
+	return &RecurringTaskHandler{RecurringTaskRepo: repo}
+}
+
+// templates returns the handler's repository scoped to the request's workspace.
+func (rh *RecurringTaskHandler) templates(c *gin.Context) repository.RecurringTaskRepository {
+	return rh.RecurringTaskRepo.WithContext(c.Request.Context())
+}
+
+func (rh *RecurringTaskHandler) GetAllRecurringTasks(c *gin.Context) {
+	templates, err := rh.templates(c).GetAllRecurringTasks()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to fetch recurring tasks"))
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+func (rh *RecurringTaskHandler) GetRecurringTaskByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid recurring task ID"))
+		return
+	}
+
+	template, err := rh.templates(c).GetRecurringTaskByID(uint(id))
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "recurring task"))
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (rh *RecurringTaskHandler) bindRecurringTask(c *gin.Context) (*models.RecurringTask, bool) {
+	var input struct {
+		Title           string `json:"title"`
+		Description     string `json:"description"`
+		Priority        string `json:"priority"`
+		AssigneeID      int    `json:"assignee_id"`
+		ProjectID       int    `json:"project_id"`
+		RRule           string `json:"rrule"`
+		StartsAt        string `json:"starts_at"`
+		Timezone        string `json:"timezone"`
+		DueAfterMinutes int    `json:"due_after_minutes"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return nil, false
+	}
+
+	template := models.RecurringTask{
+		Title:           input.Title,
+		Description:     input.Description,
+		Priority:        input.Priority,
+		AssigneeID:      input.AssigneeID,
+		ProjectID:       input.ProjectID,
+		RRule:           input.RRule,
+		Timezone:        input.Timezone,
+		DueAfterMinutes: input.DueAfterMinutes,
+	}
+
+	if input.StartsAt != "" {
+		startsAt, _, err := dates.ParseLocal(input.StartsAt, input.Timezone)
+		if err != nil {
+			problem.Write(c, problem.BadRequest("Invalid starts_at format"))
+			return nil, false
+		}
+		template.StartsAt = startsAt
+	}
+
+	if !validation.ValidateStruct(c, &template) {
+		return nil, false
+	}
+
+	loc, _ := recurrence.Location(template.Timezone)
+	rule, err := recurrence.Parse(template.RRule, loc)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid rrule: "+err.Error()))
+		return nil, false
+	}
+	template.RRule = rule.String()
+
+	return &template, true
+}
+
+func (rh *RecurringTaskHandler) CreateRecurringTask(c *gin.Context) {
+	template, ok := rh.bindRecurringTask(c)
+	if !ok {
+		return
+	}
+
+	if err := rh.templates(c).CreateRecurringTask(template); err != nil {
+		problem.Write(c, validation.FromError(c, err, "Failed to create recurring task"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateRecurringTask edits a template. With scope=all (the default) the whole
+// series changes. With scope=future&from=<date> the occurrences before from
+// keep the old values: the template is ended before from and a new template
+// in the same series takes over. Untouched future occurrences are regenerated
+// either way.
+func (rh *RecurringTaskHandler) UpdateRecurringTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid recurring task ID"))
+		return
+	}
+
+	scope := c.DefaultQuery("scope", "all")
+	if scope != "all" && scope != "future" {
+		problem.Write(c, problem.BadRequest("Query parameter 'scope' must be 'all' or 'future'"))
+		return
+	}
+
+	existing, err := rh.templates(c).GetRecurringTaskByID(uint(id))
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "recurring task"))
+		return
+	}
+
+	template, ok := rh.bindRecurringTask(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if scope == "future" {
+		from, _, err := dates.ParseLocal(c.Query("from"), existing.Timezone)
+		if err != nil {
+			problem.Write(c, problem.BadRequest("Query parameter 'from' is required with scope=future"))
+			return
+		}
+
+		if previous, ok := endSeriesBefore(existing, from); ok {
+			if template.StartsAt.Before(from) {
+				problem.Write(c, problem.BadRequest("starts_at must not be before 'from'"))
+				return
+			}
+			if err := rh.templates(c).SplitRecurringTask(previous, template, from); err != nil {
+				problem.Write(c, validation.FromError(c, err, "Failed to update recurring task"))
+				return
+			}
+			c.JSON(http.StatusOK, template)
+			return
+		}
+		// Nothing happened before from, so the whole series changes.
+	}
+
+	template.ID = existing.ID
+	template.SeriesID = existing.SeriesID
+	template.CreatedAt = existing.CreatedAt
+	if err := rh.templates(c).UpdateRecurringTask(template, now); err != nil {
+		problem.Write(c, validation.FromError(c, err, "Failed to update recurring task"))
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// endSeriesBefore returns a copy of template whose rule stops before from. It
+// reports false when the template has no occurrence before from.
+func endSeriesBefore(template *models.RecurringTask, from time.Time) (*models.RecurringTask, bool) {
+	loc, err := recurrence.Location(template.Timezone)
+	if err != nil {
+		return nil, false
+	}
+	rule, err := recurrence.Parse(template.RRule, loc)
+	if err != nil {
+		return nil, false
+	}
+
+	n := rule.CountBefore(template.StartsAt.In(loc), from)
+	if n == 0 {
+		return nil, false
+	}
+	if rule.Count > 0 {
+		rule.Count = n
+	} else {
+		until := from.Add(-time.Second)
+		if rule.Until == nil || until.Before(*rule.Until) {
+			rule.Until = &until
+		}
+	}
+
+	previous := *template
+	previous.RRule = rule.String()
+	return &previous, true
+}
+
+func (rh *RecurringTaskHandler) DeleteRecurringTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid recurring task ID"))
+		return
+	}
+
+	if _, err := rh.templates(c).GetRecurringTaskByID(uint(id)); err != nil {
+		problem.Write(c, problem.Lookup(err, "recurring task"))
+		return
+	}
+
+	if err := rh.templates(c).DeleteRecurringTask(uint(id), time.Now()); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to delete recurring task"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/oidc"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"gorm.io/gorm"
+)
+
+// ssoLoginTTL is how long a single sign-on login may take at the identity
+// provider.
+const ssoLoginTTL = 10 * time.Minute
+
+// ssoStateCookie binds a single sign-on login to the browser that started
+// it, so that nobody can complete it in another one.
+const ssoStateCookie = "oidc_state"
+
+// StartSSO redirects to the identity provider to log in there. It keeps the
+// state, PKCE code verifier and nonce of the login for SSOCallback.
+func (ah *AuthHandler) StartSSO(c *gin.Context) {
+	if ah.OIDC == nil {
+		problem.Write(c, problem.NotFound("Single sign-on is not configured"))
+		return
+	}
+	login := models.OIDCLogin{ExpiresAt: time.Now().Add(ssoLoginTTL)}
+	state, err := oidc.NewSecret()
+	if err == nil {
+		login.Verifier, err = oidc.NewSecret()
+	}
+	if err == nil {
+		login.Nonce, err = oidc.NewSecret()
+	}
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to start single sign-on"))
+		return
+	}
+	redirect, err := ah.OIDC.AuthCodeURL(c.Request.Context(), state, login.Nonce, login.Verifier)
+	if err != nil {
+		log.Printf("Error starting single sign-on: %v", err)
+		problem.Write(c, problem.FromError(err, "Failed to reach the identity provider"))
+		return
+	}
+	login.StateHash = hashSecret(state)
+	if err := ah.auth(c).CreateOIDCLogin(&login); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to start single sign-on"))
+		return
+	}
+	secure := strings.HasPrefix(ah.OIDC.Config.RedirectURL, "https:")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, state, int(ssoLoginTTL.Seconds()), callbackPath(ah.OIDC.Config.RedirectURL), "", secure, true)
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// SSOCallback completes a single sign-on login: it redeems the code for an
+// ID token, provisions the user it names and opens a session. Users are
+// matched by email; their role follows the groups the identity provider
+// puts them in, see oidc.Config.
+func (ah *AuthHandler) SSOCallback(c *gin.Context) {
+	if ah.OIDC == nil {
+		problem.Write(c, problem.NotFound("Single sign-on is not configured"))
+		return
+	}
+	if reason := c.Query("error"); reason != "" {
+		problem.Write(c, problem.Unauthorized("The identity provider refused the login: "+reason))
+		return
+	}
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		problem.Write(c, problem.BadRequest("The callback needs a state and a code"))
+		return
+	}
+	if cookie, err := c.Cookie(ssoStateCookie); err != nil || cookie != state {
+		problem.Write(c, problem.Unauthorized("The login was started in another browser"))
+		return
+	}
+	c.SetCookie(ssoStateCookie, "", -1, callbackPath(ah.OIDC.Config.RedirectURL), "", false, true)
+	login, err := ah.auth(c).ClaimOIDCLogin(hashSecret(state))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.Unauthorized("The login expired or was already completed; start it again"))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to log in"))
+		return
+	}
+	claims, err := ah.OIDC.Exchange(c.Request.Context(), code, login.Verifier, login.Nonce)
+	if err != nil {
+		log.Printf("Error completing single sign-on: %v", err)
+		ah.record(c, nil, "", models.AuthSSOFailed)
+		problem.Write(c, problem.Unauthorized("The identity provider did not confirm the login"))
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || claims.EmailVerified != nil && !*claims.EmailVerified {
+		ah.record(c, nil, email, models.AuthSSOFailed)
+		problem.Write(c, problem.Forbidden("The identity provider has no verified email for the user"))
+		return
+	}
+	user, err := ah.auth(c).FindUserByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.FromError(err, "Failed to log in"))
+		return
+	}
+	role, mapped := ah.OIDC.Config.Role(claims.Groups)
+	switch {
+	case user != nil && mapped:
+		user.Role = role
+	case user == nil && (mapped || ah.OIDC.Config.DefaultRole != ""):
+		if !mapped {
+			role = ah.OIDC.Config.DefaultRole
+		}
+		name := claims.Name
+		if name == "" {
+			name = email
+		}
+		user = &models.User{Name: name, Email: email, Role: role}
+	case user == nil:
+		ah.record(c, nil, email, models.AuthSSOFailed)
+		problem.Write(c, problem.Forbidden("The user is in no group that may log in"))
+		return
+	}
+	if err := ah.auth(c).ProvisionUser(user, ah.OIDC.Config.WorkspaceID); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to log in"))
+		return
+	}
+	ah.openSession(c, user, models.AuthSSOLogin)
+}
+
+// callbackPath is the path of the redirect URL, which the state cookie is
+// limited to.
+func callbackPath(redirectURL string) string {
+	u, err := url.Parse(redirectURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
@@ -1,13 +1,14 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/dates"
 	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
 	"github.com/togzhanzhakhani/projects/internal/repository"
 	"github.com/togzhanzhakhani/projects/internal/validation"
 )
@@ -22,15 +23,15 @@ func NewTaskHandler(taskRepo repository.TaskRepository) *TaskHandler {
 	}
 }
 
+// tasks returns the handler's repository scoped to the request's workspace.
+func (th *TaskHandler) tasks(c *gin.Context) repository.TaskRepository {
+	return th.TaskRepo.WithContext(c.Request.Context())
+}
+
 func (th *TaskHandler) GetAllTasks(c *gin.Context) {
-	tasks, err := th.TaskRepo.GetAllTasks()
+	tasks, err := th.tasks(c).GetAllTasks()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
-		return
-	}
-
-	if len(tasks) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No tasks found"})
+		problem.Write(c, problem.FromError(err, "Failed to fetch tasks"))
 		return
 	}
 
@@ -40,13 +41,13 @@ func (th *TaskHandler) GetAllTasks(c *gin.Context) {
 func (th *TaskHandler) GetTaskByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		problem.Write(c, problem.BadRequest("Invalid task ID"))
 		return
 	}
 
-	task, err := th.TaskRepo.GetTaskByID(uint(id))
+	task, err := th.tasks(c).GetTaskByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		problem.Write(c, problem.Lookup(err, "task"))
 		return
 	}
 
@@ -63,25 +64,36 @@ func (th *TaskHandler) processTask(c *gin.Context, id uint, isUpdate bool) {
 		ProjectID   int    `json:"project_id" validate:"required,gt=0"`
 		CreatedAt   string `json:"created_at" validate:"required"`
 		CompletedAt string `json:"completed_at" validate:"required,gtfield=CreatedAt"`
+		DueDate     string `json:"due_date"`
+		DueTimezone string `json:"due_timezone"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		problem.Write(c, problem.BadRequest("Invalid input"))
 		return
 	}
 
 	createdAt, err := time.Parse("2006-01-02", input.CreatedAt)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid created_at date format"})
+		problem.Write(c, problem.BadRequest("Invalid created_at date format"))
 		return
 	}
 
 	completedAt, err := time.Parse("2006-01-02", input.CompletedAt)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+		problem.Write(c, problem.BadRequest("Invalid end date format"))
 		return
 	}
 
+	var dueDate *time.Time
+	if input.DueDate != "" {
+		dueDate, err = dates.ParseDue(input.DueDate, input.DueTimezone)
+		if err != nil {
+			problem.Write(c, problem.BadRequest("Invalid due_date format"))
+			return
+		}
+	}
+
 	task := models.Task{
 		ID:          int(id),
 		Title:       input.Title,
@@ -92,26 +104,31 @@ func (th *TaskHandler) processTask(c *gin.Context, id uint, isUpdate bool) {
 		ProjectID:   input.ProjectID,
 		CreatedAt:   createdAt,
 		CompletedAt: completedAt,
+		DueDate:     dueDate,
+		DueTimezone: input.DueTimezone,
 	}
 
 	if !validation.ValidateStruct(c, &task) {
 		return
 	}
 
-	if !th.TaskRepo.UserExists(task.AssigneeID) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Assignee does not exist"})
-		return
-	}
-
-	if !th.TaskRepo.ProjectExists(task.ProjectID) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Project does not exist"})
-		return
+	if task.DueDate != nil {
+		project, err := th.tasks(c).GetProject(task.ProjectID)
+		if err != nil {
+			problem.Write(c, problem.FromError(err, "Failed to fetch project"))
+			return
+		}
+		// The project end date is a calendar day, so anything due on that day is still in time.
+		if !task.DueDate.Before(project.EndDate.AddDate(0, 0, 1)) {
+			problem.Write(c, problem.BadRequest("Due date must not be after the project end date"))
+			return
+		}
 	}
 
 	if isUpdate {
-		err = th.TaskRepo.UpdateTask(&task)
+		err = th.tasks(c).UpdateTask(&task)
 	} else {
-		err = th.TaskRepo.CreateTask(&task)
+		err = th.tasks(c).CreateTask(&task)
 	}
 
 	if err != nil {
@@ -121,8 +138,7 @@ func (th *TaskHandler) processTask(c *gin.Context, id uint, isUpdate bool) {
 		} else {
 			errMsg = "Failed to create task"
 		}
-		log.Printf("Error %s: %v", errMsg, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		problem.Write(c, validation.FromError(c, err, errMsg))
 		return
 	}
 
@@ -140,7 +156,7 @@ func (th *TaskHandler) CreateTask(c *gin.Context) {
 func (th *TaskHandler) UpdateTask(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		problem.Write(c, problem.BadRequest("Invalid task ID"))
 		return
 	}
 
@@ -150,39 +166,44 @@ func (th *TaskHandler) UpdateTask(c *gin.Context) {
 func (th *TaskHandler) DeleteTask(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		problem.Write(c, problem.BadRequest("Invalid task ID"))
 		return
 	}
 
-	task, err := th.TaskRepo.GetTaskByID(uint(id))
+	task, err := th.tasks(c).GetTaskByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+		problem.Write(c, problem.FromError(err, "Failed to fetch task"))
 		return
 	}
 
 	if task == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		problem.Write(c, problem.NotFound("Task not found"))
 		return
 	}
 
-	if err := th.TaskRepo.DeleteTask(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
+	if err := th.tasks(c).DeleteTask(uint(id)); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to delete task"))
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
-func (th *TaskHandler) SearchTasksByTitle(c *gin.Context) {
-	title := c.Query("title")
-	tasks, err := th.TaskRepo.SearchTasksByTitle(title)
+func (th *TaskHandler) GetOverdueTasks(c *gin.Context) {
+	tasks, err := th.tasks(c).GetOverdueTasks(time.Now())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks by title"})
+		problem.Write(c, problem.FromError(err, "Failed to fetch overdue tasks"))
 		return
 	}
 
-	if len(tasks) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No tasks found with the given title"})
+	c.JSON(http.StatusOK, tasks)
+}
+
+func (th *TaskHandler) SearchTasksByTitle(c *gin.Context) {
+	title := c.Query("title")
+	tasks, err := th.tasks(c).SearchTasksByTitle(title)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to search tasks by title"))
 		return
 	}
 
@@ -191,14 +212,9 @@ func (th *TaskHandler) SearchTasksByTitle(c *gin.Context) {
 
 func (th *TaskHandler) SearchTasksByStatus(c *gin.Context) {
 	status := c.Query("status")
-	tasks, err := th.TaskRepo.SearchTasksByStatus(status)
+	tasks, err := th.tasks(c).SearchTasksByStatus(status)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks by status"})
-		return
-	}
-
-	if len(tasks) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No tasks found"})
+		problem.Write(c, problem.FromError(err, "Failed to search tasks by status"))
 		return
 	}
 
@@ -207,14 +223,9 @@ func (th *TaskHandler) SearchTasksByStatus(c *gin.Context) {
 
 func (th *TaskHandler) SearchTasksByPriority(c *gin.Context) {
 	priority := c.Query("priority")
-	tasks, err := th.TaskRepo.SearchTasksByPriority(priority)
+	tasks, err := th.tasks(c).SearchTasksByPriority(priority)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks by priority"})
-		return
-	}
-
-	if len(tasks) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No tasks found"})
+		problem.Write(c, problem.FromError(err, "Failed to search tasks by priority"))
 		return
 	}
 
@@ -224,18 +235,13 @@ func (th *TaskHandler) SearchTasksByPriority(c *gin.Context) {
 func (th *TaskHandler) SearchTasksByAssignee(c *gin.Context) {
 	userID, err := strconv.ParseUint(c.Query("assignee"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignee ID"})
+		problem.Write(c, problem.BadRequest("Invalid assignee ID"))
 		return
 	}
 
-	tasks, err := th.TaskRepo.SearchTasksByAssignee(uint(userID))
+	tasks, err := th.tasks(c).SearchTasksByAssignee(uint(userID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks by assignee"})
-		return
-	}
-
-	if len(tasks) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No tasks found"})
+		problem.Write(c, problem.FromError(err, "Failed to search tasks by assignee"))
 		return
 	}
 
@@ -245,18 +251,13 @@ func (th *TaskHandler) SearchTasksByAssignee(c *gin.Context) {
 func (th *TaskHandler) SearchTasksByProject(c *gin.Context) {
 	projectID, err := strconv.ParseUint(c.Query("project"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		problem.Write(c, problem.BadRequest("Invalid project ID"))
 		return
 	}
 
-	tasks, err := th.TaskRepo.SearchTasksByProject(uint(projectID))
+	tasks, err := th.tasks(c).SearchTasksByProject(uint(projectID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks by project"})
-		return
-	}
-
-	if len(tasks) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No tasks found"})
+		problem.Write(c, problem.FromError(err, "Failed to search tasks by project"))
 		return
 	}
 
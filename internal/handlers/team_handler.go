@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+)
+
+// TeamHandler serves the teams of a workspace. Every member may list them;
+// only workspace admins change them.
+type TeamHandler struct {
+	TeamRepo repository.TeamRepository
+}
+
+func NewTeamHandler(tr repository.TeamRepository) *TeamHandler {
+	return &TeamHandler{TeamRepo: tr}
+}
+
+// teams returns the handler's repository scoped to the request's workspace.
+func (th *TeamHandler) teams(c *gin.Context) repository.TeamRepository {
+	return th.TeamRepo.WithContext(c.Request.Context())
+}
+
+func (th *TeamHandler) GetAllTeams(c *gin.Context) {
+	teams, err := th.teams(c).GetAllTeams()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve teams"))
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
+
+func (th *TeamHandler) CreateTeam(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	var input struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	team := models.Team{Name: input.Name}
+	if !validation.ValidateStruct(c, &team) {
+		return
+	}
+	if err := th.teams(c).CreateTeam(&team); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create team"))
+		return
+	}
+	c.JSON(http.StatusCreated, team)
+}
+
+func (th *TeamHandler) DeleteTeam(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	id, ok := th.team(c)
+	if !ok {
+		return
+	}
+	if err := th.teams(c).DeleteTeam(id); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to delete team"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (th *TeamHandler) GetTeamMembers(c *gin.Context) {
+	id, ok := th.team(c)
+	if !ok {
+		return
+	}
+	members, err := th.teams(c).GetTeamMembers(id)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve team members"))
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// AddTeamMember adds a user of the workspace to the team. It does not make
+// them members of the projects the team was added to.
+func (th *TeamHandler) AddTeamMember(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	id, ok := th.team(c)
+	if !ok {
+		return
+	}
+	var input struct {
+		UserID int `json:"user_id" validate:"required,gt=0,user_exists"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+	member := models.TeamMember{TeamID: id, UserID: uint(input.UserID)}
+	if err := th.teams(c).AddTeamMember(&member); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to add team member"))
+		return
+	}
+	c.JSON(http.StatusCreated, member)
+}
+
+func (th *TeamHandler) RemoveTeamMember(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	id, ok := th.team(c)
+	if !ok {
+		return
+	}
+	userID, ok := memberID(c)
+	if !ok {
+		return
+	}
+	if err := th.teams(c).RemoveTeamMember(id, userID); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to remove team member"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// team parses the team in the path and checks that it exists.
+func (th *TeamHandler) team(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid team ID"))
+		return 0, false
+	}
+	if _, err := th.teams(c).GetTeamByID(uint(id)); err != nil {
+		problem.Write(c, problem.Lookup(err, "team"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// requireAdmin checks that the caller administers the request's workspace.
+func requireAdmin(c *gin.Context) bool {
+	if membership, _ := workspace.FromContext(c.Request.Context()); membership.Role == models.WorkspaceAdmin {
+		return true
+	}
+	problem.Write(c, problem.Forbidden("Only workspace admins may do this"))
+	return false
+}
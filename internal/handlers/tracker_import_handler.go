@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/importer"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/tracker"
+)
+
+type TrackerImportHandler struct {
+	Importer *tracker.Importer
+}
+
+func NewTrackerImportHandler(importer *tracker.Importer) *TrackerImportHandler {
+	return &TrackerImportHandler{Importer: importer}
+}
+
+// ImportGitHub imports a GitHub issues JSON export.
+func (th *TrackerImportHandler) ImportGitHub(c *gin.Context) {
+	data, _, opts, ok := th.readInput(c)
+	if !ok {
+		return
+	}
+	issues, pullRequests, err := tracker.ParseGitHub(data)
+	if err != nil {
+		problem.Write(c, problem.BadRequest(err.Error()))
+		return
+	}
+	report, ok := th.run(c, tracker.SourceGitHub, issues, opts)
+	if !ok {
+		return
+	}
+	report.SkippedPullRequests = pullRequests
+	c.JSON(http.StatusOK, report)
+}
+
+// ImportJira imports a Jira CSV or XML export. The format is taken from
+// ?format=, the file name or the content type, and otherwise sniffed.
+func (th *TrackerImportHandler) ImportJira(c *gin.Context) {
+	data, filename, opts, ok := th.readInput(c)
+	if !ok {
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = jiraFormat(filename, c.GetHeader("Content-Type"), data)
+	}
+	var issues []tracker.Issue
+	var err error
+	switch format {
+	case "csv":
+		issues, err = tracker.ParseJiraCSV(bytes.NewReader(data))
+	case "xml":
+		issues, err = tracker.ParseJiraXML(bytes.NewReader(data))
+	default:
+		problem.Write(c, problem.BadRequest("Format must be 'csv' or 'xml'"))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.BadRequest(err.Error()))
+		return
+	}
+	if report, ok := th.run(c, tracker.SourceJira, issues, opts); ok {
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func (th *TrackerImportHandler) run(c *gin.Context, source string, issues []tracker.Issue, opts tracker.Options) (*tracker.Report, bool) {
+	report, err := th.Importer.Import(c.Request.Context(), source, issues, opts)
+	if err != nil {
+		if report == nil {
+			problem.Write(c, problem.BadRequest(err.Error()))
+			return nil, false
+		}
+		problem.Write(c, problem.Internal(fmt.Sprintf("Import failed after %d issues were created and %d updated", report.Created, report.Updated), err))
+		return nil, false
+	}
+	return report, true
+}
+
+// readInput reads the export file from a multipart "file" field or the raw
+// body, and the import options from the query string. The login-to-email map
+// is given as ?user_map=login:email,... or as a JSON object in the multipart
+// field "user_map".
+func (th *TrackerImportHandler) readInput(c *gin.Context) ([]byte, string, tracker.Options, bool) {
+	var opts tracker.Options
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	for name, target := range map[string]*int{
+		"project_id":          &opts.ProjectID,
+		"manager_id":          &opts.ManagerID,
+		"default_assignee_id": &opts.DefaultAssigneeID,
+	} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			problem.Write(c, problem.BadRequest("Invalid "+name))
+			return nil, "", opts, false
+		}
+		*target = id
+	}
+
+	userMap, err := importer.ParseMapping(c.Query("user_map"))
+	if err != nil {
+		problem.Write(c, problem.BadRequest(err.Error()))
+		return nil, "", opts, false
+	}
+	opts.UserMap = userMap
+
+	var data []byte
+	var filename string
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			problem.Write(c, problem.BadRequest("Form field 'file' is required"))
+			return nil, "", opts, false
+		}
+		defer file.Close()
+		filename = header.Filename
+		if data, err = io.ReadAll(file); err != nil {
+			problem.Write(c, problem.BadRequest("Failed to read file"))
+			return nil, "", opts, false
+		}
+		if value := c.PostForm("user_map"); value != "" {
+			if err := json.Unmarshal([]byte(value), &opts.UserMap); err != nil {
+				problem.Write(c, problem.BadRequest("Form field 'user_map' must be a JSON object"))
+				return nil, "", opts, false
+			}
+		}
+	} else if data, err = io.ReadAll(c.Request.Body); err != nil {
+		problem.Write(c, problem.BadRequest("Failed to read request body"))
+		return nil, "", opts, false
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		problem.Write(c, problem.BadRequest("Export file is empty"))
+		return nil, "", opts, false
+	}
+	return data, filename, opts, true
+}
+
+func jiraFormat(filename, contentType string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".xml":
+		return "xml"
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return "csv"
+	case "application/xml", "text/xml", "application/rss+xml":
+		return "xml"
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return "xml"
+	}
+	return "csv"
+}
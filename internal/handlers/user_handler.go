@@ -1,14 +1,17 @@
 package handlers
 
 import (
-	"log"
+	"errors"
 	"net/http"
 	"github.com/gin-gonic/gin"
 	"strconv"
 
+	"github.com/togzhanzhakhani/projects/internal/dates"
 	"github.com/togzhanzhakhani/projects/internal/repository"
 	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
 	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
 )
 
 type UserHandler struct {
@@ -19,10 +22,21 @@ func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
 	return &UserHandler{UserRepo: userRepo}
 }
 
+// users returns the handler's repository scoped to the request's workspace.
+func (uh *UserHandler) users(c *gin.Context) repository.UserRepository {
+	return uh.UserRepo.WithContext(c.Request.Context())
+}
+
+// CreateUser adds a user directly, with any role. It is reserved to
+// workspace admins; everyone else onboards users by invitation.
 func (uh *UserHandler) CreateUser(c *gin.Context) {
+	if !workspace.Administers(c.Request.Context()) {
+		problem.Write(c, problem.Forbidden("Only workspace admins may create users; invite them instead"))
+		return
+	}
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		problem.Write(c, problem.BadRequest("Invalid input"))
 		return
 	}
 
@@ -30,14 +44,8 @@ func (uh *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	if _, err := uh.UserRepo.FindByEmail(user.Email); err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email already exists"})
-		return
-	}
-
-	if err := uh.UserRepo.CreateUser(&user); err != nil {
-		log.Printf("Error creating user: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	if err := uh.users(c).CreateUser(&user); err != nil {
+		problem.Write(c, validation.FromError(c, err, "Failed to create user"))
 		return
 	}
 
@@ -45,9 +53,9 @@ func (uh *UserHandler) CreateUser(c *gin.Context) {
 }
 
 func (uh *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := uh.UserRepo.GetAllUsers()
+	users, err := uh.users(c).GetAllUsers()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		problem.Write(c, problem.FromError(err, "Failed to retrieve users"))
 		return
 	}
 	c.JSON(http.StatusOK, users)
@@ -56,13 +64,13 @@ func (uh *UserHandler) GetAllUsers(c *gin.Context) {
 func (uh *UserHandler) GetUserByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		problem.Write(c, problem.BadRequest("Invalid user ID"))
 		return
 	}
 
-	user, err := uh.UserRepo.GetUserByID(uint(id))
+	user, err := uh.users(c).GetUserByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		problem.Write(c, problem.Lookup(err, "user"))
 		return
 	}
 
@@ -72,59 +80,70 @@ func (uh *UserHandler) GetUserByID(c *gin.Context) {
 func (uh *UserHandler) UpdateUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		problem.Write(c, problem.BadRequest("Invalid user ID"))
 		return
 	}
 
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		problem.Write(c, problem.BadRequest("Invalid input"))
 		return
 	}
 
-	if !validation.ValidateStruct(c, &user) {
-		return
-	}
-
-	existingUser, err := uh.UserRepo.GetUserByID(uint(id))
+	existingUser, err := uh.users(c).GetUserByID(uint(id))
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+        problem.Write(c, problem.Lookup(err, "user"))
         return
     }
 
-	if user.Email != existingUser.Email {
-		if _, err := uh.UserRepo.FindByEmail(user.Email); err == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Email already exists"})
-			return
-		}
-	}
-
 	user.ID = uint(id)
 	user.RegistrationDate = existingUser.RegistrationDate
 
-	if err := uh.UserRepo.UpdateUser(&user); err != nil {
-		log.Printf("Error updating user: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+	if !validation.ValidateStruct(c, &user) {
+		return
+	}
+
+	if p := workspace.AuthorizeRoleChange(c.Request.Context(), user.ID, existingUser.Role, user.Role); p != nil {
+		problem.Write(c, p)
+		return
+	}
+	if p := workspace.AuthorizeEmailChange(existingUser.Email, user.Email); p != nil {
+		problem.Write(c, p)
+		return
+	}
+
+	if err := uh.users(c).UpdateUser(&user); err != nil {
+		problem.Write(c, validation.FromError(c, err, "Failed to update user"))
 		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
 
+// DeleteUser removes a user from the workspace, as only workspace admins
+// may, and deletes them once they belong to no workspace.
 func (uh *UserHandler) DeleteUser(c *gin.Context) {
+	if !workspace.Administers(c.Request.Context()) {
+		problem.Write(c, problem.Forbidden("Only workspace admins may delete users"))
+		return
+	}
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		problem.Write(c, problem.BadRequest("Invalid user ID"))
 		return
 	}
 
-	if _, err := uh.UserRepo.GetUserByID(uint(id)); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	if _, err := uh.users(c).GetUserByID(uint(id)); err != nil {
+		problem.Write(c, problem.Lookup(err, "user"))
 		return
 	}
 
-	if err := uh.UserRepo.DeleteUser(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+	if err := uh.users(c).DeleteUser(uint(id)); err != nil {
+		if errors.Is(err, repository.ErrLastAdmin) {
+			problem.Write(c, problem.Conflict("A workspace needs at least one admin"))
+			return
+		}
+		problem.Write(c, problem.FromError(err, "Failed to delete user"))
 		return
 	}
 
@@ -134,13 +153,23 @@ func (uh *UserHandler) DeleteUser(c *gin.Context) {
 func (uh *UserHandler) GetTasksByUserID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		problem.Write(c, problem.BadRequest("Invalid user ID"))
 		return
 	}
 
-	tasks, err := uh.UserRepo.GetTasksByUserID(uint(id))
+	var tasks []models.Task
+	if dueBefore := c.Query("due_before"); dueBefore != "" {
+		before, err := dates.ParseBefore(dueBefore)
+		if err != nil {
+			problem.Write(c, problem.BadRequest("Invalid due_before format"))
+			return
+		}
+		tasks, err = uh.users(c).GetTasksByUserIDDueBefore(uint(id), before)
+	} else {
+		tasks, err = uh.users(c).GetTasksByUserID(uint(id))
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
+		problem.Write(c, problem.FromError(err, "Failed to retrieve tasks"))
 		return
 	}
 
@@ -150,41 +179,31 @@ func (uh *UserHandler) GetTasksByUserID(c *gin.Context) {
 func (uh *UserHandler) SearchUsersByName(c *gin.Context) {
 	name := c.Query("name")
 	if name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name query parameter is required"})
+		problem.Write(c, problem.BadRequest("Name query parameter is required"))
 		return
 	}
 
-	users, err := uh.UserRepo.FindByName(name)
+	users, err := uh.users(c).FindByName(name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users"})
+		problem.Write(c, problem.FromError(err, "Failed to search users"))
 		return
 	}
 
-	if len(users) == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"message": "No users found"})
-        return
-    }
-
 	c.JSON(http.StatusOK, users)
 }
 
 func (uh *UserHandler) SearchUsersByEmail(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email query parameter is required"})
+		problem.Write(c, problem.BadRequest("Email query parameter is required"))
 		return
 	}
 
-	users, err := uh.UserRepo.FindByEmailLike(email)
+	users, err := uh.users(c).FindByEmailLike(email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users"})
+		problem.Write(c, problem.FromError(err, "Failed to search users"))
 		return
 	}
 
-	if len(users) == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"message": "No users found"})
-        return
-    }
-
 	c.JSON(http.StatusOK, users)
 }
\ No newline at end of file
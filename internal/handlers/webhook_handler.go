@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/commits"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+const maxWebhookSize = 5 << 20
+
+// WebhookHandler receives push webhooks. The webhook secret of the target
+// workspace must sign GitHub deliveries (X-Hub-Signature-256) or be sent as
+// the GitLab token (X-Gitlab-Token); workspaces without one accept no pushes.
+type WebhookHandler struct {
+	CommitRepo    repository.CommitRepository
+	WorkspaceRepo repository.WorkspaceRepository
+	Now           func() time.Time
+}
+
+func NewWebhookHandler(commitRepo repository.CommitRepository, workspaceRepo repository.WorkspaceRepository) *WebhookHandler {
+	return &WebhookHandler{CommitRepo: commitRepo, WorkspaceRepo: workspaceRepo, Now: time.Now}
+}
+
+// commits returns the handler's repository scoped to the request's workspace.
+func (wh *WebhookHandler) commits(c *gin.Context) repository.CommitRepository {
+	return wh.CommitRepo.WithContext(c.Request.Context())
+}
+
+type linkedCommit struct {
+	TaskID int    `json:"task_id"`
+	SHA    string `json:"sha"`
+	Closes bool   `json:"closes"`
+}
+
+type pushReport struct {
+	Repository     string         `json:"repository"`
+	Commits        int            `json:"commits"`
+	Linked         []linkedCommit `json:"linked"`
+	CompletedTasks []int          `json:"completed_tasks"`
+	UnknownTasks   []int          `json:"unknown_tasks"`
+}
+
+// Push links the commits of a GitHub or GitLab push to the tasks referenced in
+// their messages ("#123"). A closing keyword ("fixes #123") also moves the
+// task to done, if the push is to the default branch and transition=false was
+// not given. Redelivered pushes are harmless: commits are linked only once.
+//
+// Pushes only reach the tasks of the workspace given by the workspace query
+// parameter, or of the default workspace, since hooks cannot send headers,
+// and must be signed with that workspace's secret.
+func (wh *WebhookHandler) Push(c *gin.Context) {
+	workspaceID, ok := workspace.ParseID(c.Query("workspace"))
+	if !ok {
+		problem.Write(c, problem.BadRequest("Invalid workspace ID"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookSize))
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Failed to read request body"))
+		return
+	}
+	ws, err := wh.WorkspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		problem.Write(c, problem.FromError(err, "Failed to look up workspace"))
+		return
+	}
+	if ws == nil || ws.WebhookSecret == "" {
+		problem.Write(c, problem.Unauthorized("Webhooks are not enabled for this workspace"))
+		return
+	}
+	if !verifySignature(c, body, ws.WebhookSecret) {
+		problem.Write(c, problem.Unauthorized("Invalid webhook signature"))
+		return
+	}
+	c.Request = c.Request.WithContext(workspace.NewContext(c.Request.Context(), models.Membership{WorkspaceID: workspaceID}))
+	if event := c.GetHeader("X-GitHub-Event"); event != "" && event != "push" {
+		c.JSON(http.StatusOK, gin.H{"message": "Event ignored", "event": event})
+		return
+	}
+
+	push, err := commits.ParsePush(body)
+	if err != nil {
+		problem.Write(c, problem.BadRequest(err.Error()))
+		return
+	}
+	transition := c.Query("transition") != "false" && push.OnDefaultBranch()
+
+	refs := make(map[string][]commits.Reference)
+	var ids []int
+	for _, commit := range push.Commits {
+		refs[commit.SHA] = commits.ParseReferences(commit.Message)
+		for _, ref := range refs[commit.SHA] {
+			ids = append(ids, ref.TaskID)
+		}
+	}
+	existing, err := wh.commits(c).ExistingTaskIDs(ids)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to look up tasks"))
+		return
+	}
+
+	report := pushReport{
+		Repository:     push.Repository,
+		Commits:        len(push.Commits),
+		Linked:         []linkedCommit{},
+		CompletedTasks: []int{},
+		UnknownTasks:   []int{},
+	}
+	unknown := make(map[int]bool)
+	for _, commit := range push.Commits {
+		committedAt := commit.Timestamp
+		if committedAt.IsZero() {
+			committedAt = wh.Now()
+		}
+		for _, ref := range refs[commit.SHA] {
+			if !existing[ref.TaskID] {
+				if !unknown[ref.TaskID] {
+					unknown[ref.TaskID] = true
+					report.UnknownTasks = append(report.UnknownTasks, ref.TaskID)
+				}
+				continue
+			}
+
+			_, err := wh.commits(c).AttachCommit(&models.TaskCommit{
+				TaskID:      ref.TaskID,
+				SHA:         commit.SHA,
+				Repository:  push.Repository,
+				Author:      commit.Author,
+				AuthorEmail: commit.AuthorEmail,
+				URL:         commit.URL,
+				Message:     commit.Message,
+				Closes:      ref.Closes,
+				CommittedAt: committedAt,
+			})
+			if err != nil {
+				problem.Write(c, problem.FromError(err, "Failed to link commit"))
+				return
+			}
+			report.Linked = append(report.Linked, linkedCommit{TaskID: ref.TaskID, SHA: commit.SHA, Closes: ref.Closes})
+
+			if ref.Closes && transition {
+				completed, err := wh.commits(c).CompleteTask(ref.TaskID, committedAt)
+				if err != nil {
+					problem.Write(c, problem.FromError(err, "Failed to update task"))
+					return
+				}
+				if completed {
+					report.CompletedTasks = append(report.CompletedTasks, ref.TaskID)
+				}
+			}
+		}
+	}
+	sort.Ints(report.UnknownTasks)
+	c.JSON(http.StatusOK, report)
+}
+
+// verifySignature checks a delivery against the secret: the GitLab token if
+// one was sent, the GitHub signature of the body otherwise.
+func verifySignature(c *gin.Context, body []byte, secret string) bool {
+	if token := c.GetHeader("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(c.GetHeader("X-Hub-Signature-256")), []byte(expected))
+}
+
+// GetTaskCommits lists the commits linked to a task, newest first.
+func (wh *WebhookHandler) GetTaskCommits(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid task ID"))
+		return
+	}
+	taskCommits, err := wh.commits(c).GetCommitsByTaskID(id)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve commits"))
+		return
+	}
+	c.JSON(http.StatusOK, taskCommits)
+}
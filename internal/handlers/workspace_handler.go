@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"gorm.io/gorm"
+)
+
+// WorkspaceHandler serves the workspaces of the caller and their members.
+// The workspace is taken from the path rather than the workspace header, and
+// callers who are not members of it are told it does not exist. Members are
+// added by Invitations, so that users only join the workspaces they accept.
+type WorkspaceHandler struct {
+	WorkspaceRepo repository.WorkspaceRepository
+	Invitations   *InvitationHandler
+}
+
+func NewWorkspaceHandler(wr repository.WorkspaceRepository, invitations *InvitationHandler) *WorkspaceHandler {
+	return &WorkspaceHandler{WorkspaceRepo: wr, Invitations: invitations}
+}
+
+// memberInput is the role of a member. The field is not called Role so that
+// its messages are not those of the user's role.
+type memberInput struct {
+	WorkspaceRole string `json:"role" validate:"required,oneof=admin member"`
+}
+
+func (wh *WorkspaceHandler) GetWorkspaces(c *gin.Context) {
+	userID, _ := auth.UserID(c.Request.Context())
+	workspaces, err := wh.WorkspaceRepo.GetWorkspacesByUser(userID)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve workspaces"))
+		return
+	}
+	c.JSON(http.StatusOK, workspaces)
+}
+
+func (wh *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var input struct {
+		Name string `json:"name" validate:"required,max=100"`
+		Slug string `json:"slug" validate:"required,max=50,slug"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+
+	userID, _ := auth.UserID(c.Request.Context())
+	ws := models.Workspace{Name: input.Name, Slug: input.Slug}
+	if err := wh.WorkspaceRepo.CreateWorkspace(&ws, userID); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create workspace"))
+		return
+	}
+	c.JSON(http.StatusCreated, ws)
+}
+
+func (wh *WorkspaceHandler) GetMembers(c *gin.Context) {
+	workspaceID, ok := wh.membership(c, false)
+	if !ok {
+		return
+	}
+	members, err := wh.WorkspaceRepo.GetMembers(workspaceID)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to retrieve members"))
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember invites an email address to the workspace with a role. The
+// user joins when they accept the invitation; someone without an account
+// gets one as a developer.
+func (wh *WorkspaceHandler) AddMember(c *gin.Context) {
+	workspaceID, ok := wh.membership(c, true)
+	if !ok {
+		return
+	}
+	var input struct {
+		Email string `json:"email" validate:"required,email"`
+		memberInput
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+
+	invitation := models.Invitation{
+		Email:         strings.ToLower(strings.TrimSpace(input.Email)),
+		Role:          "developer",
+		WorkspaceID:   workspaceID,
+		WorkspaceRole: input.WorkspaceRole,
+	}
+	invitations := wh.Invitations.InvitationRepo.WithContext(c.Request.Context())
+	if !wh.Invitations.issue(c, invitations, &invitation) {
+		return
+	}
+	c.JSON(http.StatusCreated, invitation)
+}
+
+func (wh *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	workspaceID, ok := wh.membership(c, true)
+	if !ok {
+		return
+	}
+	userID, ok := memberID(c)
+	if !ok {
+		return
+	}
+	var input memberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Write(c, problem.BadRequest("Invalid input"))
+		return
+	}
+	if !validation.ValidateStruct(c, &input) {
+		return
+	}
+
+	membership, err := wh.WorkspaceRepo.GetMember(workspaceID, userID)
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "member"))
+		return
+	}
+	membership.Role = input.WorkspaceRole
+	if err := wh.WorkspaceRepo.UpdateMember(membership); err != nil {
+		problem.Write(c, memberError(err, "Failed to update member"))
+		return
+	}
+	c.JSON(http.StatusOK, membership)
+}
+
+func (wh *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	workspaceID, ok := wh.membership(c, true)
+	if !ok {
+		return
+	}
+	userID, ok := memberID(c)
+	if !ok {
+		return
+	}
+	if _, err := wh.WorkspaceRepo.GetMember(workspaceID, userID); err != nil {
+		problem.Write(c, problem.Lookup(err, "member"))
+		return
+	}
+	if err := wh.WorkspaceRepo.RemoveMember(workspaceID, userID); err != nil {
+		problem.Write(c, memberError(err, "Failed to remove member"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// RotateWebhookSecret sets a new secret for the push webhooks of the
+// workspace, which stops accepting the old one. The secret is only returned
+// here.
+func (wh *WorkspaceHandler) RotateWebhookSecret(c *gin.Context) {
+	workspaceID, ok := wh.membership(c, true)
+	if !ok {
+		return
+	}
+	ws, err := wh.WorkspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "workspace"))
+		return
+	}
+	secret, err := newSecret()
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to create webhook secret"))
+		return
+	}
+	ws.WebhookSecret = secret
+	if err := wh.WorkspaceRepo.UpdateWorkspace(ws); err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to update workspace"))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"secret": secret,
+		"url":    "/webhooks/push?workspace=" + strconv.FormatUint(uint64(workspaceID), 10),
+	})
+}
+
+// membership checks that the caller is a member of the workspace in the
+// path, and an admin of it if admin is set, and returns its ID.
+func (wh *WorkspaceHandler) membership(c *gin.Context, admin bool) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid workspace ID"))
+		return 0, false
+	}
+	userID, _ := auth.UserID(c.Request.Context())
+	membership, err := wh.WorkspaceRepo.GetMember(uint(id), userID)
+	if err != nil {
+		problem.Write(c, problem.Lookup(err, "workspace"))
+		return 0, false
+	}
+	if admin && membership.Role != models.WorkspaceAdmin {
+		problem.Write(c, problem.Forbidden("Only workspace admins may do this"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func memberID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		problem.Write(c, problem.BadRequest("Invalid user ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func memberError(err error, detail string) *problem.Problem {
+	if errors.Is(err, repository.ErrLastAdmin) {
+		return problem.Conflict("A workspace needs at least one admin")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return problem.Lookup(err, "member")
+	}
+	return problem.FromError(err, detail)
+}
@@ -0,0 +1,155 @@
+// Package i18n chooses the language of a request from its Accept-Language
+// header and translates messages into it. Packages register their message
+// catalogs for each supported locale from init.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/kk"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+)
+
+// Default is the locale used when the client accepts none of the supported
+// ones, and for messages missing from a catalog.
+const Default = "en"
+
+const translatorKey = "i18n_translator"
+
+var universal = ut.New(en.New(), en.New(), ru.New(), kk.New())
+
+// catalogs keeps the registered messages by locale for Check.
+var catalogs = make(map[string]map[string]string)
+
+// Locales lists the supported locales.
+func Locales() []string {
+	return []string{"en", "ru", "kk"}
+}
+
+// MustRegister adds a locale's messages. Messages take parameters as {0},
+// {1}, ... in that order. It panics on a malformed message or an unsupported
+// locale, as catalogs are registered from init.
+func MustRegister(locale string, messages map[string]string) {
+	trans, found := universal.GetTranslator(locale)
+	if !found {
+		panic(fmt.Sprintf("i18n: unsupported locale %q", locale))
+	}
+	if catalogs[locale] == nil {
+		catalogs[locale] = make(map[string]string)
+	}
+	for key, text := range messages {
+		if err := trans.Add(key, text, false); err != nil {
+			panic(fmt.Sprintf("i18n: %v", err))
+		}
+		catalogs[locale][key] = text
+	}
+}
+
+// Check reports messages of the Default locale that another locale lacks,
+// or translates with a different number of parameters.
+func Check() error {
+	var problems []string
+	for _, locale := range Locales() {
+		if locale == Default {
+			continue
+		}
+		for key, text := range catalogs[Default] {
+			translated, ok := catalogs[locale][key]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s: missing %q", locale, key))
+			case strings.Count(translated, "{") != strings.Count(text, "{"):
+				problems = append(problems, fmt.Sprintf("%s: %q has different parameters", locale, key))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("incomplete translations: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Translator returns the translator for the request's language, negotiated
+// on first use. The chosen locale is reported in Content-Language.
+func Translator(c *gin.Context) ut.Translator {
+	if value, ok := c.Get(translatorKey); ok {
+		return value.(ut.Translator)
+	}
+	trans := Negotiate(c.GetHeader("Accept-Language"))
+	c.Set(translatorKey, trans)
+	c.Header("Content-Language", trans.Locale())
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return trans
+}
+
+// Negotiate picks the supported locale the client prefers most, matching
+// regional tags such as ru-RU by their language, and falls back to Default.
+func Negotiate(acceptLanguage string) ut.Translator {
+	type choice struct {
+		locale string
+		q      float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if language == "" || q <= 0 {
+			continue
+		}
+		choices = append(choices, choice{language, q})
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+
+	for _, choice := range choices {
+		if trans, found := universal.GetTranslator(choice.locale); found {
+			return trans
+		}
+	}
+	trans, _ := universal.GetTranslator(Default)
+	return trans
+}
+
+// Lookup translates key into the translator's locale only.
+func Lookup(trans ut.Translator, key string, params ...string) (string, bool) {
+	text, err := trans.T(key, params...)
+	if err != nil {
+		return "", false
+	}
+	return text, true
+}
+
+// T translates key, falling back to the Default locale and then to the key
+// itself.
+func T(trans ut.Translator, key string, params ...string) string {
+	if text, ok := Lookup(trans, key, params...); ok {
+		return text
+	}
+	if fallback, found := universal.GetTranslator(Default); found {
+		if text, ok := Lookup(fallback, key, params...); ok {
+			return text
+		}
+	}
+	return key
+}
+
+// English returns the translator of the Default locale, for messages that
+// are not tied to a request.
+func English() ut.Translator {
+	trans, _ := universal.GetTranslator(Default)
+	return trans
+}
@@ -0,0 +1,112 @@
+// Package ical writes iCalendar (RFC 5545) documents.
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+)
+
+// Property is a single content line such as "DTSTART;VALUE=DATE:20240701".
+type Property struct {
+	Name   string
+	Params string
+	Value  string
+}
+
+// Component is a VEVENT, VTODO or similar block.
+type Component struct {
+	Name       string
+	Properties []Property
+}
+
+// Calendar is a VCALENDAR with its components.
+type Calendar struct {
+	ProdID     string
+	Name       string
+	Components []Component
+}
+
+// Text adds a property with an escaped text value.
+func (c *Component) Text(name, value string) {
+	c.Properties = append(c.Properties, Property{Name: name, Value: EscapeText(value)})
+}
+
+// DateTime adds a UTC date-time property.
+func (c *Component) DateTime(name string, t time.Time) {
+	c.Properties = append(c.Properties, Property{Name: name, Value: t.UTC().Format(dateTimeFormat)})
+}
+
+// Date adds an all-day date property.
+func (c *Component) Date(name string, t time.Time) {
+	c.Properties = append(c.Properties, Property{Name: name, Params: "VALUE=DATE", Value: t.Format(dateFormat)})
+}
+
+// Raw adds a property whose value is written as is.
+func (c *Component) Raw(name, value string) {
+	c.Properties = append(c.Properties, Property{Name: name, Value: value})
+}
+
+// WriteTo writes the calendar with CRLF line endings and lines folded at 75
+// octets.
+func (cal *Calendar) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+cal.ProdID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if cal.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+EscapeText(cal.Name))
+	}
+	for _, component := range cal.Components {
+		writeLine(&b, "BEGIN:"+component.Name)
+		for _, p := range component.Properties {
+			name := p.Name
+			if p.Params != "" {
+				name += ";" + p.Params
+			}
+			writeLine(&b, name+":"+p.Value)
+		}
+		writeLine(&b, "END:"+component.Name)
+	}
+	writeLine(&b, "END:VCALENDAR")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// EscapeText escapes a TEXT value.
+func EscapeText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// writeLine folds a content line so that no line exceeds 75 octets, without
+// splitting UTF-8 sequences.
+func writeLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// UID builds a globally unique identifier for a calendar object.
+func UID(kind string, id interface{}, domain string) string {
+	return fmt.Sprintf("%s-%v@%s", kind, id, domain)
+}
@@ -0,0 +1,267 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+)
+
+const (
+	EntityUsers    = "users"
+	EntityProjects = "projects"
+	EntityTasks    = "tasks"
+)
+
+// Lookup resolves references to existing rows. Each method is called at most
+// once per import with every value referenced in the file.
+type Lookup interface {
+	ExistingUserIDs(ids []int) (map[int]bool, error)
+	ExistingProjectIDs(ids []int) (map[int]bool, error)
+	UserIDsByEmail(emails []string) (map[string]int, error)
+}
+
+// Record is a valid row converted into a model ready to be created.
+type Record struct {
+	Line  int
+	Model interface{}
+}
+
+// RowError lists everything wrong with one row.
+type RowError struct {
+	Line   int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+// Build converts rows into models of the given entity, validating them with
+// the same rules as the API. Invalid rows are reported in the returned
+// RowErrors and left out of the records.
+func Build(entity string, rows []Row, lookup Lookup) ([]Record, []RowError, error) {
+	switch entity {
+	case EntityUsers:
+		return buildUsers(rows, lookup)
+	case EntityProjects:
+		return buildProjects(rows, lookup)
+	case EntityTasks:
+		return buildTasks(rows, lookup)
+	}
+	return nil, nil, fmt.Errorf("unsupported entity %q", entity)
+}
+
+func buildUsers(rows []Row, lookup Lookup) ([]Record, []RowError, error) {
+	var emails []string
+	for _, row := range rows {
+		if email := row.Fields["email"]; email != "" {
+			emails = append(emails, strings.ToLower(email))
+		}
+	}
+	existing, err := lookup.UserIDsByEmail(uniqueStrings(emails))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []Record
+	var rowErrors []RowError
+	seen := make(map[string]int)
+	for _, row := range rows {
+		user := models.User{
+			Name:  row.Fields["name"],
+			Email: row.Fields["email"],
+			Role:  row.Fields["role"],
+		}
+
+		errs := validation.Validate(&user)
+		email := strings.ToLower(user.Email)
+		if _, ok := existing[email]; ok && email != "" {
+			errs = append(errs, validation.GetMessage("Email.unique_email"))
+		} else if line, ok := seen[email]; ok && email != "" {
+			errs = append(errs, fmt.Sprintf("Email is duplicated in row %d", line))
+		}
+		seen[email] = row.Line
+
+		records, rowErrors = collect(records, rowErrors, row, &user, errs)
+	}
+	return records, rowErrors, nil
+}
+
+func buildProjects(rows []Row, lookup Lookup) ([]Record, []RowError, error) {
+	resolver, err := newResolver(rows, lookup, "manager")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []Record
+	var rowErrors []RowError
+	for _, row := range rows {
+		var errs []string
+		project := models.Project{
+			Name:        row.Fields["name"],
+			Description: row.Fields["description"],
+		}
+		project.StartDate, errs = parseDate(row, "start_date", errs)
+		project.EndDate, errs = parseDate(row, "end_date", errs)
+		project.ManagerID, errs = resolver.user(row, "manager", errs)
+
+		errs = append(errs, validation.Validate(&project)...)
+		records, rowErrors = collect(records, rowErrors, row, &project, errs)
+	}
+	return records, rowErrors, nil
+}
+
+func buildTasks(rows []Row, lookup Lookup) ([]Record, []RowError, error) {
+	resolver, err := newResolver(rows, lookup, "assignee")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []Record
+	var rowErrors []RowError
+	for _, row := range rows {
+		var errs []string
+		task := models.Task{
+			Title:       row.Fields["title"],
+			Description: row.Fields["description"],
+			Priority:    row.Fields["priority"],
+			Status:      row.Fields["status"],
+			DueTimezone: row.Fields["due_timezone"],
+		}
+		task.CreatedAt, errs = parseDate(row, "created_at", errs)
+		task.CompletedAt, errs = parseDate(row, "completed_at", errs)
+		task.AssigneeID, errs = resolver.user(row, "assignee", errs)
+		task.ProjectID, errs = resolver.project(row, errs)
+		if value := row.Fields["due_date"]; value != "" {
+			due, err := dates.ParseDue(value, task.DueTimezone)
+			if err != nil {
+				errs = append(errs, "Invalid due_date format")
+			}
+			task.DueDate = due
+		}
+
+		errs = append(errs, validation.Validate(&task)...)
+		records, rowErrors = collect(records, rowErrors, row, &task, errs)
+	}
+	return records, rowErrors, nil
+}
+
+func collect(records []Record, rowErrors []RowError, row Row, model interface{}, errs []string) ([]Record, []RowError) {
+	if len(errs) > 0 {
+		return records, append(rowErrors, RowError{Line: row.Line, Errors: errs})
+	}
+	return append(records, Record{Line: row.Line, Model: model}), rowErrors
+}
+
+func parseDate(row Row, field string, errs []string) (time.Time, []string) {
+	value := row.Fields[field]
+	if value == "" {
+		return time.Time{}, errs
+	}
+	t, err := time.Parse(dates.DateLayout, value)
+	if err != nil {
+		return time.Time{}, append(errs, fmt.Sprintf("Invalid %s format", field))
+	}
+	return t, errs
+}
+
+// resolver checks user and project references, which may be given as
+// <role>_id or <role>_email for users and project_id for projects.
+type resolver struct {
+	userIDs    map[int]bool
+	projectIDs map[int]bool
+	emails     map[string]int
+}
+
+func newResolver(rows []Row, lookup Lookup, role string) (*resolver, error) {
+	var userIDs, projectIDs []int
+	var emails []string
+	for _, row := range rows {
+		if id, err := strconv.Atoi(row.Fields[role+"_id"]); err == nil {
+			userIDs = append(userIDs, id)
+		}
+		if email := row.Fields[role+"_email"]; email != "" {
+			emails = append(emails, strings.ToLower(email))
+		}
+		if id, err := strconv.Atoi(row.Fields["project_id"]); err == nil {
+			projectIDs = append(projectIDs, id)
+		}
+	}
+
+	r := &resolver{}
+	var err error
+	if r.userIDs, err = lookup.ExistingUserIDs(uniqueInts(userIDs)); err != nil {
+		return nil, err
+	}
+	if r.emails, err = lookup.UserIDsByEmail(uniqueStrings(emails)); err != nil {
+		return nil, err
+	}
+	if r.projectIDs, err = lookup.ExistingProjectIDs(uniqueInts(projectIDs)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *resolver) user(row Row, role string, errs []string) (int, []string) {
+	label := strings.ToUpper(role[:1]) + role[1:]
+	if email := row.Fields[role+"_email"]; email != "" {
+		id, ok := r.emails[strings.ToLower(email)]
+		if !ok {
+			return 0, append(errs, fmt.Sprintf("%s with email %s does not exist", label, email))
+		}
+		return id, errs
+	}
+
+	value := row.Fields[role+"_id"]
+	if value == "" {
+		return 0, errs
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, append(errs, fmt.Sprintf("Invalid %s_id", role))
+	}
+	if id > 0 && !r.userIDs[id] {
+		return id, append(errs, label+" does not exist")
+	}
+	return id, errs
+}
+
+func (r *resolver) project(row Row, errs []string) (int, []string) {
+	value := row.Fields["project_id"]
+	if value == "" {
+		return 0, errs
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, append(errs, "Invalid project_id")
+	}
+	if id > 0 && !r.projectIDs[id] {
+		return id, append(errs, "Project does not exist")
+	}
+	return id, errs
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	var out []int
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+// Package importer turns CSV and NDJSON files into validated users, projects
+// and tasks for bulk import.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Row is one record of the input with its fields renamed by the column
+// mapping. Line is the 1-based data row (CSV, header excluded) or line number
+// (NDJSON), as shown in error reports.
+type Row struct {
+	Line   int
+	Fields map[string]string
+}
+
+// Read parses the input in the given format. mapping renames source columns
+// (or NDJSON keys) to field names; columns not in mapping keep their name.
+func Read(format string, r io.Reader, mapping map[string]string) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, mapping)
+	case FormatNDJSON:
+		return readNDJSON(r, mapping)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+func readCSV(r io.Reader, mapping map[string]string) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, column := range header {
+		// Spreadsheet exports often start with a UTF-8 byte order mark.
+		column = strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")
+		header[i] = mapColumn(column, mapping)
+	}
+
+	var rows []Row
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				fields[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, Row{Line: line, Fields: fields})
+	}
+	return rows, nil
+}
+
+func readNDJSON(r io.Reader, mapping map[string]string) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []Row
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		fields := make(map[string]string, len(object))
+		for key, value := range object {
+			if value == nil {
+				continue
+			}
+			fields[mapColumn(key, mapping)] = strings.TrimSpace(fmt.Sprint(value))
+		}
+		rows = append(rows, Row{Line: line, Fields: fields})
+	}
+	return rows, scanner.Err()
+}
+
+func mapColumn(column string, mapping map[string]string) string {
+	if target, ok := mapping[column]; ok {
+		return target
+	}
+	return strings.ToLower(column)
+}
+
+// ParseMapping parses a "source:target,source:target" column mapping.
+func ParseMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if value == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid column mapping %q", pair)
+		}
+		mapping[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return mapping, nil
+}
@@ -0,0 +1,31 @@
+// Package invite delivers invitations to the people they are addressed to.
+package invite
+
+import (
+	"log"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+// Message is an invitation to deliver, with the token that accepts it. The
+// token is only known while the invitation is created or resent.
+type Message struct {
+	Invitation models.Invitation
+	Token      string
+}
+
+// Sender delivers invitations, e.g. by e-mail.
+type Sender interface {
+	Send(message Message) error
+}
+
+// LogSender writes invitations, tokens included, to the standard logger. It
+// is meant for development.
+type LogSender struct{}
+
+func (LogSender) Send(message Message) error {
+	log.Printf("Invitation %d for %s (role %s, expires %s): token %s", message.Invitation.ID, message.Invitation.Email,
+		message.Invitation.Role, message.Invitation.ExpiresAt.Format(time.RFC3339), message.Token)
+	return nil
+}
@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+)
+
+// Locker provides the leader election for job schedules: only the replica
+// holding the lock for a schedule enqueues its runs.
+type Locker interface {
+	// TryLock acquires the lock without blocking, or confirms that it is still
+	// held. It reports whether the caller holds the lock.
+	TryLock(ctx context.Context, name string) (bool, error)
+	Unlock(ctx context.Context, name string) error
+}
+
+// AdvisoryLocker implements Locker with Postgres session-level advisory
+// locks. Each held lock pins a connection from the pool; if that connection
+// dies the database releases the lock and another replica takes over.
+type AdvisoryLocker struct {
+	DB *sql.DB
+
+	mu    sync.Mutex
+	conns map[string]*sql.Conn
+}
+
+func NewAdvisoryLocker(db *sql.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{DB: db, conns: make(map[string]*sql.Conn)}
+}
+
+func (l *AdvisoryLocker) TryLock(ctx context.Context, name string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if conn, ok := l.conns[name]; ok {
+		if err := conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		conn.Close()
+		delete(l.conns, name)
+	}
+
+	conn, err := l.DB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey(name)).Scan(&locked); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+	l.conns[name] = conn
+	return true, nil
+}
+
+func (l *AdvisoryLocker) Unlock(ctx context.Context, name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	conn, ok := l.conns[name]
+	if !ok {
+		return nil
+	}
+	delete(l.conns, name)
+	defer conn.Close()
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey(name))
+	return err
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("jobs:" + name))
+	return int64(h.Sum64())
+}
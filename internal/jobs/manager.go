@@ -0,0 +1,265 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+)
+
+// Job is a unit of periodic work.
+type Job struct {
+	Name     string
+	Schedule string
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Status describes a registered job for the admin API.
+type Status struct {
+	Name      string          `json:"name"`
+	Schedule  string          `json:"schedule"`
+	Leader    bool            `json:"leader"`
+	NextRunAt *time.Time      `json:"next_run_at,omitempty"`
+	LastRun   *models.JobRun  `json:"last_run,omitempty"`
+	Runs      []models.JobRun `json:"runs"`
+}
+
+type registeredJob struct {
+	Job
+	schedule *Schedule
+	leader   bool
+	next     time.Time
+}
+
+// Manager schedules registered jobs and executes them from the job queue.
+// Every replica runs a Manager: the one holding a job's lock enqueues its runs
+// and all of them take part in working the queue.
+type Manager struct {
+	Repo         repository.JobRepository
+	Locker       Locker
+	WorkerID     string
+	Workers      int
+	PollInterval time.Duration
+	StaleAfter   time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*registeredJob
+}
+
+func NewManager(repo repository.JobRepository, locker Locker) *Manager {
+	hostname, _ := os.Hostname()
+	return &Manager{
+		Repo:         repo,
+		Locker:       locker,
+		WorkerID:     fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		Workers:      2,
+		PollInterval: 5 * time.Second,
+		StaleAfter:   time.Hour,
+		jobs:         make(map[string]*registeredJob),
+	}
+}
+
+// Register adds a job. It must be called before Start.
+func (m *Manager) Register(job Job) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if job.Timeout == 0 {
+		job.Timeout = 10 * time.Minute
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	m.jobs[job.Name] = &registeredJob{Job: job, schedule: schedule}
+	return nil
+}
+
+// Start runs the scheduler and the workers until ctx is cancelled.
+func (m *Manager) Start(ctx context.Context) {
+	for i := 0; i < m.Workers; i++ {
+		go m.work(ctx)
+	}
+
+	ticker := time.NewTicker(m.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := m.Tick(ctx, time.Now()); err != nil {
+			log.Printf("Error scheduling jobs: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			m.releaseLocks()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick enqueues the runs of every job this replica leads that are due at now.
+// Missed activations (e.g. while no replica was running) collapse into a
+// single run.
+func (m *Manager) Tick(ctx context.Context, now time.Time) error {
+	if _, err := m.Repo.RequeueStaleRuns(now.Add(-m.StaleAfter)); err != nil {
+		return err
+	}
+
+	for _, job := range m.registered() {
+		leader, err := m.Locker.TryLock(ctx, job.Name)
+		if err != nil {
+			log.Printf("Error acquiring lock for job %s: %v", job.Name, err)
+		}
+
+		m.mu.Lock()
+		if !leader {
+			job.leader = false
+			job.next = job.schedule.Next(now)
+			m.mu.Unlock()
+			continue
+		}
+		if !job.leader {
+			// Just became leader: continue from the last run any replica scheduled.
+			job.leader = true
+			job.next = job.schedule.Next(now)
+			if last, err := m.Repo.GetLastScheduledAt(job.Name); err == nil && last != nil {
+				job.next = job.schedule.Next(*last)
+			}
+		}
+		due := !job.next.IsZero() && !job.next.After(now)
+		scheduledAt := job.next
+		m.mu.Unlock()
+
+		if !due {
+			continue
+		}
+		if _, err := m.Repo.EnqueueRun(&models.JobRun{JobName: job.Name, ScheduledAt: scheduledAt}); err != nil {
+			return err
+		}
+		m.mu.Lock()
+		job.next = job.schedule.Next(now)
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+func (m *Manager) work(ctx context.Context) {
+	for {
+		worked, err := m.RunNext(ctx, time.Now())
+		if err != nil {
+			log.Printf("Error running job: %v", err)
+		}
+		if worked {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(m.PollInterval):
+		}
+	}
+}
+
+// RunNext claims one due run from the queue and executes it. It reports
+// whether a run was executed.
+func (m *Manager) RunNext(ctx context.Context, now time.Time) (bool, error) {
+	jobs := m.registered()
+	if len(jobs) == 0 {
+		return false, nil
+	}
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.Name)
+	}
+
+	run, err := m.Repo.ClaimNextRun(names, now, m.WorkerID)
+	if err != nil || run == nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	job := m.jobs[run.JobName]
+	m.mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(workspace.NewSystemContext(ctx), job.Timeout)
+	err = runSafely(runCtx, job.Run)
+	cancel()
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Status = models.JobRunSucceeded
+	if err != nil {
+		run.Status = models.JobRunFailed
+		run.Error = err.Error()
+		log.Printf("Job %s failed: %v", run.JobName, err)
+	}
+	return true, m.Repo.FinishRun(run)
+}
+
+func runSafely(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Statuses returns the registered jobs with their recent run history.
+func (m *Manager) Statuses(historySize int) ([]Status, error) {
+	var statuses []Status
+	for _, job := range m.registered() {
+		runs, err := m.Repo.GetRecentRuns(job.Name, historySize)
+		if err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		status := Status{Name: job.Name, Schedule: job.Job.Schedule, Leader: job.leader, Runs: runs}
+		if !job.next.IsZero() {
+			next := job.next
+			status.NextRunAt = &next
+		}
+		m.mu.Unlock()
+
+		if len(runs) > 0 {
+			status.LastRun = &runs[0]
+		}
+		if status.Runs == nil {
+			status.Runs = []models.JobRun{}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *Manager) registered() []*registeredJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]*registeredJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+func (m *Manager) releaseLocks() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, job := range m.registered() {
+		if err := m.Locker.Unlock(ctx, job.Name); err != nil {
+			log.Printf("Error releasing lock for job %s: %v", job.Name, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// APIToken is a personal access token: a credential a user creates for
+// scripts and CI jobs, which acts as the user but may only do what its
+// scopes grant. Only the token's SHA-256 hash is stored; Prefix, its first
+// characters, tells tokens apart.
+type APIToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	Prefix     string     `json:"prefix"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Credential holds the password of a user. It is kept apart from User so
+// that the hash is never loaded, or serialized, with the user. Consecutive
+// failed logins lock the account until LockedUntil.
+type Credential struct {
+	UserID         uint       `json:"-" gorm:"primaryKey"`
+	PasswordHash   string     `json:"-"`
+	FailedAttempts int        `json:"-" gorm:"not null;default:0"`
+	LockedUntil    *time.Time `json:"-"`
+	UpdatedAt      time.Time  `json:"-"`
+}
+
+// Session is a login. The caller sends its token as a bearer token; only
+// the token's SHA-256 hash is stored.
+type Session struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// PasswordReset lets the holder of its token set the password of a user
+// once, until it expires.
+type PasswordReset struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// OIDCLogin is a single sign-on login in progress, from the redirect to the
+// identity provider until its callback, which presents the state. The PKCE
+// code verifier and the nonce are kept for the callback to check.
+type OIDCLogin struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	StateHash string    `json:"-" gorm:"uniqueIndex"`
+	Verifier  string    `json:"-"`
+	Nonce     string    `json:"-"`
+	CreatedAt time.Time `json:"-"`
+	ExpiresAt time.Time `json:"-" gorm:"index"`
+}
+
+const (
+	AuthLoginSucceeded = "login_succeeded"
+	AuthLoginFailed    = "login_failed"
+	AuthSSOLogin       = "sso_login_succeeded"
+	AuthSSOFailed      = "sso_login_failed"
+	AuthLockedOut      = "locked_out"
+	AuthLogout         = "logout"
+	AuthResetRequested = "password_reset_requested"
+	AuthPasswordReset  = "password_reset"
+	AuthEmailChanged   = "email_changed"
+)
+
+// AuthEvent records a login, single sign-on, logout, lockout, password reset or email change. UserID is
+// nil for logins with unknown emails.
+type AuthEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    *uint     `json:"user_id,omitempty" gorm:"index"`
+	Email     string    `json:"email"`
+	Kind      string    `json:"kind"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
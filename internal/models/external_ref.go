@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	ExternalRefTask    = "task"
+	ExternalRefProject = "project"
+)
+
+// ExternalRef links a task or project to the issue or project it was imported
+// from in an external tracker, so that re-running an import updates it
+// instead of creating a duplicate.
+type ExternalRef struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Source      string    `json:"source" gorm:"not null;default:1;uniqueIndex:idx_external_ref_workspace"`
+	Kind        string    `json:"kind" gorm:"not null;default:1;uniqueIndex:idx_external_ref_workspace"`
+	ExternalID  string    `json:"external_id" gorm:"not null;default:1;uniqueIndex:idx_external_ref_workspace"`
+	EntityID    int       `json:"entity_id"`
+	WorkspaceID uint      `json:"workspace_id" gorm:"not null;default:1;uniqueIndex:idx_external_ref_workspace"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
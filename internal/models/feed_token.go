@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	FeedOwnerUser    = "user"
+	FeedOwnerProject = "project"
+)
+
+// FeedToken grants read access to one calendar feed, in the workspace it
+// was created in. Calendar clients cannot send auth headers, so the secret
+// travels in the feed URL; only its SHA-256 hash is stored.
+type FeedToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint       `json:"workspace_id" gorm:"not null;default:1;index"`
+	OwnerType   string     `json:"owner_type" gorm:"index:idx_feed_token_owner"`
+	OwnerID     uint       `json:"owner_id" gorm:"index:idx_feed_token_owner"`
+	Name        string     `json:"name"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
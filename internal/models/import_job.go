@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+// ImportJob tracks a batched import so that it can be resumed after a failure.
+// ProcessedRows counts the leading rows of the file that have been handled,
+// i.e. either created or reported as invalid.
+type ImportJob struct {
+	ID            string    `json:"id" gorm:"primaryKey"`
+	Entity        string    `json:"entity"`
+	Format        string    `json:"format"`
+	Checksum      string    `json:"-"`
+	Status        string    `json:"status"`
+	TotalRows     int       `json:"total_rows"`
+	ProcessedRows int       `json:"processed_rows"`
+	CreatedRows   int       `json:"created_rows"`
+	InvalidRows   int       `json:"invalid_rows"`
+	Error         string    `json:"error,omitempty"`
+	WorkspaceID   uint      `json:"-" gorm:"not null;default:1;index"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
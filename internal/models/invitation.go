@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Invitation lets someone join a workspace, as a new user with Role or as
+// the existing user with Email. The token is delivered to the address by an
+// invite.Sender; only its SHA-256 hash is stored. An invitation is pending
+// until it is accepted or revoked, and can only be accepted before it
+// expires.
+//
+// WorkspaceRole is the role the invitee gets in the workspace, member when
+// it is empty. Only the members route of workspace admins sets it.
+type Invitation struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Email       string `json:"email" validate:"required,email"`
+	Role        string `json:"role" validate:"required,oneof=admin manager developer"`
+	ProjectID   *int   `json:"project_id,omitempty" validate:"omitempty,gt=0,project_exists"`
+	ProjectRole string `json:"project_role,omitempty" validate:"omitempty,oneof=owner maintainer contributor viewer"`
+	InvitedBy   uint   `json:"invited_by"`
+	TokenHash   string `json:"-" gorm:"uniqueIndex"`
+	// UserID is the user who accepted the invitation.
+	UserID        *uint      `json:"user_id,omitempty"`
+	WorkspaceID   uint       `json:"workspace_id" gorm:"not null;default:1;index"`
+	WorkspaceRole string     `json:"workspace_role,omitempty"`
+	SentAt        time.Time  `json:"sent_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	AcceptedAt    *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
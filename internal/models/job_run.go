@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	JobRunQueued    = "queued"
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// JobRun is both an entry in the job queue and, once finished, a record in the
+// run history of a scheduled job. A job is enqueued at most once per scheduled
+// time, whichever replica enqueues it.
+type JobRun struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	JobName     string     `json:"job_name" gorm:"uniqueIndex:idx_job_run_schedule;index:idx_job_run_queue"`
+	ScheduledAt time.Time  `json:"scheduled_at" gorm:"uniqueIndex:idx_job_run_schedule;index:idx_job_run_queue"`
+	Status      string     `json:"status" gorm:"index:idx_job_run_queue"`
+	Attempts    int        `json:"attempts"`
+	WorkerID    string     `json:"worker_id,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -8,6 +8,7 @@ type Project struct {
 	Description  string    `json:"description" validate:"required,max=100"`
 	StartDate    time.Time `json:"start_date" validate:"required"`
 	EndDate      time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
-	ManagerID    int       `json:"manager_id" validate:"required,gt=0"`
+	ManagerID    int       `json:"manager_id" validate:"required,gt=0,user_exists,role_in=admin manager"`
+	WorkspaceID  uint      `json:"workspace_id,omitempty" gorm:"not null;default:1;index"`
 }
 
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RateLimitBucket is the token bucket of a client on a route, for rate
+// limits shared by the replicas of the API. Tokens is how many requests
+// were left at UpdatedAt; the bucket refills from there.
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey"`
+	Tokens    float64   `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RecurringTask is a template from which concrete tasks are generated ahead of
+// time according to RRule. Editing "this and future" occurrences ends the
+// template and starts a new one in the same series.
+type RecurringTask struct {
+	ID              int       `json:"id"`
+	SeriesID        int       `json:"series_id" gorm:"index"`
+	Title           string    `json:"title" validate:"required"`
+	Description     string    `json:"description" validate:"required,max=100"`
+	Priority        string    `json:"priority" validate:"oneof=low medium high"`
+	AssigneeID      int       `json:"assignee_id" validate:"required,gt=0,user_exists,project_member=ProjectID"`
+	ProjectID       int       `json:"project_id" validate:"required,gt=0,project_exists"`
+	RRule           string    `json:"rrule" validate:"required"`
+	StartsAt        time.Time `json:"starts_at" validate:"required"`
+	Timezone        string    `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	DueAfterMinutes int       `json:"due_after_minutes" validate:"gte=0"`
+	WorkspaceID     uint      `json:"workspace_id,omitempty" gorm:"not null;default:1;index"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
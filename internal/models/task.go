@@ -3,14 +3,32 @@ package models
 import "time"
 
 type Task struct {
-	ID           int       `json:"id"`
-	Title        string    `json:"title" validate:"required"`
-	Description  string    `json:"description" validate:"required,max=100"`
-	Priority     string    `json:"priority" validate:"oneof=low medium high"`
-	Status       string    `json:"status" validate:"oneof=todo in_progress done"`
-	AssigneeID   int       `json:"assignee_id" validate:"required,gt=0"`
-	ProjectID    int       `json:"project_id" validate:"required,gt=0"`
-	CreatedAt    time.Time `json:"created_at" validate:"required"`
-	CompletedAt  time.Time `json:"completed_at" validate:"required,gtfield=CreatedAt"`
+	ID           int        `json:"id"`
+	Title        string     `json:"title" validate:"required"`
+	Description  string     `json:"description" validate:"required,max=100"`
+	Priority     string     `json:"priority" validate:"oneof=low medium high"`
+	Status       string     `json:"status" validate:"oneof=todo in_progress done"`
+	AssigneeID   int        `json:"assignee_id" validate:"required,gt=0,user_exists,project_member=ProjectID"`
+	ProjectID    int        `json:"project_id" validate:"required,gt=0,project_exists"`
+	CreatedAt    time.Time  `json:"created_at" validate:"required"`
+	CompletedAt  time.Time  `json:"completed_at" validate:"required,gtfield=CreatedAt"`
+	DueDate      *time.Time `json:"due_date,omitempty" gorm:"index" validate:"omitempty"`
+	DueTimezone  string     `json:"due_timezone,omitempty" validate:"omitempty,timezone"`
+	Labels       []string   `json:"labels,omitempty" gorm:"serializer:json"`
+	WorkspaceID  uint       `json:"workspace_id,omitempty" gorm:"not null;default:1;index"`
+
+	RecurringTaskID *int       `json:"recurring_task_id,omitempty" gorm:"uniqueIndex:idx_task_occurrence"`
+	OccurrenceDate  *time.Time `json:"occurrence_date,omitempty" gorm:"uniqueIndex:idx_task_occurrence"`
 }
 
+// TaskReminder records that a due-soon or overdue event has already been
+// emitted for a task, so the reminder scheduler never sends it twice. The
+// due date is part of the key: moving the due date re-arms the reminders.
+type TaskReminder struct {
+	ID      uint          `json:"id" gorm:"primaryKey"`
+	TaskID  int           `json:"task_id" gorm:"uniqueIndex:idx_task_reminder"`
+	Kind    string        `json:"kind" gorm:"uniqueIndex:idx_task_reminder"`
+	Offset  time.Duration `json:"offset" gorm:"column:reminder_offset;uniqueIndex:idx_task_reminder"`
+	DueDate time.Time     `json:"due_date" gorm:"uniqueIndex:idx_task_reminder"`
+	SentAt  time.Time     `json:"sent_at"`
+}
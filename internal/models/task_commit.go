@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TaskCommit is a commit whose message referenced a task, e.g. "fixes #123".
+type TaskCommit struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TaskID      int       `json:"task_id" gorm:"uniqueIndex:idx_task_commit"`
+	SHA         string    `json:"sha" gorm:"uniqueIndex:idx_task_commit"`
+	Repository  string    `json:"repository"`
+	Author      string    `json:"author"`
+	AuthorEmail string    `json:"author_email"`
+	URL         string    `json:"url"`
+	Message     string    `json:"message"`
+	Closes      bool      `json:"closes"`
+	CommittedAt time.Time `json:"committed_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
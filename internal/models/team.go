@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	ProjectOwner       = "owner"
+	ProjectMaintainer  = "maintainer"
+	ProjectContributor = "contributor"
+	ProjectViewer      = "viewer"
+)
+
+// ProjectRoles are the roles of project members, from the most to the least
+// privileged.
+var ProjectRoles = []string{ProjectOwner, ProjectMaintainer, ProjectContributor, ProjectViewer}
+
+// Team is a group of users of a workspace. Adding a team to a project adds
+// its members.
+type Team struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" validate:"required,max=100"`
+	WorkspaceID uint      `json:"workspace_id,omitempty" gorm:"not null;default:1;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type TeamMember struct {
+	TeamID      uint      `json:"team_id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"primaryKey"`
+	WorkspaceID uint      `json:"workspace_id,omitempty" gorm:"not null;default:1;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProjectMember makes a user part of a project, with a role that only
+// applies there. Tasks of a project can only be assigned to its members.
+type ProjectMember struct {
+	ProjectID   int       `json:"project_id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"primaryKey"`
+	Role        string    `json:"role"`
+	WorkspaceID uint      `json:"workspace_id,omitempty" gorm:"not null;default:1;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -7,9 +7,10 @@ import(
 type User struct {
     ID              uint      `json:"id" gorm:"primaryKey"`
     Name            string    `json:"name" validate:"required"`
-    Email           string    `json:"email" validate:"required,email"`
+    Email           string    `json:"email" gorm:"uniqueIndex" validate:"required,email,unique_email"`
     RegistrationDate time.Time `json:"registration_date" gorm:"default:now()"`
     Role            string    `json:"role" validate:"required,oneof=admin manager developer"`
 }
 
-
+// RoleRanks orders the user roles by privilege. Unknown roles rank 0.
+var RoleRanks = map[string]int{"developer": 1, "manager": 2, "admin": 3}
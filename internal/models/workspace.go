@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// DefaultWorkspaceID is the workspace that data written before workspaces
+// existed was moved into.
+const DefaultWorkspaceID = 1
+
+const (
+	WorkspaceAdmin  = "admin"
+	WorkspaceMember = "member"
+)
+
+// Workspace is an organisation. Every project and task belongs to exactly
+// one, and users belong to workspaces through their memberships.
+//
+// WebhookSecret signs the push webhooks of the workspace. It is kept as is,
+// not hashed, since it is needed to check HMAC signatures, and it is never
+// serialized.
+type Workspace struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Name          string    `json:"name"`
+	Slug          string    `json:"slug" gorm:"uniqueIndex"`
+	WebhookSecret string    `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Membership makes a user part of a workspace, with a role that only applies
+// there: the same user may administer one workspace and be a plain member of
+// another.
+type Membership struct {
+	WorkspaceID uint      `json:"workspace_id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"primaryKey"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (Membership) TableName() string {
+	return "workspace_members"
+}
@@ -0,0 +1,118 @@
+package oidc
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+// Config is an OpenID Connect client registration and how the claims of
+// its ID tokens turn into users.
+type Config struct {
+	// Issuer is the issuer URL of the identity provider, where its
+	// discovery document is served. Single sign-on is off without it.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback route of this API, as registered with
+	// the identity provider.
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim names the ID token claim listing the user's groups.
+	GroupsClaim string
+	// GroupRoles maps groups to user roles. A user in several mapped
+	// groups gets the highest of their roles.
+	GroupRoles map[string]string
+	// DefaultRole is given to new users in no mapped group. When it is
+	// empty such users may not log in.
+	DefaultRole string
+	// WorkspaceID is the workspace the users who log in join.
+	WorkspaceID uint
+}
+
+var DefaultConfig = Config{
+	Scopes:      []string{"openid", "email", "profile"},
+	GroupsClaim: "groups",
+	DefaultRole: "developer",
+	WorkspaceID: models.DefaultWorkspaceID,
+}
+
+// Enabled reports whether an identity provider is configured.
+func (c Config) Enabled() bool {
+	return c.Issuer != ""
+}
+
+// LoadConfig overrides the configuration with OIDC_ISSUER, OIDC_CLIENT_ID,
+// OIDC_CLIENT_SECRET, OIDC_REDIRECT_URL, OIDC_SCOPES (space or comma
+// separated), OIDC_GROUPS_CLAIM, OIDC_GROUP_ROLES (as
+// group=role,group=role), OIDC_DEFAULT_ROLE ("none" to refuse users in no
+// mapped group) and OIDC_WORKSPACE_ID.
+func (c *Config) LoadConfig() error {
+	for name, v := range map[string]*string{
+		"OIDC_ISSUER":        &c.Issuer,
+		"OIDC_CLIENT_ID":     &c.ClientID,
+		"OIDC_CLIENT_SECRET": &c.ClientSecret,
+		"OIDC_REDIRECT_URL":  &c.RedirectURL,
+		"OIDC_GROUPS_CLAIM":  &c.GroupsClaim,
+	} {
+		if value := os.Getenv(name); value != "" {
+			*v = value
+		}
+	}
+	if v := os.Getenv("OIDC_SCOPES"); v != "" {
+		c.Scopes = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	if v := os.Getenv("OIDC_GROUP_ROLES"); v != "" {
+		roles, err := ParseGroupRoles(v)
+		if err != nil {
+			return err
+		}
+		c.GroupRoles = roles
+	}
+	if v := os.Getenv("OIDC_DEFAULT_ROLE"); v == "none" {
+		c.DefaultRole = ""
+	} else if v != "" {
+		if _, ok := models.RoleRanks[v]; !ok {
+			return fmt.Errorf("invalid OIDC_DEFAULT_ROLE %q", v)
+		}
+		c.DefaultRole = v
+	}
+	if v := os.Getenv("OIDC_WORKSPACE_ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || id == 0 {
+			return fmt.Errorf("invalid OIDC_WORKSPACE_ID %q", v)
+		}
+		c.WorkspaceID = uint(id)
+	}
+	if c.Enabled() && (c.ClientID == "" || c.RedirectURL == "") {
+		return fmt.Errorf("OIDC_ISSUER needs OIDC_CLIENT_ID and OIDC_REDIRECT_URL")
+	}
+	return nil
+}
+
+// ParseGroupRoles parses group=role pairs separated by commas.
+func ParseGroupRoles(value string) (map[string]string, error) {
+	roles := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		group, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if _, known := models.RoleRanks[role]; !ok || group == "" || !known {
+			return nil, fmt.Errorf("invalid group role %q", pair)
+		}
+		roles[group] = role
+	}
+	return roles, nil
+}
+
+// Role returns the highest role mapped from groups, and whether any was.
+func (c Config) Role(groups []string) (string, bool) {
+	role := ""
+	for _, group := range groups {
+		if r, ok := c.GroupRoles[group]; ok && models.RoleRanks[r] > models.RoleRanks[role] {
+			role = r
+		}
+	}
+	return role, role != ""
+}
@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+// Generate builds the document for the given routes. It fails if a route has
+// no entry in operations, or an entry has no route, so the document cannot
+// silently fall behind the router.
+func Generate(routes gin.RoutesInfo) (*Document, error) {
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Task Management API",
+			Version:     "1.0.0",
+			Description: "Users, projects and tasks. Errors are returned as RFC 7807 problem details (application/problem+json) with a machine-readable code and a trace ID. Callers authenticate with a bearer token in the Authorization header, a session token from POST /auth/login or an API token; the X-User-ID header identifies them only on servers configured to trust it. All but those of /workspaces, the calendar feeds and the webhooks act on the workspace in the X-Workspace-ID header, the default workspace 1 when it is omitted. Clients are rate limited per API token, user or IP address; responses carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers, and requests over the limit get 429 with Retry-After.",
+		},
+		Paths: make(map[string]PathItem),
+	}
+	for _, name := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: name})
+	}
+
+	components := schemas{}
+	problemSchema := components.ref(reflect.TypeOf(problem.Problem{}))
+
+	seen := make(map[string]bool)
+	var undocumented []string
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		op, ok := operations[key]
+		if !ok {
+			undocumented = append(undocumented, key)
+			continue
+		}
+		seen[key] = true
+
+		path, params := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op.build(route.Method, path, params, components, problemSchema)
+	}
+
+	var unrouted []string
+	for key := range operations {
+		if !seen[key] {
+			unrouted = append(unrouted, key)
+		}
+	}
+	if len(undocumented) > 0 || len(unrouted) > 0 {
+		sort.Strings(undocumented)
+		sort.Strings(unrouted)
+		return nil, fmt.Errorf("routes and operations differ: undocumented routes %v, operations without a route %v", undocumented, unrouted)
+	}
+
+	doc.Components.Schemas = components
+	return doc, nil
+}
+
+func (op operation) build(method, path string, params []Parameter, components schemas, problemSchema *Schema) *Operation {
+	for i, param := range params {
+		if schema, ok := op.PathParams[param.Name]; ok {
+			params[i].Schema = schema
+		}
+	}
+	result := &Operation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: operationID(method, path),
+		Parameters:  append(params, op.Query...),
+		Responses: map[string]Response{
+			"default": {
+				Description: "Error",
+				Content:     map[string]MediaType{problem.ContentType: {Schema: problemSchema}},
+			},
+		},
+	}
+	if op.Tag != "" {
+		result.Tags = []string{op.Tag}
+	}
+
+	if op.Input != nil || len(op.Body) > 0 {
+		body := &RequestBody{Required: op.Input != nil, Content: map[string]MediaType{}}
+		if op.Input != nil {
+			body.Content["application/json"] = MediaType{Schema: components.inputRef(reflect.TypeOf(op.Input))}
+		}
+		for contentType, schema := range op.Body {
+			body.Content[contentType] = MediaType{Schema: schema}
+		}
+		result.RequestBody = body
+	}
+
+	response := Response{Description: http.StatusText(op.Status)}
+	if op.Output != nil || len(op.Produces) > 0 {
+		response.Content = map[string]MediaType{}
+	}
+	if op.Output != nil {
+		schema := &Schema{Type: "object"}
+		if _, generic := op.Output.(map[string]interface{}); !generic {
+			schema = components.of(reflect.TypeOf(op.Output))
+		}
+		response.Content["application/json"] = MediaType{Schema: schema}
+	}
+	for contentType, schema := range op.Produces {
+		response.Content[contentType] = MediaType{Schema: schema}
+	}
+	result.Responses[fmt.Sprint(op.Status)] = response
+	return result
+}
+
+// openAPIPath converts a gin path to an OpenAPI path and its parameters:
+// "/users/:id" becomes "/users/{id}".
+func openAPIPath(path string) (string, []Parameter) {
+	segments := strings.Split(path, "/")
+	var params []Parameter
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+		name := segment[1:]
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "Id") {
+			schema = &Schema{Type: "integer", Format: "int64"}
+		}
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+		segments[i] = "{" + name + "}"
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives an ID from the method and path:
+// "GET /users/{id}/tasks" becomes "getUsersIdTasks".
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '-' || r == '.'
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package openapi
+
+import "github.com/togzhanzhakhani/projects/internal/i18n"
+
+// Messages for values that do not match the spec. They follow the field name,
+// which is reported separately.
+func init() {
+	i18n.MustRegister("en", map[string]string{
+		"openapi.required":          "is required",
+		"openapi.body_required":     "request body is required",
+		"openapi.json":              "must be valid JSON",
+		"openapi.null":              "must not be null",
+		"openapi.object":            "must be an object",
+		"openapi.array":             "must be an array",
+		"openapi.string":            "must be a string",
+		"openapi.number":            "must be a number",
+		"openapi.integer":           "must be an integer",
+		"openapi.boolean":           "must be true or false",
+		"openapi.enum":              "must be one of: {0}",
+		"openapi.not_empty":         "must not be empty",
+		"openapi.min_length":        "must be at least {0} characters long",
+		"openapi.max_length":        "must be at most {0} characters long",
+		"openapi.date":              "must be a date in YYYY-MM-DD format",
+		"openapi.date_time":         "must be an RFC 3339 timestamp",
+		"openapi.email":             "must be a valid email address",
+		"openapi.exclusive_minimum": "must be greater than {0}",
+		"openapi.minimum":           "must be at least {0}",
+		"openapi.maximum":           "must be at most {0}",
+	})
+	i18n.MustRegister("ru", map[string]string{
+		"openapi.required":          "обязательно",
+		"openapi.body_required":     "тело запроса обязательно",
+		"openapi.json":              "должно быть корректным JSON",
+		"openapi.null":              "не может быть null",
+		"openapi.object":            "должно быть объектом",
+		"openapi.array":             "должно быть массивом",
+		"openapi.string":            "должно быть строкой",
+		"openapi.number":            "должно быть числом",
+		"openapi.integer":           "должно быть целым числом",
+		"openapi.boolean":           "должно быть true или false",
+		"openapi.enum":              "должно быть одним из: {0}",
+		"openapi.not_empty":         "не может быть пустым",
+		"openapi.min_length":        "должно содержать не менее {0} символов",
+		"openapi.max_length":        "должно содержать не более {0} символов",
+		"openapi.date":              "должно быть датой в формате YYYY-MM-DD",
+		"openapi.date_time":         "должно быть меткой времени RFC 3339",
+		"openapi.email":             "должно быть корректным адресом электронной почты",
+		"openapi.exclusive_minimum": "должно быть больше {0}",
+		"openapi.minimum":           "должно быть не меньше {0}",
+		"openapi.maximum":           "должно быть не больше {0}",
+	})
+	i18n.MustRegister("kk", map[string]string{
+		"openapi.required":          "міндетті",
+		"openapi.body_required":     "сұрау денесі міндетті",
+		"openapi.json":              "жарамды JSON болуы керек",
+		"openapi.null":              "null болмауы керек",
+		"openapi.object":            "объект болуы керек",
+		"openapi.array":             "массив болуы керек",
+		"openapi.string":            "жол болуы керек",
+		"openapi.number":            "сан болуы керек",
+		"openapi.integer":           "бүтін сан болуы керек",
+		"openapi.boolean":           "true немесе false болуы керек",
+		"openapi.enum":              "келесілердің бірі болуы керек: {0}",
+		"openapi.not_empty":         "бос болмауы керек",
+		"openapi.min_length":        "кемінде {0} таңба болуы керек",
+		"openapi.max_length":        "{0} таңбадан аспауы керек",
+		"openapi.date":              "YYYY-MM-DD форматындағы күн болуы керек",
+		"openapi.date_time":         "RFC 3339 уақыт белгісі болуы керек",
+		"openapi.email":             "дұрыс электрондық пошта мекенжайы болуы керек",
+		"openapi.exclusive_minimum": "{0} мәнінен үлкен болуы керек",
+		"openapi.minimum":           "{0} мәнінен кем болмауы керек",
+		"openapi.maximum":           "{0} мәнінен аспауы керек",
+	})
+}
@@ -0,0 +1,299 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/togzhanzhakhani/projects/internal/graphql"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/jobs"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/tracker"
+)
+
+// operation describes a route. Every route registered in internal/routes needs
+// an entry here, keyed by method and gin path; Generate fails otherwise.
+type operation struct {
+	Tag         string
+	Summary     string
+	Description string
+	Query       []Parameter
+	// PathParams overrides the schema of path parameters, which are
+	// integers when named id or ending in Id and strings otherwise.
+	PathParams map[string]*Schema
+	// Input is a model whose input schema is the JSON request body.
+	Input interface{}
+	// Body lists non-JSON request bodies by content type.
+	Body   map[string]*Schema
+	Status int
+	// Output is a value of the type of the JSON response.
+	Output interface{}
+	// Produces lists non-JSON responses by content type.
+	Produces map[string]*Schema
+}
+
+var tags = []string{"auth", "workspaces", "users", "invitations", "teams", "projects", "tasks", "recurring-tasks", "calendars", "import", "export", "webhooks", "graphql", "admin", "docs"}
+
+func query(name, description string, enum ...string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string", Enum: enum}}
+}
+
+func intQuery(name, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "integer", Format: "int64"}}
+}
+
+var (
+	object = map[string]interface{}{}
+	binary = &Schema{Type: "string", Format: "binary"}
+	upload = map[string]*Schema{
+		"multipart/form-data": {
+			Type:       "object",
+			Properties: map[string]*Schema{"file": binary},
+			Required:   []string{"file"},
+		},
+		"application/octet-stream": binary,
+	}
+	exportFormat = query("format", "Output format, csv by default.", "csv", "ndjson", "xlsx")
+	exportFiles  = map[string]*Schema{
+		"text/csv":             binary,
+		"application/x-ndjson": binary,
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": binary,
+	}
+	calendar   = map[string]*Schema{"text/calendar": {Type: "string"}}
+	feedToken  = Parameter{Name: "token", In: "query", Required: true, Description: "Feed token created with the calendar-tokens endpoint.", Schema: &Schema{Type: "string"}}
+	tokenName  = map[string]*Schema{"application/json": {Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}}}
+	trackerOps = []Parameter{
+		intQuery("project_id", "Import every issue into this project. Otherwise a project is created per repository or Jira project."),
+		intQuery("manager_id", "Manager of the projects created by the import."),
+		intQuery("default_assignee_id", "Assignee for issues whose assignee is not a known user. Without it such issues are skipped."),
+		query("user_map", "Tracker logins to user emails, as login:email,login:email."),
+	}
+	workspaceInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"name", "slug"}, Properties: map[string]*Schema{
+		"name": {Type: "string"},
+		"slug": {Type: "string", Description: "Lower-case letters, digits and hyphens."},
+	}}}
+	memberRole  = &Schema{Type: "string", Enum: []string{"admin", "member"}}
+	memberInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"email", "role"}, Properties: map[string]*Schema{
+		"email": {Type: "string", Format: "email", Description: "The address the invitation is sent to."},
+		"role":  memberRole,
+	}}}
+	memberRoleInput    = map[string]*Schema{"application/json": {Type: "object", Required: []string{"role"}, Properties: map[string]*Schema{"role": memberRole}}}
+	projectRole        = &Schema{Type: "string", Enum: models.ProjectRoles}
+	projectMemberInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"user_id", "role"}, Properties: map[string]*Schema{
+		"user_id": {Type: "integer", Format: "int64", Description: "A user of the workspace."},
+		"role":    projectRole,
+	}}}
+	projectTeamInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"team_id", "role"}, Properties: map[string]*Schema{
+		"team_id": {Type: "integer", Format: "int64"},
+		"role":    projectRole,
+	}}}
+	teamMemberInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"user_id"}, Properties: map[string]*Schema{
+		"user_id": {Type: "integer", Format: "int64", Description: "A user of the workspace."},
+	}}}
+	loginInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"email", "password"}, Properties: map[string]*Schema{
+		"email":    {Type: "string"},
+		"password": {Type: "string"},
+	}}}
+	resetInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"email"}, Properties: map[string]*Schema{
+		"email": {Type: "string", Format: "email"},
+	}}}
+	resetConfirmInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"token", "password"}, Properties: map[string]*Schema{
+		"token":    {Type: "string", Description: "Token delivered with the password reset."},
+		"password": {Type: "string", Description: "The new password, which must satisfy the password policy."},
+	}}}
+	emailInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"email", "password"}, Properties: map[string]*Schema{
+		"email":    {Type: "string", Format: "email", Description: "The new email, which no other user may have."},
+		"password": {Type: "string", Description: "The caller's current password."},
+	}}}
+	apiTokenInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"name", "scopes"}, Properties: map[string]*Schema{
+		"name":       {Type: "string"},
+		"scopes":     {Type: "array", Items: &Schema{Type: "string"}, Description: "Scopes as resource:level, e.g. tasks:read. Levels are read, write and admin, each granting the ones before it."},
+		"expires_at": {Type: "string", Format: "date-time", Description: "Defaults to 90 days from now."},
+	}}}
+	invitationInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"email", "role"}, Properties: map[string]*Schema{
+		"email":        {Type: "string", Format: "email"},
+		"role":         {Type: "string", Enum: []string{"admin", "manager", "developer"}, Description: "Role of the user the invitation creates."},
+		"project_id":   {Type: "integer", Format: "int64", Description: "A project of the workspace the invitee joins."},
+		"project_role": {Type: "string", Enum: models.ProjectRoles, Description: "Role in project_id. Defaults to contributor."},
+	}}}
+	acceptInput = map[string]*Schema{"application/json": {Type: "object", Required: []string{"token"}, Properties: map[string]*Schema{
+		"token":    {Type: "string", Description: "Token delivered with the invitation."},
+		"name":     {Type: "string", Description: "Required when the invitee has no account yet."},
+		"password": {Type: "string", Description: "Required when the invitee has no account yet, and must satisfy the password policy."},
+	}}}
+	taskSearch = []Parameter{
+		query("title", "Find tasks by title."),
+		query("status", "Find tasks by status."),
+		query("priority", "Find tasks by priority."),
+		intQuery("assignee", "Find tasks by assignee ID."),
+		intQuery("project", "Find tasks by project ID."),
+	}
+)
+
+var operations = map[string]operation{
+	"GET /openapi.json": {Tag: "docs", Summary: "Get this OpenAPI document", Status: http.StatusOK, Output: object},
+	"GET /docs": {Tag: "docs", Summary: "Browse the API documentation", Status: http.StatusOK,
+		Produces: map[string]*Schema{"text/html": {Type: "string"}}},
+
+	"POST /auth/login": {Tag: "auth", Summary: "Log in with an email and password", Body: loginInput, Status: http.StatusOK, Output: handlers.LoginResponse{},
+		Description: "Opens a session, whose token is sent as a bearer token in the Authorization header. Consecutive failed logins lock the account, with 429 and Retry-After, for a time that doubles with each further failure."},
+	"POST /auth/logout": {Tag: "auth", Summary: "End the session of the bearer token", Status: http.StatusNoContent},
+	"POST /auth/password-reset": {Tag: "auth", Summary: "Send a password reset token to a user's email", Body: resetInput, Status: http.StatusAccepted, Output: object,
+		Description: "Answers the same whether the email belongs to a user or not."},
+	"GET /auth/oidc/login": {Tag: "auth", Summary: "Log in with single sign-on", Status: http.StatusFound,
+		Description: "Redirects to the identity provider, which redirects back to the callback. Answers 404 when single sign-on is not configured."},
+	"GET /auth/oidc/callback": {Tag: "auth", Summary: "Complete a single sign-on login", Status: http.StatusOK, Output: handlers.LoginResponse{},
+		Query:       []Parameter{query("code", "Authorization code from the identity provider."), query("state", "State of the login, which must match the cookie set when it started."), query("error", "Error from the identity provider.")},
+		Description: "Creates the user with the email of the ID token if there is none, maps the user's groups to a role and opens a session."},
+	"PUT /me/email": {Tag: "auth", Summary: "Change the caller's email", Body: emailInput, Status: http.StatusNoContent,
+		Description: "Password resets are sent to the email, so changing it takes the current password. Wrong passwords count towards the lockout as failed logins do."},
+	"GET /me/tokens": {Tag: "auth", Summary: "List the caller's API tokens", Status: http.StatusOK, Output: []models.APIToken{},
+		Description: "Revoked tokens are left out; expired ones are listed."},
+	"POST /me/tokens": {Tag: "auth", Summary: "Create an API token", Body: apiTokenInput, Status: http.StatusCreated, Output: handlers.CreatedAPIToken{},
+		Description: "The token is only returned here. API tokens cannot manage API tokens."},
+	"DELETE /me/tokens/:id": {Tag: "auth", Summary: "Revoke an API token", Status: http.StatusNoContent},
+	"POST /auth/password-reset/confirm": {Tag: "auth", Summary: "Set a new password with a reset token", Body: resetConfirmInput, Status: http.StatusNoContent,
+		Description: "The token can be used once, before it expires. The account is unlocked and its sessions end."},
+
+	"GET /workspaces/":            {Tag: "workspaces", Summary: "List the caller's workspaces", Status: http.StatusOK, Output: []repository.UserWorkspace{}},
+	"POST /workspaces/":           {Tag: "workspaces", Summary: "Create a workspace administered by the caller", Body: workspaceInput, Status: http.StatusCreated, Output: models.Workspace{}},
+	"GET /workspaces/:id/members": {Tag: "workspaces", Summary: "List the members of a workspace", Status: http.StatusOK, Output: []repository.Member{}},
+	"POST /workspaces/:id/members": {Tag: "workspaces", Summary: "Invite a member to a workspace", Body: memberInput, Status: http.StatusCreated, Output: models.Invitation{},
+		Description: "Only admins of the workspace may add members. The address gets an invitation, and joins with the role once it is accepted; people without an account join as developers."},
+	"PUT /workspaces/:id/members/:userId": {Tag: "workspaces", Summary: "Change the role of a member", Body: memberRoleInput, Status: http.StatusOK, Output: models.Membership{},
+		Description: "Only admins of the workspace may change roles. The last admin cannot be demoted."},
+	"DELETE /workspaces/:id/members/:userId": {Tag: "workspaces", Summary: "Remove a member from a workspace", Status: http.StatusOK, Output: object,
+		Description: "Only admins of the workspace may remove members. The last admin, and members with assigned tasks, cannot be removed."},
+	"POST /workspaces/:id/webhook-secret": {Tag: "workspaces", Summary: "Set a new webhook secret for a workspace", Status: http.StatusCreated, Output: object,
+		Description: "Only admins of the workspace may set it. Returns the `secret`, shown only once, and the webhook `url`; the old secret stops working."},
+
+	"GET /users/": {Tag: "users", Summary: "List users", Status: http.StatusOK, Output: []models.User{}},
+	"POST /users/": {Tag: "users", Summary: "Create a user", Input: models.User{}, Status: http.StatusCreated, Output: models.User{},
+		Description: "Only workspace admins may create users directly; everyone else is onboarded by invitation."},
+	"GET /users/:id": {Tag: "users", Summary: "Get a user", Status: http.StatusOK, Output: models.User{}},
+	"PUT /users/:id": {Tag: "users", Summary: "Update a user", Input: models.User{}, Status: http.StatusOK, Output: models.User{},
+		Description: "Only admins of the default workspace may change the role, which applies in every workspace, and nobody may raise their own. The email cannot change here; users change their own with PUT /me/email."},
+	"DELETE /users/:id": {Tag: "users", Summary: "Delete a user", Status: http.StatusNoContent,
+		Description: "Only workspace admins may delete users. The user leaves the workspace, and is deleted once they belong to no workspace. The last admin cannot be deleted."},
+	"GET /users/:id/tasks": {Tag: "users", Summary: "List the tasks assigned to a user", Status: http.StatusOK, Output: []models.Task{},
+		Query: []Parameter{query("due_before", "Only tasks due before this date (inclusive) or RFC 3339 timestamp.")}},
+	"GET /users/:id/auth-events": {Tag: "users", Summary: "List the latest logins, lockouts and password resets of a user", Status: http.StatusOK, Output: []models.AuthEvent{},
+		Description: "Users see their own events, workspace admins those of every member."},
+	"GET /users/search": {Tag: "users", Summary: "Find users by name or email", Status: http.StatusOK, Output: []models.User{},
+		Query: []Parameter{query("name", "Find users by name."), query("email", "Find users by email.")}},
+
+	"GET /invitations/": {Tag: "invitations", Summary: "List the pending invitations of the workspace", Status: http.StatusOK, Output: []models.Invitation{},
+		Description: "Expired invitations are listed until they are resent or revoked."},
+	"POST /invitations/": {Tag: "invitations", Summary: "Invite someone to the workspace", Body: invitationInput, Status: http.StatusCreated, Output: models.Invitation{},
+		Description: "Workspace admins and admins invite with any role, managers with any role but admin. The token is delivered to the email, not returned."},
+	"POST /invitations/:id/resend": {Tag: "invitations", Summary: "Resend a pending invitation", Status: http.StatusOK, Output: models.Invitation{},
+		Description: "A new token replaces the one sent before, and the invitation expires later."},
+	"DELETE /invitations/:id": {Tag: "invitations", Summary: "Revoke a pending invitation", Status: http.StatusNoContent},
+	"POST /invitations/accept": {Tag: "invitations", Summary: "Accept an invitation", Body: acceptInput, Status: http.StatusCreated, Output: models.User{},
+		Description: "Needs no authentication. Creates the user with the name and password given, or adds the existing user with the invitation's email to the workspace."},
+
+	"GET /teams/":            {Tag: "teams", Summary: "List the teams of the workspace", Status: http.StatusOK, Output: []models.Team{}},
+	"POST /teams/":           {Tag: "teams", Summary: "Create a team", Input: models.Team{}, Status: http.StatusCreated, Output: models.Team{}, Description: "Only workspace admins may change teams."},
+	"DELETE /teams/:id":      {Tag: "teams", Summary: "Delete a team", Status: http.StatusNoContent, Description: "Its members stay members of the projects the team was added to."},
+	"GET /teams/:id/members": {Tag: "teams", Summary: "List the members of a team", Status: http.StatusOK, Output: []repository.TeamMemberInfo{}},
+	"POST /teams/:id/members": {Tag: "teams", Summary: "Add a user to a team", Body: teamMemberInput, Status: http.StatusCreated, Output: models.TeamMember{},
+		Description: "The user does not join the projects the team was added to before."},
+	"DELETE /teams/:id/members/:userId": {Tag: "teams", Summary: "Remove a user from a team", Status: http.StatusOK, Output: object},
+
+	"GET /projects/":          {Tag: "projects", Summary: "List projects", Status: http.StatusOK, Output: []models.Project{}},
+	"POST /projects/":         {Tag: "projects", Summary: "Create a project", Input: models.Project{}, Status: http.StatusCreated, Output: models.Project{}},
+	"GET /projects/:id":       {Tag: "projects", Summary: "Get a project", Status: http.StatusOK, Output: models.Project{}},
+	"PUT /projects/:id":       {Tag: "projects", Summary: "Update a project", Input: models.Project{}, Status: http.StatusOK, Output: models.Project{}},
+	"DELETE /projects/:id":    {Tag: "projects", Summary: "Delete a project", Status: http.StatusNoContent},
+	"GET /projects/:id/tasks": {Tag: "projects", Summary: "List the tasks of a project", Status: http.StatusOK, Output: []models.Task{}},
+	"GET /projects/:id/members": {Tag: "projects", Summary: "List the members of a project", Status: http.StatusOK, Output: []repository.ProjectMemberInfo{},
+		Description: "Tasks of a project can only be assigned to its members. The manager of a project is always one of its owners."},
+	"POST /projects/:id/members": {Tag: "projects", Summary: "Add a member to a project or change their role", Body: projectMemberInput, Status: http.StatusCreated, Output: models.ProjectMember{},
+		Description: "Workspace admins and project owners may manage every member, maintainers every member but the owners. The last owner cannot be demoted."},
+	"DELETE /projects/:id/members/:userId": {Tag: "projects", Summary: "Remove a member from a project", Status: http.StatusOK, Output: object,
+		Description: "The last owner, and members with tasks of the project assigned, cannot be removed."},
+	"POST /projects/:id/teams": {Tag: "projects", Summary: "Add the members of a team to a project", Body: projectTeamInput, Status: http.StatusCreated, Output: []models.ProjectMember{},
+		Description: "Returns the memberships created. Members of the team who already are members of the project keep their role."},
+	"GET /projects/search": {Tag: "projects", Summary: "Find projects by title or manager", Status: http.StatusOK, Output: []models.Project{},
+		Query: []Parameter{query("title", "Find projects by title."), intQuery("manager", "Find projects by manager ID.")}},
+	"GET /projects/:id/export": {Tag: "export", Summary: "Export the tasks of a project", Status: http.StatusOK,
+		Query: []Parameter{exportFormat}, Produces: exportFiles},
+	"GET /projects/:id/archive": {Tag: "projects", Summary: "Download a project archive", Status: http.StatusOK,
+		Description: "A zip file with the project, its tasks, recurring tasks and the users they reference.",
+		Produces:    map[string]*Schema{"application/zip": binary}},
+	"POST /projects/import-archive": {Tag: "projects", Summary: "Restore a project archive as a new project", Status: http.StatusCreated,
+		Body: map[string]*Schema{"application/zip": binary, "multipart/form-data": upload["multipart/form-data"]}, Output: repository.RestoreResult{}},
+
+	"GET /tasks/":        {Tag: "tasks", Summary: "List tasks", Status: http.StatusOK, Output: []models.Task{}},
+	"POST /tasks/":       {Tag: "tasks", Summary: "Create a task", Input: models.Task{}, Status: http.StatusCreated, Output: models.Task{}},
+	"GET /tasks/overdue": {Tag: "tasks", Summary: "List unfinished tasks whose due date has passed", Status: http.StatusOK, Output: []models.Task{}},
+	"GET /tasks/:id":     {Tag: "tasks", Summary: "Get a task", Status: http.StatusOK, Output: models.Task{}},
+	"PUT /tasks/:id":     {Tag: "tasks", Summary: "Update a task", Input: models.Task{}, Status: http.StatusOK, Output: models.Task{}},
+	"DELETE /tasks/:id": {Tag: "tasks", Summary: "Search tasks by one query parameter", Status: http.StatusOK, Output: []models.Task{}, Query: taskSearch,
+		Description: "Task search is routed on this method and path; the id segment is ignored and the task is not deleted. The first of the query parameters given is used."},
+	"GET /tasks/:id/commits": {Tag: "tasks", Summary: "List the commits that referenced a task", Status: http.StatusOK, Output: []models.TaskCommit{}},
+
+	"GET /recurring-tasks/":    {Tag: "recurring-tasks", Summary: "List recurring task templates", Status: http.StatusOK, Output: []models.RecurringTask{}},
+	"POST /recurring-tasks/":   {Tag: "recurring-tasks", Summary: "Create a recurring task template", Input: models.RecurringTask{}, Status: http.StatusCreated, Output: models.RecurringTask{}},
+	"GET /recurring-tasks/:id": {Tag: "recurring-tasks", Summary: "Get a recurring task template", Status: http.StatusOK, Output: models.RecurringTask{}},
+	"PUT /recurring-tasks/:id": {Tag: "recurring-tasks", Summary: "Update a recurring task template", Input: models.RecurringTask{}, Status: http.StatusOK, Output: models.RecurringTask{},
+		Query: []Parameter{
+			query("scope", "Update all upcoming occurrences, or only those from the given date.", "all", "future"),
+			query("from", "First occurrence to change when scope is future."),
+		}},
+	"DELETE /recurring-tasks/:id": {Tag: "recurring-tasks", Summary: "Delete a recurring task template and its unstarted occurrences", Status: http.StatusNoContent},
+
+	"GET /users/:id/calendar.ics": {Tag: "calendars", Summary: "Calendar feed of a user's tasks and managed projects", Status: http.StatusOK,
+		Query: []Parameter{feedToken}, Produces: calendar},
+	"GET /users/:id/calendar-tokens":             {Tag: "calendars", Summary: "List a user's calendar feed tokens", Status: http.StatusOK, Output: []models.FeedToken{}},
+	"POST /users/:id/calendar-tokens":            {Tag: "calendars", Summary: "Create a calendar feed token for a user", Body: tokenName, Status: http.StatusCreated, Output: object},
+	"DELETE /users/:id/calendar-tokens/:tokenId": {Tag: "calendars", Summary: "Revoke a user's calendar feed token", Status: http.StatusNoContent},
+	"GET /projects/:id/calendar.ics": {Tag: "calendars", Summary: "Calendar feed of a project and its tasks", Status: http.StatusOK,
+		Query: []Parameter{feedToken}, Produces: calendar},
+	"GET /projects/:id/calendar-tokens":             {Tag: "calendars", Summary: "List a project's calendar feed tokens", Status: http.StatusOK, Output: []models.FeedToken{}},
+	"POST /projects/:id/calendar-tokens":            {Tag: "calendars", Summary: "Create a calendar feed token for a project", Body: tokenName, Status: http.StatusCreated, Output: object},
+	"DELETE /projects/:id/calendar-tokens/:tokenId": {Tag: "calendars", Summary: "Revoke a project's calendar feed token", Status: http.StatusNoContent},
+
+	"POST /import": {Tag: "import", Summary: "Import users, projects or tasks from CSV or NDJSON", Status: http.StatusCreated, Output: object,
+		Description: "Returns 200 instead of 201 for dry runs and for resuming an import job that has already completed.",
+		Body:        map[string]*Schema{"text/csv": binary, "application/x-ndjson": binary, "multipart/form-data": upload["multipart/form-data"]},
+		Query: []Parameter{
+			query("entity", "What the rows describe.", "users", "projects", "tasks"),
+			query("format", "File format, taken from the content type or file name when omitted.", "csv", "ndjson"),
+			query("map", "Column renames, as source:target,source:target."),
+			query("dry_run", "Validate without writing anything.", "true", "false"),
+			query("mode", "Create everything in one transaction, or commit valid rows in batches.", "atomic", "batch"),
+			intQuery("batch_size", "Rows per batch in batch mode."),
+			query("job_id", "Resume a failed batch import."),
+		}},
+	"GET /import/:id": {Tag: "import", Summary: "Get a batched import job", Status: http.StatusOK, Output: models.ImportJob{},
+		PathParams: map[string]*Schema{"id": {Type: "string"}}},
+	"POST /import/github": {Tag: "import", Summary: "Import a GitHub issues JSON export", Status: http.StatusOK, Output: tracker.Report{},
+		Body:  map[string]*Schema{"application/json": {Type: "array", Items: &Schema{Type: "object"}}, "multipart/form-data": upload["multipart/form-data"]},
+		Query: trackerOps},
+	"POST /import/jira": {Tag: "import", Summary: "Import a Jira CSV or XML export", Status: http.StatusOK, Output: tracker.Report{},
+		Body:  map[string]*Schema{"text/csv": binary, "application/xml": binary, "multipart/form-data": upload["multipart/form-data"]},
+		Query: append([]Parameter{query("format", "Export format, detected when omitted.", "csv", "xml")}, trackerOps...)},
+
+	"GET /export/tasks": {Tag: "export", Summary: "Export tasks", Status: http.StatusOK, Produces: exportFiles,
+		Query: append([]Parameter{exportFormat}, taskSearch...)},
+	"GET /export/projects": {Tag: "export", Summary: "Export projects with their manager and number of tasks", Status: http.StatusOK,
+		Query: []Parameter{exportFormat}, Produces: exportFiles},
+
+	"POST /webhooks/push": {Tag: "webhooks", Summary: "Link pushed commits to the tasks they reference", Status: http.StatusOK, Output: object,
+		Description: "Accepts GitHub and GitLab push webhooks signed with the workspace's webhook secret. \"#123\" links a commit to task 123; \"fixes #123\" also completes it on the default branch.",
+		Body:        map[string]*Schema{"application/json": {Type: "object"}},
+		Query: []Parameter{
+			query("transition", "Set to false to only link commits.", "true", "false"),
+			intQuery("workspace", "Workspace of the referenced tasks, the default workspace when omitted."),
+		}},
+
+	"POST /graphql": {Tag: "graphql", Summary: "Execute a GraphQL query or mutation", Input: graphql.Request{}, Status: http.StatusOK, Output: object,
+		Description: "Users, projects and tasks with their relations. Errors of the query are returned in the errors of the GraphQL response with status 200. Queries may be at most 16 KiB long and nest at most 10 levels of fields deep."},
+	"GET /graphql/schema": {Tag: "graphql", Summary: "Get the GraphQL schema", Status: http.StatusOK,
+		Produces: map[string]*Schema{"text/plain": {Type: "string"}}},
+
+	"GET /admin/jobs": {Tag: "admin", Summary: "List background jobs and their recent runs", Status: http.StatusOK, Output: []jobs.Status{},
+		Query:       []Parameter{intQuery("limit", "Number of recent runs per job, 10 by default.")},
+		Description: "Only admins of the default workspace may list the jobs, with requests scoped to it; API tokens need the workspaces:admin scope."},
+}
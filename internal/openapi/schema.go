@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// inputTimeFormats describes time fields that the handlers accept in more than
+// one format. Other time fields of request bodies are plain dates.
+var inputTimeFormats = map[string]string{
+	"Task.DueDate":           "A date (end of that day), a local time or an RFC 3339 timestamp, in due_timezone.",
+	"RecurringTask.StartsAt": "A date, a local time or an RFC 3339 timestamp, in timezone.",
+}
+
+// schemas collects the component schemas referenced by the operations.
+type schemas map[string]*Schema
+
+// ref returns a reference to the component schema of struct type t,
+// registering it on first use.
+func (s schemas) ref(t reflect.Type) *Schema {
+	name := componentName(t)
+	if _, ok := s[name]; !ok {
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		s[name] = schema
+		s.fields(t, schema, hasValidateTags(t), false)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// inputRef returns a reference to the request body schema of model type t:
+// the model without its read-only fields, with dates as strings.
+func (s schemas) inputRef(t reflect.Type) *Schema {
+	name := componentName(t) + "Input"
+	if _, ok := s[name]; !ok {
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		s[name] = schema
+		s.fields(t, schema, true, true)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// of returns the schema of a value's type: a reference for structs, or an
+// inline schema for everything else.
+func (s schemas) of(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "Duration in nanoseconds."}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.of(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Struct:
+		return s.ref(t)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	return &Schema{}
+}
+
+// fields adds the JSON fields of struct type t to schema. For models, that is
+// types with validate tags, fields without a validate tag are set by the
+// server and are read-only; input schemas leave them out.
+func (s schemas) fields(t reflect.Type, schema *Schema, model, input bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			s.fields(field.Type, schema, model, input)
+			continue
+		}
+
+		rules, validated := field.Tag.Lookup("validate")
+		readOnly := model && (!validated || name == "id")
+		if input && readOnly {
+			continue
+		}
+
+		var property *Schema
+		if input && indirect(field.Type) == timeType {
+			property = &Schema{Type: "string", Format: "date", Description: "A date in YYYY-MM-DD format."}
+			if description, ok := inputTimeFormats[t.Name()+"."+field.Name]; ok {
+				property = &Schema{Type: "string", Description: description}
+			}
+		} else {
+			property = s.of(field.Type)
+		}
+		if property.Ref == "" {
+			// Siblings of $ref are ignored in OpenAPI 3.0, so references
+			// only carry the required flag.
+			property.ReadOnly = readOnly
+		}
+		if applyRules(property, rules, t) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	sort.Strings(schema.Required)
+}
+
+// applyRules translates validator rules into schema constraints and reports
+// whether the field is required.
+func applyRules(schema *Schema, rules string, owner reflect.Type) bool {
+	required := false
+	if schema.Ref != "" {
+		return strings.Contains(","+rules+",", ",required,")
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		tag, param, _ := strings.Cut(rule, "=")
+		switch tag {
+		case "required":
+			required = true
+			if schema.Type == "string" {
+				one := 1
+				schema.MinLength = &one
+			}
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "email":
+			schema.Format = "email"
+		case "timezone":
+			describe(schema, "An IANA time zone name such as Asia/Almaty.")
+		case "max", "min", "gt", "gte", "lt", "lte":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(schema, tag, n)
+		case "unique_email":
+			describe(schema, "Must not be used by another user.")
+		case "user_exists":
+			describe(schema, "The ID of an existing user.")
+		case "project_exists":
+			describe(schema, "The ID of an existing project.")
+		case "role_in":
+			describe(schema, "The user must have one of the roles: "+strings.Join(strings.Fields(param), ", ")+".")
+		case "project_member":
+			if field, ok := owner.FieldByName(param); ok {
+				other, _ := jsonName(field)
+				describe(schema, "The user must be a member of the project in "+other+".")
+			}
+		case "gtfield":
+			if field, ok := owner.FieldByName(param); ok {
+				other, _ := jsonName(field)
+				describe(schema, "Must be after "+other+".")
+			}
+		}
+	}
+	return required
+}
+
+func describe(schema *Schema, sentence string) {
+	if schema.Description != "" {
+		sentence = schema.Description + " " + sentence
+	}
+	schema.Description = sentence
+}
+
+func applyBound(schema *Schema, tag string, n float64) {
+	if schema.Type == "string" {
+		length := int(n)
+		switch tag {
+		case "max", "lte":
+			schema.MaxLength = &length
+		case "min", "gte":
+			schema.MinLength = &length
+		}
+		return
+	}
+	switch tag {
+	case "max", "lte":
+		schema.Maximum = &n
+	case "min", "gte":
+		schema.Minimum = &n
+	case "gt":
+		schema.Minimum = &n
+		schema.ExclusiveMinimum = true
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+func hasValidateTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("validate"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// componentName names a schema after its type, prefixed with the package name
+// for types outside the models package: "Task", "JobsStatus".
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+	if pkg == "models" || pkg == "" {
+		return t.Name()
+	}
+	prefix := strings.ToUpper(pkg[:1]) + pkg[1:]
+	if strings.HasPrefix(t.Name(), prefix) {
+		return t.Name()
+	}
+	return prefix + t.Name()
+}
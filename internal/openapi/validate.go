@@ -0,0 +1,356 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+const maxValidatedBody = 10 << 20
+
+// Load parses an OpenAPI document generated by this package.
+func Load(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %v", err)
+	}
+	return &doc, nil
+}
+
+// RequestValidator checks requests against the operations of a document.
+type RequestValidator struct {
+	Doc *Document
+}
+
+func NewRequestValidator(doc *Document) *RequestValidator {
+	return &RequestValidator{Doc: doc}
+}
+
+// Middleware rejects requests whose path parameters, query parameters or JSON
+// body do not match the spec, with 400 and a list of field errors, before the
+// handler runs. Requests to routes missing from the spec are passed through.
+func (v *RequestValidator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := v.operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		trans := i18n.Translator(c)
+		errs := v.params(trans, op, c.Params, c.Request.URL.Query())
+		bodyErrs, p := v.body(trans, op, c)
+		errs = append(errs, bodyErrs...)
+		if p != nil {
+			problem.Write(c, p)
+			return
+		}
+		if len(errs) > 0 {
+			problem.Write(c, problem.Validation(errs))
+			return
+		}
+		c.Next()
+	}
+}
+
+func (v *RequestValidator) operation(method, fullPath string) *Operation {
+	if fullPath == "" {
+		return nil
+	}
+	path, _ := openAPIPath(fullPath)
+	return v.Doc.Paths[path][strings.ToLower(method)]
+}
+
+func (v *RequestValidator) params(trans ut.Translator, op *Operation, pathParams gin.Params, query url.Values) []problem.FieldError {
+	var errs []problem.FieldError
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = pathParams.Get(param.Name)
+		case "query":
+			present = query.Has(param.Name)
+			value = query.Get(param.Name)
+		default:
+			continue
+		}
+		if !present || (param.In == "query" && value == "") {
+			if param.Required {
+				errs = append(errs, fieldError(trans, param.In, param.Name, msg("required")))
+			}
+			continue
+		}
+		if m := v.checkParam(param.Schema, value); m != nil {
+			errs = append(errs, fieldError(trans, param.In, param.Name, m))
+		}
+	}
+	return errs
+}
+
+// checkParam validates a path or query value, which arrives as a string.
+func (v *RequestValidator) checkParam(schema *Schema, value string) *message {
+	switch schema.Type {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return msg("integer")
+		}
+		return checkNumber(schema, float64(n))
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return msg("number")
+		}
+		return checkNumber(schema, n)
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return msg("boolean")
+		}
+		return nil
+	}
+	return checkString(schema, value)
+}
+
+// body validates a JSON request body and puts it back for the handler.
+// Operations that only accept JSON reject bodies of any other type, and take
+// a body without a Content-Type to be JSON; the others leave bodies that
+// are not JSON to the handler. Bodies that cannot be read at all are
+// rejected with a problem instead.
+func (v *RequestValidator) body(trans ut.Translator, op *Operation, c *gin.Context) ([]problem.FieldError, *problem.Problem) {
+	if op.RequestBody == nil {
+		return nil, nil
+	}
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return nil, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != "application/json" && mediaType != "" {
+		if len(op.RequestBody.Content) == 1 {
+			return nil, problem.UnsupportedMediaType(mediaType)
+		}
+		return nil, nil
+	}
+	if mediaType == "" && len(op.RequestBody.Content) > 1 {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxValidatedBody))
+	if err != nil {
+		return nil, problem.PayloadTooLarge()
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if op.RequestBody.Required {
+			return []problem.FieldError{fieldError(trans, "body", "", msg("body_required"))}, nil
+		}
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []problem.FieldError{fieldError(trans, "body", "", msg("json"))}, nil
+	}
+	var errs []problem.FieldError
+	v.check(trans, media.Schema, value, "", &errs)
+	return errs, nil
+}
+
+// check validates a decoded JSON value against schema, appending an error per
+// offending field. field is the dotted path of value within the body.
+func (v *RequestValidator) check(trans ut.Translator, schema *Schema, value interface{}, field string, errs *[]problem.FieldError) {
+	schema = v.resolve(schema)
+	fail := func(m *message) {
+		*errs = append(*errs, fieldError(trans, "body", field, m))
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			fail(msg("null"))
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail(msg("object"))
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				*errs = append(*errs, fieldError(trans, "body", join(field, name), msg("required")))
+			}
+		}
+		for _, name := range sortedKeys(object) {
+			if property, ok := schema.Properties[name]; ok {
+				v.check(trans, property, object[name], join(field, name), errs)
+			} else if schema.AdditionalProperties != nil {
+				v.check(trans, schema.AdditionalProperties, object[name], join(field, name), errs)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail(msg("array"))
+			return
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				v.check(trans, schema.Items, item, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail(msg("string"))
+			return
+		}
+		if m := checkString(schema, s); m != nil {
+			fail(m)
+		}
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			fail(msg("number"))
+			return
+		}
+		f, err := n.Float64()
+		if err != nil {
+			fail(msg("number"))
+			return
+		}
+		if schema.Type == "integer" && f != math.Trunc(f) {
+			fail(msg("integer"))
+			return
+		}
+		if m := checkNumber(schema, f); m != nil {
+			fail(m)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail(msg("boolean"))
+		}
+	}
+}
+
+func (v *RequestValidator) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = v.Doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	if schema == nil {
+		return &Schema{}
+	}
+	return schema
+}
+
+func checkString(schema *Schema, s string) *message {
+	if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+		return msg("enum", strings.Join(schema.Enum, ", "))
+	}
+	length := utf8.RuneCountInString(s)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		if *schema.MinLength == 1 {
+			return msg("not_empty")
+		}
+		return msg("min_length", strconv.Itoa(*schema.MinLength))
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return msg("max_length", strconv.Itoa(*schema.MaxLength))
+	}
+	switch schema.Format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return msg("date")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return msg("date_time")
+		}
+	case "email":
+		if address, err := mail.ParseAddress(s); err != nil || address.Address != s {
+			return msg("email")
+		}
+	}
+	return nil
+}
+
+func checkNumber(schema *Schema, n float64) *message {
+	if schema.Minimum != nil {
+		if schema.ExclusiveMinimum && n <= *schema.Minimum {
+			return msg("exclusive_minimum", formatNumber(*schema.Minimum))
+		}
+		if n < *schema.Minimum {
+			return msg("minimum", formatNumber(*schema.Minimum))
+		}
+	}
+	if schema.Maximum != nil && n > *schema.Maximum {
+		return msg("maximum", formatNumber(*schema.Maximum))
+	}
+	return nil
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+// message is a catalog key, without its "openapi." prefix, and its
+// parameters.
+type message struct {
+	key    string
+	params []string
+}
+
+func msg(key string, params ...string) *message {
+	return &message{key: key, params: params}
+}
+
+func fieldError(trans ut.Translator, in, field string, m *message) problem.FieldError {
+	return problem.FieldError{In: in, Field: field, Message: i18n.T(trans, "openapi."+m.key, m.params...)}
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
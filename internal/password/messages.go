@@ -0,0 +1,30 @@
+package password
+
+import "github.com/togzhanzhakhani/projects/internal/i18n"
+
+func init() {
+	i18n.MustRegister("en", map[string]string{
+		"password.min_length":     "Password must be at least {0} characters long",
+		"password.max_length":     "Password must be at most {0} characters long",
+		"password.require.upper":  "Password must contain an upper-case letter",
+		"password.require.lower":  "Password must contain a lower-case letter",
+		"password.require.digit":  "Password must contain a digit",
+		"password.require.symbol": "Password must contain a symbol",
+	})
+	i18n.MustRegister("ru", map[string]string{
+		"password.min_length":     "Пароль должен содержать не менее {0} символов",
+		"password.max_length":     "Пароль должен содержать не более {0} символов",
+		"password.require.upper":  "Пароль должен содержать заглавную букву",
+		"password.require.lower":  "Пароль должен содержать строчную букву",
+		"password.require.digit":  "Пароль должен содержать цифру",
+		"password.require.symbol": "Пароль должен содержать символ",
+	})
+	i18n.MustRegister("kk", map[string]string{
+		"password.min_length":     "Құпиясөз кемінде {0} таңбадан тұруы керек",
+		"password.max_length":     "Құпиясөз {0} таңбадан аспауы керек",
+		"password.require.upper":  "Құпиясөзде бас әріп болуы керек",
+		"password.require.lower":  "Құпиясөзде кіші әріп болуы керек",
+		"password.require.digit":  "Құпиясөзде сан болуы керек",
+		"password.require.symbol": "Құпиясөзде арнайы таңба болуы керек",
+	})
+}
@@ -0,0 +1,138 @@
+package password
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+)
+
+// Character classes a Policy may require.
+const (
+	ClassUpper  = "upper"
+	ClassLower  = "lower"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+// Policy is what a new password must satisfy.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	// Require lists character classes the password must contain.
+	Require []string
+}
+
+// DefaultPolicy asks for length only, as NIST SP 800-63B recommends.
+var DefaultPolicy = Policy{MinLength: 8, MaxLength: 128}
+
+// LoadConfig overrides the policy with PASSWORD_MIN_LENGTH,
+// PASSWORD_MAX_LENGTH and PASSWORD_REQUIRE, a comma-separated list of
+// upper, lower, digit and symbol.
+func (p *Policy) LoadConfig() error {
+	if v := os.Getenv("PASSWORD_MIN_LENGTH"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid PASSWORD_MIN_LENGTH %q", v)
+		}
+		p.MinLength = n
+	}
+	if v := os.Getenv("PASSWORD_MAX_LENGTH"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < p.MinLength {
+			return fmt.Errorf("invalid PASSWORD_MAX_LENGTH %q", v)
+		}
+		p.MaxLength = n
+	}
+	if v := os.Getenv("PASSWORD_REQUIRE"); v != "" {
+		p.Require = nil
+		for _, class := range strings.Split(v, ",") {
+			class = strings.TrimSpace(class)
+			if _, ok := classes[class]; !ok {
+				return fmt.Errorf("invalid PASSWORD_REQUIRE class %q", class)
+			}
+			p.Require = append(p.Require, class)
+		}
+	}
+	return nil
+}
+
+var classes = map[string]func(rune) bool{
+	ClassUpper:  unicode.IsUpper,
+	ClassLower:  unicode.IsLower,
+	ClassDigit:  unicode.IsDigit,
+	ClassSymbol: func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) },
+}
+
+// Check returns what password lacks to satisfy the policy, in the language
+// of trans, and nothing if it does.
+func (p Policy) Check(trans ut.Translator, password string) []string {
+	var violations []string
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, i18n.T(trans, "password.min_length", strconv.Itoa(p.MinLength)))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, i18n.T(trans, "password.max_length", strconv.Itoa(p.MaxLength)))
+	}
+	for _, class := range p.Require {
+		if strings.IndexFunc(password, classes[class]) < 0 {
+			violations = append(violations, i18n.T(trans, "password.require."+class))
+		}
+	}
+	return violations
+}
+
+// Lockout locks an account out after Threshold consecutive failed logins.
+// Each further failure doubles the lock, starting at Base, up to Max.
+type Lockout struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+}
+
+var DefaultLockout = Lockout{Threshold: 5, Base: time.Minute, Max: time.Hour}
+
+// LoadConfig overrides the lockout with LOGIN_LOCKOUT_THRESHOLD,
+// LOGIN_LOCKOUT_BASE and LOGIN_LOCKOUT_MAX.
+func (l *Lockout) LoadConfig() error {
+	if v := os.Getenv("LOGIN_LOCKOUT_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid LOGIN_LOCKOUT_THRESHOLD %q", v)
+		}
+		l.Threshold = n
+	}
+	for name, d := range map[string]*time.Duration{"LOGIN_LOCKOUT_BASE": &l.Base, "LOGIN_LOCKOUT_MAX": &l.Max} {
+		if v := os.Getenv(name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("invalid %s %q", name, v)
+			}
+			*d = parsed
+		}
+	}
+	return nil
+}
+
+// Duration returns how long an account is locked after failures consecutive
+// failed logins, or 0 if it is not.
+func (l Lockout) Duration(failures int) time.Duration {
+	if failures < l.Threshold {
+		return 0
+	}
+	d := l.Base
+	for i := l.Threshold; i < failures && d < l.Max; i++ {
+		d *= 2
+	}
+	if d > l.Max {
+		d = l.Max
+	}
+	return d
+}
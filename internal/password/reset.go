@@ -0,0 +1,31 @@
+package password
+
+import (
+	"log"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+// ResetMessage is a password reset to deliver to the user's email, with the
+// token that confirms it.
+type ResetMessage struct {
+	User      models.User
+	Token     string
+	ExpiresAt time.Time
+}
+
+// ResetSender delivers password resets, e.g. by e-mail.
+type ResetSender interface {
+	SendReset(message ResetMessage) error
+}
+
+// LogResetSender writes password resets, tokens included, to the standard
+// logger. It is meant for development.
+type LogResetSender struct{}
+
+func (LogResetSender) SendReset(message ResetMessage) error {
+	log.Printf("Password reset for user %d <%s> (expires %s): token %s", message.User.ID, message.User.Email,
+		message.ExpiresAt.Format(time.RFC3339), message.Token)
+	return nil
+}
@@ -0,0 +1,110 @@
+package problem
+
+import "github.com/togzhanzhakhani/projects/internal/i18n"
+
+// Titles are keyed by "problem.<code>", generic details by
+// "problem.detail.<name>".
+func init() {
+	i18n.MustRegister("en", map[string]string{
+		"problem.invalid_request":        "Invalid request",
+		"problem.validation_failed":      "Validation failed",
+		"problem.unauthorized":           "Unauthorized",
+		"problem.forbidden":              "Forbidden",
+		"problem.not_found":              "Not found",
+		"problem.conflict":               "Conflict",
+		"problem.payload_too_large":      "Payload too large",
+		"problem.unsupported_media_type": "Unsupported media type",
+		"problem.rate_limited":           "Too many requests",
+		"problem.internal_error":         "Internal server error",
+		"problem.service_unavailable":    "Service unavailable",
+
+		"problem.detail.validation_failed":        "The request has invalid fields",
+		"problem.detail.payload_too_large":        "The request body is too large",
+		"problem.detail.unsupported_media_type":   "Request bodies of type {0} are not accepted",
+		"problem.detail.record_not_found":         "The requested record does not exist",
+		"problem.detail.duplicate":                "A record with the same unique value already exists",
+		"problem.detail.foreign_key":              "The record references a record that does not exist, or is still referenced by other records",
+		"problem.detail.timeout":                  "The request timed out",
+		"problem.detail.internal":                 "Internal server error",
+		"problem.detail.no_route":                 "No route for {0} {1}",
+		"problem.detail.not_found.user":           "User not found",
+		"problem.detail.not_found.task":           "Task not found",
+		"problem.detail.not_found.project":        "Project not found",
+		"problem.detail.not_found.recurring_task": "Recurring task not found",
+		"problem.detail.not_found.import_job":     "Import job not found",
+		"problem.detail.not_found.workspace":      "Workspace not found",
+		"problem.detail.not_found.member":         "Member not found",
+		"problem.detail.not_found.team":           "Team not found",
+		"problem.detail.not_found.invitation":     "Invitation not found or expired",
+		"problem.detail.not_found.password_reset": "Password reset not found or expired",
+		"problem.detail.not_found.api_token":      "API token not found",
+	})
+	i18n.MustRegister("ru", map[string]string{
+		"problem.invalid_request":        "Некорректный запрос",
+		"problem.validation_failed":      "Ошибка валидации",
+		"problem.unauthorized":           "Требуется авторизация",
+		"problem.forbidden":              "Доступ запрещён",
+		"problem.not_found":              "Не найдено",
+		"problem.conflict":               "Конфликт",
+		"problem.payload_too_large":      "Слишком большой запрос",
+		"problem.unsupported_media_type": "Неподдерживаемый тип данных",
+		"problem.rate_limited":           "Слишком много запросов",
+		"problem.internal_error":         "Внутренняя ошибка сервера",
+		"problem.service_unavailable":    "Сервис недоступен",
+
+		"problem.detail.validation_failed":        "Некоторые поля запроса заполнены неверно",
+		"problem.detail.payload_too_large":        "Тело запроса слишком большое",
+		"problem.detail.unsupported_media_type":   "Тело запроса типа {0} не принимается",
+		"problem.detail.record_not_found":         "Запрошенная запись не существует",
+		"problem.detail.duplicate":                "Запись с таким уникальным значением уже существует",
+		"problem.detail.foreign_key":              "Запись ссылается на несуществующую запись или на неё ещё ссылаются другие записи",
+		"problem.detail.timeout":                  "Время ожидания запроса истекло",
+		"problem.detail.internal":                 "Внутренняя ошибка сервера",
+		"problem.detail.no_route":                 "Маршрут {0} {1} не найден",
+		"problem.detail.not_found.user":           "Пользователь не найден",
+		"problem.detail.not_found.task":           "Задача не найдена",
+		"problem.detail.not_found.project":        "Проект не найден",
+		"problem.detail.not_found.recurring_task": "Повторяющаяся задача не найдена",
+		"problem.detail.not_found.import_job":     "Задание импорта не найдено",
+		"problem.detail.not_found.workspace":      "Рабочее пространство не найдено",
+		"problem.detail.not_found.member":         "Участник не найден",
+		"problem.detail.not_found.team":           "Команда не найдена",
+		"problem.detail.not_found.invitation":     "Приглашение не найдено или истекло",
+		"problem.detail.not_found.password_reset": "Сброс пароля не найден или истёк",
+		"problem.detail.not_found.api_token":      "API-токен не найден",
+	})
+	i18n.MustRegister("kk", map[string]string{
+		"problem.invalid_request":        "Жарамсыз сұрау",
+		"problem.validation_failed":      "Тексеру сәтсіз аяқталды",
+		"problem.unauthorized":           "Авторизация қажет",
+		"problem.forbidden":              "Қол жеткізуге тыйым салынған",
+		"problem.not_found":              "Табылмады",
+		"problem.conflict":               "Қайшылық",
+		"problem.payload_too_large":      "Сұрау тым үлкен",
+		"problem.unsupported_media_type": "Қолдау көрсетілмейтін деректер түрі",
+		"problem.rate_limited":           "Сұраулар тым көп",
+		"problem.internal_error":         "Сервердің ішкі қатесі",
+		"problem.service_unavailable":    "Қызмет қолжетімсіз",
+
+		"problem.detail.validation_failed":        "Сұраудың кейбір өрістері қате толтырылған",
+		"problem.detail.payload_too_large":        "Сұрау денесі тым үлкен",
+		"problem.detail.unsupported_media_type":   "{0} түріндегі сұрау денесі қабылданбайды",
+		"problem.detail.record_not_found":         "Сұралған жазба жоқ",
+		"problem.detail.duplicate":                "Осындай бірегей мәні бар жазба бұрыннан бар",
+		"problem.detail.foreign_key":              "Жазба жоқ жазбаға сілтейді немесе оған әлі басқа жазбалар сілтейді",
+		"problem.detail.timeout":                  "Сұраудың күту уақыты аяқталды",
+		"problem.detail.internal":                 "Сервердің ішкі қатесі",
+		"problem.detail.no_route":                 "{0} {1} маршруты табылмады",
+		"problem.detail.not_found.user":           "Пайдаланушы табылмады",
+		"problem.detail.not_found.task":           "Тапсырма табылмады",
+		"problem.detail.not_found.project":        "Жоба табылмады",
+		"problem.detail.not_found.recurring_task": "Қайталанатын тапсырма табылмады",
+		"problem.detail.not_found.import_job":     "Импорт тапсырмасы табылмады",
+		"problem.detail.not_found.workspace":      "Жұмыс кеңістігі табылмады",
+		"problem.detail.not_found.member":         "Қатысушы табылмады",
+		"problem.detail.not_found.team":           "Команда табылмады",
+		"problem.detail.not_found.invitation":     "Шақыру табылмады немесе мерзімі өтті",
+		"problem.detail.not_found.password_reset": "Құпиясөзді қалпына келтіру табылмады немесе мерзімі өтті",
+		"problem.detail.not_found.api_token":      "API токені табылмады",
+	})
+}
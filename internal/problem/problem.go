@@ -0,0 +1,231 @@
+// Package problem renders errors as RFC 7807 problem details
+// (application/problem+json) and maps repository errors to HTTP statuses.
+package problem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"gorm.io/gorm"
+)
+
+const ContentType = "application/problem+json"
+
+// typeBase prefixes the code to form the problem type URI.
+const typeBase = "https://task-management-api/problems/"
+
+// Machine-readable problem codes.
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeUnsupportedMedia = "unsupported_media_type"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal_error"
+	CodeUnavailable      = "service_unavailable"
+)
+
+// Postgres SQLSTATE codes of constraint violations.
+const (
+	sqlUniqueViolation     = "23505"
+	sqlForeignKeyViolation = "23503"
+)
+
+// FieldError describes one invalid request field. In is "body", "query" or
+// "path"; it is empty for fields validated after binding.
+type FieldError struct {
+	In      string `json:"in,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is the body of every error response.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Code     string       `json:"code"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	TraceID  string       `json:"trace_id"`
+	Errors   []FieldError `json:"errors,omitempty"`
+
+	cause error
+	// key and params translate Detail when the message is generic rather
+	// than written by a handler.
+	key    string
+	params []string
+}
+
+func (p *Problem) Error() string {
+	if p.cause != nil {
+		return fmt.Sprintf("%s: %s: %v", p.Code, p.Detail, p.cause)
+	}
+	return p.Code + ": " + p.Detail
+}
+
+func (p *Problem) Unwrap() error {
+	return p.cause
+}
+
+func New(status int, code, detail string) *Problem {
+	title, ok := i18n.Lookup(i18n.English(), "problem."+code)
+	if !ok {
+		title = http.StatusText(status)
+	}
+	return &Problem{
+		Type:   typeBase + code,
+		Title:  title,
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+func BadRequest(detail string) *Problem {
+	return New(http.StatusBadRequest, CodeInvalidRequest, detail)
+}
+
+func Unauthorized(detail string) *Problem {
+	return New(http.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+func Forbidden(detail string) *Problem {
+	return New(http.StatusForbidden, CodeForbidden, detail)
+}
+
+func NotFound(detail string) *Problem {
+	return New(http.StatusNotFound, CodeNotFound, detail)
+}
+
+func Conflict(detail string) *Problem {
+	return New(http.StatusConflict, CodeConflict, detail)
+}
+
+// PayloadTooLarge rejects a request body over the size limit.
+func PayloadTooLarge() *Problem {
+	return translated(New(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "The request body is too large"), "payload_too_large")
+}
+
+// UnsupportedMediaType rejects a request body of a type the operation does
+// not accept.
+func UnsupportedMediaType(mediaType string) *Problem {
+	return translated(New(http.StatusUnsupportedMediaType, CodeUnsupportedMedia, "Request bodies of type "+mediaType+" are not accepted"), "unsupported_media_type", mediaType)
+}
+
+// RateLimited reports a request refused until later. The caller should set
+// the Retry-After header.
+func RateLimited(detail string) *Problem {
+	return New(http.StatusTooManyRequests, CodeRateLimited, detail)
+}
+
+// Internal is a server error. The cause is logged with the trace ID but not
+// shown to the client.
+func Internal(detail string, cause error) *Problem {
+	p := New(http.StatusInternalServerError, CodeInternal, detail)
+	p.cause = cause
+	return p
+}
+
+// Validation lists the invalid fields of a request.
+func Validation(errs []FieldError) *Problem {
+	p := translated(New(http.StatusBadRequest, CodeValidationFailed, "The request has invalid fields"), "validation_failed")
+	p.Errors = errs
+	return p
+}
+
+// FromError maps an error returned by a repository. Missing records become
+// 404, unique and foreign key violations 409, and anything else a 500 with
+// the given detail.
+func FromError(err error, detail string) *Problem {
+	var p *Problem
+	switch {
+	case errors.As(err, &p):
+		return p
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		p = translated(NotFound("The requested record does not exist"), "record_not_found")
+	case errors.Is(err, gorm.ErrDuplicatedKey) || sqlState(err) == sqlUniqueViolation:
+		p = translated(Conflict("A record with the same unique value already exists"), "duplicate")
+	case errors.Is(err, gorm.ErrForeignKeyViolated) || sqlState(err) == sqlForeignKeyViolation:
+		p = translated(Conflict("The record references a record that does not exist, or is still referenced by other records"), "foreign_key")
+	case errors.Is(err, context.DeadlineExceeded):
+		p = translated(New(http.StatusServiceUnavailable, CodeUnavailable, "The request timed out"), "timeout")
+	default:
+		return Internal(detail, err)
+	}
+	p.cause = err
+	return p
+}
+
+// Lookup maps the error of loading a single resource, such as "user": a
+// missing record is "User not found", anything else is handled by FromError.
+func Lookup(err error, resource string) *Problem {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		p := translated(NotFound(capitalize(resource)+" not found"), "not_found."+strings.ReplaceAll(resource, " ", "_"))
+		p.cause = err
+		return p
+	}
+	return FromError(err, "Failed to retrieve "+resource)
+}
+
+// Write renders p as the response, in the request's language where the
+// catalog has its messages, and aborts the handler chain.
+func Write(c *gin.Context, p *Problem) {
+	p.Localize(i18n.Translator(c))
+	p.TraceID = TraceID(c)
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.Status >= http.StatusInternalServerError {
+		log.Printf("[%s] %s %s: %v", p.TraceID, c.Request.Method, c.Request.URL.Path, p)
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Localize translates the title of p and, unless it was written by a
+// handler, its detail.
+func (p *Problem) Localize(trans ut.Translator) {
+	if title, ok := i18n.Lookup(trans, "problem."+p.Code); ok {
+		p.Title = title
+	}
+	if p.key != "" {
+		if detail, ok := i18n.Lookup(trans, p.key, p.params...); ok {
+			p.Detail = detail
+		}
+	}
+}
+
+// translated marks the detail of p as the catalog message
+// "problem.detail.<key>".
+func translated(p *Problem, key string, params ...string) *Problem {
+	p.key = "problem.detail." + key
+	p.params = params
+	return p
+}
+
+func sqlState(err error) string {
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		return state.SQLState()
+	}
+	return ""
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,56 @@
+package problem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	TraceHeader = "X-Request-ID"
+	traceKey    = "trace_id"
+)
+
+var validTraceID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Trace assigns every request a trace ID, taken from the X-Request-ID header
+// when the caller sends a well-formed one, and echoes it in the response.
+func Trace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		TraceID(c)
+		c.Next()
+	}
+}
+
+// TraceID returns the request's trace ID, assigning one if needed.
+func TraceID(c *gin.Context) string {
+	if id := c.GetString(traceKey); id != "" {
+		return id
+	}
+	id := c.GetHeader(TraceHeader)
+	if !validTraceID.MatchString(id) {
+		id = newTraceID()
+	}
+	c.Set(traceKey, id)
+	c.Header(TraceHeader, id)
+	return id
+}
+
+// Recover renders panics as internal errors.
+func Recover(c *gin.Context, recovered interface{}) {
+	Write(c, translated(Internal("Internal server error", fmt.Errorf("panic: %v", recovered)), "internal"))
+}
+
+// NoRoute renders requests for unknown paths.
+func NoRoute(c *gin.Context) {
+	Write(c, translated(NotFound("No route for "+c.Request.Method+" "+c.Request.URL.Path), "no_route", c.Request.Method, c.Request.URL.Path))
+}
+
+func newTraceID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,160 @@
+// Package ratelimit limits how often clients may call the API, with a token
+// bucket per client and route.
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests requests per Period, in bursts of up to Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// rate is how many tokens the bucket regains per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d requests per %s", l.Requests, l.Period)
+}
+
+var periods = map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}
+
+// ParseLimit parses a limit as requests/period, where the period is s, m,
+// h, d or a duration such as 10s: 600/m, 5/10s.
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, want requests/period", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid request count in rate limit %q", s)
+	}
+	d, ok := periods[period]
+	if !ok {
+		if d, err = time.ParseDuration(period); err != nil || d <= 0 {
+			return Limit{}, fmt.Errorf("invalid period in rate limit %q", s)
+		}
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Config is the limits: Default applies to each client across the routes
+// without a limit of their own, Routes to each client per route. IP applies
+// to each IP address before its requests are authenticated, so that
+// guessing credentials is limited too. A nil limit means unlimited.
+type Config struct {
+	Default *Limit
+	// Routes are keyed by method and route, as "GET /tasks/", or by route
+	// alone for every method. Routes are gin's, with :params, or the full
+	// names of gRPC methods.
+	Routes map[string]*Limit
+	IP     *Limit
+	// TrustedProxies are the IPs and CIDRs of the proxies whose
+	// X-Forwarded-For header gives the client IP, for the router's
+	// SetTrustedProxies. With none, the header is ignored, so that clients
+	// cannot pick a new IP bucket for each request.
+	TrustedProxies []string
+}
+
+// DefaultConfig allows each client 600 requests a minute, and each IP
+// address 1200 requests a minute before authentication, as several users
+// may share one address.
+var DefaultConfig = Config{
+	Default: &Limit{Requests: 600, Period: time.Minute},
+	IP:      &Limit{Requests: 1200, Period: time.Minute},
+}
+
+// off disables a limit.
+const off = "off"
+
+// LoadConfig reads the limits from RATE_LIMIT, the default limit or off,
+// RATE_LIMIT_IP, the limit before authentication or off, and
+// RATE_LIMIT_ROUTES, a comma-separated list of route=limit such as
+// "GET /tasks/=30/m,POST /auth/login=10/m", where a limit may be off too.
+// TRUSTED_PROXIES is a comma-separated list of IPs and CIDRs.
+func LoadConfig() (Config, error) {
+	config := Config{Default: DefaultConfig.Default, IP: DefaultConfig.IP, Routes: map[string]*Limit{}}
+	for name, limit := range map[string]**Limit{"RATE_LIMIT": &config.Default, "RATE_LIMIT_IP": &config.IP} {
+		if v := os.Getenv(name); v != "" {
+			parsed, err := parseLimitOrOff(v)
+			if err != nil {
+				return Config{}, fmt.Errorf("%s: %w", name, err)
+			}
+			*limit = parsed
+		}
+	}
+	for _, pair := range strings.Split(os.Getenv("RATE_LIMIT_ROUTES"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("RATE_LIMIT_ROUTES: invalid entry %q, want route=limit", pair)
+		}
+		limit, err := parseLimitOrOff(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+		}
+		config.Routes[strings.Join(strings.Fields(route), " ")] = limit
+	}
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return Config{}, fmt.Errorf("TRUSTED_PROXIES: invalid IP or CIDR %q", proxy)
+		}
+		config.TrustedProxies = append(config.TrustedProxies, proxy)
+	}
+	return config, nil
+}
+
+func parseLimitOrOff(s string) (*Limit, error) {
+	if strings.TrimSpace(s) == off {
+		return nil, nil
+	}
+	limit, err := ParseLimit(s)
+	if err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// limit returns the limit of a request and the route whose bucket it
+// takes from, empty for the default one.
+func (c Config) limit(method, route string) (*Limit, string) {
+	for _, key := range []string{method + " " + route, route} {
+		if limit, ok := c.Routes[key]; ok {
+			return limit, key
+		}
+	}
+	return c.Default, ""
+}
+
+// Longest returns the longest period of the limits, after which an unused
+// bucket is full again and may be forgotten.
+func (c Config) Longest() time.Duration {
+	var longest time.Duration
+	for _, limit := range c.Routes {
+		if limit != nil && limit.Period > longest {
+			longest = limit.Period
+		}
+	}
+	for _, limit := range []*Limit{c.Default, c.IP} {
+		if limit != nil && limit.Period > longest {
+			longest = limit.Period
+		}
+	}
+	return longest
+}
@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+// Limiter limits the requests of each client, told apart by API token,
+// verified user or IP address, in that order.
+type Limiter struct {
+	Config Config
+	Store  Store
+}
+
+func NewLimiter(config Config, store Store) *Limiter {
+	return &Limiter{Config: config, Store: store}
+}
+
+// Decision is the outcome of taking a request from its bucket.
+type Decision struct {
+	Limit   Limit
+	Allowed bool
+	// Tokens is what is left in the bucket.
+	Tokens float64
+}
+
+// Remaining returns how many requests the bucket allows now.
+func (d Decision) Remaining() int {
+	return int(math.Floor(d.Tokens))
+}
+
+// Reset returns in how many seconds the bucket is full again.
+func (d Decision) Reset() int {
+	return seconds(float64(d.Limit.Requests)-d.Tokens, d.Limit)
+}
+
+// RetryAfter returns in how many seconds the bucket allows a request.
+func (d Decision) RetryAfter() int {
+	return seconds(1-d.Tokens, d.Limit)
+}
+
+func (d Decision) String() string {
+	return fmt.Sprintf("Rate limit of %s exceeded", d.Limit)
+}
+
+// Take takes a request of the client key from its bucket of limit. It
+// returns false when the request is not limited: when limit is nil, or when
+// the store fails, so that requests go through while it is down.
+func (l *Limiter) Take(ctx context.Context, key string, limit *Limit) (Decision, bool) {
+	if limit == nil {
+		return Decision{}, false
+	}
+	tokens, taken, err := l.Store.Take(ctx, key, *limit)
+	if err != nil {
+		log.Printf("Error checking the rate limit of %s: %v", key, err)
+		return Decision{}, false
+	}
+	return Decision{Limit: *limit, Allowed: taken, Tokens: tokens}, true
+}
+
+// TakeCall takes a gRPC call to method from the buckets of the IP address
+// it comes from. Calls are limited by address only, since they are checked
+// before the caller is authenticated.
+func (l *Limiter) TakeCall(ctx context.Context, ip, method string) (Decision, bool) {
+	limit, route := l.Config.limit("", method)
+	return l.Take(ctx, routeKey("ip:"+ip, route), limit)
+}
+
+// Middleware refuses requests over the limit with 429 and Retry-After, and
+// reports the limit in the RateLimit-* headers. It must run after the
+// caller is identified.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, route := l.Config.limit(c.Request.Method, c.FullPath())
+		l.serve(c, routeKey(client(c), route), limit)
+	}
+}
+
+// IPMiddleware limits the requests of each IP address to Config.IP, as
+// Middleware does. It runs before the caller is authenticated, so that the
+// credentials of addresses over the limit are not even checked.
+func (l *Limiter) IPMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l.serve(c, "auth-ip:"+c.ClientIP(), l.Config.IP)
+	}
+}
+
+func (l *Limiter) serve(c *gin.Context, key string, limit *Limit) {
+	d, limited := l.Take(c.Request.Context(), key, limit)
+	if !limited {
+		c.Next()
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", d.Limit.Requests, int(math.Ceil(d.Limit.Period.Seconds()))))
+	header.Set("RateLimit-Limit", strconv.Itoa(d.Limit.Requests))
+	header.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining()))
+	header.Set("RateLimit-Reset", strconv.Itoa(d.Reset()))
+	if !d.Allowed {
+		header.Set("Retry-After", strconv.Itoa(d.RetryAfter()))
+		problem.Write(c, problem.RateLimited(d.String()))
+		return
+	}
+	c.Next()
+}
+
+// seconds returns how many whole seconds the bucket of limit takes to gain
+// tokens.
+func seconds(tokens float64, limit Limit) int {
+	if tokens <= 0 {
+		return 0
+	}
+	return int(math.Ceil(tokens / limit.rate()))
+}
+
+// routeKey returns the key of a client's bucket for a route with a limit of
+// its own, or of its default bucket when route is empty.
+func routeKey(client, route string) string {
+	if route == "" {
+		return client
+	}
+	return client + " " + route
+}
+
+// client returns the key of the caller's buckets. API tokens have their own
+// buckets, separate from their user's; the key holds a hash of the token,
+// not the token. Users only have buckets of their own when their
+// credentials were checked, as a trusted X-User-ID header could otherwise
+// move a client to a fresh bucket at will.
+func client(c *gin.Context) string {
+	ctx := c.Request.Context()
+	if _, limited := auth.Scopes(ctx); limited {
+		if token, ok := auth.BearerToken(c); ok {
+			sum := sha256.Sum256([]byte(token))
+			return "token:" + hex.EncodeToString(sum[:16])
+		}
+	}
+	if userID, ok := auth.UserID(ctx); ok && auth.Verified(ctx) {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
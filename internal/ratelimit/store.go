@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// Store keeps the token buckets.
+type Store interface {
+	// Take takes a token from the bucket key of limit, which starts full
+	// and refills continuously. It returns the tokens left, and whether
+	// there was one to take.
+	Take(ctx context.Context, key string, limit Limit) (float64, bool, error)
+}
+
+// refill returns the tokens of a bucket of limit that had tokens elapsed
+// ago.
+func refill(limit Limit, tokens float64, elapsed time.Duration) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Requests), tokens+elapsed.Seconds()*limit.rate())
+}
+
+// sweepInterval is how often MemoryStore forgets the buckets that are full.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+	// full is when the bucket is full again if left alone.
+	full time.Time
+}
+
+// MemoryStore keeps the buckets in memory. Each replica of the API then
+// limits clients on its own.
+type MemoryStore struct {
+	Now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{Now: time.Now, buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), at: now}
+		s.buckets[key] = b
+	}
+	b.tokens = refill(limit, b.tokens, now.Sub(b.at))
+	b.at = now
+	taken := b.tokens >= 1
+	if taken {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((float64(limit.Requests) - b.tokens) / limit.rate() * float64(time.Second)))
+	return b.tokens, taken, nil
+}
+
+// PostgresStore keeps the buckets in the rate_limit_buckets table, so that
+// the replicas of the API share them. Times are the database's, so that
+// the replicas' clocks need not agree.
+type PostgresStore struct {
+	DB *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+// takeQuery takes a token, refilling the bucket first; when there is none
+// to take it leaves the bucket alone and returns no row.
+const takeQuery = `
+INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at) VALUES ($1, $2::float8 - 1, clock_timestamp())
+ON CONFLICT (key) DO UPDATE SET
+	tokens = LEAST($2::float8, b.tokens + $3::float8 * GREATEST(0, EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at)::float8)) - 1,
+	updated_at = clock_timestamp()
+WHERE LEAST($2::float8, b.tokens + $3::float8 * GREATEST(0, EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at)::float8)) >= 1
+RETURNING tokens`
+
+// peekQuery returns the tokens of a bucket without taking one.
+const peekQuery = `
+SELECT LEAST($2::float8, tokens + $3::float8 * GREATEST(0, EXTRACT(EPOCH FROM clock_timestamp() - updated_at)::float8))
+FROM rate_limit_buckets WHERE key = $1`
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (float64, bool, error) {
+	var tokens float64
+	err := s.DB.QueryRowContext(ctx, takeQuery, key, limit.Requests, limit.rate()).Scan(&tokens)
+	if err == nil {
+		return tokens, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, err
+	}
+	if err := s.DB.QueryRowContext(ctx, peekQuery, key, limit.Requests, limit.rate()).Scan(&tokens); err != nil {
+		return 0, false, err
+	}
+	return tokens, false, nil
+}
+
+// Prune deletes the buckets unused for idle, which should be at least the
+// longest period of the limits, when they are full again.
+func (s *PostgresStore) Prune(ctx context.Context, idle time.Duration) (int64, error) {
+	result, err := s.DB.ExecContext(ctx,
+		"DELETE FROM rate_limit_buckets WHERE updated_at < clock_timestamp() - $1 * interval '1 second'", idle.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
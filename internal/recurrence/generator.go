@@ -0,0 +1,118 @@
+package recurrence
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+// Generator creates concrete tasks from recurring task templates for every
+// occurrence within Horizon. Occurrences that already exist are skipped, so it
+// is safe to run repeatedly and from several replicas.
+type Generator struct {
+	Repo    repository.RecurringTaskRepository
+	Horizon time.Duration
+}
+
+func NewGenerator(repo repository.RecurringTaskRepository) *Generator {
+	return &Generator{
+		Repo:    repo,
+		Horizon: 14 * 24 * time.Hour,
+	}
+}
+
+// LoadConfig overrides the horizon from RECURRENCE_HORIZON.
+func (g *Generator) LoadConfig() error {
+	if v := os.Getenv("RECURRENCE_HORIZON"); v != "" {
+		horizon, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		g.Horizon = horizon
+	}
+	return nil
+}
+
+// RunOnce generates the occurrences between now and now+Horizon and returns
+// the number of tasks created.
+func (g *Generator) RunOnce(now time.Time) (int, error) {
+	templates, err := g.Repo.GetAllRecurringTasks()
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, template := range templates {
+		n, err := g.generate(template, now)
+		if err != nil {
+			log.Printf("Error generating occurrences of recurring task %d: %v", template.ID, err)
+			continue
+		}
+		created += n
+	}
+	return created, nil
+}
+
+func (g *Generator) generate(template models.RecurringTask, now time.Time) (int, error) {
+	loc, err := Location(template.Timezone)
+	if err != nil {
+		return 0, err
+	}
+	rule, err := Parse(template.RRule, loc)
+	if err != nil {
+		return 0, err
+	}
+	project, err := g.Repo.GetProject(template.ProjectID)
+	if err != nil {
+		return 0, err
+	}
+	projectEnd := project.EndDate.AddDate(0, 0, 1)
+
+	created := 0
+	for _, occurrence := range rule.Between(template.StartsAt.In(loc), now, now.Add(g.Horizon)) {
+		task := NewOccurrence(template, occurrence, now)
+		if !task.DueDate.Before(projectEnd) {
+			break
+		}
+		ok, err := g.Repo.CreateOccurrence(&task)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// NewOccurrence builds the task for a single occurrence of template.
+func NewOccurrence(template models.RecurringTask, occurrence, now time.Time) models.Task {
+	templateID := template.ID
+	occurrenceDate := occurrence.UTC()
+	dueDate := occurrence.Add(time.Duration(template.DueAfterMinutes) * time.Minute).UTC()
+	return models.Task{
+		Title:           template.Title,
+		Description:     template.Description,
+		Priority:        template.Priority,
+		Status:          "todo",
+		AssigneeID:      template.AssigneeID,
+		ProjectID:       template.ProjectID,
+		CreatedAt:       now,
+		DueDate:         &dueDate,
+		DueTimezone:     template.Timezone,
+		RecurringTaskID: &templateID,
+		OccurrenceDate:  &occurrenceDate,
+		WorkspaceID:     template.WorkspaceID,
+	}
+}
+
+// Location returns the named IANA location, or UTC when name is empty.
+func Location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
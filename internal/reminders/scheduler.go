@@ -0,0 +1,146 @@
+package reminders
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+const (
+	EventDueSoon = "due_soon"
+	EventOverdue = "overdue"
+)
+
+// Event is emitted once per task, kind and offset. For due-soon events the
+// offset is how long before the due date the event fires; for overdue events
+// it is how long after.
+type Event struct {
+	Kind   string        `json:"kind"`
+	Offset time.Duration `json:"offset"`
+	Task   models.Task   `json:"task"`
+	At     time.Time     `json:"at"`
+}
+
+// Notifier delivers reminder events, e.g. by e-mail or to a message queue.
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// LogNotifier writes events to the standard logger.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(event Event) error {
+	log.Printf("Task %d %s (offset %s, due %s)", event.Task.ID, event.Kind, event.Offset, event.Task.DueDate.Format(time.RFC3339))
+	return nil
+}
+
+type Scheduler struct {
+	TaskRepo       repository.TaskRepository
+	ReminderRepo   repository.ReminderRepository
+	Notifier       Notifier
+	DueSoonOffsets []time.Duration
+	OverdueOffsets []time.Duration
+}
+
+func NewScheduler(taskRepo repository.TaskRepository, reminderRepo repository.ReminderRepository, notifier Notifier) *Scheduler {
+	return &Scheduler{
+		TaskRepo:       taskRepo,
+		ReminderRepo:   reminderRepo,
+		Notifier:       notifier,
+		DueSoonOffsets: []time.Duration{24 * time.Hour, time.Hour},
+		OverdueOffsets: []time.Duration{0},
+	}
+}
+
+// LoadConfig overrides the offsets from REMINDER_DUE_SOON_OFFSETS and
+// REMINDER_OVERDUE_OFFSETS (comma separated durations, e.g. "24h,1h").
+func (s *Scheduler) LoadConfig() error {
+	if v := os.Getenv("REMINDER_DUE_SOON_OFFSETS"); v != "" {
+		offsets, err := ParseOffsets(v)
+		if err != nil {
+			return err
+		}
+		s.DueSoonOffsets = offsets
+	}
+	if v := os.Getenv("REMINDER_OVERDUE_OFFSETS"); v != "" {
+		offsets, err := ParseOffsets(v)
+		if err != nil {
+			return err
+		}
+		s.OverdueOffsets = offsets
+	}
+	return nil
+}
+
+func ParseOffsets(value string) ([]time.Duration, error) {
+	var offsets []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		offset, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, offset)
+	}
+	return offsets, nil
+}
+
+// RunOnce emits every event that is due at now and has not been sent yet.
+func (s *Scheduler) RunOnce(now time.Time) error {
+	for _, offset := range s.DueSoonOffsets {
+		tasks, err := s.TaskRepo.GetTasksDueBetween(now, now.Add(offset))
+		if err != nil {
+			return err
+		}
+		if err := s.emit(EventDueSoon, offset, tasks, now); err != nil {
+			return err
+		}
+	}
+	for _, offset := range s.OverdueOffsets {
+		tasks, err := s.TaskRepo.GetOverdueTasks(now.Add(-offset))
+		if err != nil {
+			return err
+		}
+		if err := s.emit(EventOverdue, offset, tasks, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) emit(kind string, offset time.Duration, tasks []models.Task, now time.Time) error {
+	for _, task := range tasks {
+		if task.DueDate == nil {
+			continue
+		}
+		sent, err := s.ReminderRepo.HasReminder(task.ID, kind, offset, *task.DueDate)
+		if err != nil {
+			return err
+		}
+		if sent {
+			continue
+		}
+		if err := s.Notifier.Notify(Event{Kind: kind, Offset: offset, Task: task, At: now}); err != nil {
+			log.Printf("Error sending %s reminder for task %d: %v", kind, task.ID, err)
+			continue
+		}
+		reminder := models.TaskReminder{
+			TaskID:  task.ID,
+			Kind:    kind,
+			Offset:  offset,
+			DueDate: *task.DueDate,
+			SentAt:  now,
+		}
+		if err := s.ReminderRepo.CreateReminder(&reminder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
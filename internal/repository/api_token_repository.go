@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// touchInterval is how stale the last use of an API token may get before
+// it is written again, so that busy tokens do not write on every request.
+const touchInterval = time.Minute
+
+// APITokenRepository keeps the API tokens of users. Like sessions they do
+// not belong to a workspace.
+type APITokenRepository interface {
+	WithContext(ctx context.Context) APITokenRepository
+	CreateAPIToken(token *models.APIToken) error
+	// GetAPITokensByUser returns the tokens of a user that are not
+	// revoked, expired ones included.
+	GetAPITokensByUser(userID uint) ([]models.APIToken, error)
+	FindActiveAPIToken(tokenHash string) (*models.APIToken, error)
+	// TouchAPIToken records that a token was used now.
+	TouchAPIToken(token *models.APIToken) error
+	// RevokeAPIToken revokes a token of a user.
+	RevokeAPIToken(id, userID uint) error
+}
+
+type apiTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{DB: db}
+}
+
+func (repo *apiTokenRepository) WithContext(ctx context.Context) APITokenRepository {
+	return &apiTokenRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *apiTokenRepository) CreateAPIToken(token *models.APIToken) error {
+	return repo.DB.Create(token).Error
+}
+
+func (repo *apiTokenRepository) GetAPITokensByUser(userID uint) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	err := repo.DB.Where("user_id = ? AND revoked_at IS NULL", userID).Order("id").Find(&tokens).Error
+	return tokens, err
+}
+
+func (repo *apiTokenRepository) FindActiveAPIToken(tokenHash string) (*models.APIToken, error) {
+	var token models.APIToken
+	err := repo.DB.Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", tokenHash, time.Now()).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (repo *apiTokenRepository) TouchAPIToken(token *models.APIToken) error {
+	now := time.Now()
+	if token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < touchInterval {
+		return nil
+	}
+	token.LastUsedAt = &now
+	return repo.DB.Model(&models.APIToken{}).Where("id = ?", token.ID).Update("last_used_at", now).Error
+}
+
+func (repo *apiTokenRepository) RevokeAPIToken(id, userID uint) error {
+	result := repo.DB.Model(&models.APIToken{}).Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
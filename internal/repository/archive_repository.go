@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/togzhanzhakhani/projects/internal/archive"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// RestoreResult reports the IDs assigned to restored records, keyed by their
+// IDs in the archive.
+type RestoreResult struct {
+	Project          models.Project `json:"project"`
+	UserIDs          map[int]int    `json:"user_ids"`
+	TaskIDs          map[int]int    `json:"task_ids"`
+	RecurringTaskIDs map[int]int    `json:"recurring_task_ids"`
+	UsersLinked      int            `json:"users_linked"`
+	UsersCreated     int            `json:"users_created"`
+	// UsersReassigned counts the archived users who belong to other
+	// workspaces, replaced by the caller.
+	UsersReassigned int `json:"users_reassigned"`
+}
+
+type ArchiveRepository interface {
+	WithContext(ctx context.Context) ArchiveRepository
+	LoadProjectArchive(projectID uint) (*archive.Archive, error)
+	RestoreProjectArchive(a *archive.Archive) (*RestoreResult, error)
+}
+
+type archiveRepository struct {
+	DB *gorm.DB
+}
+
+func NewArchiveRepository(db *gorm.DB) ArchiveRepository {
+	return &archiveRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *archiveRepository) WithContext(ctx context.Context) ArchiveRepository {
+	return &archiveRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+// LoadProjectArchive collects a project, its tasks and recurring task
+// templates, and every user they reference.
+func (repo *archiveRepository) LoadProjectArchive(projectID uint) (*archive.Archive, error) {
+	a := &archive.Archive{}
+	if err := repo.DB.First(&a.Project, projectID).Error; err != nil {
+		return nil, err
+	}
+	if err := repo.DB.Where("project_id = ?", projectID).Order("id").Find(&a.Tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := repo.DB.Where("project_id = ?", projectID).Order("id").Find(&a.RecurringTasks).Error; err != nil {
+		return nil, err
+	}
+
+	userIDs := []int{a.Project.ManagerID}
+	for _, task := range a.Tasks {
+		userIDs = append(userIDs, task.AssigneeID)
+	}
+	for _, template := range a.RecurringTasks {
+		userIDs = append(userIDs, template.AssigneeID)
+	}
+	if err := repo.DB.Where("id IN ?", userIDs).Order("id").Find(&a.Users).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// RestoreProjectArchive recreates an archived project in one transaction. All
+// records get new IDs. Archived users are linked to the members of the
+// workspace with the same email, and created as members otherwise. Users
+// of other workspaces are never linked, as an archive could name anyone:
+// what they were assigned goes to the caller instead. Assignees become
+// contributors of the project.
+func (repo *archiveRepository) RestoreProjectArchive(a *archive.Archive) (*RestoreResult, error) {
+	result := &RestoreResult{
+		UserIDs:          make(map[int]int),
+		TaskIDs:          make(map[int]int),
+		RecurringTaskIDs: make(map[int]int),
+	}
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		for _, user := range a.Users {
+			var existing models.User
+			err := tx.Where("LOWER(email) = LOWER(?)", user.Email).First(&existing).Error
+			if err == nil {
+				result.UserIDs[int(user.ID)] = int(existing.ID)
+				result.UsersLinked++
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			var taken int64
+			if err := workspace.Global(tx).Model(&models.User{}).Where("LOWER(email) = LOWER(?)", user.Email).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				caller, ok := workspace.FromContext(tx.Statement.Context)
+				if !ok {
+					return fmt.Errorf("user %s belongs to another workspace", user.Email)
+				}
+				result.UserIDs[int(user.ID)] = int(caller.UserID)
+				result.UsersReassigned++
+				continue
+			}
+
+			oldID := int(user.ID)
+			user.ID = 0
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			result.UserIDs[oldID] = int(user.ID)
+			result.UsersCreated++
+		}
+
+		mapUser := func(id int) (int, error) {
+			if newID, ok := result.UserIDs[id]; ok {
+				return newID, nil
+			}
+			return 0, fmt.Errorf("user %d is referenced but missing from the archive", id)
+		}
+
+		project := a.Project
+		project.ID = 0
+		var err error
+		if project.ManagerID, err = mapUser(project.ManagerID); err != nil {
+			return err
+		}
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+		result.Project = project
+
+		templates := make([]models.RecurringTask, len(a.RecurringTasks))
+		for i, template := range a.RecurringTasks {
+			oldID := template.ID
+			template.ID = 0
+			template.ProjectID = project.ID
+			if template.AssigneeID, err = mapUser(template.AssigneeID); err != nil {
+				return err
+			}
+			if err := joinProject(tx, project.ID, template.AssigneeID); err != nil {
+				return err
+			}
+			if err := tx.Create(&template).Error; err != nil {
+				return err
+			}
+			result.RecurringTaskIDs[oldID] = template.ID
+			templates[i] = template
+		}
+		// Series are identified by the ID of their first template, which
+		// might not be part of this project.
+		for i, template := range templates {
+			seriesID, ok := result.RecurringTaskIDs[a.RecurringTasks[i].SeriesID]
+			if !ok {
+				seriesID = template.ID
+			}
+			if err := tx.Model(&template).Update("series_id", seriesID).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, task := range a.Tasks {
+			oldID := task.ID
+			task.ID = 0
+			task.ProjectID = project.ID
+			if task.AssigneeID, err = mapUser(task.AssigneeID); err != nil {
+				return err
+			}
+			if err := joinProject(tx, project.ID, task.AssigneeID); err != nil {
+				return err
+			}
+			if task.RecurringTaskID != nil {
+				if newID, ok := result.RecurringTaskIDs[*task.RecurringTaskID]; ok {
+					task.RecurringTaskID = &newID
+				} else {
+					task.RecurringTaskID = nil
+					task.OccurrenceDate = nil
+				}
+			}
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+			result.TaskIDs[oldID] = task.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLocked is returned for a login attempt on an account locked after too
+// many failed ones.
+var ErrLocked = errors.New("the account is locked")
+
+// AuthRepository keeps credentials, sessions, password resets and the auth
+// event log. None of them belong to a workspace: users log in once for all
+// of theirs.
+type AuthRepository interface {
+	WithContext(ctx context.Context) AuthRepository
+	// FindUserByEmail looks a user up by email, ignoring case, in every
+	// workspace.
+	FindUserByEmail(email string) (*models.User, error)
+	GetCredential(userID uint) (*models.Credential, error)
+	// SetEmail changes the email of a user, which password resets are sent
+	// to.
+	SetEmail(userID uint, email string) error
+	// SetPassword replaces the password hash of a user.
+	SetPassword(userID uint, hash string) error
+	// AttemptLogin checks a password of a user with verify while holding
+	// the row lock of its credential, so that concurrent attempts see each
+	// other's failures. It returns ErrLocked without calling verify while
+	// the account is locked. A failed attempt is counted, and locks the
+	// account for the duration lockout returns for the new count; a
+	// successful one clears the count.
+	AttemptLogin(userID uint, verify func(hash string) (bool, error), lockout func(failures int) time.Duration) (*models.Credential, bool, error)
+	CreateSession(session *models.Session) error
+	FindActiveSession(tokenHash string) (*models.Session, error)
+	RevokeSession(tokenHash string) error
+	CreatePasswordReset(reset *models.PasswordReset) error
+	// ResetPassword uses the pending reset with tokenHash to set the
+	// password of its user, unlocks the account and ends its sessions.
+	ResetPassword(tokenHash, passwordHash string) (*models.PasswordReset, error)
+	CreateOIDCLogin(login *models.OIDCLogin) error
+	// ClaimOIDCLogin deletes the unexpired single sign-on login with
+	// stateHash and returns it, so that its state is used once.
+	ClaimOIDCLogin(stateHash string) (*models.OIDCLogin, error)
+	// ProvisionUser creates a user logging in with single sign-on, or
+	// updates the role of an existing one, and makes the user a member of
+	// a workspace.
+	ProvisionUser(user *models.User, workspaceID uint) error
+	RecordEvent(event *models.AuthEvent) error
+	GetAuthEvents(userID uint) ([]models.AuthEvent, error)
+}
+
+type authRepository struct {
+	DB *gorm.DB
+}
+
+func NewAuthRepository(db *gorm.DB) AuthRepository {
+	return &authRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx. They are not scoped to its workspace.
+func (repo *authRepository) WithContext(ctx context.Context) AuthRepository {
+	return &authRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *authRepository) FindUserByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := workspace.Global(repo.DB).Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (repo *authRepository) GetCredential(userID uint) (*models.Credential, error) {
+	var credential models.Credential
+	if err := repo.DB.First(&credential, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (repo *authRepository) SetEmail(userID uint, email string) error {
+	return workspace.Global(repo.DB).Model(&models.User{}).Where("id = ?", userID).Update("email", email).Error
+}
+
+func (repo *authRepository) SetPassword(userID uint, hash string) error {
+	return setPassword(repo.DB, userID, hash)
+}
+
+// setPassword upserts the credential of a user with a new hash, and clears
+// its failed logins.
+func setPassword(tx *gorm.DB, userID uint, hash string) error {
+	credential := models.Credential{UserID: userID, PasswordHash: hash}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"password_hash": hash, "failed_attempts": 0, "locked_until": nil, "updated_at": time.Now()}),
+	}).Create(&credential).Error
+}
+
+func (repo *authRepository) AttemptLogin(userID uint, verify func(hash string) (bool, error), lockout func(failures int) time.Duration) (*models.Credential, bool, error) {
+	var credential models.Credential
+	var ok bool
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&credential, "user_id = ?", userID).Error
+		if err != nil {
+			return err
+		}
+		if credential.LockedUntil != nil && credential.LockedUntil.After(time.Now()) {
+			return ErrLocked
+		}
+		if ok, err = verify(credential.PasswordHash); err != nil {
+			return err
+		}
+		if ok {
+			if credential.FailedAttempts == 0 {
+				return nil
+			}
+			credential.FailedAttempts, credential.LockedUntil = 0, nil
+		} else {
+			credential.FailedAttempts++
+			if d := lockout(credential.FailedAttempts); d > 0 {
+				until := time.Now().Add(d)
+				credential.LockedUntil = &until
+			}
+		}
+		return tx.Model(&credential).Updates(map[string]interface{}{
+			"failed_attempts": credential.FailedAttempts,
+			"locked_until":    credential.LockedUntil,
+		}).Error
+	})
+	if errors.Is(err, ErrLocked) {
+		return &credential, false, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &credential, ok, nil
+}
+
+func (repo *authRepository) CreateSession(session *models.Session) error {
+	return repo.DB.Create(session).Error
+}
+
+func (repo *authRepository) FindActiveSession(tokenHash string) (*models.Session, error) {
+	var session models.Session
+	err := repo.DB.Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", tokenHash, time.Now()).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (repo *authRepository) RevokeSession(tokenHash string) error {
+	return repo.DB.Model(&models.Session{}).Where("token_hash = ? AND revoked_at IS NULL", tokenHash).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (repo *authRepository) CreatePasswordReset(reset *models.PasswordReset) error {
+	return repo.DB.Create(reset).Error
+}
+
+func (repo *authRepository) ResetPassword(tokenHash, passwordHash string) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&reset).Clauses(clause.Returning{}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := setPassword(tx, reset.UserID, passwordHash); err != nil {
+			return err
+		}
+		return tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", reset.UserID).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+// CreateOIDCLogin also deletes the logins that expired, which their
+// callbacks never claimed.
+func (repo *authRepository) CreateOIDCLogin(login *models.OIDCLogin) error {
+	if err := repo.DB.Where("expires_at <= ?", time.Now()).Delete(&models.OIDCLogin{}).Error; err != nil {
+		return err
+	}
+	return repo.DB.Create(login).Error
+}
+
+func (repo *authRepository) ClaimOIDCLogin(stateHash string) (*models.OIDCLogin, error) {
+	var login models.OIDCLogin
+	result := repo.DB.Clauses(clause.Returning{}).
+		Where("state_hash = ? AND expires_at > ?", stateHash, time.Now()).
+		Delete(&login)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &login, nil
+}
+
+func (repo *authRepository) ProvisionUser(user *models.User, workspaceID uint) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		tx = workspace.Global(tx)
+		var err error
+		if user.ID == 0 {
+			err = tx.Create(user).Error
+		} else {
+			err = tx.Model(user).Update("role", user.Role).Error
+		}
+		if err != nil {
+			return err
+		}
+		membership := models.Membership{WorkspaceID: workspaceID, UserID: user.ID, Role: models.WorkspaceMember}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&membership).Error
+	})
+}
+
+func (repo *authRepository) RecordEvent(event *models.AuthEvent) error {
+	return repo.DB.Create(event).Error
+}
+
+// GetAuthEvents returns the latest 100 events of a user, newest first.
+func (repo *authRepository) GetAuthEvents(userID uint) ([]models.AuthEvent, error) {
+	var events []models.AuthEvent
+	err := repo.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(100).Find(&events).Error
+	return events, err
+}
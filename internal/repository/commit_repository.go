@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommitRepository interface {
+	WithContext(ctx context.Context) CommitRepository
+	ExistingTaskIDs(ids []int) (map[int]bool, error)
+	AttachCommit(commit *models.TaskCommit) (bool, error)
+	CompleteTask(taskID int, at time.Time) (bool, error)
+	GetCommitsByTaskID(taskID int) ([]models.TaskCommit, error)
+}
+
+type commitRepository struct {
+	DB *gorm.DB
+}
+
+func NewCommitRepository(db *gorm.DB) CommitRepository {
+	return &commitRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *commitRepository) WithContext(ctx context.Context) CommitRepository {
+	return &commitRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *commitRepository) ExistingTaskIDs(ids []int) (map[int]bool, error) {
+	existing := make(map[int]bool)
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	var found []int
+	if err := repo.DB.Model(&models.Task{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// AttachCommit links a commit to a task. It reports false if the commit was
+// already linked, which happens when a webhook is redelivered.
+func (repo *commitRepository) AttachCommit(commit *models.TaskCommit) (bool, error) {
+	result := repo.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(commit)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CompleteTask marks a task as done unless it already is. The completion time
+// is never earlier than the task's creation.
+func (repo *commitRepository) CompleteTask(taskID int, at time.Time) (bool, error) {
+	result := repo.DB.Model(&models.Task{}).
+		Where("id = ? AND status <> ?", taskID, "done").
+		Updates(map[string]interface{}{
+			"status":       "done",
+			"completed_at": gorm.Expr("GREATEST(?, created_at)", at),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (repo *commitRepository) GetCommitsByTaskID(taskID int) ([]models.TaskCommit, error) {
+	var commits []models.TaskCommit
+	err := repo.DB.Where("task_id = ?", taskID).Order("committed_at DESC").Find(&commits).Error
+	return commits, err
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// TaskFilter narrows a task export with the same criteria as the task search.
+// Zero values are ignored.
+type TaskFilter struct {
+	Title      string
+	Status     string
+	Priority   string
+	AssigneeID uint
+	ProjectID  uint
+}
+
+func (filter TaskFilter) apply(query *gorm.DB) *gorm.DB {
+	if filter.Title != "" {
+		query = query.Where("tasks.title LIKE ?", "%"+filter.Title+"%")
+	}
+	if filter.Status != "" {
+		query = query.Where("tasks.status = ?", filter.Status)
+	}
+	if filter.Priority != "" {
+		query = query.Where("tasks.priority = ?", filter.Priority)
+	}
+	if filter.AssigneeID != 0 {
+		query = query.Where("tasks.assignee_id = ?", filter.AssigneeID)
+	}
+	if filter.ProjectID != 0 {
+		query = query.Where("tasks.project_id = ?", filter.ProjectID)
+	}
+	return query
+}
+
+// TaskExportRow is a task with the names of its assignee and project resolved.
+type TaskExportRow struct {
+	models.Task
+	AssigneeName string
+	ProjectName  string
+}
+
+// ProjectExportRow is a project with the name of its manager resolved and the
+// number of its tasks.
+type ProjectExportRow struct {
+	models.Project
+	ManagerName string
+	TaskCount   int
+}
+
+type ExportRepository interface {
+	StreamTasks(ctx context.Context, filter TaskFilter, fn func(TaskExportRow) error) error
+	StreamProjects(ctx context.Context, fn func(ProjectExportRow) error) error
+}
+
+type exportRepository struct {
+	DB *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) ExportRepository {
+	return &exportRepository{DB: db}
+}
+
+// StreamTasks calls fn for every matching task, reading them from a database
+// cursor one at a time.
+func (repo *exportRepository) StreamTasks(ctx context.Context, filter TaskFilter, fn func(TaskExportRow) error) error {
+	query := workspace.DB(ctx, repo.DB).Model(&models.Task{}).
+		Select("tasks.*, COALESCE(users.name, '') AS assignee_name, COALESCE(projects.name, '') AS project_name").
+		Joins("LEFT JOIN users ON users.id = tasks.assignee_id").
+		Joins("LEFT JOIN projects ON projects.id = tasks.project_id").
+		Order("tasks.id")
+
+	rows, err := filter.apply(query).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row TaskExportRow
+		if err := repo.DB.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamProjects calls fn for every project, reading them from a database
+// cursor one at a time.
+func (repo *exportRepository) StreamProjects(ctx context.Context, fn func(ProjectExportRow) error) error {
+	rows, err := workspace.DB(ctx, repo.DB).Model(&models.Project{}).
+		Select("projects.*, COALESCE(users.name, '') AS manager_name, " +
+			"(SELECT COUNT(*) FROM tasks WHERE tasks.project_id = projects.id) AS task_count").
+		Joins("LEFT JOIN users ON users.id = projects.manager_id").
+		Order("projects.id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row ProjectExportRow
+		if err := repo.DB.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
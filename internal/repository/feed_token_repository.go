@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+type FeedTokenRepository interface {
+	WithContext(ctx context.Context) FeedTokenRepository
+	CreateFeedToken(token *models.FeedToken) error
+	GetFeedTokenByID(id uint) (*models.FeedToken, error)
+	FindActiveFeedToken(tokenHash string) (*models.FeedToken, error)
+	GetFeedTokensByOwner(ownerType string, ownerID uint) ([]models.FeedToken, error)
+	RevokeFeedToken(id uint) error
+}
+
+type feedTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewFeedTokenRepository(db *gorm.DB) FeedTokenRepository {
+	return &feedTokenRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *feedTokenRepository) WithContext(ctx context.Context) FeedTokenRepository {
+	return &feedTokenRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *feedTokenRepository) CreateFeedToken(token *models.FeedToken) error {
+	return repo.DB.Create(token).Error
+}
+
+func (repo *feedTokenRepository) GetFeedTokenByID(id uint) (*models.FeedToken, error) {
+	var token models.FeedToken
+	if err := repo.DB.First(&token, id).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (repo *feedTokenRepository) FindActiveFeedToken(tokenHash string) (*models.FeedToken, error) {
+	var token models.FeedToken
+	err := repo.DB.Where("token_hash = ? AND revoked_at IS NULL", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (repo *feedTokenRepository) GetFeedTokensByOwner(ownerType string, ownerID uint) ([]models.FeedToken, error) {
+	var tokens []models.FeedToken
+	err := repo.DB.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Order("id").Find(&tokens).Error
+	return tokens, err
+}
+
+func (repo *feedTokenRepository) RevokeFeedToken(id uint) error {
+	return repo.DB.Model(&models.FeedToken{}).Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
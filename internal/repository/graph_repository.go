@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// UserFilter narrows a user list. Zero values are ignored.
+type UserFilter struct {
+	Name  string
+	Email string
+	Role  string
+}
+
+// ProjectFilter narrows a project list. Zero values are ignored.
+type ProjectFilter struct {
+	Name      string
+	ManagerID int
+}
+
+// Page selects part of a list. A zero Limit means no limit.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// GraphRepository serves the GraphQL API: filtered lists, the batched
+// lookups of its loaders, and the writes of its mutations.
+type GraphRepository interface {
+	WithContext(ctx context.Context) GraphRepository
+	FindUsers(filter UserFilter, page Page) ([]models.User, error)
+	FindProjects(filter ProjectFilter, page Page) ([]models.Project, error)
+	FindTasks(filter TaskFilter, page Page) ([]models.Task, error)
+
+	GetUsersByIDs(ids []int) ([]models.User, error)
+	GetProjectsByIDs(ids []int) ([]models.Project, error)
+	GetTasksByIDs(ids []int) ([]models.Task, error)
+	GetProjectsByManagers(managerIDs []int) ([]models.Project, error)
+	GetTasksByAssignees(assigneeIDs []int, filter TaskFilter) ([]models.Task, error)
+	GetTasksByProjects(projectIDs []int, filter TaskFilter) ([]models.Task, error)
+
+	CreateUser(user *models.User) error
+	UpdateUser(user *models.User) error
+	DeleteUser(id int) error
+	CreateProject(project *models.Project) error
+	UpdateProject(project *models.Project) error
+	DeleteProject(id int) error
+	CreateTask(task *models.Task) error
+	UpdateTask(task *models.Task) error
+	DeleteTask(id int) error
+}
+
+type graphRepository struct {
+	DB *gorm.DB
+}
+
+func NewGraphRepository(db *gorm.DB) GraphRepository {
+	return &graphRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *graphRepository) WithContext(ctx context.Context) GraphRepository {
+	return &graphRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (page Page) apply(query *gorm.DB) *gorm.DB {
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit)
+	}
+	if page.Offset > 0 {
+		query = query.Offset(page.Offset)
+	}
+	return query
+}
+
+func (repo *graphRepository) FindUsers(filter UserFilter, page Page) ([]models.User, error) {
+	query := repo.DB.Order("id")
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.Email != "" {
+		query = query.Where("email LIKE ?", "%"+filter.Email+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	var users []models.User
+	err := page.apply(query).Find(&users).Error
+	return users, err
+}
+
+func (repo *graphRepository) FindProjects(filter ProjectFilter, page Page) ([]models.Project, error) {
+	query := repo.DB.Order("id")
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.ManagerID != 0 {
+		query = query.Where("manager_id = ?", filter.ManagerID)
+	}
+	var projects []models.Project
+	err := page.apply(query).Find(&projects).Error
+	return projects, err
+}
+
+func (repo *graphRepository) FindTasks(filter TaskFilter, page Page) ([]models.Task, error) {
+	var tasks []models.Task
+	err := page.apply(filter.apply(repo.DB.Order("tasks.id"))).Find(&tasks).Error
+	return tasks, err
+}
+
+func (repo *graphRepository) GetUsersByIDs(ids []int) ([]models.User, error) {
+	var users []models.User
+	err := repo.DB.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (repo *graphRepository) GetProjectsByIDs(ids []int) ([]models.Project, error) {
+	var projects []models.Project
+	err := repo.DB.Where("id IN ?", ids).Find(&projects).Error
+	return projects, err
+}
+
+func (repo *graphRepository) GetTasksByIDs(ids []int) ([]models.Task, error) {
+	var tasks []models.Task
+	err := repo.DB.Where("id IN ?", ids).Find(&tasks).Error
+	return tasks, err
+}
+
+func (repo *graphRepository) GetProjectsByManagers(managerIDs []int) ([]models.Project, error) {
+	var projects []models.Project
+	err := repo.DB.Where("manager_id IN ?", managerIDs).Order("id").Find(&projects).Error
+	return projects, err
+}
+
+func (repo *graphRepository) GetTasksByAssignees(assigneeIDs []int, filter TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	err := filter.apply(repo.DB.Where("tasks.assignee_id IN ?", assigneeIDs).Order("tasks.id")).Find(&tasks).Error
+	return tasks, err
+}
+
+func (repo *graphRepository) GetTasksByProjects(projectIDs []int, filter TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	err := filter.apply(repo.DB.Where("tasks.project_id IN ?", projectIDs).Order("tasks.id")).Find(&tasks).Error
+	return tasks, err
+}
+
+func (repo *graphRepository) CreateUser(user *models.User) error {
+	return repo.DB.Create(user).Error
+}
+
+func (repo *graphRepository) UpdateUser(user *models.User) error {
+	return repo.DB.Save(user).Error
+}
+
+func (repo *graphRepository) DeleteUser(id int) error {
+	return deleteUser(repo.DB, uint(id))
+}
+
+func (repo *graphRepository) CreateProject(project *models.Project) error {
+	return repo.DB.Create(project).Error
+}
+
+func (repo *graphRepository) UpdateProject(project *models.Project) error {
+	return repo.DB.Save(project).Error
+}
+
+func (repo *graphRepository) DeleteProject(id int) error {
+	return repo.DB.Delete(&models.Project{}, id).Error
+}
+
+func (repo *graphRepository) CreateTask(task *models.Task) error {
+	return repo.DB.Create(task).Error
+}
+
+func (repo *graphRepository) UpdateTask(task *models.Task) error {
+	return repo.DB.Save(task).Error
+}
+
+func (repo *graphRepository) DeleteTask(id int) error {
+	return repo.DB.Delete(&models.Task{}, id).Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+type ImportRepository interface {
+	WithContext(ctx context.Context) ImportRepository
+	ExistingUserIDs(ids []int) (map[int]bool, error)
+	ExistingProjectIDs(ids []int) (map[int]bool, error)
+	UserIDsByEmail(emails []string) (map[string]int, error)
+	CreateAll(records []interface{}) error
+	CreateImportJob(job *models.ImportJob) error
+	GetImportJob(id string) (*models.ImportJob, error)
+	UpdateImportJob(job *models.ImportJob) error
+}
+
+type importRepository struct {
+	DB *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &importRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *importRepository) WithContext(ctx context.Context) ImportRepository {
+	return &importRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *importRepository) ExistingUserIDs(ids []int) (map[int]bool, error) {
+	return repo.existingIDs(&models.User{}, ids)
+}
+
+func (repo *importRepository) ExistingProjectIDs(ids []int) (map[int]bool, error) {
+	return repo.existingIDs(&models.Project{}, ids)
+}
+
+func (repo *importRepository) existingIDs(model interface{}, ids []int) (map[int]bool, error) {
+	existing := make(map[int]bool)
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	var found []int
+	if err := repo.DB.Model(model).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// UserIDsByEmail maps the lower-cased emails of existing users to their IDs.
+func (repo *importRepository) UserIDsByEmail(emails []string) (map[string]int, error) {
+	ids := make(map[string]int)
+	if len(emails) == 0 {
+		return ids, nil
+	}
+	var users []models.User
+	if err := repo.DB.Where("LOWER(email) IN ?", emails).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		ids[strings.ToLower(user.Email)] = int(user.ID)
+	}
+	return ids, nil
+}
+
+// CreateAll creates every record in a single transaction. The assignees of
+// imported tasks become contributors of their projects.
+func (repo *importRepository) CreateAll(records []interface{}) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		for _, record := range records {
+			if task, ok := record.(*models.Task); ok {
+				if err := joinProject(tx, task.ProjectID, task.AssigneeID); err != nil {
+					return err
+				}
+			}
+			if err := tx.Create(record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (repo *importRepository) CreateImportJob(job *models.ImportJob) error {
+	return repo.DB.Create(job).Error
+}
+
+func (repo *importRepository) GetImportJob(id string) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := repo.DB.First(&job, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (repo *importRepository) UpdateImportJob(job *models.ImportJob) error {
+	return repo.DB.Save(job).Error
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvitationClosed is returned when an invitation that was already
+// accepted or revoked is changed, or when an expired one is accepted.
+var ErrInvitationClosed = errors.New("the invitation is no longer pending")
+
+// pending selects the invitations that were neither accepted nor revoked.
+const pending = "accepted_at IS NULL AND revoked_at IS NULL"
+
+type InvitationRepository interface {
+	WithContext(ctx context.Context) InvitationRepository
+	CreateInvitation(invitation *models.Invitation) error
+	GetInvitationByID(id uint) (*models.Invitation, error)
+	GetPendingInvitations() ([]models.Invitation, error)
+	// FindPendingInvitation looks an invitation up by the hash of its token,
+	// in every workspace.
+	FindPendingInvitation(tokenHash string) (*models.Invitation, error)
+	RenewInvitation(id uint, tokenHash string, expiresAt time.Time) error
+	RevokeInvitation(id uint) error
+	// AcceptInvitation makes user a member of the invitation's workspace,
+	// with its workspace role, and of its project if it has one. A user
+	// without an ID is created first, with the password hash as credential.
+	AcceptInvitation(invitation *models.Invitation, user *models.User, passwordHash string) error
+}
+
+type invitationRepository struct {
+	DB *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *invitationRepository) WithContext(ctx context.Context) InvitationRepository {
+	return &invitationRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *invitationRepository) CreateInvitation(invitation *models.Invitation) error {
+	return repo.DB.Create(invitation).Error
+}
+
+func (repo *invitationRepository) GetInvitationByID(id uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := repo.DB.First(&invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPendingInvitations returns the pending invitations, expired ones
+// included so that they can be resent.
+func (repo *invitationRepository) GetPendingInvitations() ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	err := repo.DB.Where(pending).Order("id").Find(&invitations).Error
+	return invitations, err
+}
+
+func (repo *invitationRepository) FindPendingInvitation(tokenHash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := workspace.Global(repo.DB).Where("token_hash = ? AND "+pending, tokenHash).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// RenewInvitation replaces the token of a pending invitation, so that the
+// one sent before no longer works, and extends it.
+func (repo *invitationRepository) RenewInvitation(id uint, tokenHash string, expiresAt time.Time) error {
+	result := repo.DB.Model(&models.Invitation{}).Where("id = ? AND "+pending, id).
+		Updates(map[string]interface{}{"token_hash": tokenHash, "expires_at": expiresAt, "sent_at": time.Now()})
+	return closedUnlessUpdated(result)
+}
+
+func (repo *invitationRepository) RevokeInvitation(id uint) error {
+	result := repo.DB.Model(&models.Invitation{}).Where("id = ? AND "+pending, id).
+		Update("revoked_at", time.Now())
+	return closedUnlessUpdated(result)
+}
+
+func (repo *invitationRepository) AcceptInvitation(invitation *models.Invitation, user *models.User, passwordHash string) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		tx = workspace.Global(tx)
+		if user.ID == 0 {
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.Credential{UserID: user.ID, PasswordHash: passwordHash}).Error; err != nil {
+				return err
+			}
+		}
+		// Claiming the invitation fails for a concurrent accept of the same
+		// token, which then rolls back the user it created.
+		now := time.Now()
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND expires_at > ? AND "+pending, invitation.ID, now).
+			Updates(map[string]interface{}{"accepted_at": now, "user_id": user.ID})
+		if err := closedUnlessUpdated(result); err != nil {
+			return err
+		}
+		membership := models.Membership{WorkspaceID: invitation.WorkspaceID, UserID: user.ID, Role: models.WorkspaceMember}
+		if invitation.WorkspaceRole != "" {
+			membership.Role = invitation.WorkspaceRole
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&membership).Error; err != nil {
+			return err
+		}
+		if invitation.ProjectID == nil {
+			return nil
+		}
+		member := models.ProjectMember{
+			ProjectID:   *invitation.ProjectID,
+			UserID:      user.ID,
+			Role:        invitation.ProjectRole,
+			WorkspaceID: invitation.WorkspaceID,
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error
+	})
+}
+
+// closedUnlessUpdated maps an update of a pending invitation that matched no
+// row to ErrInvitationClosed.
+func closedUnlessUpdated(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvitationClosed
+	}
+	return nil
+}
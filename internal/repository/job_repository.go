@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository interface {
+	EnqueueRun(run *models.JobRun) (bool, error)
+	ClaimNextRun(jobNames []string, now time.Time, workerID string) (*models.JobRun, error)
+	FinishRun(run *models.JobRun) error
+	RequeueStaleRuns(startedBefore time.Time) (int64, error)
+	GetLastScheduledAt(jobName string) (*time.Time, error)
+	GetRecentRuns(jobName string, limit int) ([]models.JobRun, error)
+}
+
+type jobRepository struct {
+	DB *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{DB: db}
+}
+
+// EnqueueRun adds a queued run and reports whether it was new. A run for the
+// same job and scheduled time is only ever enqueued once.
+func (repo *jobRepository) EnqueueRun(run *models.JobRun) (bool, error) {
+	run.Status = models.JobRunQueued
+	result := repo.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_name"}, {Name: "scheduled_at"}},
+		DoNothing: true,
+	}).Create(run)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimNextRun marks the oldest due run of one of jobNames as running and
+// returns it, or returns nil when there is nothing to do. Rows locked by other
+// workers are skipped, so concurrent workers never claim the same run.
+func (repo *jobRepository) ClaimNextRun(jobNames []string, now time.Time, workerID string) (*models.JobRun, error) {
+	var run models.JobRun
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND scheduled_at <= ? AND job_name IN ?", models.JobRunQueued, now, jobNames).
+			Order("scheduled_at").
+			First(&run).Error
+		if err != nil {
+			return err
+		}
+		run.Status = models.JobRunRunning
+		run.Attempts++
+		run.WorkerID = workerID
+		run.StartedAt = &now
+		return tx.Save(&run).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (repo *jobRepository) FinishRun(run *models.JobRun) error {
+	return repo.DB.Save(run).Error
+}
+
+// RequeueStaleRuns puts runs whose worker disappeared back into the queue.
+func (repo *jobRepository) RequeueStaleRuns(startedBefore time.Time) (int64, error) {
+	result := repo.DB.Model(&models.JobRun{}).
+		Where("status = ? AND started_at < ?", models.JobRunRunning, startedBefore).
+		Updates(map[string]interface{}{"status": models.JobRunQueued, "worker_id": ""})
+	return result.RowsAffected, result.Error
+}
+
+func (repo *jobRepository) GetLastScheduledAt(jobName string) (*time.Time, error) {
+	var run models.JobRun
+	err := repo.DB.Where("job_name = ?", jobName).Order("scheduled_at DESC").First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run.ScheduledAt, nil
+}
+
+func (repo *jobRepository) GetRecentRuns(jobName string, limit int) ([]models.JobRun, error) {
+	var runs []models.JobRun
+	err := repo.DB.Where("job_name = ?", jobName).Order("scheduled_at DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLastOwner is returned when a change would leave a project without an
+// owner.
+var ErrLastOwner = errors.New("a project needs at least one owner")
+
+// ProjectMemberInfo is a project membership together with the user's name
+// and email.
+type ProjectMemberInfo struct {
+	models.ProjectMember
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// ProjectMemberRepository manages who is part of a project, and with which
+// role. Project managers become owners by themselves, see
+// pkg/database.createProjectOwnerTrigger.
+type ProjectMemberRepository interface {
+	WithContext(ctx context.Context) ProjectMemberRepository
+	GetProject(projectID int) (*models.Project, error)
+	GetProjectMember(projectID int, userID uint) (*models.ProjectMember, error)
+	GetProjectMembers(projectID int) ([]ProjectMemberInfo, error)
+	AddProjectMember(member *models.ProjectMember) error
+	AddProjectTeam(projectID int, teamID uint, role string) ([]models.ProjectMember, error)
+	RemoveProjectMember(projectID int, userID uint) error
+}
+
+type projectMemberRepository struct {
+	DB *gorm.DB
+}
+
+func NewProjectMemberRepository(db *gorm.DB) ProjectMemberRepository {
+	return &projectMemberRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *projectMemberRepository) WithContext(ctx context.Context) ProjectMemberRepository {
+	return &projectMemberRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *projectMemberRepository) GetProject(projectID int) (*models.Project, error) {
+	var project models.Project
+	if err := repo.DB.First(&project, projectID).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (repo *projectMemberRepository) GetProjectMember(projectID int, userID uint) (*models.ProjectMember, error) {
+	var member models.ProjectMember
+	if err := repo.DB.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (repo *projectMemberRepository) GetProjectMembers(projectID int) ([]ProjectMemberInfo, error) {
+	var members []ProjectMemberInfo
+	err := repo.DB.Model(&models.ProjectMember{}).
+		Select("project_members.*, users.name, users.email").
+		Joins("JOIN users ON users.id = project_members.user_id").
+		Where("project_members.project_id = ?", projectID).
+		Order("project_members.user_id").
+		Scan(&members).Error
+	return members, err
+}
+
+// AddProjectMember adds a user to a project, or changes the role of a
+// member. The last owner cannot be demoted.
+func (repo *projectMemberRepository) AddProjectMember(member *models.ProjectMember) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if member.Role != models.ProjectOwner {
+			if err := keepOwner(tx, member.ProjectID, member.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).Create(member).Error
+	})
+}
+
+// AddProjectTeam adds the current members of a team to a project, and
+// returns the memberships it created. Members of the team who already are
+// members of the project keep their role, and later members of the team
+// are not added.
+func (repo *projectMemberRepository) AddProjectTeam(projectID int, teamID uint, role string) ([]models.ProjectMember, error) {
+	var added []models.ProjectMember
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		var team models.Team
+		if err := tx.First(&team, teamID).Error; err != nil {
+			return err
+		}
+		var userIDs []uint
+		err := tx.Model(&models.TeamMember{}).
+			Where("team_id = ? AND user_id NOT IN (?)", teamID,
+				tx.Model(&models.ProjectMember{}).Select("user_id").Where("project_id = ?", projectID)).
+			Order("user_id").
+			Pluck("user_id", &userIDs).Error
+		if err != nil || len(userIDs) == 0 {
+			return err
+		}
+		for _, userID := range userIDs {
+			added = append(added, models.ProjectMember{ProjectID: projectID, UserID: userID, Role: role})
+		}
+		return tx.Create(&added).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// RemoveProjectMember removes a user from a project. The last owner cannot
+// be removed, and neither can members who still have tasks of the project
+// assigned, which the tasks' foreign key reports.
+func (repo *projectMemberRepository) RemoveProjectMember(projectID int, userID uint) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := keepOwner(tx, projectID, userID); err != nil {
+			return err
+		}
+		return tx.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.ProjectMember{}).Error
+	})
+}
+
+// keepOwner fails with ErrLastOwner if userID is the only owner of the
+// project. The owners are locked so that two requests cannot demote the
+// last two owners at once.
+func keepOwner(tx *gorm.DB, projectID int, userID uint) error {
+	var owners []uint
+	err := tx.Model(&models.ProjectMember{}).
+		Where("project_id = ? AND role = ?", projectID, models.ProjectOwner).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Pluck("user_id", &owners).Error
+	if err != nil {
+		return err
+	}
+	if len(owners) == 1 && owners[0] == userID {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// joinProject makes a user a contributor of a project, if it is not a
+// member already. Writes that assign tasks in bulk, such as imports and
+// archive restores, use it so that their assignees satisfy the project
+// membership the tasks' foreign keys require.
+func joinProject(tx *gorm.DB, projectID, userID int) error {
+	return tx.Exec(`INSERT INTO project_members (project_id, user_id, role, workspace_id, created_at)
+		SELECT id, ?, ?, workspace_id, now() FROM projects WHERE id = ?
+		ON CONFLICT DO NOTHING`, userID, models.ProjectContributor, projectID).Error
+}
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
 	"gorm.io/gorm"
 )
 
@@ -13,6 +16,12 @@ func NewProjectRepository(db *gorm.DB) *ProjectRepository {
 	return &ProjectRepository{DB: db}
 }
 
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (pr *ProjectRepository) WithContext(ctx context.Context) *ProjectRepository {
+	return &ProjectRepository{DB: workspace.DB(ctx, pr.DB)}
+}
+
 func (pr *ProjectRepository) GetAllProjects() ([]models.Project, error) {
 	var projects []models.Project
 	if err := pr.DB.Find(&projects).Error; err != nil {
@@ -64,9 +73,3 @@ func (pr *ProjectRepository) SearchProjectsByManagerID(managerID uint) ([]models
 	}
 	return projects, nil
 }
-
-func (pr *ProjectRepository) UserExists(userID int) bool {
-    var count int64
-    pr.DB.Model(&models.User{}).Where("id = ?", userID).Count(&count)
-    return count > 0
-}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecurringTaskRepository interface {
+	WithContext(ctx context.Context) RecurringTaskRepository
+	GetAllRecurringTasks() ([]models.RecurringTask, error)
+	GetRecurringTaskByID(id uint) (*models.RecurringTask, error)
+	CreateRecurringTask(template *models.RecurringTask) error
+	UpdateRecurringTask(template *models.RecurringTask, from time.Time) error
+	SplitRecurringTask(previous, next *models.RecurringTask, from time.Time) error
+	DeleteRecurringTask(id uint, from time.Time) error
+	CreateOccurrence(task *models.Task) (bool, error)
+	GetProject(projectID int) (*models.Project, error)
+}
+
+type recurringTaskRepository struct {
+	DB *gorm.DB
+}
+
+func NewRecurringTaskRepository(db *gorm.DB) RecurringTaskRepository {
+	return &recurringTaskRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *recurringTaskRepository) WithContext(ctx context.Context) RecurringTaskRepository {
+	return &recurringTaskRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *recurringTaskRepository) GetAllRecurringTasks() ([]models.RecurringTask, error) {
+	var templates []models.RecurringTask
+	err := repo.DB.Order("id").Find(&templates).Error
+	return templates, err
+}
+
+func (repo *recurringTaskRepository) GetRecurringTaskByID(id uint) (*models.RecurringTask, error) {
+	var template models.RecurringTask
+	if err := repo.DB.First(&template, id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateRecurringTask stores a new template and starts a new series with it.
+func (repo *recurringTaskRepository) CreateRecurringTask(template *models.RecurringTask) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(template).Error; err != nil {
+			return err
+		}
+		if template.SeriesID == 0 {
+			template.SeriesID = template.ID
+			return tx.Model(template).Update("series_id", template.ID).Error
+		}
+		return nil
+	})
+}
+
+// UpdateRecurringTask saves the template and drops its untouched occurrences
+// from onwards, so that the generator recreates them from the new values.
+func (repo *recurringTaskRepository) UpdateRecurringTask(template *models.RecurringTask, from time.Time) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(template).Error; err != nil {
+			return err
+		}
+		return deleteFutureOccurrences(tx, template.ID, from)
+	})
+}
+
+// SplitRecurringTask ends previous before from and continues the series with
+// next, which takes over all occurrences from then on.
+func (repo *recurringTaskRepository) SplitRecurringTask(previous, next *models.RecurringTask, from time.Time) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(previous).Error; err != nil {
+			return err
+		}
+		if err := deleteFutureOccurrences(tx, previous.ID, from); err != nil {
+			return err
+		}
+		next.SeriesID = previous.SeriesID
+		return tx.Create(next).Error
+	})
+}
+
+// DeleteRecurringTask removes the template together with its untouched
+// occurrences from onwards. Past and already started tasks are kept.
+func (repo *recurringTaskRepository) DeleteRecurringTask(id uint, from time.Time) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteFutureOccurrences(tx, int(id), from); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Task{}).Where("recurring_task_id = ?", id).Update("recurring_task_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.RecurringTask{}, id).Error
+	})
+}
+
+func deleteFutureOccurrences(tx *gorm.DB, templateID int, from time.Time) error {
+	return tx.Where("recurring_task_id = ? AND occurrence_date >= ? AND status = ?", templateID, from, "todo").
+		Delete(&models.Task{}).Error
+}
+
+// CreateOccurrence inserts a generated task unless the occurrence already
+// exists, and reports whether a row was created.
+func (repo *recurringTaskRepository) CreateOccurrence(task *models.Task) (bool, error) {
+	result := repo.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "recurring_task_id"}, {Name: "occurrence_date"}},
+		DoNothing: true,
+	}).Create(task)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (repo *recurringTaskRepository) GetProject(projectID int) (*models.Project, error) {
+	var project models.Project
+	if err := repo.DB.First(&project, projectID).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReminderRepository interface {
+	HasReminder(taskID int, kind string, offset time.Duration, dueDate time.Time) (bool, error)
+	CreateReminder(reminder *models.TaskReminder) error
+}
+
+type reminderRepository struct {
+	DB *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{DB: db}
+}
+
+func (repo *reminderRepository) HasReminder(taskID int, kind string, offset time.Duration, dueDate time.Time) (bool, error) {
+	var count int64
+	err := repo.DB.Model(&models.TaskReminder{}).
+		Where("task_id = ? AND kind = ? AND reminder_offset = ? AND due_date = ?", taskID, kind, offset, dueDate).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (repo *reminderRepository) CreateReminder(reminder *models.TaskReminder) error {
+	return repo.DB.Create(reminder).Error
+}
@@ -1,11 +1,16 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
 	"gorm.io/gorm"
 )
 
 type TaskRepository interface {
+	WithContext(ctx context.Context) TaskRepository
 	GetAllTasks() ([]models.Task, error)
 	GetTaskByID(id uint) (*models.Task, error)
 	CreateTask(task *models.Task) error
@@ -16,8 +21,9 @@ type TaskRepository interface {
 	SearchTasksByPriority(priority string) ([]models.Task, error)
 	SearchTasksByAssignee(userID uint) ([]models.Task, error)
 	SearchTasksByProject(projectID uint) ([]models.Task, error)
-	UserExists(userID int) bool
-	ProjectExists(ProjectID int) bool
+	GetOverdueTasks(now time.Time) ([]models.Task, error)
+	GetTasksDueBetween(from, to time.Time) ([]models.Task, error)
+	GetProject(projectID int) (*models.Project, error)
 }
 
 type taskRepository struct {
@@ -30,6 +36,12 @@ func NewTaskRepository(db *gorm.DB) TaskRepository {
 	}
 }
 
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *taskRepository) WithContext(ctx context.Context) TaskRepository {
+	return &taskRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
 func (repo *taskRepository) GetAllTasks() ([]models.Task, error) {
 	var tasks []models.Task
 	err := repo.DB.Find(&tasks).Error
@@ -87,14 +99,26 @@ func (repo *taskRepository) SearchTasksByProject(projectID uint) ([]models.Task,
 	return tasks, err
 }
 
-func (tr *taskRepository) UserExists(userID int) bool {
-    var count int64
-    tr.DB.Model(&models.User{}).Where("id = ?", userID).Count(&count)
-    return count > 0
+// GetOverdueTasks returns unfinished tasks whose due date is before now.
+func (repo *taskRepository) GetOverdueTasks(now time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := repo.DB.Where("due_date IS NOT NULL AND due_date < ? AND status <> ?", now, "done").
+		Order("due_date").Find(&tasks).Error
+	return tasks, err
 }
 
-func (tr *taskRepository) ProjectExists(ProjectID int) bool {
-    var count int64
-    tr.DB.Model(&models.Project{}).Where("id = ?", ProjectID).Count(&count)
-    return count > 0
+// GetTasksDueBetween returns unfinished tasks due in the half-open range [from, to).
+func (repo *taskRepository) GetTasksDueBetween(from, to time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := repo.DB.Where("due_date >= ? AND due_date < ? AND status <> ?", from, to, "done").
+		Order("due_date").Find(&tasks).Error
+	return tasks, err
+}
+
+func (repo *taskRepository) GetProject(projectID int) (*models.Project, error) {
+	var project models.Project
+	if err := repo.DB.First(&project, projectID).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
 }
\ No newline at end of file
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// TeamMemberInfo is a team membership together with the user's name and
+// email.
+type TeamMemberInfo struct {
+	models.TeamMember
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type TeamRepository interface {
+	WithContext(ctx context.Context) TeamRepository
+	GetAllTeams() ([]models.Team, error)
+	GetTeamByID(id uint) (*models.Team, error)
+	CreateTeam(team *models.Team) error
+	DeleteTeam(id uint) error
+	GetTeamMembers(teamID uint) ([]TeamMemberInfo, error)
+	AddTeamMember(member *models.TeamMember) error
+	RemoveTeamMember(teamID, userID uint) error
+}
+
+type teamRepository struct {
+	DB *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *teamRepository) WithContext(ctx context.Context) TeamRepository {
+	return &teamRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+func (repo *teamRepository) GetAllTeams() ([]models.Team, error) {
+	var teams []models.Team
+	if err := repo.DB.Order("id").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (repo *teamRepository) GetTeamByID(id uint) (*models.Team, error) {
+	var team models.Team
+	if err := repo.DB.First(&team, id).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (repo *teamRepository) CreateTeam(team *models.Team) error {
+	return repo.DB.Create(team).Error
+}
+
+// DeleteTeam deletes a team and its memberships. The project memberships
+// of its members stay.
+func (repo *teamRepository) DeleteTeam(id uint) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", id).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Team{}, id).Error
+	})
+}
+
+func (repo *teamRepository) GetTeamMembers(teamID uint) ([]TeamMemberInfo, error) {
+	var members []TeamMemberInfo
+	err := repo.DB.Model(&models.TeamMember{}).
+		Select("team_members.*, users.name, users.email").
+		Joins("JOIN users ON users.id = team_members.user_id").
+		Where("team_members.team_id = ?", teamID).
+		Order("team_members.user_id").
+		Scan(&members).Error
+	return members, err
+}
+
+func (repo *teamRepository) AddTeamMember(member *models.TeamMember) error {
+	return repo.DB.Create(member).Error
+}
+
+func (repo *teamRepository) RemoveTeamMember(teamID, userID uint) error {
+	return repo.DB.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{}).Error
+}
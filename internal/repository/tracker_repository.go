@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+type TrackerRepository interface {
+	WithContext(ctx context.Context) TrackerRepository
+	UserIDsByEmail(emails []string) (map[string]int, error)
+	GetProject(projectID int) (*models.Project, error)
+	UpsertProject(source, externalID string, project *models.Project) (bool, error)
+	UpsertTask(source, externalID string, task *models.Task) (bool, error)
+}
+
+type trackerRepository struct {
+	DB *gorm.DB
+}
+
+func NewTrackerRepository(db *gorm.DB) TrackerRepository {
+	return &trackerRepository{DB: db}
+}
+
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *trackerRepository) WithContext(ctx context.Context) TrackerRepository {
+	return &trackerRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
+// UserIDsByEmail maps the lower-cased emails of existing users to their IDs.
+func (repo *trackerRepository) UserIDsByEmail(emails []string) (map[string]int, error) {
+	ids := make(map[string]int)
+	if len(emails) == 0 {
+		return ids, nil
+	}
+	var users []models.User
+	if err := repo.DB.Where("LOWER(email) IN ?", emails).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		ids[strings.ToLower(user.Email)] = int(user.ID)
+	}
+	return ids, nil
+}
+
+func (repo *trackerRepository) GetProject(projectID int) (*models.Project, error) {
+	var project models.Project
+	if err := repo.DB.First(&project, projectID).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// UpsertProject returns the project previously imported under externalID in
+// project, or creates it. Existing projects are left unchanged, since their
+// dates and manager may have been edited after the first import. The result
+// reports whether the project was created.
+func (repo *trackerRepository) UpsertProject(source, externalID string, project *models.Project) (bool, error) {
+	created := false
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		ref, err := findExternalRef(tx, source, models.ExternalRefProject, externalID)
+		if err != nil {
+			return err
+		}
+		if ref != nil {
+			err := tx.First(project, ref.EntityID).Error
+			if err == nil {
+				return nil
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+		project.ID = 0
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		created = true
+		return saveExternalRef(tx, ref, source, models.ExternalRefProject, externalID, project.ID)
+	})
+	return created, err
+}
+
+// UpsertTask updates the task previously imported under externalID, or creates
+// it. Its assignee becomes a contributor of the project. The result reports
+// whether the task was created.
+func (repo *trackerRepository) UpsertTask(source, externalID string, task *models.Task) (bool, error) {
+	created := false
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := joinProject(tx, task.ProjectID, task.AssigneeID); err != nil {
+			return err
+		}
+		ref, err := findExternalRef(tx, source, models.ExternalRefTask, externalID)
+		if err != nil {
+			return err
+		}
+		if ref != nil {
+			var existing models.Task
+			err := tx.Select("id").First(&existing, ref.EntityID).Error
+			if err == nil {
+				task.ID = existing.ID
+				return tx.Save(task).Error
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+		task.ID = 0
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		created = true
+		return saveExternalRef(tx, ref, source, models.ExternalRefTask, externalID, task.ID)
+	})
+	return created, err
+}
+
+func findExternalRef(tx *gorm.DB, source, kind, externalID string) (*models.ExternalRef, error) {
+	var ref models.ExternalRef
+	err := tx.Where("source = ? AND kind = ? AND external_id = ?", source, kind, externalID).First(&ref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
+
+// saveExternalRef points ref at entityID, creating the reference if this is
+// the first import. An existing ref is re-pointed when its entity was deleted.
+func saveExternalRef(tx *gorm.DB, ref *models.ExternalRef, source, kind, externalID string, entityID int) error {
+	if ref == nil {
+		ref = &models.ExternalRef{Source: source, Kind: kind, ExternalID: externalID}
+	}
+	ref.EntityID = entityID
+	return tx.Save(ref).Error
+}
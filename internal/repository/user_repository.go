@@ -1,12 +1,17 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"gorm.io/gorm"
 	
 	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
 )
 
 type UserRepository interface {
+	WithContext(ctx context.Context) UserRepository
 	CreateUser(user *models.User) error
 	FindByEmail(email string) (*models.User, error)
 	GetAllUsers() ([]models.User, error)
@@ -16,6 +21,7 @@ type UserRepository interface {
 	FindByName(name string) ([]models.User, error)
 	FindByEmailLike(email string) ([]models.User, error)
 	GetTasksByUserID(userID uint) ([]models.Task, error)
+	GetTasksByUserIDDueBefore(userID uint, before time.Time) ([]models.Task, error)
 }
 
 type userRepository struct {
@@ -26,6 +32,12 @@ func NewUserRepository(db *gorm.DB) *userRepository {
 	return &userRepository{DB: db}
 }
 
+// WithContext returns a copy of the repository whose statements run with
+// ctx, and so are scoped to its workspace.
+func (repo *userRepository) WithContext(ctx context.Context) UserRepository {
+	return &userRepository{DB: workspace.DB(ctx, repo.DB)}
+}
+
 func (repo *userRepository) CreateUser(user *models.User) error {
 	return repo.DB.Create(user).Error
 }
@@ -55,8 +67,34 @@ func (repo *userRepository) UpdateUser(user *models.User) error {
 	return repo.DB.Save(user).Error
 }
 
+// DeleteUser removes the user from the workspace of the repository's
+// context, and deletes the user once they belong to no workspace. The last
+// admin of the workspace cannot be removed.
 func (repo *userRepository) DeleteUser(id uint) error {
-	return repo.DB.Delete(&models.User{}, id).Error
+	return deleteUser(repo.DB, id)
+}
+
+func deleteUser(db *gorm.DB, id uint) error {
+	workspaceID, ok := workspace.ID(db.Statement.Context)
+	if !ok {
+		return db.Delete(&models.User{}, id).Error
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := keepAdmin(tx, workspaceID, id); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.Membership{}).Error; err != nil {
+			return err
+		}
+		var memberships int64
+		if err := workspace.Global(tx).Model(&models.Membership{}).Where("user_id = ?", id).Count(&memberships).Error; err != nil {
+			return err
+		}
+		if memberships > 0 {
+			return nil
+		}
+		return workspace.Global(tx).Delete(&models.User{}, id).Error
+	})
 }
 
 func (repo *userRepository) FindByName(name string) ([]models.User, error) {
@@ -75,4 +113,10 @@ func (repo *userRepository) GetTasksByUserID(userID uint) ([]models.Task, error)
 	var tasks []models.Task
 	err := repo.DB.Where("assignee_id = ?", userID).Find(&tasks).Error
 	return tasks, err
+}
+
+func (repo *userRepository) GetTasksByUserIDDueBefore(userID uint, before time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := repo.DB.Where("assignee_id = ? AND due_date < ?", userID, before).Order("due_date").Find(&tasks).Error
+	return tasks, err
 }
\ No newline at end of file
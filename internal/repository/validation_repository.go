@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// ValidationRepository answers the lookups of the database-backed
+// validation rules.
+type ValidationRepository interface {
+	EmailTaken(ctx context.Context, email string, exceptUserID uint) (bool, error)
+	UserRole(ctx context.Context, id int) (string, bool, error)
+	ProjectExists(ctx context.Context, id int) (bool, error)
+	ProjectMember(ctx context.Context, projectID, userID int) (bool, error)
+}
+
+type validationRepository struct {
+	DB *gorm.DB
+}
+
+func NewValidationRepository(db *gorm.DB) ValidationRepository {
+	return &validationRepository{DB: db}
+}
+
+// EmailTaken looks at the users of every workspace, as emails are unique
+// across them.
+func (repo *validationRepository) EmailTaken(ctx context.Context, email string, exceptUserID uint) (bool, error) {
+	var count int64
+	err := workspace.Global(workspace.DB(ctx, repo.DB)).Model(&models.User{}).Where("email = ? AND id <> ?", email, exceptUserID).Count(&count).Error
+	return count > 0, err
+}
+
+func (repo *validationRepository) UserRole(ctx context.Context, id int) (string, bool, error) {
+	var user models.User
+	err := workspace.DB(ctx, repo.DB).Select("role").First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return user.Role, true, nil
+}
+
+func (repo *validationRepository) ProjectExists(ctx context.Context, id int) (bool, error) {
+	var count int64
+	err := workspace.DB(ctx, repo.DB).Model(&models.Project{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+func (repo *validationRepository) ProjectMember(ctx context.Context, projectID, userID int) (bool, error) {
+	var count int64
+	err := workspace.DB(ctx, repo.DB).Model(&models.ProjectMember{}).Where("project_id = ? AND user_id = ?", projectID, userID).Count(&count).Error
+	return count > 0, err
+}
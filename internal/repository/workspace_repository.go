@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLastAdmin is returned when a change would leave a workspace without an
+// admin.
+var ErrLastAdmin = errors.New("a workspace needs at least one admin")
+
+// UserWorkspace is a workspace together with the caller's role in it.
+type UserWorkspace struct {
+	models.Workspace
+	Role string `json:"role"`
+}
+
+// Member is a membership together with the user's name and email.
+type Member struct {
+	models.Membership
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// WorkspaceRepository manages workspaces and their memberships. Its queries
+// name their workspace explicitly, since they serve the requests that pick
+// a workspace in the first place.
+type WorkspaceRepository interface {
+	CreateWorkspace(ws *models.Workspace, ownerID uint) error
+	GetWorkspaceByID(id uint) (*models.Workspace, error)
+	GetWorkspacesByUser(userID uint) ([]UserWorkspace, error)
+	UpdateWorkspace(ws *models.Workspace) error
+	GetMember(workspaceID, userID uint) (*models.Membership, error)
+	GetMembers(workspaceID uint) ([]Member, error)
+	UpdateMember(membership *models.Membership) error
+	RemoveMember(workspaceID, userID uint) error
+}
+
+type workspaceRepository struct {
+	DB *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{DB: db}
+}
+
+// CreateWorkspace creates a workspace administered by its owner.
+func (repo *workspaceRepository) CreateWorkspace(ws *models.Workspace, ownerID uint) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ws).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.Membership{WorkspaceID: ws.ID, UserID: ownerID, Role: models.WorkspaceAdmin}).Error
+	})
+}
+
+func (repo *workspaceRepository) GetWorkspaceByID(id uint) (*models.Workspace, error) {
+	var ws models.Workspace
+	if err := repo.DB.First(&ws, id).Error; err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+func (repo *workspaceRepository) GetWorkspacesByUser(userID uint) ([]UserWorkspace, error) {
+	var workspaces []UserWorkspace
+	err := repo.DB.Model(&models.Workspace{}).
+		Select("workspaces.*, workspace_members.role").
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.id").
+		Scan(&workspaces).Error
+	return workspaces, err
+}
+
+func (repo *workspaceRepository) UpdateWorkspace(ws *models.Workspace) error {
+	return repo.DB.Save(ws).Error
+}
+
+func (repo *workspaceRepository) GetMember(workspaceID, userID uint) (*models.Membership, error) {
+	var membership models.Membership
+	err := repo.DB.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&membership).Error
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (repo *workspaceRepository) GetMembers(workspaceID uint) ([]Member, error) {
+	var members []Member
+	err := repo.DB.Model(&models.Membership{}).
+		Select("workspace_members.*, users.name, users.email").
+		Joins("JOIN users ON users.id = workspace_members.user_id").
+		Where("workspace_members.workspace_id = ?", workspaceID).
+		Order("workspace_members.user_id").
+		Scan(&members).Error
+	return members, err
+}
+
+// UpdateMember changes the role of a member. The last admin cannot be
+// demoted.
+func (repo *workspaceRepository) UpdateMember(membership *models.Membership) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if membership.Role != models.WorkspaceAdmin {
+			if err := keepAdmin(tx, membership.WorkspaceID, membership.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Membership{}).
+			Where("workspace_id = ? AND user_id = ?", membership.WorkspaceID, membership.UserID).
+			Update("role", membership.Role).Error
+	})
+}
+
+// RemoveMember removes a user from a workspace. The last admin cannot be
+// removed, and neither can members who still have tasks assigned, which the
+// tasks' foreign key reports.
+func (repo *workspaceRepository) RemoveMember(workspaceID, userID uint) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := keepAdmin(tx, workspaceID, userID); err != nil {
+			return err
+		}
+		return tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&models.Membership{}).Error
+	})
+}
+
+// keepAdmin fails with ErrLastAdmin if userID is the only admin of the
+// workspace. The admins are locked so that two requests cannot demote the
+// last two admins at once.
+func keepAdmin(tx *gorm.DB, workspaceID, userID uint) error {
+	var admins []uint
+	err := workspace.Global(tx).Model(&models.Membership{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, models.WorkspaceAdmin).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Pluck("user_id", &admins).Error
+	if err != nil {
+		return err
+	}
+	if len(admins) == 1 && admins[0] == userID {
+		return ErrLastAdmin
+	}
+	return nil
+}
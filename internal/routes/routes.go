@@ -0,0 +1,236 @@
+// Package routes registers the API's HTTP routes. It is shared by the server
+// and the OpenAPI generator, so the published spec is built from the same
+// routing table that serves requests.
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+)
+
+// Handlers holds the handlers the routes dispatch to, and the middleware
+// that identifies the caller and scopes requests to a workspace.
+type Handlers struct {
+	Workspace     *handlers.WorkspaceHandler
+	User          *handlers.UserHandler
+	Task          *handlers.TaskHandler
+	Project       *handlers.ProjectHandler
+	ProjectMember *handlers.ProjectMemberHandler
+	Team          *handlers.TeamHandler
+	Invitation    *handlers.InvitationHandler
+	Auth          *handlers.AuthHandler
+	APIToken      *handlers.APITokenHandler
+	RecurringTask *handlers.RecurringTaskHandler
+	Job           *handlers.JobHandler
+	Calendar      *handlers.CalendarHandler
+	Import        *handlers.ImportHandler
+	Export        *handlers.ExportHandler
+	Archive       *handlers.ArchiveHandler
+	TrackerImport *handlers.TrackerImportHandler
+	Webhook       *handlers.WebhookHandler
+	GraphQL       *handlers.GraphQLHandler
+	Docs          *handlers.DocsHandler
+
+	// Authenticate identifies the caller of the /workspaces routes and of
+	// the scoped ones.
+	Authenticate []gin.HandlerFunc
+	// Scope runs after Authenticate on the routes that act on the data of a
+	// workspace. The calendar feeds and webhooks are authorized by their
+	// tokens and secrets instead.
+	Scope []gin.HandlerFunc
+	// RateLimitIP runs before Authenticate, limiting callers by IP address
+	// before their credentials are checked.
+	RateLimitIP []gin.HandlerFunc
+	// RateLimit runs on every route but the docs, after Authenticate on
+	// the routes that have it, so that callers are limited by identity.
+	RateLimit []gin.HandlerFunc
+}
+
+func Register(router *gin.Engine, h Handlers) {
+	router.NoRoute(problem.NoRoute)
+	router.GET("/openapi.json", h.Docs.GetSpec)
+	router.GET("/docs", h.Docs.GetDocs)
+
+	public := router.Group("/", h.RateLimit...)
+	authenticated := router.Group("/", h.RateLimitIP...)
+	authenticated.Use(h.Authenticate...)
+	authenticated.Use(h.RateLimit...)
+	scoped := authenticated.Group("/", h.Scope...)
+
+	// API tokens may only reach the routes their scopes grant; see
+	// auth.RequireScope. Managing members and deleting take admin scopes.
+	// Exports, archives and calendar feeds take data out of the API, so
+	// they also need the export scope, and importing archives the import
+	// scope.
+	exportScope := auth.RequireScope("export")
+	importScope := auth.RequireScope("import")
+	workspaceAdmin := auth.RequireAdminScope("workspaces")
+	workspaceRoutes := authenticated.Group("/workspaces", auth.RequireScope("workspaces"))
+	{
+		workspaceRoutes.GET("/", h.Workspace.GetWorkspaces)
+		workspaceRoutes.POST("/", h.Workspace.CreateWorkspace)
+		workspaceRoutes.GET("/:id/members", h.Workspace.GetMembers)
+		workspaceRoutes.POST("/:id/members", workspaceAdmin, h.Workspace.AddMember)
+		workspaceRoutes.PUT("/:id/members/:userId", workspaceAdmin, h.Workspace.UpdateMember)
+		workspaceRoutes.DELETE("/:id/members/:userId", workspaceAdmin, h.Workspace.RemoveMember)
+		workspaceRoutes.POST("/:id/webhook-secret", workspaceAdmin, h.Workspace.RotateWebhookSecret)
+	}
+
+	authRoutes := public.Group("/auth")
+	{
+		authRoutes.POST("/login", h.Auth.Login)
+		authRoutes.POST("/password-reset", h.Auth.RequestPasswordReset)
+		authRoutes.POST("/password-reset/confirm", h.Auth.ConfirmPasswordReset)
+		authRoutes.GET("/oidc/login", h.Auth.StartSSO)
+		authRoutes.GET("/oidc/callback", h.Auth.SSOCallback)
+	}
+	authenticated.POST("/auth/logout", h.Auth.Logout)
+
+	meRoutes := authenticated.Group("/me")
+	{
+		meRoutes.PUT("/email", h.Auth.ChangeEmail)
+		meRoutes.GET("/tokens", h.APIToken.GetTokens)
+		meRoutes.POST("/tokens", h.APIToken.CreateToken)
+		meRoutes.DELETE("/tokens/:id", h.APIToken.RevokeToken)
+	}
+
+	public.GET("/users/:id/calendar.ics", h.Calendar.GetUserCalendar)
+	public.GET("/projects/:id/calendar.ics", h.Calendar.GetProjectCalendar)
+
+	userAdmin := auth.RequireAdminScope("users")
+	userRoutes := scoped.Group("/users", auth.RequireScope("users"))
+	{
+		userRoutes.GET("/", h.User.GetAllUsers)
+		userRoutes.POST("/", userAdmin, h.User.CreateUser)
+		userRoutes.GET("/:id", h.User.GetUserByID)
+		userRoutes.PUT("/:id", h.User.UpdateUser)
+		userRoutes.DELETE("/:id", userAdmin, h.User.DeleteUser)
+		userRoutes.GET("/:id/tasks", h.User.GetTasksByUserID)
+		userRoutes.GET("/:id/auth-events", userAdmin, h.Auth.GetAuthEvents)
+		userRoutes.GET("/:id/calendar-tokens", exportScope, h.Calendar.GetUserFeedTokens)
+		userRoutes.POST("/:id/calendar-tokens", exportScope, h.Calendar.CreateUserFeedToken)
+		userRoutes.DELETE("/:id/calendar-tokens/:tokenId", exportScope, h.Calendar.RevokeUserFeedToken)
+		userRoutes.GET("/search", func(c *gin.Context) {
+			if name := c.Query("name"); name != "" {
+				h.User.SearchUsersByName(c)
+			} else if email := c.Query("email"); email != "" {
+				h.User.SearchUsersByEmail(c)
+			} else {
+				problem.Write(c, problem.BadRequest("Query parameter 'name' or 'email' is required"))
+			}
+		})
+	}
+
+	taskRoutes := scoped.Group("/tasks", auth.RequireScope("tasks"))
+	{
+		taskRoutes.GET("/", h.Task.GetAllTasks)
+		taskRoutes.GET("/overdue", h.Task.GetOverdueTasks)
+		taskRoutes.POST("/", h.Task.CreateTask)
+		taskRoutes.GET("/:id", h.Task.GetTaskByID)
+		taskRoutes.GET("/:id/commits", h.Webhook.GetTaskCommits)
+		taskRoutes.PUT("/:id", h.Task.UpdateTask)
+		taskRoutes.DELETE("/:id", func(c *gin.Context) {
+			if title := c.Query("title"); title != "" {
+				h.Task.SearchTasksByTitle(c)
+			} else if status := c.Query("status"); status != "" {
+				h.Task.SearchTasksByStatus(c)
+			} else if priority := c.Query("priority"); priority != "" {
+				h.Task.SearchTasksByPriority(c)
+			} else if assignee := c.Query("assignee"); assignee != "" {
+				h.Task.SearchTasksByAssignee(c)
+			} else if project := c.Query("project"); project != "" {
+				h.Task.SearchTasksByProject(c)
+			} else {
+				problem.Write(c, problem.BadRequest("Missing query parameter"))
+			}
+		})
+	}
+
+	recurringTaskRoutes := scoped.Group("/recurring-tasks", auth.RequireScope("recurring-tasks"))
+	{
+		recurringTaskRoutes.GET("/", h.RecurringTask.GetAllRecurringTasks)
+		recurringTaskRoutes.POST("/", h.RecurringTask.CreateRecurringTask)
+		recurringTaskRoutes.GET("/:id", h.RecurringTask.GetRecurringTaskByID)
+		recurringTaskRoutes.PUT("/:id", h.RecurringTask.UpdateRecurringTask)
+		recurringTaskRoutes.DELETE("/:id", h.RecurringTask.DeleteRecurringTask)
+	}
+
+	projectAdmin := auth.RequireAdminScope("projects")
+	projectRoutes := scoped.Group("/projects", auth.RequireScope("projects"))
+	{
+		projectRoutes.GET("/", h.Project.GetAllProjects)
+		projectRoutes.POST("/", h.Project.CreateProject)
+		projectRoutes.GET("/:id", h.Project.GetProjectByID)
+		projectRoutes.PUT("/:id", h.Project.UpdateProject)
+		projectRoutes.DELETE("/:id", projectAdmin, h.Project.DeleteProject)
+		projectRoutes.GET("/:id/tasks", h.Project.GetTasksByProjectID)
+		projectRoutes.GET("/:id/members", h.ProjectMember.GetMembers)
+		projectRoutes.POST("/:id/members", projectAdmin, h.ProjectMember.AddMember)
+		projectRoutes.DELETE("/:id/members/:userId", projectAdmin, h.ProjectMember.RemoveMember)
+		projectRoutes.POST("/:id/teams", projectAdmin, h.ProjectMember.AddTeam)
+		projectRoutes.GET("/:id/export", exportScope, h.Export.ExportProjectTasks)
+		projectRoutes.GET("/:id/archive", exportScope, h.Archive.ExportProjectArchive)
+		projectRoutes.POST("/import-archive", importScope, h.Archive.ImportProjectArchive)
+		projectRoutes.GET("/:id/calendar-tokens", exportScope, h.Calendar.GetProjectFeedTokens)
+		projectRoutes.POST("/:id/calendar-tokens", exportScope, h.Calendar.CreateProjectFeedToken)
+		projectRoutes.DELETE("/:id/calendar-tokens/:tokenId", exportScope, h.Calendar.RevokeProjectFeedToken)
+		projectRoutes.GET("/search", func(c *gin.Context) {
+			if title := c.Query("title"); title != "" {
+				h.Project.SearchProjectsByTitle(c)
+			} else if manager := c.Query("manager"); manager != "" {
+				h.Project.SearchProjectsByManagerID(c)
+			} else {
+				problem.Write(c, problem.BadRequest("Missing query parameter"))
+			}
+		})
+	}
+
+	teamRoutes := scoped.Group("/teams", auth.RequireScope("teams"))
+	{
+		teamRoutes.GET("/", h.Team.GetAllTeams)
+		teamRoutes.POST("/", h.Team.CreateTeam)
+		teamRoutes.DELETE("/:id", h.Team.DeleteTeam)
+		teamRoutes.GET("/:id/members", h.Team.GetTeamMembers)
+		teamRoutes.POST("/:id/members", h.Team.AddTeamMember)
+		teamRoutes.DELETE("/:id/members/:userId", h.Team.RemoveTeamMember)
+	}
+
+	invitationRoutes := scoped.Group("/invitations", auth.RequireScope("invitations"))
+	{
+		invitationRoutes.GET("/", h.Invitation.GetPendingInvitations)
+		invitationRoutes.POST("/", h.Invitation.CreateInvitation)
+		invitationRoutes.POST("/:id/resend", h.Invitation.ResendInvitation)
+		invitationRoutes.DELETE("/:id", h.Invitation.RevokeInvitation)
+	}
+	// Invitations are accepted by people who have no account yet.
+	public.POST("/invitations/accept", h.Invitation.AcceptInvitation)
+
+	importRoutes := scoped.Group("/import", importScope)
+	{
+		importRoutes.POST("", h.Import.Import)
+		importRoutes.GET("/:id", h.Import.GetImportJob)
+		importRoutes.POST("/github", h.TrackerImport.ImportGitHub)
+		importRoutes.POST("/jira", h.TrackerImport.ImportJira)
+	}
+
+	public.POST("/webhooks/push", h.Webhook.Push)
+
+	scoped.POST("/graphql", h.GraphQL.Query)
+	public.GET("/graphql/schema", h.GraphQL.GetSchema)
+
+	exportRoutes := scoped.Group("/export", exportScope)
+	{
+		exportRoutes.GET("/tasks", h.Export.ExportTasks)
+		exportRoutes.GET("/projects", h.Export.ExportProjects)
+	}
+
+	// The admin routes are about the whole server, so they are for the
+	// admins of the default workspace.
+	adminRoutes := scoped.Group("/admin", auth.RequireAdminScope("workspaces"), workspace.RequireDefaultAdmin)
+	{
+		adminRoutes.GET("/jobs", h.Job.GetJobs)
+	}
+}
@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	pb "github.com/togzhanzhakhani/projects/pkg/pb/projects/v1"
+)
+
+type ProjectService struct {
+	pb.UnimplementedProjectServiceServer
+	ProjectRepo *repository.ProjectRepository
+}
+
+func NewProjectService(projectRepo *repository.ProjectRepository) *ProjectService {
+	return &ProjectService{ProjectRepo: projectRepo}
+}
+
+func (ps *ProjectService) ListProjects(ctx context.Context, req *pb.ListProjectsRequest) (*pb.ListProjectsResponse, error) {
+	projects, err := ps.ProjectRepo.WithContext(ctx).GetAllProjects()
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to retrieve projects"))
+	}
+	return &pb.ListProjectsResponse{Projects: projectMessages(projects)}, nil
+}
+
+func (ps *ProjectService) GetProject(ctx context.Context, req *pb.GetProjectRequest) (*pb.Project, error) {
+	project, err := ps.ProjectRepo.WithContext(ctx).GetProjectByID(uint(req.Id))
+	if err != nil {
+		return nil, statusError(ctx, problem.Lookup(err, "project"))
+	}
+	return projectMessage(*project), nil
+}
+
+func (ps *ProjectService) CreateProject(ctx context.Context, req *pb.CreateProjectRequest) (*pb.Project, error) {
+	return ps.saveProject(ctx, 0, req.Project)
+}
+
+func (ps *ProjectService) UpdateProject(ctx context.Context, req *pb.UpdateProjectRequest) (*pb.Project, error) {
+	return ps.saveProject(ctx, req.Id, req.Project)
+}
+
+// saveProject creates the project, or updates the project with the given ID
+// if it is not 0.
+func (ps *ProjectService) saveProject(ctx context.Context, id uint32, input *pb.ProjectInput) (*pb.Project, error) {
+	startDate, err := time.Parse(dates.DateLayout, input.GetStartDate())
+	if err != nil {
+		return nil, statusError(ctx, problem.BadRequest("Invalid start date format"))
+	}
+	endDate, err := time.Parse(dates.DateLayout, input.GetEndDate())
+	if err != nil {
+		return nil, statusError(ctx, problem.BadRequest("Invalid end date format"))
+	}
+
+	project := models.Project{
+		Name:        input.GetName(),
+		Description: input.GetDescription(),
+		StartDate:   startDate,
+		EndDate:     endDate,
+		ManagerID:   int(input.GetManagerId()),
+	}
+	if err := validate(ctx, &project); err != nil {
+		return nil, err
+	}
+
+	if id != 0 {
+		project.ID = int(id)
+		err = ps.ProjectRepo.WithContext(ctx).UpdateProject(&project)
+	} else {
+		err = ps.ProjectRepo.WithContext(ctx).CreateProject(&project)
+	}
+	if err != nil {
+		detail := "Failed to create project"
+		if id != 0 {
+			detail = "Failed to update project"
+		}
+		return nil, statusError(ctx, validation.MapError(translator(ctx), err, detail))
+	}
+	return projectMessage(project), nil
+}
+
+func (ps *ProjectService) DeleteProject(ctx context.Context, req *pb.DeleteProjectRequest) (*pb.DeleteProjectResponse, error) {
+	if _, err := ps.ProjectRepo.WithContext(ctx).GetProjectByID(uint(req.Id)); err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to retrieve project"))
+	}
+	if err := ps.ProjectRepo.WithContext(ctx).DeleteProject(uint(req.Id)); err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to delete project"))
+	}
+	return &pb.DeleteProjectResponse{}, nil
+}
+
+func (ps *ProjectService) ListProjectTasks(ctx context.Context, req *pb.ListProjectTasksRequest) (*pb.ListTasksResponse, error) {
+	tasks, err := ps.ProjectRepo.WithContext(ctx).GetTasksByProjectID(uint(req.Id))
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to retrieve tasks for project"))
+	}
+	return &pb.ListTasksResponse{Tasks: taskMessages(tasks)}, nil
+}
+
+func (ps *ProjectService) SearchProjects(ctx context.Context, req *pb.SearchProjectsRequest) (*pb.ListProjectsResponse, error) {
+	var projects []models.Project
+	var err error
+	switch {
+	case req.Title != "":
+		projects, err = ps.ProjectRepo.WithContext(ctx).SearchProjectsByTitle(req.Title)
+	case req.ManagerId != 0:
+		projects, err = ps.ProjectRepo.WithContext(ctx).SearchProjectsByManagerID(uint(req.ManagerId))
+	default:
+		return nil, statusError(ctx, problem.BadRequest("Missing query parameter"))
+	}
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to search projects"))
+	}
+	return &pb.ListProjectsResponse{Projects: projectMessages(projects)}, nil
+}
+
+func projectMessage(project models.Project) *pb.Project {
+	return &pb.Project{
+		Id:          uint32(project.ID),
+		Name:        project.Name,
+		Description: project.Description,
+		StartDate:   project.StartDate.Format(dates.DateLayout),
+		EndDate:     project.EndDate.Format(dates.DateLayout),
+		ManagerId:   uint32(project.ManagerID),
+	}
+}
+
+func projectMessages(projects []models.Project) []*pb.Project {
+	messages := make([]*pb.Project, len(projects))
+	for i, project := range projects {
+		messages[i] = projectMessage(project)
+	}
+	return messages
+}
@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// RateLimit returns the server options that limit the calls of each IP
+// address, as ratelimit.Limiter.Middleware does for REST requests. Calls
+// over the limit fail with ResourceExhausted and a retry-after header. The
+// options must come before those of Scope, so that calls are limited before
+// their credentials are checked.
+func RateLimit(limiter *ratelimit.Limiter) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := takeCall(ctx, limiter, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := takeCall(stream.Context(), limiter, info.FullMethod, stream.SetHeader); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+func takeCall(ctx context.Context, limiter *ratelimit.Limiter, method string, setHeader func(metadata.MD) error) error {
+	d, limited := limiter.TakeCall(ctx, peerIP(ctx), method)
+	if !limited || d.Allowed {
+		return nil
+	}
+	_ = setHeader(metadata.Pairs("retry-after", strconv.Itoa(d.RetryAfter())))
+	return statusError(ctx, problem.RateLimited(d.String()))
+}
+
+// peerIP returns the IP address the call comes from.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"gorm.io/gorm"
+)
+
+// Identify returns the user ID of the caller of a call, and false if the
+// call does not identify one.
+type Identify func(md metadata.MD) (uint, bool)
+
+// UserMetadata identifies the caller by the x-user-id metadata, on the same
+// terms as auth.Header.
+func UserMetadata(md metadata.MD) (uint, bool) {
+	id, err := strconv.ParseUint(first(md, strings.ToLower(auth.UserHeader)), 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// BearerMetadata identifies the caller by the bearer token of the
+// authorization metadata, on the same terms as auth.Bearer.
+func BearerMetadata(lookup func(token string) (uint, bool)) Identify {
+	return func(md metadata.MD) (uint, bool) {
+		scheme, token, found := strings.Cut(first(md, "authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return 0, false
+		}
+		return lookup(token)
+	}
+}
+
+// AnyMetadata identifies the caller by the first of identify that does.
+func AnyMetadata(identify ...Identify) Identify {
+	return func(md metadata.MD) (uint, bool) {
+		for _, id := range identify {
+			if userID, ok := id(md); ok {
+				return userID, true
+			}
+		}
+		return 0, false
+	}
+}
+
+// Scope returns the server options that scope every call to a workspace, as
+// auth.Middleware and workspace.Middleware do for REST requests. The
+// workspace is selected by the x-workspace-id metadata.
+func Scope(identify Identify, members workspace.Members) []grpc.ServerOption {
+	s := scope{identify: identify, members: members}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, err := s.resolve(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := s.resolve(stream.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, &scopedStream{ServerStream: stream, ctx: ctx})
+		}),
+	}
+}
+
+type scope struct {
+	identify Identify
+	members  workspace.Members
+}
+
+func (s scope) resolve(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	userID, ok := s.identify(md)
+	if !ok {
+		return nil, statusError(ctx, problem.Unauthorized("Authentication is required"))
+	}
+	workspaceID, ok := workspace.ParseID(first(md, strings.ToLower(workspace.Header)))
+	if !ok {
+		return nil, statusError(ctx, problem.BadRequest("Invalid "+strings.ToLower(workspace.Header)+" metadata"))
+	}
+	membership, err := s.members.GetMember(workspaceID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, statusError(ctx, problem.Lookup(err, "workspace"))
+	}
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to retrieve workspace"))
+	}
+	return workspace.NewContext(auth.NewContext(ctx, userID), *membership), nil
+}
+
+// scopedStream is a stream whose context is scoped to a workspace.
+type scopedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *scopedStream) Context() context.Context {
+	return s.ctx
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
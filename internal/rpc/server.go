@@ -0,0 +1,71 @@
+// Package rpc serves the gRPC API defined in api/proto. Its services mirror
+// the REST handlers: they use the same repositories and validation rules and
+// report the same problems, as gRPC statuses.
+package rpc
+
+import (
+	"context"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	pb "github.com/togzhanzhakhani/projects/pkg/pb/projects/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Services holds the implementations of the gRPC services.
+type Services struct {
+	User    *UserService
+	Project *ProjectService
+	Task    *TaskService
+}
+
+func Register(server *grpc.Server, s Services) {
+	pb.RegisterUserServiceServer(server, s.User)
+	pb.RegisterProjectServiceServer(server, s.Project)
+	pb.RegisterTaskServiceServer(server, s.Task)
+}
+
+// Routes maps each RPC to the REST route it mirrors, in the grpc-gateway
+// sense: the request message holds the path parameters and the body, and
+// the two return the same results and errors. WatchTasks has no REST
+// equivalent.
+//
+// DeleteTask is the exception: it deletes the task, as
+// handlers.TaskHandler.DeleteTask does, but REST routes task search on
+// DELETE /tasks/:id and does not route that handler, so the two differ.
+var Routes = map[string]string{
+	pb.UserService_ListUsers_FullMethodName:     "GET /users/",
+	pb.UserService_GetUser_FullMethodName:       "GET /users/:id",
+	pb.UserService_CreateUser_FullMethodName:    "POST /users/",
+	pb.UserService_UpdateUser_FullMethodName:    "PUT /users/:id",
+	pb.UserService_DeleteUser_FullMethodName:    "DELETE /users/:id",
+	pb.UserService_ListUserTasks_FullMethodName: "GET /users/:id/tasks",
+	pb.UserService_SearchUsers_FullMethodName:   "GET /users/search",
+
+	pb.ProjectService_ListProjects_FullMethodName:     "GET /projects/",
+	pb.ProjectService_GetProject_FullMethodName:       "GET /projects/:id",
+	pb.ProjectService_CreateProject_FullMethodName:    "POST /projects/",
+	pb.ProjectService_UpdateProject_FullMethodName:    "PUT /projects/:id",
+	pb.ProjectService_DeleteProject_FullMethodName:    "DELETE /projects/:id",
+	pb.ProjectService_ListProjectTasks_FullMethodName: "GET /projects/:id/tasks",
+	pb.ProjectService_SearchProjects_FullMethodName:   "GET /projects/search",
+
+	pb.TaskService_ListTasks_FullMethodName:        "GET /tasks/",
+	pb.TaskService_ListOverdueTasks_FullMethodName: "GET /tasks/overdue",
+	pb.TaskService_GetTask_FullMethodName:          "GET /tasks/:id",
+	pb.TaskService_CreateTask_FullMethodName:       "POST /tasks/",
+	pb.TaskService_UpdateTask_FullMethodName:       "PUT /tasks/:id",
+	pb.TaskService_DeleteTask_FullMethodName:       "DELETE /tasks/:id",
+}
+
+// translator negotiates the language of a call from its accept-language
+// metadata, as REST does from the Accept-Language header.
+func translator(ctx context.Context) ut.Translator {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var acceptLanguage string
+	if values := md.Get("accept-language"); len(values) > 0 {
+		acceptLanguage = values[0]
+	}
+	return i18n.Negotiate(acceptLanguage)
+}
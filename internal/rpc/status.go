@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorDomain is the domain of the ErrorInfo detail of every error status.
+const ErrorDomain = "projects"
+
+// problemCodes maps problem codes to the gRPC codes that grpc-gateway maps
+// back to the problem's HTTP status, so that both APIs report an error the
+// same way. A conflict is Aborted rather than FailedPrecondition, which the
+// gateway reports as 400.
+var problemCodes = map[string]codes.Code{
+	problem.CodeInvalidRequest:   codes.InvalidArgument,
+	problem.CodeValidationFailed: codes.InvalidArgument,
+	problem.CodeUnauthorized:     codes.Unauthenticated,
+	problem.CodeForbidden:        codes.PermissionDenied,
+	problem.CodeNotFound:         codes.NotFound,
+	problem.CodeConflict:         codes.Aborted,
+	problem.CodeRateLimited:      codes.ResourceExhausted,
+	problem.CodeInternal:         codes.Internal,
+	problem.CodeUnavailable:      codes.Unavailable,
+}
+
+// statusError converts p into the error status of a call, in the call's
+// language. The problem code is the reason of an ErrorInfo detail, and
+// invalid fields are the field violations of a BadRequest detail.
+func statusError(ctx context.Context, p *problem.Problem) error {
+	p.Localize(translator(ctx))
+	if p.Status >= http.StatusInternalServerError {
+		method, _ := grpc.Method(ctx)
+		log.Printf("%s: %v", method, p)
+	}
+
+	code, ok := problemCodes[p.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: p.Code, Domain: ErrorDomain}}
+	if len(p.Errors) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(p.Errors))
+		for i, fieldError := range p.Errors {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: fieldError.Field, Description: fieldError.Message}
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	st, err := status.New(code, p.Detail).WithDetails(details...)
+	if err != nil {
+		return status.Error(code, p.Detail)
+	}
+	return st.Err()
+}
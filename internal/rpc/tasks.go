@@ -0,0 +1,245 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/changes"
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	pb "github.com/togzhanzhakhani/projects/pkg/pb/projects/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// watchBuffer is the number of changes a WatchTasks stream may fall behind
+// before it is ended.
+const watchBuffer = 256
+
+type TaskService struct {
+	pb.UnimplementedTaskServiceServer
+	TaskRepo repository.TaskRepository
+	Changes  *changes.Broker
+}
+
+func NewTaskService(taskRepo repository.TaskRepository, broker *changes.Broker) *TaskService {
+	return &TaskService{TaskRepo: taskRepo, Changes: broker}
+}
+
+func (ts *TaskService) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	tasks, err := ts.TaskRepo.WithContext(ctx).GetAllTasks()
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to fetch tasks"))
+	}
+	return &pb.ListTasksResponse{Tasks: taskMessages(tasks)}, nil
+}
+
+func (ts *TaskService) ListOverdueTasks(ctx context.Context, req *pb.ListOverdueTasksRequest) (*pb.ListTasksResponse, error) {
+	tasks, err := ts.TaskRepo.WithContext(ctx).GetOverdueTasks(time.Now())
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to fetch overdue tasks"))
+	}
+	return &pb.ListTasksResponse{Tasks: taskMessages(tasks)}, nil
+}
+
+func (ts *TaskService) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Task, error) {
+	task, err := ts.TaskRepo.WithContext(ctx).GetTaskByID(uint(req.Id))
+	if err != nil {
+		return nil, statusError(ctx, problem.Lookup(err, "task"))
+	}
+	return taskMessage(*task), nil
+}
+
+func (ts *TaskService) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.Task, error) {
+	return ts.saveTask(ctx, 0, req.Task)
+}
+
+func (ts *TaskService) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.Task, error) {
+	return ts.saveTask(ctx, req.Id, req.Task)
+}
+
+// saveTask creates the task, or updates the task with the given ID if it is
+// not 0.
+func (ts *TaskService) saveTask(ctx context.Context, id uint32, input *pb.TaskInput) (*pb.Task, error) {
+	createdAt, err := time.Parse(dates.DateLayout, input.GetCreatedAt())
+	if err != nil {
+		return nil, statusError(ctx, problem.BadRequest("Invalid created_at date format"))
+	}
+	completedAt, err := time.Parse(dates.DateLayout, input.GetCompletedAt())
+	if err != nil {
+		return nil, statusError(ctx, problem.BadRequest("Invalid end date format"))
+	}
+	var dueDate *time.Time
+	if input.GetDueDate() != "" {
+		dueDate, err = dates.ParseDue(input.GetDueDate(), input.GetDueTimezone())
+		if err != nil {
+			return nil, statusError(ctx, problem.BadRequest("Invalid due_date format"))
+		}
+	}
+
+	task := models.Task{
+		ID:          int(id),
+		Title:       input.GetTitle(),
+		Description: input.GetDescription(),
+		Priority:    input.GetPriority(),
+		Status:      input.GetStatus(),
+		AssigneeID:  int(input.GetAssigneeId()),
+		ProjectID:   int(input.GetProjectId()),
+		CreatedAt:   createdAt,
+		CompletedAt: completedAt,
+		DueDate:     dueDate,
+		DueTimezone: input.GetDueTimezone(),
+	}
+	if err := validate(ctx, &task); err != nil {
+		return nil, err
+	}
+
+	if task.DueDate != nil {
+		project, err := ts.TaskRepo.WithContext(ctx).GetProject(task.ProjectID)
+		if err != nil {
+			return nil, statusError(ctx, problem.FromError(err, "Failed to fetch project"))
+		}
+		// The project end date is a calendar day, so anything due on that day is still in time.
+		if !task.DueDate.Before(project.EndDate.AddDate(0, 0, 1)) {
+			return nil, statusError(ctx, problem.BadRequest("Due date must not be after the project end date"))
+		}
+	}
+
+	if id != 0 {
+		err = ts.TaskRepo.WithContext(ctx).UpdateTask(&task)
+	} else {
+		err = ts.TaskRepo.WithContext(ctx).CreateTask(&task)
+	}
+	if err != nil {
+		detail := "Failed to create task"
+		if id != 0 {
+			detail = "Failed to update task"
+		}
+		return nil, statusError(ctx, validation.MapError(translator(ctx), err, detail))
+	}
+	return taskMessage(task), nil
+}
+
+func (ts *TaskService) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
+	task, err := ts.TaskRepo.WithContext(ctx).GetTaskByID(uint(req.Id))
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to fetch task"))
+	}
+	if task == nil {
+		return nil, statusError(ctx, problem.NotFound("Task not found"))
+	}
+	if err := ts.TaskRepo.WithContext(ctx).DeleteTask(uint(req.Id)); err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to delete task"))
+	}
+	return &pb.DeleteTaskResponse{}, nil
+}
+
+// WatchTasks sends an event for each change of a task matching the request.
+// Created and updated tasks are loaded when the event is sent, so a task
+// that changes again in between is sent as it is by then. The response
+// headers are sent once the stream is subscribed to the feed.
+func (ts *TaskService) WatchTasks(req *pb.WatchTasksRequest, stream grpc.ServerStreamingServer[pb.TaskEvent]) error {
+	ctx := stream.Context()
+	feed, stop := ts.Changes.Subscribe(watchBuffer)
+	defer stop()
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-feed:
+			if !ok {
+				return statusError(ctx, problem.New(http.StatusServiceUnavailable, problem.CodeUnavailable, "The change feed fell behind; watch again to resume"))
+			}
+			if !watches(ctx, req, change) {
+				continue
+			}
+			event, err := ts.taskEvent(ctx, change)
+			if err != nil {
+				return statusError(ctx, problem.FromError(err, "Failed to fetch task"))
+			}
+			if event == nil {
+				continue
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watches reports whether change is of a task in the call's workspace that
+// matches the request.
+func watches(ctx context.Context, req *pb.WatchTasksRequest, change changes.TaskChange) bool {
+	if workspaceID, ok := workspace.ID(ctx); ok && workspaceID != change.WorkspaceID {
+		return false
+	}
+	return (req.ProjectId == 0 || int(req.ProjectId) == change.ProjectID) &&
+		(req.AssigneeId == 0 || int(req.AssigneeId) == change.AssigneeID)
+}
+
+// taskEvent returns the event of change, or nil if the task was deleted
+// before it could be loaded; its deletion is an event of its own.
+func (ts *TaskService) taskEvent(ctx context.Context, change changes.TaskChange) (*pb.TaskEvent, error) {
+	event := &pb.TaskEvent{TaskId: uint32(change.ID)}
+	switch change.Op {
+	case changes.Inserted:
+		event.Type = pb.TaskEvent_TYPE_CREATED
+	case changes.Updated:
+		event.Type = pb.TaskEvent_TYPE_UPDATED
+	case changes.Deleted:
+		event.Type = pb.TaskEvent_TYPE_DELETED
+		return event, nil
+	default:
+		return nil, nil
+	}
+
+	task, err := ts.TaskRepo.WithContext(ctx).GetTaskByID(uint(change.ID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	event.Task = taskMessage(*task)
+	return event, nil
+}
+
+func taskMessage(task models.Task) *pb.Task {
+	message := &pb.Task{
+		Id:          uint32(task.ID),
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		Status:      task.Status,
+		AssigneeId:  uint32(task.AssigneeID),
+		ProjectId:   uint32(task.ProjectID),
+		CreatedAt:   timestamppb.New(task.CreatedAt),
+		CompletedAt: timestamppb.New(task.CompletedAt),
+		DueTimezone: task.DueTimezone,
+		Labels:      task.Labels,
+	}
+	if task.DueDate != nil {
+		message.DueDate = timestamppb.New(*task.DueDate)
+	}
+	return message
+}
+
+func taskMessages(tasks []models.Task) []*pb.Task {
+	messages := make([]*pb.Task, len(tasks))
+	for i, task := range tasks {
+		messages[i] = taskMessage(task)
+	}
+	return messages
+}
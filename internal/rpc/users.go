@@ -0,0 +1,167 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/togzhanzhakhani/projects/internal/dates"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	pb "github.com/togzhanzhakhani/projects/pkg/pb/projects/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type UserService struct {
+	pb.UnimplementedUserServiceServer
+	UserRepo repository.UserRepository
+}
+
+func NewUserService(userRepo repository.UserRepository) *UserService {
+	return &UserService{UserRepo: userRepo}
+}
+
+func (us *UserService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	users, err := us.UserRepo.WithContext(ctx).GetAllUsers()
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to retrieve users"))
+	}
+	return &pb.ListUsersResponse{Users: userMessages(users)}, nil
+}
+
+func (us *UserService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	user, err := us.UserRepo.WithContext(ctx).GetUserByID(uint(req.Id))
+	if err != nil {
+		return nil, statusError(ctx, problem.Lookup(err, "user"))
+	}
+	return userMessage(*user), nil
+}
+
+func (us *UserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	if !workspace.Administers(ctx) {
+		return nil, statusError(ctx, problem.Forbidden("Only workspace admins may create users; invite them instead"))
+	}
+	user := userFromInput(req.User)
+	if err := validate(ctx, &user); err != nil {
+		return nil, err
+	}
+	if err := us.UserRepo.WithContext(ctx).CreateUser(&user); err != nil {
+		return nil, statusError(ctx, validation.MapError(translator(ctx), err, "Failed to create user"))
+	}
+	return userMessage(user), nil
+}
+
+func (us *UserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	existingUser, err := us.UserRepo.WithContext(ctx).GetUserByID(uint(req.Id))
+	if err != nil {
+		return nil, statusError(ctx, problem.Lookup(err, "user"))
+	}
+
+	user := userFromInput(req.User)
+	user.ID = uint(req.Id)
+	user.RegistrationDate = existingUser.RegistrationDate
+	if err := validate(ctx, &user); err != nil {
+		return nil, err
+	}
+	if p := workspace.AuthorizeRoleChange(ctx, user.ID, existingUser.Role, user.Role); p != nil {
+		return nil, statusError(ctx, p)
+	}
+	if p := workspace.AuthorizeEmailChange(existingUser.Email, user.Email); p != nil {
+		return nil, statusError(ctx, p)
+	}
+	if err := us.UserRepo.WithContext(ctx).UpdateUser(&user); err != nil {
+		return nil, statusError(ctx, validation.MapError(translator(ctx), err, "Failed to update user"))
+	}
+	return userMessage(user), nil
+}
+
+func (us *UserService) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	if !workspace.Administers(ctx) {
+		return nil, statusError(ctx, problem.Forbidden("Only workspace admins may delete users"))
+	}
+	if _, err := us.UserRepo.WithContext(ctx).GetUserByID(uint(req.Id)); err != nil {
+		return nil, statusError(ctx, problem.Lookup(err, "user"))
+	}
+	if err := us.UserRepo.WithContext(ctx).DeleteUser(uint(req.Id)); err != nil {
+		if errors.Is(err, repository.ErrLastAdmin) {
+			return nil, statusError(ctx, problem.Conflict("A workspace needs at least one admin"))
+		}
+		return nil, statusError(ctx, problem.FromError(err, "Failed to delete user"))
+	}
+	return &pb.DeleteUserResponse{}, nil
+}
+
+func (us *UserService) ListUserTasks(ctx context.Context, req *pb.ListUserTasksRequest) (*pb.ListTasksResponse, error) {
+	var tasks []models.Task
+	var err error
+	if req.DueBefore != "" {
+		before, parseErr := dates.ParseBefore(req.DueBefore)
+		if parseErr != nil {
+			return nil, statusError(ctx, problem.BadRequest("Invalid due_before format"))
+		}
+		tasks, err = us.UserRepo.WithContext(ctx).GetTasksByUserIDDueBefore(uint(req.Id), before)
+	} else {
+		tasks, err = us.UserRepo.WithContext(ctx).GetTasksByUserID(uint(req.Id))
+	}
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to retrieve tasks"))
+	}
+	return &pb.ListTasksResponse{Tasks: taskMessages(tasks)}, nil
+}
+
+func (us *UserService) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.ListUsersResponse, error) {
+	var users []models.User
+	var err error
+	switch {
+	case req.Name != "":
+		users, err = us.UserRepo.WithContext(ctx).FindByName(req.Name)
+	case req.Email != "":
+		users, err = us.UserRepo.WithContext(ctx).FindByEmailLike(req.Email)
+	default:
+		return nil, statusError(ctx, problem.BadRequest("Query parameter 'name' or 'email' is required"))
+	}
+	if err != nil {
+		return nil, statusError(ctx, problem.FromError(err, "Failed to search users"))
+	}
+	return &pb.ListUsersResponse{Users: userMessages(users)}, nil
+}
+
+func userFromInput(input *pb.UserInput) models.User {
+	return models.User{
+		Name:  input.GetName(),
+		Email: input.GetEmail(),
+		Role:  input.GetRole(),
+	}
+}
+
+func userMessage(user models.User) *pb.User {
+	return &pb.User{
+		Id:               uint32(user.ID),
+		Name:             user.Name,
+		Email:            user.Email,
+		RegistrationDate: timestamppb.New(user.RegistrationDate),
+		Role:             user.Role,
+	}
+}
+
+func userMessages(users []models.User) []*pb.User {
+	messages := make([]*pb.User, len(users))
+	for i, user := range users {
+		messages[i] = userMessage(user)
+	}
+	return messages
+}
+
+// validate runs the model's validation rules, as the REST handlers do.
+func validate(ctx context.Context, obj interface{}) error {
+	fieldErrors, err := validation.ValidateFields(ctx, translator(ctx), obj)
+	if err != nil {
+		return statusError(ctx, problem.FromError(err, "Failed to validate request"))
+	}
+	if len(fieldErrors) > 0 {
+		return statusError(ctx, problem.Validation(fieldErrors))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type githubIssue struct {
+	Number        int        `json:"number"`
+	Title         string     `json:"title"`
+	Body          string     `json:"body"`
+	State         string     `json:"state"`
+	StateReason   string     `json:"state_reason"`
+	HTMLURL       string     `json:"html_url"`
+	RepositoryURL string     `json:"repository_url"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ClosedAt      *time.Time `json:"closed_at"`
+	Labels        []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignee  *githubUser  `json:"assignee"`
+	Assignees []githubUser `json:"assignees"`
+	Milestone *struct {
+		DueOn *time.Time `json:"due_on"`
+	} `json:"milestone"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+}
+
+// ParseGitHub reads a JSON array of issues as returned by the GitHub REST API
+// (GET /repos/{owner}/{repo}/issues). Pull requests, which that endpoint also
+// returns, are skipped and counted.
+func ParseGitHub(data []byte) (issues []Issue, pullRequests int, err error) {
+	var raw []githubIssue
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, fmt.Errorf("expected a JSON array of GitHub issues: %v", err)
+	}
+
+	for _, gh := range raw {
+		if len(gh.PullRequest) > 0 && string(gh.PullRequest) != "null" {
+			pullRequests++
+			continue
+		}
+
+		repo := githubRepo(gh.RepositoryURL, gh.HTMLURL)
+		issue := Issue{
+			ExternalID:  fmt.Sprintf("%s#%d", repo, gh.Number),
+			Title:       gh.Title,
+			Description: gh.Body,
+			State:       gh.State,
+			CreatedAt:   gh.CreatedAt,
+			ClosedAt:    gh.ClosedAt,
+			ProjectKey:  repo,
+			ProjectName: repo,
+			URL:         gh.HTMLURL,
+		}
+		if gh.State == "closed" && gh.StateReason != "" {
+			issue.State = "closed:" + gh.StateReason
+		}
+		for _, label := range gh.Labels {
+			if priority, ok := githubPriorityLabel(label.Name); ok {
+				issue.Priority = priority
+				continue
+			}
+			issue.Labels = append(issue.Labels, label.Name)
+		}
+		assignee := gh.Assignee
+		if assignee == nil && len(gh.Assignees) > 0 {
+			assignee = &gh.Assignees[0]
+		}
+		if assignee != nil {
+			issue.AssigneeLogin = assignee.Login
+			issue.AssigneeEmail = assignee.Email
+		}
+		if gh.Milestone != nil {
+			issue.DueDate = gh.Milestone.DueOn
+		}
+		issues = append(issues, issue)
+	}
+	return issues, pullRequests, nil
+}
+
+// githubRepo extracts "owner/repo" from an issue's API or HTML URL.
+func githubRepo(urls ...string) string {
+	for _, u := range urls {
+		for _, marker := range []string{"/repos/", "github.com/"} {
+			i := strings.Index(u, marker)
+			if i < 0 {
+				continue
+			}
+			parts := strings.Split(u[i+len(marker):], "/")
+			if len(parts) >= 2 && parts[0] != "" && parts[1] != "" {
+				return parts[0] + "/" + parts[1]
+			}
+		}
+	}
+	return "github"
+}
+
+// githubPriorityLabel recognises priority labels such as "priority: high",
+// "priority/low" or "P1". GitHub has no priority field, so teams use labels.
+func githubPriorityLabel(label string) (string, bool) {
+	l := strings.ToLower(strings.TrimSpace(label))
+	for _, prefix := range []string{"priority:", "priority/", "priority-", "prio:"} {
+		if strings.HasPrefix(l, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(l, prefix)), true
+		}
+	}
+	if len(l) == 2 && l[0] == 'p' && l[1] >= '0' && l[1] <= '9' {
+		return l, true
+	}
+	return "", false
+}
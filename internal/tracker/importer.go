@@ -0,0 +1,328 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+// maxDescription mirrors the max=100 rule on Task.Description and
+// Project.Description.
+const maxDescription = 100
+
+var statuses = map[string]string{
+	"open":                     "todo",
+	"to do":                    "todo",
+	"todo":                     "todo",
+	"backlog":                  "todo",
+	"selected for development": "todo",
+	"reopened":                 "todo",
+	"in progress":              "in_progress",
+	"in review":                "in_progress",
+	"review":                   "in_progress",
+	"closed":                   "done",
+	"closed:completed":         "done",
+	"closed:not_planned":       "done",
+	"done":                     "done",
+	"resolved":                 "done",
+}
+
+var priorities = map[string]string{
+	"highest":  "high",
+	"high":     "high",
+	"blocker":  "high",
+	"critical": "high",
+	"p0":       "high",
+	"p1":       "high",
+	"medium":   "medium",
+	"major":    "medium",
+	"normal":   "medium",
+	"p2":       "medium",
+	"low":      "low",
+	"lowest":   "low",
+	"minor":    "low",
+	"trivial":  "low",
+	"p3":       "low",
+	"p4":       "low",
+}
+
+// Options controls how issues are mapped.
+type Options struct {
+	// ProjectID imports every issue into an existing project. When zero, a
+	// project is created per GitHub repository or Jira project and reused on
+	// later imports.
+	ProjectID int
+	// ManagerID is the manager of projects created by the import.
+	ManagerID int
+	// DefaultAssigneeID is used for issues whose assignee cannot be matched to
+	// a user. When zero, such issues are skipped.
+	DefaultAssigneeID int
+	// UserMap maps tracker logins to user emails, for trackers that do not
+	// export emails.
+	UserMap map[string]string
+}
+
+// IssueError describes an issue that was not imported.
+type IssueError struct {
+	ExternalID string `json:"external_id"`
+	Error      string `json:"error"`
+}
+
+// Report summarises an import. Unmapped counts the values that had no
+// equivalent, per field ("states", "priorities", "assignees"); such values
+// fall back to todo, medium and DefaultAssigneeID respectively.
+type Report struct {
+	Source                string                    `json:"source"`
+	TotalIssues           int                       `json:"total_issues"`
+	Created               int                       `json:"created"`
+	Updated               int                       `json:"updated"`
+	Skipped               int                       `json:"skipped"`
+	SkippedPullRequests   int                       `json:"skipped_pull_requests,omitempty"`
+	ProjectsCreated       int                       `json:"projects_created"`
+	TruncatedDescriptions int                       `json:"truncated_descriptions"`
+	Unmapped              map[string]map[string]int `json:"unmapped"`
+	Errors                []IssueError              `json:"errors"`
+}
+
+func (r *Report) unmapped(field, value string) {
+	if r.Unmapped[field] == nil {
+		r.Unmapped[field] = make(map[string]int)
+	}
+	r.Unmapped[field][value]++
+}
+
+func (r *Report) skip(issue Issue, format string, args ...interface{}) {
+	r.Skipped++
+	r.Errors = append(r.Errors, IssueError{ExternalID: issue.ExternalID, Error: fmt.Sprintf(format, args...)})
+}
+
+// Importer writes mapped issues through the repository.
+type Importer struct {
+	Repo repository.TrackerRepository
+	Now  func() time.Time
+}
+
+func NewImporter(repo repository.TrackerRepository) *Importer {
+	return &Importer{Repo: repo, Now: time.Now}
+}
+
+// Import maps and upserts issues into the workspace of ctx. Per-issue
+// problems are recorded in the report; an error is returned only when the
+// import cannot proceed.
+func (im *Importer) Import(ctx context.Context, source string, issues []Issue, opts Options) (*Report, error) {
+	repo := im.Repo.WithContext(ctx)
+	report := &Report{
+		Source:      source,
+		TotalIssues: len(issues),
+		Unmapped:    make(map[string]map[string]int),
+		Errors:      []IssueError{},
+	}
+
+	users, err := repo.UserIDsByEmail(assigneeEmails(issues, opts.UserMap))
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[string]int)
+	if opts.ProjectID != 0 {
+		if _, err := repo.GetProject(opts.ProjectID); err != nil {
+			return nil, fmt.Errorf("project %d not found", opts.ProjectID)
+		}
+	} else if opts.ManagerID == 0 {
+		return nil, fmt.Errorf("either project_id or manager_id is required")
+	}
+
+	for _, issue := range issues {
+		if issue.ExternalID == "" || strings.TrimSpace(issue.Title) == "" {
+			report.skip(issue, "issue has no key or title")
+			continue
+		}
+
+		task := models.Task{
+			Title:       strings.TrimSpace(issue.Title),
+			Description: issue.Description,
+			Status:      "todo",
+			Priority:    "medium",
+			CreatedAt:   issue.CreatedAt,
+			DueDate:     issue.DueDate,
+			Labels:      issue.Labels,
+		}
+		if task.CreatedAt.IsZero() {
+			task.CreatedAt = im.Now()
+		}
+		if task.Description == "" {
+			task.Description = issue.Title
+		}
+		if truncated, ok := truncate(task.Description, maxDescription); ok {
+			task.Description = truncated
+			report.TruncatedDescriptions++
+		}
+
+		if status, ok := statuses[strings.ToLower(issue.State)]; ok {
+			task.Status = status
+		} else if issue.State != "" {
+			report.unmapped("states", issue.State)
+		}
+		if task.Status == "todo" && hasLabel(issue.Labels, "in progress", "in-progress", "wip") {
+			task.Status = "in_progress"
+		}
+		if task.Status == "done" {
+			task.CompletedAt = task.CreatedAt
+			if issue.ClosedAt != nil && issue.ClosedAt.After(task.CreatedAt) {
+				task.CompletedAt = *issue.ClosedAt
+			}
+		}
+
+		if priority, ok := priorities[strings.ToLower(issue.Priority)]; ok {
+			task.Priority = priority
+		} else if issue.Priority != "" {
+			report.unmapped("priorities", issue.Priority)
+		}
+
+		if email := assigneeEmail(issue, opts.UserMap); email != "" {
+			task.AssigneeID = users[email]
+		}
+		if task.AssigneeID == 0 {
+			if issue.AssigneeLogin != "" || issue.AssigneeEmail != "" {
+				report.unmapped("assignees", firstNonEmpty(issue.AssigneeEmail, issue.AssigneeLogin))
+			}
+			task.AssigneeID = opts.DefaultAssigneeID
+		}
+		if task.AssigneeID == 0 {
+			report.skip(issue, "assignee could not be matched to a user and no default_assignee_id was given")
+			continue
+		}
+
+		task.ProjectID = opts.ProjectID
+		if task.ProjectID == 0 {
+			if task.ProjectID, err = im.project(repo, source, issue, issues, opts, projects, report); err != nil {
+				return report, err
+			}
+		}
+
+		created, err := repo.UpsertTask(source, issue.ExternalID, &task)
+		if err != nil {
+			report.skip(issue, "failed to save task: %v", err)
+			continue
+		}
+		if created {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+	return report, nil
+}
+
+// project returns the ID of the project for the issue's repository or Jira
+// project, creating it on first use. A new project spans the dates of all of
+// its issues.
+func (im *Importer) project(repo repository.TrackerRepository, source string, issue Issue, issues []Issue, opts Options, cache map[string]int, report *Report) (int, error) {
+	key := issue.ProjectKey
+	if id, ok := cache[key]; ok {
+		return id, nil
+	}
+
+	start, end := time.Time{}, time.Time{}
+	for _, other := range issues {
+		if other.ProjectKey != key {
+			continue
+		}
+		for _, t := range []*time.Time{&other.CreatedAt, other.ClosedAt, other.DueDate} {
+			if t == nil || t.IsZero() {
+				continue
+			}
+			if start.IsZero() || t.Before(start) {
+				start = *t
+			}
+			if t.After(end) {
+				end = *t
+			}
+		}
+	}
+	if start.IsZero() {
+		start = im.Now()
+	}
+	if !end.After(start) {
+		end = start.AddDate(0, 0, 1)
+	}
+
+	name := firstNonEmpty(issue.ProjectName, key)
+	description, _ := truncate(fmt.Sprintf("Imported from %s %s", source, key), maxDescription)
+	project := models.Project{
+		Name:        name,
+		Description: description,
+		StartDate:   start,
+		EndDate:     end,
+		ManagerID:   opts.ManagerID,
+	}
+	created, err := repo.UpsertProject(source, key, &project)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save project %s: %v", key, err)
+	}
+	if created {
+		report.ProjectsCreated++
+	}
+	cache[key] = project.ID
+	return project.ID, nil
+}
+
+func assigneeEmail(issue Issue, userMap map[string]string) string {
+	if issue.AssigneeEmail != "" {
+		return strings.ToLower(issue.AssigneeEmail)
+	}
+	if email, ok := userMap[issue.AssigneeLogin]; ok {
+		return strings.ToLower(email)
+	}
+	if strings.Contains(issue.AssigneeLogin, "@") {
+		return strings.ToLower(issue.AssigneeLogin)
+	}
+	return ""
+}
+
+func assigneeEmails(issues []Issue, userMap map[string]string) []string {
+	seen := make(map[string]bool)
+	var emails []string
+	for _, issue := range issues {
+		if email := assigneeEmail(issue, userMap); email != "" && !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	sort.Strings(emails)
+	return emails
+}
+
+func hasLabel(labels []string, names ...string) bool {
+	for _, label := range labels {
+		for _, name := range names {
+			if strings.EqualFold(label, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func truncate(value string, max int) (string, bool) {
+	if utf8.RuneCountInString(value) <= max {
+		return value, false
+	}
+	runes := []rune(value)
+	return string(runes[:max-1]) + "…", true
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
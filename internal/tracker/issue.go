@@ -0,0 +1,30 @@
+// Package tracker reads issues exported from GitHub and Jira and maps them
+// onto tasks and projects.
+package tracker
+
+import "time"
+
+const (
+	SourceGitHub = "github"
+	SourceJira   = "jira"
+)
+
+// Issue is an issue from an external tracker, before mapping.
+type Issue struct {
+	// ExternalID identifies the issue in its tracker, e.g. "owner/repo#12" or
+	// "PROJ-12". Together with the source it makes re-imports idempotent.
+	ExternalID    string
+	Title         string
+	Description   string
+	State         string
+	Priority      string
+	Labels        []string
+	AssigneeLogin string
+	AssigneeEmail string
+	CreatedAt     time.Time
+	ClosedAt      *time.Time
+	DueDate       *time.Time
+	ProjectKey    string
+	ProjectName   string
+	URL           string
+}
@@ -0,0 +1,185 @@
+package tracker
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// jiraDateLayouts are the date formats Jira uses in its CSV and XML exports,
+// depending on the instance's locale settings.
+var jiraDateLayouts = []string{
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	time.RFC1123,
+	"02/Jan/06 3:04 PM",
+	"02/Jan/06 15:04",
+	"02/Jan/06",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05.000-0700",
+	time.RFC3339,
+	"2006-01-02",
+}
+
+func parseJiraDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range jiraDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognised date %q", value)
+}
+
+// ParseJiraCSV reads a Jira "Export CSV (all fields)" file. Jira repeats the
+// header for multi-valued fields such as Labels, so all columns with the same
+// name are collected.
+func ParseJiraCSV(r io.Reader) ([]Issue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	columns := make(map[string][]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = append(columns[name], i)
+	}
+	if len(columns["issue key"]) == 0 || len(columns["summary"]) == 0 {
+		return nil, fmt.Errorf("CSV export must contain \"Issue key\" and \"Summary\" columns")
+	}
+
+	var issues []Issue
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		get := func(name string) string {
+			for _, i := range columns[name] {
+				if i < len(record) && strings.TrimSpace(record[i]) != "" {
+					return strings.TrimSpace(record[i])
+				}
+			}
+			return ""
+		}
+
+		issue := Issue{
+			ExternalID:    get("issue key"),
+			Title:         get("summary"),
+			Description:   get("description"),
+			State:         get("status"),
+			Priority:      get("priority"),
+			AssigneeLogin: get("assignee"),
+			ProjectKey:    get("project key"),
+			ProjectName:   get("project name"),
+		}
+		for _, i := range columns["labels"] {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				issue.Labels = append(issue.Labels, strings.TrimSpace(record[i]))
+			}
+		}
+		if issue.ProjectKey == "" {
+			issue.ProjectKey = jiraProjectKey(issue.ExternalID)
+		}
+		if err := setJiraDates(&issue, get("created"), get("resolved"), get("due date")); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+type jiraRSS struct {
+	Items []struct {
+		Key         string `xml:"key"`
+		Summary     string `xml:"summary"`
+		Description string `xml:"description"`
+		Link        string `xml:"link"`
+		Status      string `xml:"status"`
+		Priority    string `xml:"priority"`
+		Assignee    struct {
+			Username string `xml:"username,attr"`
+			Name     string `xml:",chardata"`
+		} `xml:"assignee"`
+		Project struct {
+			Key  string `xml:"key,attr"`
+			Name string `xml:",chardata"`
+		} `xml:"project"`
+		Labels   []string `xml:"labels>label"`
+		Created  string   `xml:"created"`
+		Resolved string   `xml:"resolved"`
+		Due      string   `xml:"due"`
+	} `xml:"channel>item"`
+}
+
+// ParseJiraXML reads a Jira "Export XML" (RSS) file.
+func ParseJiraXML(r io.Reader) ([]Issue, error) {
+	var rss jiraRSS
+	if err := xml.NewDecoder(r).Decode(&rss); err != nil {
+		return nil, fmt.Errorf("failed to parse Jira XML: %v", err)
+	}
+	issues := make([]Issue, 0, len(rss.Items))
+	for _, item := range rss.Items {
+		issue := Issue{
+			ExternalID:    strings.TrimSpace(item.Key),
+			Title:         strings.TrimSpace(item.Summary),
+			Description:   strings.TrimSpace(item.Description),
+			State:         strings.TrimSpace(item.Status),
+			Priority:      strings.TrimSpace(item.Priority),
+			AssigneeLogin: strings.TrimSpace(item.Assignee.Username),
+			ProjectKey:    strings.TrimSpace(item.Project.Key),
+			ProjectName:   strings.TrimSpace(item.Project.Name),
+			URL:           strings.TrimSpace(item.Link),
+			Labels:        item.Labels,
+		}
+		if issue.AssigneeLogin == "" || issue.AssigneeLogin == "-1" {
+			issue.AssigneeLogin = strings.TrimSpace(item.Assignee.Name)
+		}
+		if issue.AssigneeLogin == "Unassigned" {
+			issue.AssigneeLogin = ""
+		}
+		if issue.ProjectKey == "" {
+			issue.ProjectKey = jiraProjectKey(issue.ExternalID)
+		}
+		if err := setJiraDates(&issue, item.Created, item.Resolved, item.Due); err != nil {
+			return nil, fmt.Errorf("%s: %v", issue.ExternalID, err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func setJiraDates(issue *Issue, created, resolved, due string) error {
+	createdAt, err := parseJiraDate(created)
+	if err != nil {
+		return err
+	}
+	if createdAt != nil {
+		issue.CreatedAt = *createdAt
+	}
+	if issue.ClosedAt, err = parseJiraDate(resolved); err != nil {
+		return err
+	}
+	issue.DueDate, err = parseJiraDate(due)
+	return err
+}
+
+// jiraProjectKey derives "PROJ" from an issue key such as "PROJ-12".
+func jiraProjectKey(issueKey string) string {
+	if i := strings.LastIndex(issueKey, "-"); i > 0 {
+		return issueKey[:i]
+	}
+	return issueKey
+}
@@ -0,0 +1,63 @@
+package validation
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+// constraint is a database constraint backing a rule, with the field and
+// message the rule reports.
+type constraint struct {
+	table string
+	field string
+	key   string
+}
+
+// constraints are created by pkg/database.
+var constraints = map[string]constraint{
+	"idx_users_email":             {"users", "email", "Email.unique_email"},
+	"fk_projects_manager":         {"projects", "manager_id", "ManagerID.user_exists"},
+	"fk_tasks_assignee":           {"tasks", "assignee_id", "AssigneeID.user_exists"},
+	"fk_tasks_project":            {"tasks", "project_id", "ProjectID.project_exists"},
+	"fk_recurring_tasks_assignee": {"recurring_tasks", "assignee_id", "AssigneeID.user_exists"},
+	"fk_recurring_tasks_project":  {"recurring_tasks", "project_id", "ProjectID.project_exists"},
+
+	"fk_projects_manager_member":           {"projects", "manager_id", "ManagerID.user_exists"},
+	"fk_tasks_assignee_member":             {"tasks", "assignee_id", "AssigneeID.user_exists"},
+	"fk_tasks_project_workspace":           {"tasks", "project_id", "ProjectID.project_exists"},
+	"fk_recurring_tasks_assignee_member":   {"recurring_tasks", "assignee_id", "AssigneeID.user_exists"},
+	"fk_recurring_tasks_project_workspace": {"recurring_tasks", "project_id", "ProjectID.project_exists"},
+
+	"fk_tasks_assignee_project":           {"tasks", "assignee_id", "AssigneeID.project_member"},
+	"fk_recurring_tasks_assignee_project": {"recurring_tasks", "assignee_id", "AssigneeID.project_member"},
+
+	"fk_invitations_project": {"invitations", "project_id", "ProjectID.project_exists"},
+}
+
+// FromError maps the error of writing a validated model. A violation of a
+// constraint backing a rule, which happens when a concurrent request changed
+// the data after the rule passed, is reported as that rule's validation
+// error. Anything else is mapped by problem.FromError.
+func FromError(c *gin.Context, err error, detail string) *problem.Problem {
+	return MapError(i18n.Translator(c), err, detail)
+}
+
+// MapError is FromError with the field error messages in the language of
+// trans.
+func MapError(trans ut.Translator, err error, detail string) *problem.Problem {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if con, ok := constraints[pgErr.ConstraintName]; ok && pgErr.TableName == con.table {
+			return problem.Validation([]problem.FieldError{{
+				Field:   con.field,
+				Message: i18n.T(trans, con.key),
+			}})
+		}
+	}
+	return problem.FromError(err, detail)
+}
@@ -1,33 +1,75 @@
 package validation
 
-var validateMessages = map[string]string{
+import "github.com/togzhanzhakhani/projects/internal/i18n"
+
+// Messages are keyed by "Field.tag" for the rules of the models, and by
+// "validation.tag" for the generated messages used by everything else, with
+// the field as {0} and the rule's parameter as {1}.
+var messagesEn = map[string]string{
 	"Name.required":      "Name is required",
 	"Email.required":     "Email is required",
 	"Email.email":        "Email must be a valid email address",
-	"Email.unique":       "Email already exists",
+	"Email.unique_email": "Email already exists",
 	"Role.required":      "Role is required",
 	"Role.oneof":         "Role must be one of: admin, manager, developer",
-	
-    "Description.required":"Description is required",
-    "Description.max":     "Description must be at most 100 characters long",
-	"StartDate.required":  "Start date is required",
-	"EndDate.required":    "End date is required",
-	"EndDate.gtfield":     "End date must be after start date",
-	"ManagerID.required":  "Manager ID is required",
-    "ManagerID.gt":            "Manager ID must be greater than 0",
 
-    "Title.required":          "The title field is required.",
-	"Priority.oneof":          "Invalid priority. Must be one of: low, medium, high.",
-	"Status.oneof":            "Invalid status. Must be one of: todo, in_progress, done.",
-	"AssigneeID.required":    "The assignee ID field is required.",
-	"AssigneeID.gt":          "The assignee ID must be greater than 0.",
-	"ProjectID.required":     "The project ID field is required.",
-	"ProjectID.gt":           "The project ID must be greater than 0.",
-    "CreatedAt.required":      "Start date is required",
-	"CompletedAt.required":    "End date is required",
-	"CompletedAt.gtfield":     "End date must be after start date",
+	"Description.required":  "Description is required",
+	"Description.max":       "Description must be at most 100 characters long",
+	"StartDate.required":    "Start date is required",
+	"EndDate.required":      "End date is required",
+	"EndDate.gtfield":       "End date must be after the start date",
+	"ManagerID.required":    "Manager ID is required",
+	"ManagerID.gt":          "Manager ID must be greater than 0",
+	"ManagerID.user_exists": "Manager does not exist",
+	"ManagerID.role_in":     "Manager must be an admin or a manager",
+
+	"Title.required":            "Title is required",
+	"Priority.oneof":            "Priority must be one of: low, medium, high",
+	"Status.oneof":              "Status must be one of: todo, in_progress, done",
+	"AssigneeID.required":       "Assignee ID is required",
+	"AssigneeID.gt":             "Assignee ID must be greater than 0",
+	"AssigneeID.user_exists":    "Assignee does not exist",
+	"AssigneeID.project_member": "Assignee must be a member of the project",
+	"ProjectID.required":        "Project ID is required",
+	"ProjectID.gt":              "Project ID must be greater than 0",
+	"ProjectID.project_exists":  "Project does not exist",
+	"CreatedAt.required":        "Creation date is required",
+	"CompletedAt.required":      "Completion date is required",
+	"CompletedAt.gtfield":       "Completion date must be after the creation date",
+	"RRule.required":            "Recurrence rule is required",
+	"StartsAt.required":         "Start time is required",
+	"Timezone.timezone":         "Timezone must be a valid IANA time zone, e.g. Asia/Almaty",
+	"DueAfterMinutes.gte":       "Due offset must not be negative",
+	"DueTimezone.timezone":      "Due timezone must be a valid IANA time zone, e.g. Asia/Almaty",
+
+	"validation.required":       "{0} is required",
+	"validation.email":          "{0} must be a valid email address",
+	"validation.oneof":          "{0} must be one of: {1}",
+	"validation.max":            "{0} must be at most {1}",
+	"validation.min":            "{0} must be at least {1}",
+	"validation.gt":             "{0} must be greater than {1}",
+	"validation.gte":            "{0} must be at least {1}",
+	"validation.lt":             "{0} must be less than {1}",
+	"validation.lte":            "{0} must be at most {1}",
+	"validation.gtfield":        "{0} must be after {1}",
+	"validation.timezone":       "{0} must be a valid IANA time zone",
+	"validation.slug":           "{0} may only contain lower-case letters, digits and hyphens",
+	"validation.scope":          "{0} must be a scope such as tasks:read",
+	"validation.unique_email":   "{0} is already used by another user",
+	"validation.user_exists":    "{0} must be the ID of an existing user",
+	"validation.project_exists": "{0} must be the ID of an existing project",
+	"validation.role_in":        "{0} must be a user with one of the roles: {1}",
+	"validation.project_member": "{0} must be a member of the project in {1}",
+	"validation.invalid":        "{0} is invalid ({1})",
 }
 
+func init() {
+	i18n.MustRegister("en", messagesEn)
+	i18n.MustRegister("ru", messagesRu)
+	i18n.MustRegister("kk", messagesKk)
+}
+
+// GetMessage returns the English message for a "Field.tag" key.
 func GetMessage(key string) string {
-    return validateMessages[key]
-}
\ No newline at end of file
+	return i18n.T(i18n.English(), key)
+}
@@ -0,0 +1,59 @@
+package validation
+
+var messagesKk = map[string]string{
+	"Name.required":      "Аты міндетті",
+	"Email.required":     "Email міндетті",
+	"Email.email":        "Email дұрыс электрондық пошта мекенжайы болуы керек",
+	"Email.unique_email": "Мұндай email бұрыннан бар",
+	"Role.required":      "Рөл міндетті",
+	"Role.oneof":         "Рөл келесілердің бірі болуы керек: admin, manager, developer",
+
+	"Description.required":  "Сипаттама міндетті",
+	"Description.max":       "Сипаттама 100 таңбадан аспауы керек",
+	"StartDate.required":    "Басталу күні міндетті",
+	"EndDate.required":      "Аяқталу күні міндетті",
+	"EndDate.gtfield":       "Аяқталу күні басталу күнінен кейін болуы керек",
+	"ManagerID.required":    "Менеджер ID міндетті",
+	"ManagerID.gt":          "Менеджер ID 0-ден үлкен болуы керек",
+	"ManagerID.user_exists": "Менеджер жоқ",
+	"ManagerID.role_in":     "Менеджер әкімші немесе менеджер болуы керек",
+
+	"Title.required":            "Атауы міндетті",
+	"Priority.oneof":            "Басымдық келесілердің бірі болуы керек: low, medium, high",
+	"Status.oneof":              "Күй келесілердің бірі болуы керек: todo, in_progress, done",
+	"AssigneeID.required":       "Орындаушы ID міндетті",
+	"AssigneeID.gt":             "Орындаушы ID 0-ден үлкен болуы керек",
+	"AssigneeID.user_exists":    "Орындаушы жоқ",
+	"AssigneeID.project_member": "Орындаушы жоба мүшесі болуы керек",
+	"ProjectID.required":        "Жоба ID міндетті",
+	"ProjectID.gt":              "Жоба ID 0-ден үлкен болуы керек",
+	"ProjectID.project_exists":  "Жоба жоқ",
+	"CreatedAt.required":        "Құрылған күні міндетті",
+	"CompletedAt.required":      "Аяқталған күні міндетті",
+	"CompletedAt.gtfield":       "Аяқталған күні құрылған күнінен кейін болуы керек",
+	"RRule.required":            "Қайталану ережесі міндетті",
+	"StartsAt.required":         "Басталу уақыты міндетті",
+	"Timezone.timezone":         "Уақыт белдеуі жарамды IANA уақыт белдеуі болуы керек, мысалы Asia/Almaty",
+	"DueAfterMinutes.gte":       "Мерзім теріс болмауы керек",
+	"DueTimezone.timezone":      "Мерзімнің уақыт белдеуі жарамды IANA уақыт белдеуі болуы керек, мысалы Asia/Almaty",
+
+	"validation.required":       "{0} өрісі міндетті",
+	"validation.email":          "{0} өрісі дұрыс электрондық пошта мекенжайы болуы керек",
+	"validation.oneof":          "{0} өрісі келесілердің бірі болуы керек: {1}",
+	"validation.max":            "{0} өрісі {1} мәнінен аспауы керек",
+	"validation.min":            "{0} өрісі {1} мәнінен кем болмауы керек",
+	"validation.gt":             "{0} өрісі {1} мәнінен үлкен болуы керек",
+	"validation.gte":            "{0} өрісі {1} мәнінен кем болмауы керек",
+	"validation.lt":             "{0} өрісі {1} мәнінен кіші болуы керек",
+	"validation.lte":            "{0} өрісі {1} мәнінен аспауы керек",
+	"validation.gtfield":        "{0} өрісі {1} өрісінен кейін болуы керек",
+	"validation.timezone":       "{0} өрісі жарамды IANA уақыт белдеуі болуы керек",
+	"validation.slug":           "{0} өрісінде тек кіші латын әріптері, сандар және дефис болуы мүмкін",
+	"validation.scope":          "{0} өрісі қол жеткізу аясы болуы керек, мысалы tasks:read",
+	"validation.unique_email":   "{0} өрісін басқа пайдаланушы қолданып жүр",
+	"validation.user_exists":    "{0} өрісі бар пайдаланушының ID болуы керек",
+	"validation.project_exists": "{0} өрісі бар жобаның ID болуы керек",
+	"validation.role_in":        "{0} өрісі келесі рөлдердің біріне ие пайдаланушы болуы керек: {1}",
+	"validation.project_member": "{0} өрісі {1} өрісіндегі жобаның мүшесі болуы керек",
+	"validation.invalid":        "{0} өрісі жарамсыз ({1})",
+}
@@ -0,0 +1,59 @@
+package validation
+
+var messagesRu = map[string]string{
+	"Name.required":      "Имя обязательно",
+	"Email.required":     "Email обязателен",
+	"Email.email":        "Email должен быть корректным адресом электронной почты",
+	"Email.unique_email": "Такой email уже существует",
+	"Role.required":      "Роль обязательна",
+	"Role.oneof":         "Роль должна быть одной из: admin, manager, developer",
+
+	"Description.required":  "Описание обязательно",
+	"Description.max":       "Описание должно быть не длиннее 100 символов",
+	"StartDate.required":    "Дата начала обязательна",
+	"EndDate.required":      "Дата окончания обязательна",
+	"EndDate.gtfield":       "Дата окончания должна быть позже даты начала",
+	"ManagerID.required":    "ID менеджера обязателен",
+	"ManagerID.gt":          "ID менеджера должен быть больше 0",
+	"ManagerID.user_exists": "Менеджер не существует",
+	"ManagerID.role_in":     "Менеджер должен быть администратором или менеджером",
+
+	"Title.required":            "Название обязательно",
+	"Priority.oneof":            "Приоритет должен быть одним из: low, medium, high",
+	"Status.oneof":              "Статус должен быть одним из: todo, in_progress, done",
+	"AssigneeID.required":       "ID исполнителя обязателен",
+	"AssigneeID.gt":             "ID исполнителя должен быть больше 0",
+	"AssigneeID.user_exists":    "Исполнитель не существует",
+	"AssigneeID.project_member": "Исполнитель должен быть участником проекта",
+	"ProjectID.required":        "ID проекта обязателен",
+	"ProjectID.gt":              "ID проекта должен быть больше 0",
+	"ProjectID.project_exists":  "Проект не существует",
+	"CreatedAt.required":        "Дата создания обязательна",
+	"CompletedAt.required":      "Дата завершения обязательна",
+	"CompletedAt.gtfield":       "Дата завершения должна быть позже даты создания",
+	"RRule.required":            "Правило повторения обязательно",
+	"StartsAt.required":         "Время начала обязательно",
+	"Timezone.timezone":         "Часовой пояс должен быть корректным часовым поясом IANA, например Asia/Almaty",
+	"DueAfterMinutes.gte":       "Срок выполнения не может быть отрицательным",
+	"DueTimezone.timezone":      "Часовой пояс срока должен быть корректным часовым поясом IANA, например Asia/Almaty",
+
+	"validation.required":       "Поле {0} обязательно",
+	"validation.email":          "Поле {0} должно быть корректным адресом электронной почты",
+	"validation.oneof":          "Поле {0} должно быть одним из: {1}",
+	"validation.max":            "Поле {0} должно быть не больше {1}",
+	"validation.min":            "Поле {0} должно быть не меньше {1}",
+	"validation.gt":             "Поле {0} должно быть больше {1}",
+	"validation.gte":            "Поле {0} должно быть не меньше {1}",
+	"validation.lt":             "Поле {0} должно быть меньше {1}",
+	"validation.lte":            "Поле {0} должно быть не больше {1}",
+	"validation.gtfield":        "Поле {0} должно быть позже поля {1}",
+	"validation.timezone":       "Поле {0} должно быть корректным часовым поясом IANA",
+	"validation.slug":           "Поле {0} может содержать только строчные латинские буквы, цифры и дефисы",
+	"validation.scope":          "Поле {0} должно быть областью доступа, например tasks:read",
+	"validation.unique_email":   "Поле {0} уже используется другим пользователем",
+	"validation.user_exists":    "Поле {0} должно быть ID существующего пользователя",
+	"validation.project_exists": "Поле {0} должно быть ID существующего проекта",
+	"validation.role_in":        "Поле {0} должно быть пользователем с одной из ролей: {1}",
+	"validation.project_member": "Поле {0} должно быть участником проекта из поля {1}",
+	"validation.invalid":        "Поле {0} заполнено неверно ({1})",
+}
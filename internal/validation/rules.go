@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Lookups is the repository access of the rules that check values against
+// the database. The context is that of the validated request, so lookups
+// only see the request's workspace.
+type Lookups interface {
+	// EmailTaken reports whether a user other than exceptUserID has email,
+	// in any workspace.
+	EmailTaken(ctx context.Context, email string, exceptUserID uint) (bool, error)
+	// UserRole returns the role of a user, and false if there is no such user.
+	UserRole(ctx context.Context, id int) (string, bool, error)
+	ProjectExists(ctx context.Context, id int) (bool, error)
+	// ProjectMember reports whether a user is a member of a project.
+	ProjectMember(ctx context.Context, projectID, userID int) (bool, error)
+}
+
+// rule is a validation tag checked against the database.
+type rule func(ctx context.Context, lookups Lookups, fl validator.FieldLevel) (bool, error)
+
+// rules are the database-backed tags. Each is also enforced by a database
+// constraint, listed in constraints, for requests that race past the check.
+var rules = map[string]rule{
+	// unique_email: no other user has the address. The user being updated
+	// is taken from the ID field of the validated struct.
+	"unique_email": func(ctx context.Context, lookups Lookups, fl validator.FieldLevel) (bool, error) {
+		taken, err := lookups.EmailTaken(ctx, fl.Field().String(), ownID(fl))
+		return !taken, err
+	},
+	// user_exists: the field is the ID of an existing user.
+	"user_exists": func(ctx context.Context, lookups Lookups, fl validator.FieldLevel) (bool, error) {
+		_, found, err := lookups.UserRole(ctx, int(fl.Field().Int()))
+		return found, err
+	},
+	// project_exists: the field is the ID of an existing project.
+	"project_exists": func(ctx context.Context, lookups Lookups, fl validator.FieldLevel) (bool, error) {
+		return lookups.ProjectExists(ctx, int(fl.Field().Int()))
+	},
+	// role_in=admin manager: the field is the ID of a user with one of the
+	// roles. Missing users pass, as user_exists reports them.
+	"role_in": func(ctx context.Context, lookups Lookups, fl validator.FieldLevel) (bool, error) {
+		role, found, err := lookups.UserRole(ctx, int(fl.Field().Int()))
+		if err != nil || !found {
+			return true, err
+		}
+		for _, allowed := range strings.Fields(fl.Param()) {
+			if role == allowed {
+				return true, nil
+			}
+		}
+		return false, nil
+	},
+	// project_member=ProjectID: the field is the ID of a member of the
+	// project in the named field. Missing projects pass, as project_exists
+	// reports them.
+	"project_member": func(ctx context.Context, lookups Lookups, fl validator.FieldLevel) (bool, error) {
+		projectID := int(sibling(fl, fl.Param()).Int())
+		exists, err := lookups.ProjectExists(ctx, projectID)
+		if err != nil || !exists {
+			return true, err
+		}
+		return lookups.ProjectMember(ctx, projectID, int(fl.Field().Int()))
+	},
+}
+
+var lookups Lookups
+
+// RegisterRules gives the database-backed rules their repository access.
+// Until it is called, and in Validate, those rules pass.
+func RegisterRules(l Lookups) {
+	lookups = l
+}
+
+// lookupState is carried in the validation context of requests that run the
+// database-backed rules, and keeps the first lookup error.
+type lookupState struct {
+	err error
+}
+
+type lookupStateKey struct{}
+
+func registerRules(validate *validator.Validate) {
+	for tag, check := range rules {
+		check := check
+		validate.RegisterValidationCtx(tag, func(ctx context.Context, fl validator.FieldLevel) bool {
+			state, _ := ctx.Value(lookupStateKey{}).(*lookupState)
+			if state == nil || lookups == nil || state.err != nil {
+				return true
+			}
+			ok, err := check(ctx, lookups, fl)
+			if err != nil {
+				state.err = err
+				return true
+			}
+			return ok
+		})
+	}
+}
+
+func ownID(fl validator.FieldLevel) uint {
+	id := sibling(fl, "ID")
+	switch id.Kind() {
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return uint(id.Uint())
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return uint(id.Int())
+	}
+	return 0
+}
+
+// sibling returns the named field of the struct holding the validated field,
+// or the zero Value if there is none.
+func sibling(fl validator.FieldLevel, name string) reflect.Value {
+	parent := fl.Parent()
+	for parent.Kind() == reflect.Ptr {
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return parent.FieldByName(name)
+}
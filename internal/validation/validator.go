@@ -1,34 +1,130 @@
 package validation
 
 import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
 	"github.com/gin-gonic/gin"
-	"net/http"
-    "github.com/go-playground/validator/v10"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"github.com/togzhanzhakhani/projects/internal/problem"
 )
 
 var validate *validator.Validate
 
+// slugPattern is the form of the slugs that name things in URLs, such as
+// workspaces: lower-case words joined by single hyphens.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
 func init() {
-    validate = validator.New()
+	validate = validator.New()
+	// Report fields by their JSON names, as clients know them.
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	validate.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return slugPattern.MatchString(fl.Field().String())
+	})
+	validate.RegisterValidation("scope", func(fl validator.FieldLevel) bool {
+		return auth.ValidScope(fl.Field().String())
+	})
+	registerRules(validate)
 }
 
 func GetValidator() *validator.Validate {
     return validate
 }
 
+// Validate returns the English validation messages for obj, or nil if it is
+// valid. The database-backed rules are skipped: callers such as the importer
+// look up the referenced rows in bulk.
+func Validate(obj interface{}) []string {
+	var messages []string
+	for _, fieldError := range fieldErrors(i18n.English(), GetValidator().Struct(obj)) {
+		messages = append(messages, fieldError.Message)
+	}
+	return messages
+}
+
+// ValidateFields returns the invalid fields of obj with messages in the
+// translator's language, or nil if it is valid. The database-backed rules
+// look rows up in the workspace of ctx. The error is set if one of them
+// could not be checked.
+func ValidateFields(ctx context.Context, trans ut.Translator, obj interface{}) ([]problem.FieldError, error) {
+	state := &lookupState{}
+	err := GetValidator().StructCtx(context.WithValue(ctx, lookupStateKey{}, state), obj)
+	if state.err != nil {
+		return nil, state.err
+	}
+	return fieldErrors(trans, err), nil
+}
+
+func fieldErrors(trans ut.Translator, err error) []problem.FieldError {
+	if err == nil {
+		return nil
+	}
+	var fieldErrors []problem.FieldError
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldErrors = append(fieldErrors, problem.FieldError{
+			Field:   err.Field(),
+			Message: Message(trans, err),
+		})
+	}
+	return fieldErrors
+}
+
+// Message translates a failed rule. Rules without a message of their own in
+// the catalog get a generated one naming the field and the rule.
+func Message(trans ut.Translator, err validator.FieldError) string {
+	if message, ok := i18n.Lookup(trans, err.StructField()+"."+err.Tag()); ok {
+		return message
+	}
+	param := err.Param()
+	switch err.Tag() {
+	case "oneof", "role_in":
+		param = strings.Join(strings.Fields(param), ", ")
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield", "project_member":
+		param = snakeCase(param)
+	}
+	if message, ok := i18n.Lookup(trans, "validation."+err.Tag(), err.Field(), param); ok {
+		return message
+	}
+	return i18n.T(trans, "validation.invalid", err.Field(), err.Tag())
+}
+
 func ValidateStruct(c *gin.Context, obj interface{}) bool {
-	validate := GetValidator()
-	if err := validate.Struct(obj); err != nil {
-		var validationErrors []string
-		for _, err := range err.(validator.ValidationErrors) {
-			field := err.Field()
-			translation := GetMessage(field + "." + err.Tag())
-			validationErrors = append(validationErrors, translation)
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"errors": validationErrors})
+	fieldErrors, err := ValidateFields(c.Request.Context(), i18n.Translator(c), obj)
+	if err != nil {
+		problem.Write(c, problem.FromError(err, "Failed to validate request"))
+		return false
+	}
+	if fieldErrors != nil {
+		problem.Write(c, problem.Validation(fieldErrors))
 		return false
 	}
 	return true
 }
 
-
+// snakeCase turns a Go field name such as StartDate into its JSON name.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(rune(name[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
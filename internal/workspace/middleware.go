@@ -0,0 +1,58 @@
+package workspace
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"gorm.io/gorm"
+)
+
+// Members looks up memberships, for Middleware to check that the caller
+// belongs to the workspace they asked for.
+type Members interface {
+	GetMember(workspaceID, userID uint) (*models.Membership, error)
+}
+
+// Middleware scopes a request to the workspace selected by Header. The
+// caller, identified by auth.Middleware, must be a member of it; other
+// workspaces are reported as missing, whether they exist or not.
+func Middleware(members Members) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := auth.UserID(c.Request.Context())
+		if !ok {
+			problem.Write(c, problem.Unauthorized("Authentication is required"))
+			return
+		}
+		workspaceID, ok := ParseID(c.GetHeader(Header))
+		if !ok {
+			problem.Write(c, problem.BadRequest("Invalid "+Header+" header"))
+			return
+		}
+		membership, err := members.GetMember(workspaceID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			problem.Write(c, problem.Lookup(err, "workspace"))
+			return
+		}
+		if err != nil {
+			problem.Write(c, problem.FromError(err, "Failed to retrieve workspace"))
+			return
+		}
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), *membership))
+		c.Next()
+	}
+}
+
+// RequireDefaultAdmin rejects the requests of callers who are not admins of
+// the default workspace, for routes about the whole server rather than one
+// workspace. It runs after Middleware, so the request must be scoped to the
+// default workspace.
+func RequireDefaultAdmin(c *gin.Context) {
+	if !AdministersDefault(c.Request.Context()) {
+		problem.Write(c, problem.Forbidden("Only admins of the default workspace may do this"))
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,164 @@
+package workspace
+
+import (
+	"reflect"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// column holds the workspace of a row in every table scoped by Plugin.
+	column = "workspace_id"
+	// usersTable is scoped by membership instead, since users may belong to
+	// several workspaces.
+	usersTable = "users"
+	// globalKey marks statements that must not be scoped, see Global.
+	globalKey = "workspace:global"
+)
+
+// Plugin scopes the statements of a gorm.DB whose context is scoped to a
+// workspace, see NewContext:
+//
+//   - queries, updates and deletes of tables with a workspace_id column only
+//     match the workspace's rows, and those of users only its members;
+//   - created and updated rows are put into the workspace, whatever their
+//     WorkspaceID field said, and an upsert never takes over the row of
+//     another workspace;
+//   - created users become members of the workspace.
+//
+// Statements without a workspace in their context, such as those of
+// background jobs, are left alone. Raw SQL is never scoped.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "workspace"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("workspace:scope", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("workspace:scope", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("workspace:scope", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("workspace:scope", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:setup_reflect_value").Before("gorm:update").Register("workspace:assign", assign); err != nil {
+		return err
+	}
+	if err := callbacks.Create().Before("gorm:create").Register("workspace:assign", assign); err != nil {
+		return err
+	}
+	return callbacks.Create().After("gorm:create").Register("workspace:join", join)
+}
+
+// Global returns db with the workspace scope lifted, for the few lookups
+// that are global by nature, such as whether an email is taken.
+func Global(db *gorm.DB) *gorm.DB {
+	return db.Set(globalKey, true)
+}
+
+// scoped returns the workspace a statement is scoped to.
+func scoped(db *gorm.DB) (uint, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return 0, false
+	}
+	if global, _ := db.Get(globalKey); global == true {
+		return 0, false
+	}
+	return ID(db.Statement.Context)
+}
+
+func scope(db *gorm.DB) {
+	id, ok := scoped(db)
+	if !ok {
+		return
+	}
+	current := clause.Column{Table: clause.CurrentTable, Name: column}
+	switch {
+	case db.Statement.Schema.LookUpField(column) != nil:
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: current, Value: id}}})
+	case db.Statement.Schema.Table == usersTable:
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Expr{
+			SQL:  "? IN (SELECT user_id FROM workspace_members WHERE workspace_id = ?)",
+			Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: "id"}, id},
+		}}})
+	}
+}
+
+func assign(db *gorm.DB) {
+	id, ok := scoped(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(column)
+	if field == nil {
+		return
+	}
+	setField(db, field, id)
+
+	if expr, ok := db.Statement.Clauses["ON CONFLICT"].Expression.(clause.OnConflict); ok && !expr.DoNothing {
+		current := clause.Column{Table: clause.CurrentTable, Name: column}
+		expr.Where.Exprs = append(expr.Where.Exprs, clause.Eq{Column: current, Value: id})
+		db.Statement.AddClause(expr)
+	}
+}
+
+// setField sets field of the statement's records, be it a struct or a slice.
+func setField(db *gorm.DB, field *schema.Field, id uint) {
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := field.Set(db.Statement.Context, reflect.Indirect(rv.Index(i)), id); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(db.Statement.Context, rv, id); err != nil {
+			db.AddError(err)
+		}
+	}
+}
+
+// join makes created users members of the workspace.
+func join(db *gorm.DB) {
+	id, ok := scoped(db)
+	if !ok || db.Statement.Schema.Table != usersTable {
+		return
+	}
+	var userIDs []interface{}
+	add := func(rv reflect.Value) {
+		if value, zero := db.Statement.Schema.PrioritizedPrimaryField.ValueOf(db.Statement.Context, rv); !zero {
+			userIDs = append(userIDs, value)
+		}
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			add(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		add(rv)
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	for _, userID := range userIDs {
+		err := tx.Exec("INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, now()) ON CONFLICT DO NOTHING",
+			id, userID, models.WorkspaceMember).Error
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+	}
+}
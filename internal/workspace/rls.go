@@ -0,0 +1,135 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"gorm.io/gorm"
+)
+
+// Setting is the Postgres setting the row-level security policies compare
+// the workspace_id of rows with. Sessions that leave it unset, such as
+// those of background jobs, are not restricted.
+const Setting = "app.workspace_id"
+
+type txKey struct{}
+
+// DB returns db for the statements of ctx: scoped to its workspace, and in
+// the transaction RowLevelSecurity opened for the request if there is one.
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// RowLevelSecurity runs each request scoped by Middleware in a transaction
+// with Setting set to its workspace, as a second line of defence should a
+// query escape Plugin. It needs the policies created by pkg/database when
+// WORKSPACE_RLS is set.
+//
+// The transaction is committed when the response is not an error. The
+// responses of writes are held back until then, so that a failed commit is
+// reported instead of a success.
+func RowLevelSecurity(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		id, ok := ID(ctx)
+		if !ok {
+			c.Next()
+			return
+		}
+		tx := db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			problem.Write(c, problem.FromError(tx.Error, "Failed to begin transaction"))
+			return
+		}
+		committed := false
+		defer func() {
+			if !committed {
+				tx.Rollback()
+			}
+		}()
+		if err := tx.Exec("SELECT set_config(?, ?, true)", Setting, strconv.FormatUint(uint64(id), 10)).Error; err != nil {
+			problem.Write(c, problem.FromError(err, "Failed to begin transaction"))
+			return
+		}
+		c.Request = c.Request.WithContext(context.WithValue(ctx, txKey{}, tx))
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			committed = c.Writer.Status() < http.StatusBadRequest && tx.Commit().Error == nil
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		if writer.status >= http.StatusBadRequest {
+			writer.flush()
+			return
+		}
+		if err := tx.Commit().Error; err != nil {
+			problem.Write(c, problem.FromError(err, "Failed to commit transaction"))
+			return
+		}
+		committed = true
+		writer.flush()
+	}
+}
+
+// bufferedWriter holds a response back until flush.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+func (w *bufferedWriter) Flush() {}
+
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	w.ResponseWriter.Write(w.body.Bytes())
+}
@@ -0,0 +1,113 @@
+// Package workspace isolates the data of workspaces from each other. A
+// request is scoped to one workspace by Middleware, which puts the caller's
+// membership in the request context; repositories given that context only
+// see and write the workspace's rows, as enforced by Plugin.
+package workspace
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+// Header selects the workspace of a request. Requests without it use the
+// default workspace.
+const Header = "X-Workspace-ID"
+
+type membershipKey struct{}
+
+// NewContext returns a copy of ctx scoped to the workspace of membership.
+func NewContext(ctx context.Context, membership models.Membership) context.Context {
+	return context.WithValue(ctx, membershipKey{}, membership)
+}
+
+// FromContext returns the membership ctx is scoped by.
+func FromContext(ctx context.Context) (models.Membership, bool) {
+	membership, ok := ctx.Value(membershipKey{}).(models.Membership)
+	return membership, ok
+}
+
+// ID returns the ID of the workspace ctx is scoped to.
+func ID(ctx context.Context) (uint, bool) {
+	membership, ok := FromContext(ctx)
+	return membership.WorkspaceID, ok
+}
+
+// ParseID parses a workspace ID as sent in Header, where an empty value
+// stands for the default workspace.
+func ParseID(value string) (uint, bool) {
+	if value == "" {
+		return models.DefaultWorkspaceID, true
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+type systemKey struct{}
+
+// NewSystemContext returns a copy of ctx for work the server does on its
+// own, such as background jobs, which may do what admins do.
+func NewSystemContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemKey{}, true)
+}
+
+func isSystem(ctx context.Context) bool {
+	system, _ := ctx.Value(systemKey{}).(bool)
+	return system
+}
+
+// Administers reports whether the caller administers the workspace ctx is
+// scoped to. Contexts that are not scoped to a workspace are refused, unless
+// they come from NewSystemContext.
+func Administers(ctx context.Context) bool {
+	if isSystem(ctx) {
+		return true
+	}
+	membership, ok := FromContext(ctx)
+	return ok && membership.Role == models.WorkspaceAdmin
+}
+
+// AdministersDefault reports whether the caller administers the default
+// workspace and ctx is scoped to it, as changes that concern the whole
+// server require.
+func AdministersDefault(ctx context.Context) bool {
+	if isSystem(ctx) {
+		return true
+	}
+	membership, ok := FromContext(ctx)
+	return ok && membership.WorkspaceID == models.DefaultWorkspaceID && membership.Role == models.WorkspaceAdmin
+}
+
+// AuthorizeRoleChange returns the problem with the caller of ctx changing
+// the role of user userID from role to newRole, or nil if they may. Roles
+// apply in every workspace, so only admins of the default workspace change
+// them, and not to raise their own.
+func AuthorizeRoleChange(ctx context.Context, userID uint, role, newRole string) *problem.Problem {
+	if role == newRole {
+		return nil
+	}
+	if !AdministersDefault(ctx) {
+		return problem.Forbidden("Only admins of the default workspace may change the role of users")
+	}
+	if callerID, ok := auth.UserID(ctx); ok && callerID == userID && models.RoleRanks[newRole] > models.RoleRanks[role] {
+		return problem.Forbidden("Users may not raise their own role")
+	}
+	return nil
+}
+
+// AuthorizeEmailChange returns the problem with changing the email of a
+// user from email to newEmail in an update of the user, or nil if it stays.
+// Password resets are sent to the email, so users change their own with
+// their password at PUT /me/email instead.
+func AuthorizeEmailChange(email, newEmail string) *problem.Problem {
+	if email == newEmail {
+		return nil
+	}
+	return problem.Forbidden("Users change their own email with their password at PUT /me/email")
+}
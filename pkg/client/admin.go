@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// AdminService calls the /admin routes.
+type AdminService struct {
+	client *Client
+}
+
+// Jobs returns the background jobs with their last runs, at most runs
+// (1-100, 10 when 0) per job.
+func (s *AdminService) Jobs(ctx context.Context, runs int) ([]JobStatus, error) {
+	req := &request{method: http.MethodGet, path: "/admin/jobs"}
+	if runs > 0 {
+		req.query = url.Values{"limit": {strconv.Itoa(runs)}}
+	}
+	var statuses []JobStatus
+	if _, err := s.client.do(ctx, req, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// AuthService calls the /auth routes.
+type AuthService struct {
+	client *Client
+}
+
+// Session is an open session. Its Token is the bearer token to set as
+// Client.Token.
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      User      `json:"user"`
+}
+
+// Login opens a session with an email and password. It does not set
+// Client.Token.
+func (s *AuthService) Login(ctx context.Context, email, password string) (*Session, error) {
+	req, err := jsonRequest(http.MethodPost, "/auth/login", map[string]string{"email": email, "password": password})
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if _, err := s.client.do(ctx, req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Logout ends the session of Client.Token.
+func (s *AuthService) Logout(ctx context.Context) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodPost, path: "/auth/logout"}, nil)
+	return err
+}
+
+// RequestPasswordReset sends a reset token to the user with the email, if
+// there is one.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	req, err := jsonRequest(http.MethodPost, "/auth/password-reset", map[string]string{"email": email})
+	if err != nil {
+		return err
+	}
+	_, err = s.client.do(ctx, req, nil)
+	return err
+}
+
+// ConfirmPasswordReset sets a new password with a reset token.
+func (s *AuthService) ConfirmPasswordReset(ctx context.Context, token, password string) error {
+	req, err := jsonRequest(http.MethodPost, "/auth/password-reset/confirm", map[string]string{"token": token, "password": password})
+	if err != nil {
+		return err
+	}
+	_, err = s.client.do(ctx, req, nil)
+	return err
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CalendarOptions select a calendar feed.
+type CalendarOptions struct {
+	// Token is the secret of a feed token of the user or project.
+	Token string
+	// TasksAsEvents lists tasks with a due date as events instead of
+	// listing all tasks as to-dos.
+	TasksAsEvents bool
+}
+
+func calendar(ctx context.Context, c *Client, owner string, opts CalendarOptions) (io.ReadCloser, error) {
+	query := url.Values{"token": {opts.Token}}
+	if opts.TasksAsEvents {
+		query.Set("tasks", "event")
+	}
+	return c.download(ctx, &request{method: http.MethodGet, path: owner + "/calendar.ics", query: query})
+}
+
+func feedTokens(ctx context.Context, c *Client, owner string) ([]FeedToken, error) {
+	var tokens []FeedToken
+	if _, err := c.do(ctx, &request{method: http.MethodGet, path: owner + "/calendar-tokens"}, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func createFeedToken(ctx context.Context, c *Client, owner, name string) (*NewFeedToken, error) {
+	req, err := jsonRequest(http.MethodPost, owner+"/calendar-tokens", map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+	var token NewFeedToken
+	if _, err := c.do(ctx, req, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func revokeFeedToken(ctx context.Context, c *Client, owner string, tokenID uint) error {
+	_, err := c.do(ctx, &request{method: http.MethodDelete, path: owner + "/calendar-tokens/" + pathUint(tokenID)}, nil)
+	return err
+}
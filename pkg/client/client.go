@@ -0,0 +1,350 @@
+// Package client is a Go client for the task management API.
+//
+//	c := client.NewClient("https://api.example.com")
+//	c.Token = os.Getenv("API_TOKEN")
+//	c.WorkspaceID = 2
+//	task, err := c.Tasks.Get(ctx, 42)
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
+//
+// Every method takes a context, which bounds the call including its retries.
+// Collection routes return an Iterator that follows the pages of the
+// response. Requests that fail with a 5xx status or 429 Too Many Requests
+// are retried with exponential backoff; see RetryPolicy.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Idempotent requests
+// (GET, PUT, DELETE and GraphQL queries) are retried on network errors, 5xx
+// statuses and 429; other POST requests only on 429, which the server sends
+// before doing anything.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per request, including the
+	// first one. Values below 1 mean no retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry; it doubles with each
+	// further retry, up to MaxBackoff. Each delay is jittered by up to half.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  200 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// Client calls the API. Its fields may be changed before the first request,
+// but not while requests are in flight.
+type Client struct {
+	// BaseURL is the URL the API is served at, e.g. "https://api.example.com".
+	BaseURL string
+	// Token is sent as a bearer token when not empty.
+	Token string
+	// UserID, when not 0, is sent in the X-User-ID header to identify the
+	// caller to an API behind an authenticating proxy.
+	UserID uint
+	// WorkspaceID selects the workspace the requests act on; the API uses
+	// the default workspace when it is 0.
+	WorkspaceID uint
+	// AcceptLanguage selects the language of error messages, e.g. "ru".
+	AcceptLanguage string
+	UserAgent      string
+	HTTPClient     *http.Client
+	Retry          RetryPolicy
+
+	Users          *UserService
+	Projects       *ProjectService
+	Tasks          *TaskService
+	RecurringTasks *RecurringTaskService
+	Imports        *ImportService
+	Exports        *ExportService
+	Webhooks       *WebhookService
+	GraphQL        *GraphQLService
+	Admin          *AdminService
+	Workspaces     *WorkspaceService
+	Teams          *TeamService
+	Invitations    *InvitationService
+	Auth           *AuthService
+	Tokens         *TokenService
+}
+
+// NewClient returns a client of the API at baseURL.
+func NewClient(baseURL string) *Client {
+	c := &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		UserAgent:  "projects-go-client",
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+		Retry:      DefaultRetryPolicy,
+	}
+	c.Users = &UserService{c}
+	c.Projects = &ProjectService{c}
+	c.Tasks = &TaskService{c}
+	c.RecurringTasks = &RecurringTaskService{c}
+	c.Imports = &ImportService{c}
+	c.Exports = &ExportService{c}
+	c.Webhooks = &WebhookService{c}
+	c.GraphQL = &GraphQLService{c}
+	c.Admin = &AdminService{c}
+	c.Workspaces = &WorkspaceService{c}
+	c.Teams = &TeamService{c}
+	c.Invitations = &InvitationService{c}
+	c.Auth = &AuthService{c}
+	c.Tokens = &TokenService{c}
+	return c
+}
+
+// OpenAPISpec returns the OpenAPI document of the API.
+func (c *Client) OpenAPISpec(ctx context.Context) (json.RawMessage, error) {
+	var spec json.RawMessage
+	_, err := c.do(ctx, &request{method: http.MethodGet, path: "/openapi.json"}, &spec)
+	return spec, err
+}
+
+// request is one API call. The body is kept in memory so that it can be
+// sent again on retries.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        []byte
+	contentType string
+	// safe marks a POST that only reads, so it is retried like a GET.
+	safe bool
+	// report receives the body of error responses that carry a result
+	// rather than a problem, e.g. the rejected rows of an import.
+	report interface{}
+}
+
+func jsonRequest(method, path string, body interface{}) (*request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &request{method: method, path: path, body: data, contentType: "application/json"}, nil
+}
+
+// do sends req, retrying as the policy allows, and decodes the response
+// into out unless it is nil.
+func (c *Client) do(ctx context.Context, req *request, out interface{}) (http.Header, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp.Header, nil
+	}
+	if raw, ok := out.(*string); ok {
+		data, err := io.ReadAll(resp.Body)
+		*raw = string(data)
+		return resp.Header, err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("decode %s %s response: %w", req.method, req.path, err)
+	}
+	return resp.Header, nil
+}
+
+// send returns the first successful response to req. The caller closes its
+// body.
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	attempts := c.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	idempotent := req.method != http.MethodPost || req.safe
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil || !idempotent || attempt == attempts {
+				return nil, err
+			}
+			if err := c.sleep(ctx, c.backoff(attempt, nil)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode < 400 {
+			return resp, nil
+		}
+
+		apiErr := readError(resp, req.report)
+		retryable := resp.StatusCode == http.StatusTooManyRequests ||
+			(idempotent && resp.StatusCode >= http.StatusInternalServerError)
+		if !retryable || attempt == attempts {
+			return nil, apiErr
+		}
+		if err := c.sleep(ctx, c.backoff(attempt, resp.Header)); err != nil {
+			return nil, apiErr
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req *request) (*http.Response, error) {
+	target, err := c.url(req.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Accept", "application/json, application/problem+json;q=0.9, */*;q=0.8")
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.UserID != 0 {
+		httpReq.Header.Set("X-User-ID", pathUint(c.UserID))
+	}
+	if c.WorkspaceID != 0 {
+		httpReq.Header.Set("X-Workspace-ID", pathUint(c.WorkspaceID))
+	}
+	if c.AcceptLanguage != "" {
+		httpReq.Header.Set("Accept-Language", c.AcceptLanguage)
+	}
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return httpClient.Do(httpReq)
+}
+
+// url resolves path, which is either relative to the base URL or, for the
+// links of paginated responses, absolute.
+func (c *Client) url(path string) (string, error) {
+	if strings.HasPrefix(path, "/") {
+		return c.BaseURL + path, nil
+	}
+	target, err := url.Parse(path)
+	if err != nil || !target.IsAbs() {
+		return "", fmt.Errorf("invalid request URL %q", path)
+	}
+	return path, nil
+}
+
+// backoff returns the delay before retrying after the given attempt. A
+// Retry-After header overrides the exponential delay, within MaxBackoff.
+func (c *Client) backoff(attempt int, header http.Header) time.Duration {
+	if delay, ok := retryAfter(header); ok {
+		if c.Retry.MaxBackoff > 0 && delay > c.Retry.MaxBackoff {
+			delay = c.Retry.MaxBackoff
+		}
+		return delay
+	}
+	delay := c.Retry.MinBackoff
+	for i := 1; i < attempt && (c.Retry.MaxBackoff <= 0 || delay < c.Retry.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if c.Retry.MaxBackoff > 0 && delay > c.Retry.MaxBackoff {
+		delay = c.Retry.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(at); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func (c *Client) sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// readError reads an error response. Bodies that are not problem details,
+// e.g. from a proxy, still produce an Error with the status.
+func readError(resp *http.Response, report interface{}) *Error {
+	defer resp.Body.Close()
+	apiErr := &Error{StatusCode: resp.StatusCode, Header: resp.Header}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil || len(data) == 0 {
+		return apiErr
+	}
+	if report != nil && !strings.HasPrefix(resp.Header.Get("Content-Type"), problemContentType) {
+		if json.Unmarshal(data, report) == nil {
+			apiErr.Report = report
+		}
+		return apiErr
+	}
+	if json.Unmarshal(data, apiErr) != nil {
+		apiErr.Detail = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
+
+// download sends req and returns the response body for the caller to read
+// and close.
+func (c *Client) download(ctx context.Context, req *request) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func pathID(id int) string {
+	return strconv.Itoa(id)
+}
+
+func pathUint(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+var errNoID = errors.New("client: ID is required")
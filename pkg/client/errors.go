@@ -0,0 +1,113 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const problemContentType = "application/problem+json"
+
+// Errors that an *Error matches with errors.Is, by its problem code or, for
+// responses without one, its status.
+var (
+	ErrInvalidRequest   = errors.New("invalid request")
+	ErrValidationFailed = errors.New("validation failed")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrNotFound         = errors.New("not found")
+	ErrConflict         = errors.New("conflict")
+	ErrPayloadTooLarge  = errors.New("payload too large")
+	ErrUnsupportedMedia = errors.New("unsupported media type")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrInternal         = errors.New("internal error")
+	ErrUnavailable      = errors.New("service unavailable")
+)
+
+var codeErrors = map[string]error{
+	"invalid_request":        ErrInvalidRequest,
+	"validation_failed":      ErrValidationFailed,
+	"unauthorized":           ErrUnauthorized,
+	"forbidden":              ErrForbidden,
+	"not_found":              ErrNotFound,
+	"conflict":               ErrConflict,
+	"payload_too_large":      ErrPayloadTooLarge,
+	"unsupported_media_type": ErrUnsupportedMedia,
+	"rate_limited":           ErrRateLimited,
+	"internal_error":         ErrInternal,
+	"service_unavailable":    ErrUnavailable,
+}
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:            ErrInvalidRequest,
+	http.StatusUnprocessableEntity:   ErrValidationFailed,
+	http.StatusUnauthorized:          ErrUnauthorized,
+	http.StatusForbidden:             ErrForbidden,
+	http.StatusNotFound:              ErrNotFound,
+	http.StatusConflict:              ErrConflict,
+	http.StatusRequestEntityTooLarge: ErrPayloadTooLarge,
+	http.StatusUnsupportedMediaType:  ErrUnsupportedMedia,
+	http.StatusTooManyRequests:       ErrRateLimited,
+	http.StatusInternalServerError:   ErrInternal,
+	http.StatusServiceUnavailable:    ErrUnavailable,
+}
+
+// FieldError describes one invalid request field.
+type FieldError struct {
+	In      string `json:"in,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error response of the API, decoded from its RFC 7807 problem
+// details.
+type Error struct {
+	StatusCode int         `json:"-"`
+	Header     http.Header `json:"-"`
+
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Code     string       `json:"code"`
+	Detail   string       `json:"detail"`
+	Instance string       `json:"instance"`
+	TraceID  string       `json:"trace_id"`
+	Errors   []FieldError `json:"errors"`
+
+	// Report is set by the methods whose failed responses carry a result
+	// instead of problem details, such as ImportService.Import.
+	Report interface{} `json:"-"`
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "client: HTTP %d", e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " %s", e.Code)
+	}
+	switch {
+	case e.Detail != "":
+		fmt.Fprintf(&b, ": %s", e.Detail)
+	case e.Title != "":
+		fmt.Fprintf(&b, ": %s", e.Title)
+	}
+	for i, fieldError := range e.Errors {
+		sep := "; "
+		if i == 0 {
+			sep = " ("
+		}
+		fmt.Fprintf(&b, "%s%s: %s", sep, fieldError.Field, fieldError.Message)
+	}
+	if len(e.Errors) > 0 {
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// Is reports whether target is the Err value of e's problem code.
+func (e *Error) Is(target error) bool {
+	if err, ok := codeErrors[e.Code]; ok {
+		return err == target
+	}
+	return statusErrors[e.StatusCode] == target
+}
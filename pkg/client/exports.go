@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ExportService calls the /export routes.
+type ExportService struct {
+	client *Client
+}
+
+// Tasks returns the tasks matching filter as a file in format (FormatCSV,
+// FormatNDJSON or FormatXLSX). Unlike TaskService.Search, all the fields set
+// in filter apply. The caller closes the file.
+func (s *ExportService) Tasks(ctx context.Context, format string, filter TaskQuery) (io.ReadCloser, error) {
+	query := url.Values{"format": {format}}
+	for name, value := range map[string]string{"title": filter.Title, "status": filter.Status, "priority": filter.Priority} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if filter.AssigneeID != 0 {
+		query.Set("assignee", pathUint(filter.AssigneeID))
+	}
+	if filter.ProjectID != 0 {
+		query.Set("project", pathUint(filter.ProjectID))
+	}
+	return s.client.download(ctx, &request{method: http.MethodGet, path: "/export/tasks", query: query})
+}
+
+// Projects returns all projects, with their manager's name and number of
+// tasks, as a file in format. The caller closes it.
+func (s *ExportService) Projects(ctx context.Context, format string) (io.ReadCloser, error) {
+	return s.client.download(ctx, &request{method: http.MethodGet, path: "/export/projects", query: url.Values{"format": {format}}})
+}
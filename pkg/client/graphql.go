@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// GraphQLService calls the /graphql routes.
+type GraphQLService struct {
+	client *Client
+}
+
+// GraphQLError is an error of a GraphQL query.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLErrors are the errors of a GraphQL response. The data of the
+// fields that did not fail is still decoded.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// Query executes a GraphQL query or mutation and decodes its data into out.
+// Errors reported in the response are returned as GraphQLErrors.
+func (s *GraphQLService) Query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	req, err := jsonRequest(http.MethodPost, "/graphql", map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	// Queries only read and can be retried; mutations cannot.
+	req.safe = !strings.Contains(query, "mutation")
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}
+	if _, err := s.client.do(ctx, req, &resp); err != nil {
+		return err
+	}
+	if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return err
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
+
+// Schema returns the schema in the GraphQL schema definition language.
+func (s *GraphQLService) Schema(ctx context.Context) (string, error) {
+	var schema string
+	_, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/graphql/schema"}, &schema)
+	return schema, err
+}
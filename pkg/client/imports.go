@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Import formats and modes.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+	FormatXML    = "xml"
+
+	ImportAtomic = "atomic"
+	ImportBatch  = "batch"
+)
+
+// ImportService calls the /import routes.
+type ImportService struct {
+	client *Client
+}
+
+// ImportRequest is a CSV or NDJSON file of users, projects or tasks.
+type ImportRequest struct {
+	// Entity is "users", "projects" or "tasks".
+	Entity string
+	// Format is FormatCSV or FormatNDJSON.
+	Format string
+	File   io.Reader
+	// Mapping renames columns of the file to the API's field names.
+	Mapping map[string]string
+	// DryRun only validates the rows.
+	DryRun bool
+	// Mode is ImportAtomic (the default), which creates all rows or none, or
+	// ImportBatch, which commits BatchSize rows at a time and skips invalid
+	// ones.
+	Mode      string
+	BatchSize int
+	// JobID resumes a failed batch import of the same file.
+	JobID string
+}
+
+// Import creates the rows of a file. When the import is rejected or a batch
+// fails, the error is an *Error whose Report is the *ImportReport.
+func (s *ImportService) Import(ctx context.Context, r ImportRequest) (*ImportReport, error) {
+	data, err := io.ReadAll(r.File)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"entity": {r.Entity}, "format": {r.Format}}
+	if len(r.Mapping) > 0 {
+		query.Set("map", mapping(r.Mapping))
+	}
+	if r.DryRun {
+		query.Set("dry_run", "true")
+	}
+	if r.Mode != "" {
+		query.Set("mode", r.Mode)
+	}
+	if r.BatchSize > 0 {
+		query.Set("batch_size", strconv.Itoa(r.BatchSize))
+	}
+	if r.JobID != "" {
+		query.Set("job_id", r.JobID)
+	}
+
+	var report ImportReport
+	req := &request{method: http.MethodPost, path: "/import", query: query, body: data, contentType: "application/octet-stream", report: &ImportReport{}}
+	if _, err := s.client.do(ctx, req, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Job returns a batched import job.
+func (s *ImportService) Job(ctx context.Context, id string) (*ImportJob, error) {
+	var job ImportJob
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/import/" + url.PathEscape(id)}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// TrackerImportOptions control a GitHub or Jira import.
+type TrackerImportOptions struct {
+	// ProjectID puts all issues in an existing project; otherwise projects
+	// are created per repository or Jira project, managed by ManagerID.
+	ProjectID int
+	ManagerID int
+	// DefaultAssigneeID is assigned the issues whose assignee is unknown.
+	DefaultAssigneeID int
+	// UserMap maps tracker logins to user emails.
+	UserMap map[string]string
+}
+
+func (o TrackerImportOptions) values() url.Values {
+	query := url.Values{}
+	for name, id := range map[string]int{
+		"project_id":          o.ProjectID,
+		"manager_id":          o.ManagerID,
+		"default_assignee_id": o.DefaultAssigneeID,
+	} {
+		if id != 0 {
+			query.Set(name, strconv.Itoa(id))
+		}
+	}
+	if len(o.UserMap) > 0 {
+		query.Set("user_map", mapping(o.UserMap))
+	}
+	return query
+}
+
+// GitHub imports a GitHub issues JSON export, the array returned by
+// GET /repos/{owner}/{repo}/issues.
+func (s *ImportService) GitHub(ctx context.Context, export io.Reader, opts TrackerImportOptions) (*TrackerImportReport, error) {
+	return s.tracker(ctx, "/import/github", export, "application/json", opts.values())
+}
+
+// Jira imports a Jira export in format FormatCSV or FormatXML.
+func (s *ImportService) Jira(ctx context.Context, export io.Reader, format string, opts TrackerImportOptions) (*TrackerImportReport, error) {
+	query := opts.values()
+	query.Set("format", format)
+	contentType := "text/csv"
+	if format == FormatXML {
+		contentType = "application/xml"
+	}
+	return s.tracker(ctx, "/import/jira", export, contentType, query)
+}
+
+func (s *ImportService) tracker(ctx context.Context, path string, export io.Reader, contentType string, query url.Values) (*TrackerImportReport, error) {
+	data, err := io.ReadAll(export)
+	if err != nil {
+		return nil, err
+	}
+	var report TrackerImportReport
+	req := &request{method: http.MethodPost, path: path, query: query, body: data, contentType: contentType}
+	if _, err := s.client.do(ctx, req, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// mapping formats a column or user mapping as "from:to,...".
+func mapping(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for from, to := range m {
+		pairs = append(pairs, from+":"+to)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
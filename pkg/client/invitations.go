@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// InvitationService calls the /invitations routes. Workspace admins and
+// admins invite with any role, managers with any role but admin.
+type InvitationService struct {
+	client *Client
+}
+
+// InvitationInput is the invitation to create. ProjectID, when not nil,
+// names a project the invitee joins with ProjectRole, contributor by
+// default.
+type InvitationInput struct {
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	ProjectID   *int   `json:"project_id,omitempty"`
+	ProjectRole string `json:"project_role,omitempty"`
+}
+
+// AcceptInput accepts an invitation. Name and Password are required when
+// the invitee has no account yet, and ignored otherwise.
+type AcceptInput struct {
+	Token    string `json:"token"`
+	Name     string `json:"name,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// List returns the pending invitations of the workspace, expired ones
+// included.
+func (s *InvitationService) List(ctx context.Context) ([]Invitation, error) {
+	var invitations []Invitation
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/invitations/"}, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// Create invites someone to the workspace. The token is delivered to the
+// email address, not returned.
+func (s *InvitationService) Create(ctx context.Context, input InvitationInput) (*Invitation, error) {
+	req, err := jsonRequest(http.MethodPost, "/invitations/", input)
+	if err != nil {
+		return nil, err
+	}
+	var created Invitation
+	if _, err := s.client.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Resend sends a pending invitation again with a new token.
+func (s *InvitationService) Resend(ctx context.Context, id uint) (*Invitation, error) {
+	var invitation Invitation
+	if _, err := s.client.do(ctx, &request{method: http.MethodPost, path: "/invitations/" + pathUint(id) + "/resend"}, &invitation); err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// Revoke revokes a pending invitation.
+func (s *InvitationService) Revoke(ctx context.Context, id uint) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: "/invitations/" + pathUint(id)}, nil)
+	return err
+}
+
+// Accept accepts an invitation and returns the user who joined the
+// workspace. It needs no UserID.
+func (s *InvitationService) Accept(ctx context.Context, input AcceptInput) (*User, error) {
+	req, err := jsonRequest(http.MethodPost, "/invitations/accept", input)
+	if err != nil {
+		return nil, err
+	}
+	var user User
+	if _, err := s.client.do(ctx, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Iterator walks the items of a collection route page by page, fetching the
+// next page, as given by the Link header of the response, once the current
+// one is used up. The current routes return whole collections in one page.
+//
+//	it := c.Tasks.List(ctx)
+//	for it.Next() {
+//		task := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	ctx    context.Context
+	client *Client
+	next   *request
+	page   []T
+	index  int
+	err    error
+}
+
+func newIterator[T any](ctx context.Context, c *Client, path string, query url.Values) *Iterator[T] {
+	return &Iterator[T]{ctx: ctx, client: c, next: &request{method: http.MethodGet, path: path, query: query}, index: -1}
+}
+
+// newSearchIterator iterates over a collection returned by another method
+// than GET.
+func newSearchIterator[T any](ctx context.Context, c *Client, req *request) *Iterator[T] {
+	return &Iterator[T]{ctx: ctx, client: c, next: req, index: -1}
+}
+
+// failedIterator returns an iterator that fails with err without sending a
+// request.
+func failedIterator[T any](err error) *Iterator[T] {
+	return &Iterator[T]{err: err, index: -1}
+}
+
+// Next advances to the next item, fetching a page if needed. It returns false
+// at the end of the collection or on error.
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.index++
+	for it.index >= len(it.page) {
+		if it.next == nil {
+			return false
+		}
+		var page []T
+		header, err := it.client.do(it.ctx, it.next, &page)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.index = page, 0
+
+		link := nextLink(header)
+		if link == "" {
+			it.next = nil
+			continue
+		}
+		// Links may be relative to the page they came from.
+		current, err := url.Parse(it.client.BaseURL + it.next.path)
+		if err == nil && !strings.HasPrefix(it.next.path, "/") {
+			current, err = url.Parse(it.next.path)
+		}
+		var target *url.URL
+		if err == nil {
+			target, err = current.Parse(link)
+		}
+		if err != nil {
+			it.err = fmt.Errorf("client: invalid next page link %q", link)
+			return false
+		}
+		it.next = &request{method: http.MethodGet, path: target.String()}
+	}
+	return true
+}
+
+// Value returns the current item.
+func (it *Iterator[T]) Value() T {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All returns the remaining items.
+func (it *Iterator[T]) All() ([]T, error) {
+	items := []T{}
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	return items, it.Err()
+}
+
+// nextLink returns the URL of the rel="next" link of a response.
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				name, rel, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if ok && strings.EqualFold(name, "rel") && strings.Trim(rel, `"`) == "next" {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ProjectService calls the /projects routes.
+type ProjectService struct {
+	client *Client
+}
+
+// List returns all projects.
+func (s *ProjectService) List(ctx context.Context) *Iterator[Project] {
+	return newIterator[Project](ctx, s.client, "/projects/", nil)
+}
+
+// Get returns a project.
+func (s *ProjectService) Get(ctx context.Context, id int) (*Project, error) {
+	var project Project
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/projects/" + pathID(id)}, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// Create creates project and returns it as stored. Only the dates of
+// StartDate and EndDate are sent.
+func (s *ProjectService) Create(ctx context.Context, project *Project) (*Project, error) {
+	req, err := jsonRequest(http.MethodPost, "/projects/", projectBody(project))
+	if err != nil {
+		return nil, err
+	}
+	var created Project
+	if _, err := s.client.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Update replaces the project with ID project.ID.
+func (s *ProjectService) Update(ctx context.Context, project *Project) (*Project, error) {
+	if project.ID == 0 {
+		return nil, errNoID
+	}
+	req, err := jsonRequest(http.MethodPut, "/projects/"+pathID(project.ID), projectBody(project))
+	if err != nil {
+		return nil, err
+	}
+	var updated Project
+	if _, err := s.client.do(ctx, req, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func projectBody(project *Project) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        project.Name,
+		"description": project.Description,
+		"start_date":  project.StartDate.Format(dateLayout),
+		"end_date":    project.EndDate.Format(dateLayout),
+		"manager_id":  project.ManagerID,
+	}
+}
+
+// Delete deletes a project.
+func (s *ProjectService) Delete(ctx context.Context, id int) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: "/projects/" + pathID(id)}, nil)
+	return err
+}
+
+// Tasks returns the tasks of a project.
+func (s *ProjectService) Tasks(ctx context.Context, id int) *Iterator[Task] {
+	return newIterator[Task](ctx, s.client, "/projects/"+pathID(id)+"/tasks", nil)
+}
+
+// ProjectQuery selects projects by title or manager; set one of the fields.
+type ProjectQuery struct {
+	Title     string
+	ManagerID uint
+}
+
+// Search returns the projects matching query.
+func (s *ProjectService) Search(ctx context.Context, query ProjectQuery) *Iterator[Project] {
+	values := url.Values{}
+	switch {
+	case query.Title != "":
+		values.Set("title", query.Title)
+	case query.ManagerID != 0:
+		values.Set("manager", pathUint(query.ManagerID))
+	default:
+		return failedIterator[Project](errors.New("client: ProjectQuery needs a title or a manager ID"))
+	}
+	return newIterator[Project](ctx, s.client, "/projects/search", values)
+}
+
+// Export returns the tasks of a project as a file in format (FormatCSV,
+// FormatNDJSON or FormatXLSX). The caller closes it.
+func (s *ProjectService) Export(ctx context.Context, id int, format string) (io.ReadCloser, error) {
+	return s.client.download(ctx, &request{
+		method: http.MethodGet,
+		path:   "/projects/" + pathID(id) + "/export",
+		query:  url.Values{"format": {format}},
+	})
+}
+
+// Archive returns a zip archive of a project, its tasks, recurring tasks and
+// the users involved. The caller closes it.
+func (s *ProjectService) Archive(ctx context.Context, id int) (io.ReadCloser, error) {
+	return s.client.download(ctx, &request{method: http.MethodGet, path: "/projects/" + pathID(id) + "/archive"})
+}
+
+// RestoreArchive restores a project archive as a new project.
+func (s *ProjectService) RestoreArchive(ctx context.Context, archive io.Reader) (*ArchiveRestore, error) {
+	data, err := io.ReadAll(archive)
+	if err != nil {
+		return nil, err
+	}
+	var result ArchiveRestore
+	req := &request{method: http.MethodPost, path: "/projects/import-archive", body: data, contentType: "application/zip"}
+	if _, err := s.client.do(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Calendar returns the iCalendar feed of a project and its tasks. The caller
+// closes it.
+func (s *ProjectService) Calendar(ctx context.Context, id int, opts CalendarOptions) (io.ReadCloser, error) {
+	return calendar(ctx, s.client, "/projects/"+pathID(id), opts)
+}
+
+// FeedTokens returns the calendar feed tokens of a project.
+func (s *ProjectService) FeedTokens(ctx context.Context, id int) ([]FeedToken, error) {
+	return feedTokens(ctx, s.client, "/projects/"+pathID(id))
+}
+
+// CreateFeedToken creates a calendar feed token for a project.
+func (s *ProjectService) CreateFeedToken(ctx context.Context, id int, name string) (*NewFeedToken, error) {
+	return createFeedToken(ctx, s.client, "/projects/"+pathID(id), name)
+}
+
+// RevokeFeedToken revokes a calendar feed token of a project.
+func (s *ProjectService) RevokeFeedToken(ctx context.Context, id int, tokenID uint) error {
+	return revokeFeedToken(ctx, s.client, "/projects/"+pathID(id), tokenID)
+}
+
+// Members returns the members of a project. Tasks of a project can only be
+// assigned to its members.
+func (s *ProjectService) Members(ctx context.Context, id int) ([]ProjectMemberInfo, error) {
+	var members []ProjectMemberInfo
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/projects/" + pathID(id) + "/members"}, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddMember adds a user of the workspace to a project with a role, such as
+// ProjectContributor, or changes the role of a member.
+func (s *ProjectService) AddMember(ctx context.Context, id int, userID uint, role string) (*ProjectMember, error) {
+	req, err := jsonRequest(http.MethodPost, "/projects/"+pathID(id)+"/members", map[string]interface{}{"user_id": userID, "role": role})
+	if err != nil {
+		return nil, err
+	}
+	var member ProjectMember
+	if _, err := s.client.do(ctx, req, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// AddTeam adds the members of a team to a project with a role, and returns
+// the memberships it created.
+func (s *ProjectService) AddTeam(ctx context.Context, id int, teamID uint, role string) ([]ProjectMember, error) {
+	req, err := jsonRequest(http.MethodPost, "/projects/"+pathID(id)+"/teams", map[string]interface{}{"team_id": teamID, "role": role})
+	if err != nil {
+		return nil, err
+	}
+	var added []ProjectMember
+	if _, err := s.client.do(ctx, req, &added); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// RemoveMember removes a user from a project. The last owner, and members
+// with tasks of the project assigned, cannot be removed.
+func (s *ProjectService) RemoveMember(ctx context.Context, id int, userID uint) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: "/projects/" + pathID(id) + "/members/" + pathUint(userID)}, nil)
+	return err
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RecurringTaskService calls the /recurring-tasks routes.
+type RecurringTaskService struct {
+	client *Client
+}
+
+// List returns all recurring task templates.
+func (s *RecurringTaskService) List(ctx context.Context) *Iterator[RecurringTask] {
+	return newIterator[RecurringTask](ctx, s.client, "/recurring-tasks/", nil)
+}
+
+// Get returns a recurring task template.
+func (s *RecurringTaskService) Get(ctx context.Context, id int) (*RecurringTask, error) {
+	var template RecurringTask
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/recurring-tasks/" + pathID(id)}, &template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Create creates a recurring task template and generates its upcoming
+// occurrences.
+func (s *RecurringTaskService) Create(ctx context.Context, template *RecurringTask) (*RecurringTask, error) {
+	req, err := jsonRequest(http.MethodPost, "/recurring-tasks/", recurringTaskBody(template))
+	if err != nil {
+		return nil, err
+	}
+	var created RecurringTask
+	if _, err := s.client.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateOptions select the occurrences a template update applies to.
+type UpdateOptions struct {
+	// From, if set, changes only the occurrences from then on; earlier ones
+	// keep the old values. Otherwise the whole series changes.
+	From time.Time
+}
+
+// Update changes the template with ID template.ID. opts may be nil.
+func (s *RecurringTaskService) Update(ctx context.Context, template *RecurringTask, opts *UpdateOptions) (*RecurringTask, error) {
+	if template.ID == 0 {
+		return nil, errNoID
+	}
+	req, err := jsonRequest(http.MethodPut, "/recurring-tasks/"+pathID(template.ID), recurringTaskBody(template))
+	if err != nil {
+		return nil, err
+	}
+	req.query = url.Values{"scope": {"all"}}
+	if opts != nil && !opts.From.IsZero() {
+		req.query = url.Values{"scope": {"future"}, "from": {opts.From.Format(time.RFC3339)}}
+	}
+	var updated RecurringTask
+	if _, err := s.client.do(ctx, req, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func recurringTaskBody(template *RecurringTask) map[string]interface{} {
+	body := map[string]interface{}{
+		"title":             template.Title,
+		"description":       template.Description,
+		"priority":          template.Priority,
+		"assignee_id":       template.AssigneeID,
+		"project_id":        template.ProjectID,
+		"rrule":             template.RRule,
+		"timezone":          template.Timezone,
+		"due_after_minutes": template.DueAfterMinutes,
+	}
+	if !template.StartsAt.IsZero() {
+		body["starts_at"] = template.StartsAt.Format(time.RFC3339)
+	}
+	return body
+}
+
+// Delete deletes a template and its upcoming occurrences that have not been
+// started.
+func (s *RecurringTaskService) Delete(ctx context.Context, id int) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: "/recurring-tasks/" + pathID(id)}, nil)
+	return err
+}
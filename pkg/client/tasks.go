@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TaskService calls the /tasks routes.
+type TaskService struct {
+	client *Client
+}
+
+// List returns all tasks.
+func (s *TaskService) List(ctx context.Context) *Iterator[Task] {
+	return newIterator[Task](ctx, s.client, "/tasks/", nil)
+}
+
+// Overdue returns the unfinished tasks whose due date has passed.
+func (s *TaskService) Overdue(ctx context.Context) *Iterator[Task] {
+	return newIterator[Task](ctx, s.client, "/tasks/overdue", nil)
+}
+
+// Get returns a task.
+func (s *TaskService) Get(ctx context.Context, id int) (*Task, error) {
+	var task Task
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/tasks/" + pathID(id)}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Create creates task and returns it as stored. Only the dates of CreatedAt
+// and CompletedAt are sent; DueDate is interpreted in DueTimezone when set.
+func (s *TaskService) Create(ctx context.Context, task *Task) (*Task, error) {
+	req, err := jsonRequest(http.MethodPost, "/tasks/", taskBody(task))
+	if err != nil {
+		return nil, err
+	}
+	var created Task
+	if _, err := s.client.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Update replaces the task with ID task.ID.
+func (s *TaskService) Update(ctx context.Context, task *Task) (*Task, error) {
+	if task.ID == 0 {
+		return nil, errNoID
+	}
+	req, err := jsonRequest(http.MethodPut, "/tasks/"+pathID(task.ID), taskBody(task))
+	if err != nil {
+		return nil, err
+	}
+	var updated Task
+	if _, err := s.client.do(ctx, req, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func taskBody(task *Task) map[string]interface{} {
+	body := map[string]interface{}{
+		"title":        task.Title,
+		"description":  task.Description,
+		"priority":     task.Priority,
+		"status":       task.Status,
+		"assignee_id":  task.AssigneeID,
+		"project_id":   task.ProjectID,
+		"created_at":   task.CreatedAt.Format(dateLayout),
+		"completed_at": task.CompletedAt.Format(dateLayout),
+	}
+	if task.DueDate != nil {
+		body["due_date"] = task.DueDate.Format(time.RFC3339)
+		body["due_timezone"] = task.DueTimezone
+	}
+	return body
+}
+
+// TaskQuery selects tasks by one field. Set one of the fields; if several
+// are set, the first in field order is used.
+type TaskQuery struct {
+	Title      string
+	Status     string
+	Priority   string
+	AssigneeID uint
+	ProjectID  uint
+}
+
+func (q TaskQuery) values() url.Values {
+	values := url.Values{}
+	switch {
+	case q.Title != "":
+		values.Set("title", q.Title)
+	case q.Status != "":
+		values.Set("status", q.Status)
+	case q.Priority != "":
+		values.Set("priority", q.Priority)
+	case q.AssigneeID != 0:
+		values.Set("assignee", pathUint(q.AssigneeID))
+	case q.ProjectID != 0:
+		values.Set("project", pathUint(q.ProjectID))
+	}
+	return values
+}
+
+// Search returns the tasks matching query.
+//
+// The API serves task search on DELETE /tasks/{id} with a query, ignoring
+// the ID, and that route never deletes a task, so there is no method to
+// delete tasks.
+func (s *TaskService) Search(ctx context.Context, query TaskQuery) *Iterator[Task] {
+	values := query.values()
+	if len(values) == 0 {
+		return failedIterator[Task](errors.New("client: TaskQuery needs a field to search by"))
+	}
+	return newSearchIterator[Task](ctx, s.client, &request{method: http.MethodDelete, path: "/tasks/0", query: values})
+}
+
+// Commits returns the commits that referenced a task, newest first.
+func (s *TaskService) Commits(ctx context.Context, id int) *Iterator[TaskCommit] {
+	return newIterator[TaskCommit](ctx, s.client, "/tasks/"+pathID(id)+"/commits", nil)
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// TeamService calls the /teams routes. Only workspace admins may change
+// teams.
+type TeamService struct {
+	client *Client
+}
+
+// List returns the teams of the workspace.
+func (s *TeamService) List(ctx context.Context) ([]Team, error) {
+	var teams []Team
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/teams/"}, &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// Create creates a team.
+func (s *TeamService) Create(ctx context.Context, name string) (*Team, error) {
+	req, err := jsonRequest(http.MethodPost, "/teams/", map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+	var created Team
+	if _, err := s.client.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Delete deletes a team. Its members stay members of the projects the team
+// was added to.
+func (s *TeamService) Delete(ctx context.Context, id uint) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: "/teams/" + pathUint(id)}, nil)
+	return err
+}
+
+// Members returns the members of a team.
+func (s *TeamService) Members(ctx context.Context, id uint) ([]TeamMemberInfo, error) {
+	var members []TeamMemberInfo
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: teamMembersPath(id)}, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddMember adds a user of the workspace to a team.
+func (s *TeamService) AddMember(ctx context.Context, id, userID uint) (*TeamMember, error) {
+	req, err := jsonRequest(http.MethodPost, teamMembersPath(id), map[string]interface{}{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	var member TeamMember
+	if _, err := s.client.do(ctx, req, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// RemoveMember removes a user from a team.
+func (s *TeamService) RemoveMember(ctx context.Context, id, userID uint) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: teamMembersPath(id) + "/" + pathUint(userID)}, nil)
+	return err
+}
+
+func teamMembersPath(id uint) string {
+	return "/teams/" + pathUint(id) + "/members"
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TokenService calls the /me/tokens routes, which manage the personal API
+// tokens of the caller. They need a session; API tokens cannot manage API
+// tokens.
+type TokenService struct {
+	client *Client
+}
+
+// TokenInput is the API token to create. Scopes are resource:level, such
+// as tasks:read; ExpiresAt, when nil, is left to the server's default.
+type TokenInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedToken is a created API token with the token itself, which is only
+// ever returned once.
+type CreatedToken struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// List returns the tokens of the caller that are not revoked, expired ones
+// included.
+func (s *TokenService) List(ctx context.Context) ([]APIToken, error) {
+	var tokens []APIToken
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/me/tokens"}, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Create creates an API token. Use it as the client's Token.
+func (s *TokenService) Create(ctx context.Context, input TokenInput) (*CreatedToken, error) {
+	req, err := jsonRequest(http.MethodPost, "/me/tokens", input)
+	if err != nil {
+		return nil, err
+	}
+	var created CreatedToken
+	if _, err := s.client.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Revoke revokes a token of the caller.
+func (s *TokenService) Revoke(ctx context.Context, id uint) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: "/me/tokens/" + pathUint(id)}, nil)
+	return err
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"time"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+)
+
+// The API models. They are aliases, so values can be passed to and from
+// packages of this module that use the models directly.
+type (
+	User          = models.User
+	Project       = models.Project
+	Task          = models.Task
+	RecurringTask = models.RecurringTask
+	TaskCommit    = models.TaskCommit
+	FeedToken     = models.FeedToken
+	ImportJob     = models.ImportJob
+	JobRun        = models.JobRun
+	Workspace     = models.Workspace
+	Membership    = models.Membership
+	Team          = models.Team
+	TeamMember    = models.TeamMember
+	ProjectMember = models.ProjectMember
+	Invitation    = models.Invitation
+	APIToken      = models.APIToken
+)
+
+// UserWorkspace is a workspace of the caller, with the caller's role in it.
+type UserWorkspace struct {
+	Workspace
+	Role string `json:"role"`
+}
+
+// Member is a member of a workspace.
+type Member struct {
+	Membership
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Workspace roles.
+const (
+	WorkspaceAdmin  = models.WorkspaceAdmin
+	WorkspaceMember = models.WorkspaceMember
+)
+
+// ProjectMemberInfo is a member of a project.
+type ProjectMemberInfo struct {
+	ProjectMember
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// TeamMemberInfo is a member of a team.
+type TeamMemberInfo struct {
+	TeamMember
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Project roles.
+const (
+	ProjectOwner       = models.ProjectOwner
+	ProjectMaintainer  = models.ProjectMaintainer
+	ProjectContributor = models.ProjectContributor
+	ProjectViewer      = models.ProjectViewer
+)
+
+// Task statuses and priorities.
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
+
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+// dateLayout is the format of the dates of tasks and projects.
+const dateLayout = "2006-01-02"
+
+// NewFeedToken is a created calendar feed token. Token is only returned
+// when the token is created.
+type NewFeedToken struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+}
+
+// ArchiveRestore is the result of restoring a project archive. The ID maps
+// take the IDs in the archive to those of the restored entities.
+type ArchiveRestore struct {
+	Project          Project     `json:"project"`
+	UserIDs          map[int]int `json:"user_ids"`
+	TaskIDs          map[int]int `json:"task_ids"`
+	RecurringTaskIDs map[int]int `json:"recurring_task_ids"`
+	UsersLinked      int         `json:"users_linked"`
+	UsersCreated     int         `json:"users_created"`
+	UsersReassigned  int         `json:"users_reassigned"`
+}
+
+// ImportReport is the result of a CSV or NDJSON import.
+type ImportReport struct {
+	Entity      string     `json:"entity"`
+	Format      string     `json:"format"`
+	DryRun      bool       `json:"dry_run"`
+	TotalRows   int        `json:"total_rows"`
+	ValidRows   int        `json:"valid_rows"`
+	InvalidRows int        `json:"invalid_rows"`
+	CreatedRows int        `json:"created_rows"`
+	Errors      []RowError `json:"errors"`
+	Job         *ImportJob `json:"job,omitempty"`
+}
+
+// RowError lists the validation errors of one imported row.
+type RowError struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+// TrackerImportReport is the result of a GitHub or Jira import. Unmapped
+// counts, per field, the values that had no equivalent.
+type TrackerImportReport struct {
+	Source                string                    `json:"source"`
+	TotalIssues           int                       `json:"total_issues"`
+	Created               int                       `json:"created"`
+	Updated               int                       `json:"updated"`
+	Skipped               int                       `json:"skipped"`
+	SkippedPullRequests   int                       `json:"skipped_pull_requests,omitempty"`
+	ProjectsCreated       int                       `json:"projects_created"`
+	TruncatedDescriptions int                       `json:"truncated_descriptions"`
+	Unmapped              map[string]map[string]int `json:"unmapped"`
+	Errors                []IssueError              `json:"errors"`
+}
+
+// IssueError is an issue that could not be imported.
+type IssueError struct {
+	ExternalID string `json:"external_id"`
+	Error      string `json:"error"`
+}
+
+// PushReport is the result of a push webhook.
+type PushReport struct {
+	Repository     string         `json:"repository"`
+	Commits        int            `json:"commits"`
+	Linked         []LinkedCommit `json:"linked"`
+	CompletedTasks []int          `json:"completed_tasks"`
+	UnknownTasks   []int          `json:"unknown_tasks"`
+	// Ignored is set instead when the event is not a push.
+	Ignored string `json:"event,omitempty"`
+}
+
+// LinkedCommit is a commit linked to a task by a push.
+type LinkedCommit struct {
+	TaskID int    `json:"task_id"`
+	SHA    string `json:"sha"`
+	Closes bool   `json:"closes"`
+}
+
+// JobStatus describes a background job.
+type JobStatus struct {
+	Name      string     `json:"name"`
+	Schedule  string     `json:"schedule"`
+	Leader    bool       `json:"leader"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	LastRun   *JobRun    `json:"last_run,omitempty"`
+	Runs      []JobRun   `json:"runs"`
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// UserService calls the /users routes.
+type UserService struct {
+	client *Client
+}
+
+// List returns all users.
+func (s *UserService) List(ctx context.Context) *Iterator[User] {
+	return newIterator[User](ctx, s.client, "/users/", nil)
+}
+
+// Get returns a user.
+func (s *UserService) Get(ctx context.Context, id uint) (*User, error) {
+	var user User
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/users/" + pathUint(id)}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Create creates user and returns it as stored.
+func (s *UserService) Create(ctx context.Context, user *User) (*User, error) {
+	req, err := jsonRequest(http.MethodPost, "/users/", userBody(user))
+	if err != nil {
+		return nil, err
+	}
+	var created User
+	if _, err := s.client.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Update replaces the user with ID user.ID. The registration date cannot
+// be changed.
+func (s *UserService) Update(ctx context.Context, user *User) (*User, error) {
+	if user.ID == 0 {
+		return nil, errNoID
+	}
+	req, err := jsonRequest(http.MethodPut, "/users/"+pathUint(user.ID), userBody(user))
+	if err != nil {
+		return nil, err
+	}
+	var updated User
+	if _, err := s.client.do(ctx, req, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func userBody(user *User) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  user.Name,
+		"email": user.Email,
+		"role":  user.Role,
+	}
+}
+
+// Delete deletes a user.
+func (s *UserService) Delete(ctx context.Context, id uint) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: "/users/" + pathUint(id)}, nil)
+	return err
+}
+
+// UserTasksOptions filter the tasks of a user.
+type UserTasksOptions struct {
+	// DueBefore, if set, limits the tasks to those due before it.
+	DueBefore time.Time
+}
+
+// Tasks returns the tasks assigned to a user. opts may be nil.
+func (s *UserService) Tasks(ctx context.Context, id uint, opts *UserTasksOptions) *Iterator[Task] {
+	query := url.Values{}
+	if opts != nil && !opts.DueBefore.IsZero() {
+		query.Set("due_before", opts.DueBefore.Format(time.RFC3339))
+	}
+	return newIterator[Task](ctx, s.client, "/users/"+pathUint(id)+"/tasks", query)
+}
+
+// UserQuery selects users by name or email; set one of the fields.
+type UserQuery struct {
+	Name  string
+	Email string
+}
+
+// Search returns the users matching query.
+func (s *UserService) Search(ctx context.Context, query UserQuery) *Iterator[User] {
+	values := url.Values{}
+	switch {
+	case query.Name != "":
+		values.Set("name", query.Name)
+	case query.Email != "":
+		values.Set("email", query.Email)
+	default:
+		return failedIterator[User](errors.New("client: UserQuery needs a name or an email"))
+	}
+	return newIterator[User](ctx, s.client, "/users/search", values)
+}
+
+// Calendar returns the iCalendar feed of a user: their tasks and the
+// projects they manage. The caller closes the feed.
+func (s *UserService) Calendar(ctx context.Context, id uint, opts CalendarOptions) (io.ReadCloser, error) {
+	return calendar(ctx, s.client, "/users/"+pathUint(id), opts)
+}
+
+// FeedTokens returns the calendar feed tokens of a user.
+func (s *UserService) FeedTokens(ctx context.Context, id uint) ([]FeedToken, error) {
+	return feedTokens(ctx, s.client, "/users/"+pathUint(id))
+}
+
+// CreateFeedToken creates a calendar feed token for a user.
+func (s *UserService) CreateFeedToken(ctx context.Context, id uint, name string) (*NewFeedToken, error) {
+	return createFeedToken(ctx, s.client, "/users/"+pathUint(id), name)
+}
+
+// RevokeFeedToken revokes a calendar feed token of a user.
+func (s *UserService) RevokeFeedToken(ctx context.Context, id, tokenID uint) error {
+	return revokeFeedToken(ctx, s.client, "/users/"+pathUint(id), tokenID)
+}
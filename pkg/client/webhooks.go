@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+)
+
+// WebhookService calls the /webhooks routes.
+type WebhookService struct {
+	client *Client
+}
+
+// PushEvent is a GitHub or GitLab push webhook delivery.
+type PushEvent struct {
+	Payload []byte
+	// Event is the X-GitHub-Event header, "push" by default.
+	Event string
+	// Secret, if set, signs the payload as GitHub does.
+	Secret string
+	// NoTransition keeps the tasks closed by the commits open.
+	NoTransition bool
+	// WorkspaceID is the workspace of the referenced tasks, the default
+	// workspace when 0. Client.WorkspaceID does not apply, as hooks cannot
+	// send headers.
+	WorkspaceID uint
+}
+
+// Push delivers a push webhook, which links the pushed commits to the tasks
+// they reference.
+func (s *WebhookService) Push(ctx context.Context, event PushEvent) (*PushReport, error) {
+	req := &request{method: http.MethodPost, path: "/webhooks/push", body: event.Payload, contentType: "application/json", header: http.Header{}}
+	name := event.Event
+	if name == "" {
+		name = "push"
+	}
+	req.header.Set("X-GitHub-Event", name)
+	if event.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(event.Secret))
+		mac.Write(event.Payload)
+		req.header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	req.query = url.Values{}
+	if event.NoTransition {
+		req.query.Set("transition", "false")
+	}
+	if event.WorkspaceID != 0 {
+		req.query.Set("workspace", pathUint(event.WorkspaceID))
+	}
+
+	var report PushReport
+	if _, err := s.client.do(ctx, req, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// WorkspaceService calls the /workspaces routes. They act on the workspace
+// in their path, not on Client.WorkspaceID.
+type WorkspaceService struct {
+	client *Client
+}
+
+// List returns the workspaces the caller is a member of.
+func (s *WorkspaceService) List(ctx context.Context) ([]UserWorkspace, error) {
+	var workspaces []UserWorkspace
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: "/workspaces/"}, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// Create creates a workspace administered by the caller. The slug may only
+// contain lower-case letters, digits and hyphens.
+func (s *WorkspaceService) Create(ctx context.Context, name, slug string) (*Workspace, error) {
+	req, err := jsonRequest(http.MethodPost, "/workspaces/", map[string]string{"name": name, "slug": slug})
+	if err != nil {
+		return nil, err
+	}
+	var created Workspace
+	if _, err := s.client.do(ctx, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Members returns the members of a workspace.
+func (s *WorkspaceService) Members(ctx context.Context, workspaceID uint) ([]Member, error) {
+	var members []Member
+	if _, err := s.client.do(ctx, &request{method: http.MethodGet, path: membersPath(workspaceID)}, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddMember invites an email address to a workspace with a role,
+// WorkspaceAdmin or WorkspaceMember. The user joins once they accept the
+// invitation. Only admins of the workspace may add members.
+func (s *WorkspaceService) AddMember(ctx context.Context, workspaceID uint, email, role string) (*Invitation, error) {
+	req, err := jsonRequest(http.MethodPost, membersPath(workspaceID), map[string]string{"email": email, "role": role})
+	if err != nil {
+		return nil, err
+	}
+	var invitation Invitation
+	if _, err := s.client.do(ctx, req, &invitation); err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// UpdateMember changes the role of a member. The last admin of a workspace
+// cannot be demoted.
+func (s *WorkspaceService) UpdateMember(ctx context.Context, workspaceID, userID uint, role string) (*Membership, error) {
+	req, err := jsonRequest(http.MethodPut, membersPath(workspaceID)+"/"+pathUint(userID), map[string]string{"role": role})
+	if err != nil {
+		return nil, err
+	}
+	var membership Membership
+	if _, err := s.client.do(ctx, req, &membership); err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// RemoveMember removes a member from a workspace. The last admin, and
+// members with assigned tasks, cannot be removed.
+func (s *WorkspaceService) RemoveMember(ctx context.Context, workspaceID, userID uint) error {
+	_, err := s.client.do(ctx, &request{method: http.MethodDelete, path: membersPath(workspaceID) + "/" + pathUint(userID)}, nil)
+	return err
+}
+
+func membersPath(workspaceID uint) string {
+	return "/workspaces/" + pathUint(workspaceID) + "/members"
+}
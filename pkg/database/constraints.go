@@ -0,0 +1,76 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// foreignKeys back the user_exists and project_exists validation rules, so
+// that rows referenced by a request cannot be deleted between the check and
+// the write. The names are mapped back to the rules by the validation
+// package.
+//
+// The _member and _workspace keys also keep workspaces apart: a task can
+// only belong to a project and be assigned to a member of its own
+// workspace. The _project keys back the project_member rule: tasks are
+// assigned to members of their project, who cannot leave it while they are.
+var foreignKeys = []struct {
+	table, name, columns, references string
+}{
+	{"projects", "fk_projects_manager", "manager_id", "users (id)"},
+	{"tasks", "fk_tasks_assignee", "assignee_id", "users (id)"},
+	{"tasks", "fk_tasks_project", "project_id", "projects (id)"},
+	{"recurring_tasks", "fk_recurring_tasks_assignee", "assignee_id", "users (id)"},
+	{"recurring_tasks", "fk_recurring_tasks_project", "project_id", "projects (id)"},
+
+	{"workspace_members", "fk_workspace_members_workspace", "workspace_id", "workspaces (id) ON DELETE CASCADE"},
+	{"workspace_members", "fk_workspace_members_user", "user_id", "users (id) ON DELETE CASCADE"},
+	{"projects", "fk_projects_workspace", "workspace_id", "workspaces (id)"},
+	{"projects", "fk_projects_manager_member", "workspace_id, manager_id", "workspace_members (workspace_id, user_id)"},
+	{"tasks", "fk_tasks_workspace", "workspace_id", "workspaces (id)"},
+	{"tasks", "fk_tasks_assignee_member", "workspace_id, assignee_id", "workspace_members (workspace_id, user_id)"},
+	{"tasks", "fk_tasks_project_workspace", "workspace_id, project_id", "projects (workspace_id, id)"},
+	{"recurring_tasks", "fk_recurring_tasks_workspace", "workspace_id", "workspaces (id)"},
+	{"recurring_tasks", "fk_recurring_tasks_assignee_member", "workspace_id, assignee_id", "workspace_members (workspace_id, user_id)"},
+	{"recurring_tasks", "fk_recurring_tasks_project_workspace", "workspace_id, project_id", "projects (workspace_id, id)"},
+	{"import_jobs", "fk_import_jobs_workspace", "workspace_id", "workspaces (id)"},
+	{"external_refs", "fk_external_refs_workspace", "workspace_id", "workspaces (id)"},
+
+	{"teams", "fk_teams_workspace", "workspace_id", "workspaces (id)"},
+	{"team_members", "fk_team_members_team", "workspace_id, team_id", "teams (workspace_id, id) ON DELETE CASCADE"},
+	{"team_members", "fk_team_members_member", "workspace_id, user_id", "workspace_members (workspace_id, user_id) ON DELETE CASCADE"},
+	{"project_members", "fk_project_members_project", "workspace_id, project_id", "projects (workspace_id, id) ON DELETE CASCADE"},
+	{"project_members", "fk_project_members_member", "workspace_id, user_id", "workspace_members (workspace_id, user_id) ON DELETE CASCADE"},
+	{"tasks", "fk_tasks_assignee_project", "project_id, assignee_id", "project_members (project_id, user_id)"},
+	{"recurring_tasks", "fk_recurring_tasks_assignee_project", "project_id, assignee_id", "project_members (project_id, user_id)"},
+
+	{"invitations", "fk_invitations_workspace", "workspace_id", "workspaces (id) ON DELETE CASCADE"},
+	{"invitations", "fk_invitations_project", "workspace_id, project_id", "projects (workspace_id, id) ON DELETE CASCADE"},
+	{"feed_tokens", "fk_feed_tokens_workspace", "workspace_id", "workspaces (id) ON DELETE CASCADE"},
+	{"credentials", "fk_credentials_user", "user_id", "users (id) ON DELETE CASCADE"},
+	{"sessions", "fk_sessions_user", "user_id", "users (id) ON DELETE CASCADE"},
+	{"password_resets", "fk_password_resets_user", "user_id", "users (id) ON DELETE CASCADE"},
+	{"auth_events", "fk_auth_events_user", "user_id", "users (id) ON DELETE SET NULL"},
+	{"api_tokens", "fk_api_tokens_user", "user_id", "users (id) ON DELETE CASCADE"},
+}
+
+// createForeignKeys adds the missing foreign keys. They are NOT VALID: rows
+// written before they existed are not checked, only new and changed ones.
+func createForeignKeys(db *gorm.DB) error {
+	for _, fk := range foreignKeys {
+		var count int64
+		if err := db.Raw("SELECT COUNT(*) FROM pg_constraint WHERE conname = ?", fk.name).Scan(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		statement := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s NOT VALID",
+			fk.table, fk.name, fk.columns, fk.references)
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("create %s: %w", fk.name, err)
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
     "os"
     "fmt"
 	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
 
     _ "github.com/lib/pq"
 )
@@ -25,11 +26,43 @@ func SetupDatabase() {
         log.Fatal(err)
     }
 
-    err = db.AutoMigrate(&models.User{}, &models.Task{}, &models.Project{})
+    err = db.AutoMigrate(&models.User{}, &models.Task{}, &models.Project{}, &models.TaskReminder{}, &models.RecurringTask{}, &models.JobRun{}, &models.FeedToken{}, &models.ImportJob{}, &models.ExternalRef{}, &models.TaskCommit{}, &models.Workspace{}, &models.Membership{}, &models.Team{}, &models.TeamMember{}, &models.ProjectMember{}, &models.Invitation{}, &models.Credential{}, &models.Session{}, &models.PasswordReset{}, &models.AuthEvent{}, &models.OIDCLogin{}, &models.APIToken{}, &models.RateLimitBucket{})
     if err != nil {
         log.Fatal(err)
     }
 
+    if err := migrateWorkspaces(db); err != nil {
+        log.Fatal(err)
+    }
+
+    if err := migrateProjectMembers(db); err != nil {
+        log.Fatal(err)
+    }
+
+    if err := createInvitationIndex(db); err != nil {
+        log.Fatal(err)
+    }
+
+    if err := createForeignKeys(db); err != nil {
+        log.Fatal(err)
+    }
+
+    if err := createTaskChangeTrigger(db); err != nil {
+        log.Fatal(err)
+    }
+
+    if err := createProjectOwnerTrigger(db); err != nil {
+        log.Fatal(err)
+    }
+
+    if err := rowLevelSecurity(db); err != nil {
+        log.Fatal(err)
+    }
+
+    if err := db.Use(workspace.Plugin{}); err != nil {
+        log.Fatal(err)
+    }
+
     log.Println("Database migrated successfully")
 }
 
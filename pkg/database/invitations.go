@@ -0,0 +1,18 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// createInvitationIndex allows one pending invitation per address and
+// workspace. Expired invitations still count: they are resent instead.
+func createInvitationIndex(db *gorm.DB) error {
+	err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_pending ON invitations (workspace_id, email)
+		WHERE accepted_at IS NULL AND revoked_at IS NULL`).Error
+	if err != nil {
+		return fmt.Errorf("create idx_invitations_pending: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"gorm.io/gorm"
+)
+
+// migrateProjectMembers gives the projects that predate memberships their
+// members: the manager as owner, and the assignees of their tasks and
+// recurring tasks as contributors. Projects created since get their owner
+// from the project_owner trigger, so only projects without any member are
+// filled in, and members removed later are not added back.
+func migrateProjectMembers(db *gorm.DB) error {
+	err := db.Exec(`INSERT INTO project_members (project_id, user_id, role, workspace_id, created_at)
+		SELECT DISTINCT ON (project_id, user_id) project_id, user_id, role, workspace_id, now() FROM (
+			SELECT id AS project_id, manager_id AS user_id, ? AS role, 0 AS rank, workspace_id FROM projects
+			UNION ALL SELECT project_id, assignee_id, ?, 1, workspace_id FROM tasks
+			UNION ALL SELECT project_id, assignee_id, ?, 1, workspace_id FROM recurring_tasks
+		) candidates
+		WHERE NOT EXISTS (SELECT 1 FROM project_members WHERE project_members.project_id = candidates.project_id)
+			AND EXISTS (SELECT 1 FROM projects WHERE projects.id = candidates.project_id AND projects.workspace_id = candidates.workspace_id)
+			AND EXISTS (SELECT 1 FROM workspace_members WHERE workspace_members.workspace_id = candidates.workspace_id AND workspace_members.user_id = candidates.user_id)
+		ORDER BY project_id, user_id, rank
+		ON CONFLICT DO NOTHING`,
+		models.ProjectOwner, models.ProjectContributor, models.ProjectContributor).Error
+	if err != nil {
+		return fmt.Errorf("add project members: %w", err)
+	}
+	// The target of the foreign key that keeps team members in their team's
+	// workspace.
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_teams_workspace ON teams (workspace_id, id)").Error; err != nil {
+		return fmt.Errorf("create idx_teams_workspace: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/togzhanzhakhani/projects/internal/changes"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"gorm.io/gorm"
+)
+
+// taskChangeFunction notifies changes.Channel of each changed task with a
+// changes.TaskChange payload.
+const taskChangeFunction = `
+CREATE OR REPLACE FUNCTION notify_task_change() RETURNS trigger AS $$
+DECLARE
+	task tasks;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		task := OLD;
+	ELSE
+		task := NEW;
+	END IF;
+	PERFORM pg_notify('%s', json_build_object(
+		'op', lower(TG_OP),
+		'id', task.id,
+		'project_id', task.project_id,
+		'assignee_id', task.assignee_id,
+		'workspace_id', task.workspace_id
+	)::text);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`
+
+// createTaskChangeTrigger installs the trigger behind the task change feed.
+// The function is replaced on every start, the trigger only created once.
+func createTaskChangeTrigger(db *gorm.DB) error {
+	if err := db.Exec(fmt.Sprintf(taskChangeFunction, changes.Channel)).Error; err != nil {
+		return fmt.Errorf("create notify_task_change: %w", err)
+	}
+	if exists, err := hasTrigger(db, "task_changes"); err != nil || exists {
+		return err
+	}
+	err := db.Exec("CREATE TRIGGER task_changes AFTER INSERT OR UPDATE OR DELETE ON tasks FOR EACH ROW EXECUTE PROCEDURE notify_task_change()").Error
+	if err != nil {
+		return fmt.Errorf("create task_changes: %w", err)
+	}
+	return nil
+}
+
+// projectOwnerFunction makes the manager of a created project, or the new
+// manager of an updated one, an owner of it.
+const projectOwnerFunction = `
+CREATE OR REPLACE FUNCTION add_project_owner() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'UPDATE' AND OLD.manager_id = NEW.manager_id THEN
+		RETURN NULL;
+	END IF;
+	INSERT INTO project_members (project_id, user_id, role, workspace_id, created_at)
+	VALUES (NEW.id, NEW.manager_id, '%s', NEW.workspace_id, now())
+	ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`
+
+// createProjectOwnerTrigger installs the trigger that keeps project managers
+// owners of their projects, whichever code path wrote the project.
+func createProjectOwnerTrigger(db *gorm.DB) error {
+	if err := db.Exec(fmt.Sprintf(projectOwnerFunction, models.ProjectOwner)).Error; err != nil {
+		return fmt.Errorf("create add_project_owner: %w", err)
+	}
+	if exists, err := hasTrigger(db, "project_owner"); err != nil || exists {
+		return err
+	}
+	err := db.Exec("CREATE TRIGGER project_owner AFTER INSERT OR UPDATE OF manager_id ON projects FOR EACH ROW EXECUTE PROCEDURE add_project_owner()").Error
+	if err != nil {
+		return fmt.Errorf("create project_owner: %w", err)
+	}
+	return nil
+}
+
+func hasTrigger(db *gorm.DB, name string) (bool, error) {
+	var count int64
+	if err := db.Raw("SELECT COUNT(*) FROM pg_trigger WHERE tgname = ?", name).Scan(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+// workspaceTables are the tables whose rows belong to a workspace. Their
+// workspace_id columns default to models.DefaultWorkspaceID, so rows
+// written before workspaces existed were moved into it when the column was
+// added.
+var workspaceTables = []string{"projects", "tasks", "recurring_tasks", "import_jobs", "external_refs", "workspace_members",
+	"teams", "team_members", "project_members", "invitations", "feed_tokens"}
+
+// migrateWorkspaces creates the default workspace and makes every existing
+// user a member of it. Users who were admins administer it.
+func migrateWorkspaces(db *gorm.DB) error {
+	err := db.Exec("INSERT INTO workspaces (id, name, slug, created_at) VALUES (?, 'Default', 'default', now()) ON CONFLICT DO NOTHING",
+		models.DefaultWorkspaceID).Error
+	if err != nil {
+		return fmt.Errorf("create default workspace: %w", err)
+	}
+	err = db.Exec("SELECT setval(pg_get_serial_sequence('workspaces', 'id'), GREATEST((SELECT MAX(id) FROM workspaces), 1))").Error
+	if err != nil {
+		return fmt.Errorf("reset workspaces sequence: %w", err)
+	}
+	err = db.Exec(`INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		SELECT ?, id, CASE WHEN role = 'admin' THEN ? ELSE ? END, now() FROM users
+		WHERE NOT EXISTS (SELECT 1 FROM workspace_members WHERE workspace_members.user_id = users.id)`,
+		models.DefaultWorkspaceID, models.WorkspaceAdmin, models.WorkspaceMember).Error
+	if err != nil {
+		return fmt.Errorf("add users to default workspace: %w", err)
+	}
+	if err := bootstrapAdmin(db); err != nil {
+		return err
+	}
+	// External references were unique per source before; they are per
+	// workspace now, so that two workspaces can import the same issue.
+	if err := db.Exec("DROP INDEX IF EXISTS idx_external_ref").Error; err != nil {
+		return fmt.Errorf("drop idx_external_ref: %w", err)
+	}
+	// The target of the foreign keys that keep tasks in their project's
+	// workspace.
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_workspace ON projects (workspace_id, id)").Error; err != nil {
+		return fmt.Errorf("create idx_projects_workspace: %w", err)
+	}
+	return nil
+}
+
+// bootstrapAdmin creates an admin of the default workspace with the email in
+// ADMIN_EMAIL when there are no users yet, as every other user is created
+// by a member of a workspace.
+func bootstrapAdmin(db *gorm.DB) error {
+	email := os.Getenv("ADMIN_EMAIL")
+	if email == "" {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&models.User{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		admin := models.User{Name: "Admin", Email: email, Role: "admin"}
+		if err := tx.Create(&admin).Error; err != nil {
+			return fmt.Errorf("create admin: %w", err)
+		}
+		membership := models.Membership{WorkspaceID: models.DefaultWorkspaceID, UserID: admin.ID, Role: models.WorkspaceAdmin}
+		if err := tx.Create(&membership).Error; err != nil {
+			return fmt.Errorf("create admin: %w", err)
+		}
+		return nil
+	})
+}
+
+// rowLevelSecurity turns the workspace policies on when WORKSPACE_RLS is
+// set, and off otherwise. They only restrict sessions that set
+// workspace.Setting, see workspace.RowLevelSecurity; superusers and owners
+// of the tables without FORCE are never restricted by Postgres.
+func rowLevelSecurity(db *gorm.DB) error {
+	enabled, _ := strconv.ParseBool(os.Getenv("WORKSPACE_RLS"))
+	current := fmt.Sprintf("NULLIF(current_setting('%s', true), '')", workspace.Setting)
+	for _, table := range workspaceTables {
+		if !enabled {
+			if err := db.Exec(fmt.Sprintf("ALTER TABLE %s DISABLE ROW LEVEL SECURITY", table)).Error; err != nil {
+				return fmt.Errorf("disable row level security on %s: %w", table, err)
+			}
+			continue
+		}
+		policy := fmt.Sprintf("%s IS NULL OR workspace_id = %s::bigint", current, current)
+		statements := []string{
+			fmt.Sprintf("DROP POLICY IF EXISTS workspace_isolation ON %s", table),
+			fmt.Sprintf("CREATE POLICY workspace_isolation ON %s USING (%s) WITH CHECK (%s)", table, policy, policy),
+			fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY", table),
+			fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY", table),
+		}
+		for _, statement := range statements {
+			if err := db.Exec(statement).Error; err != nil {
+				return fmt.Errorf("enable row level security on %s: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
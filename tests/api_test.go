@@ -2,23 +2,28 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
-	"errors"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/togzhanzhakhani/projects/internal/handlers"
 	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
 )
 
 type MockUserRepository struct {
 	mock.Mock
 }
 
+func (m *MockUserRepository) WithContext(ctx context.Context) repository.UserRepository {
+	return m
+}
+
 func (m *MockUserRepository) CreateUser(user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
@@ -67,6 +72,90 @@ func (m *MockUserRepository) GetTasksByUserID(userID uint) ([]models.Task, error
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
+func (m *MockUserRepository) GetTasksByUserIDDueBefore(userID uint, before time.Time) ([]models.Task, error) {
+	args := m.Called(userID, before)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+type MockTaskRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaskRepository) WithContext(ctx context.Context) repository.TaskRepository {
+	return m
+}
+
+func (m *MockTaskRepository) GetAllTasks() ([]models.Task, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetTaskByID(id uint) (*models.Task, error) {
+	args := m.Called(id)
+	if task, ok := args.Get(0).(*models.Task); ok {
+		return task, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateTask(task *models.Task) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) UpdateTask(task *models.Task) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) DeleteTask(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) SearchTasksByTitle(title string) ([]models.Task, error) {
+	args := m.Called(title)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) SearchTasksByStatus(status string) ([]models.Task, error) {
+	args := m.Called(status)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) SearchTasksByPriority(priority string) ([]models.Task, error) {
+	args := m.Called(priority)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) SearchTasksByAssignee(userID uint) ([]models.Task, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) SearchTasksByProject(projectID uint) ([]models.Task, error) {
+	args := m.Called(projectID)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetOverdueTasks(now time.Time) ([]models.Task, error) {
+	args := m.Called(now)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetTasksDueBetween(from, to time.Time) ([]models.Task, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetProject(projectID int) (*models.Project, error) {
+	args := m.Called(projectID)
+	if project, ok := args.Get(0).(*models.Project); ok {
+		return project, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func setupUserHandler(t *testing.T) (*handlers.UserHandler, *MockUserRepository) {
 	mockRepo := new(MockUserRepository)
 	handler := handlers.NewUserHandler(mockRepo)
@@ -80,8 +169,6 @@ func formatTime(t time.Time) string {
 func TestCreateUser_Success(t *testing.T) {
     handler, mockRepo := setupUserHandler(t)
 
-    mockRepo.On("FindByEmail", "johndoe@example.com").Return(nil, errors.New("not found"))
-
     userJSON := `{"name":"John Doe","email":"johndoe@example.com","role":"admin"}`
     req, err := http.NewRequest("POST", "/users", bytes.NewBuffer([]byte(userJSON)))
     if err != nil {
@@ -93,6 +180,7 @@ func TestCreateUser_Success(t *testing.T) {
 
     rr := httptest.NewRecorder()
     router := gin.Default()
+    router.Use(asMember(models.WorkspaceAdmin))
     router.POST("/users", handler.CreateUser)
     router.ServeHTTP(rr, req)
 
@@ -130,26 +218,26 @@ func TestGetUserByID(t *testing.T) {
 func TestUpdateUser(t *testing.T) {
 	handler, mockRepo := setupUserHandler(t)
 
-	userJSON := `{"name":"Jane Doe","email":"janedoe@example.com","role":"admin"}`
-	req, err := http.NewRequest("PUT", "/users/1", bytes.NewBuffer([]byte(userJSON)))
+	userJSON := `{"name":"Jane Doe","email":"johndoe@example.com","role":"admin"}`
+	req, err := http.NewRequest("PUT", "/users/5", bytes.NewBuffer([]byte(userJSON)))
 	if err != nil {
 		t.Fatal(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	mockUser := &models.User{
-		ID:    1,
+		ID:    5,
 		Name:  "John Doe",
 		Email: "johndoe@example.com",
 		Role:  "admin",
 	}
-	mockRepo.On("GetUserByID", uint(1)).Return(mockUser, nil)
+	mockRepo.On("GetUserByID", uint(5)).Return(mockUser, nil)
 
-	mockRepo.On("FindByEmail", "janedoe@example.com").Return(nil, errors.New("not found"))
 	mockRepo.On("UpdateUser", mock.AnythingOfType("*models.User")).Return(nil)
 
 	rr := httptest.NewRecorder()
 	router := gin.Default()
+	router.Use(asMember(models.WorkspaceMember))
 	router.PUT("/users/:id", handler.UpdateUser)
 	router.ServeHTTP(rr, req)
 
@@ -170,6 +258,7 @@ func TestDeleteUser(t *testing.T) {
 
 	rr := httptest.NewRecorder()
 	router := gin.Default()
+	router.Use(asMember(models.WorkspaceAdmin))
 	router.DELETE("/users/:id", handler.DeleteUser)
 	router.ServeHTTP(rr, req)
 
@@ -247,3 +336,47 @@ func TestSearchUsersByEmail(t *testing.T) {
 	expected := `[{"id":1,"name":"John Doe","email":"johndoe@example.com","registration_date":"` + formatTime(mockUsers[0].RegistrationDate) + `","role":"admin"},{"id":2,"name":"Jane Smith","email":"janesmith@example.com","registration_date":"` + formatTime(mockUsers[1].RegistrationDate) + `","role":"user"}]`
 	assert.JSONEq(t, expected, rr.Body.String(), "тело ответа не соответствует ожидаемому")
 }
+
+
+func TestGetTasksByUserIDDueBefore(t *testing.T) {
+	handler, mockRepo := setupUserHandler(t)
+
+	due := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+	mockTasks := []models.Task{{ID: 1, Title: "Report", AssigneeID: 1, DueDate: &due}}
+	mockRepo.On("GetTasksByUserIDDueBefore", uint(1), time.Date(2024, 7, 11, 0, 0, 0, 0, time.UTC)).Return(mockTasks, nil)
+
+	req, err := http.NewRequest("GET", "/users/1/tasks?due_before=2024-07-10", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.GET("/users/:id/tasks", handler.GetTasksByUserID)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTask_DueDateAfterProjectEnd(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	handler := handlers.NewTaskHandler(mockRepo)
+
+	mockRepo.On("GetProject", 5).Return(&models.Project{ID: 5, EndDate: time.Date(2024, 7, 31, 0, 0, 0, 0, time.UTC)}, nil)
+
+	taskJSON := `{"title":"Finish Report","description":"Quarterly report","priority":"high","status":"todo","assignee_id":3,"project_id":5,"created_at":"2024-07-01","completed_at":"2024-07-15","due_date":"2024-08-01","due_timezone":"Asia/Almaty"}`
+	req, err := http.NewRequest("POST", "/tasks", bytes.NewBuffer([]byte(taskJSON)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.POST("/tasks", handler.CreateTask)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	mockRepo.AssertNotCalled(t, "CreateTask", mock.Anything)
+}
@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/graph"
+	"github.com/togzhanzhakhani/projects/internal/graphql"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+	"github.com/togzhanzhakhani/projects/pkg/client"
+	"gorm.io/gorm"
+)
+
+type MockAPITokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPITokenRepository) WithContext(ctx context.Context) repository.APITokenRepository {
+	return m
+}
+
+func (m *MockAPITokenRepository) CreateAPIToken(token *models.APIToken) error {
+	args := m.Called(token)
+	token.ID = 1
+	return args.Error(0)
+}
+
+func (m *MockAPITokenRepository) GetAPITokensByUser(userID uint) ([]models.APIToken, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.APIToken), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) FindActiveAPIToken(tokenHash string) (*models.APIToken, error) {
+	args := m.Called(tokenHash)
+	token, _ := args.Get(0).(*models.APIToken)
+	return token, args.Error(1)
+}
+
+func (m *MockAPITokenRepository) TouchAPIToken(token *models.APIToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockAPITokenRepository) RevokeAPIToken(id, userID uint) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+// setupAPITokenRouter serves the routes with API tokens and the X-User-ID
+// header accepted, as the server does.
+func setupAPITokenRouter() (*gin.Engine, *MockAPITokenRepository, *MockTaskRepository) {
+	tokenRepo := new(MockAPITokenRepository)
+	tokenRepo.On("FindActiveAPIToken", mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+	tokenRepo.On("TouchAPIToken", mock.AnythingOfType("*models.APIToken")).Return(nil).Maybe()
+	taskRepo := new(MockTaskRepository)
+	handler := handlers.NewAPITokenHandler(tokenRepo)
+	router := gin.New()
+	routes.Register(router, routes.Handlers{
+		Task:         handlers.NewTaskHandler(taskRepo),
+		GraphQL:      handlers.NewGraphQLHandler(graph.NewResolver(newFakeGraphRepository())),
+		APIToken:     handler,
+		Authenticate: []gin.HandlerFunc{auth.Middleware(auth.Any(auth.BearerScoped(handler.Authenticate), auth.Header))},
+	})
+	return router, tokenRepo, taskRepo
+}
+
+// withToken registers an active API token of user 5 with scopes.
+func withToken(tokenRepo *MockAPITokenRepository, token string, scopes ...string) {
+	tokenRepo.On("FindActiveAPIToken", hashToken(token)).Return(&models.APIToken{ID: 3, UserID: 5, Scopes: scopes}, nil)
+	// The catch-all registered by setupAPITokenRouter must come last.
+	calls := tokenRepo.ExpectedCalls
+	tokenRepo.ExpectedCalls = append([]*mock.Call{calls[len(calls)-1]}, calls[:len(calls)-1]...)
+}
+
+func sendAs(router *gin.Engine, method, path, body string, header, value string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(header, value)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCreateAPIToken(t *testing.T) {
+	router, tokenRepo, _ := setupAPITokenRouter()
+	tokenRepo.On("CreateAPIToken", mock.AnythingOfType("*models.APIToken")).Return(nil)
+
+	rr := sendAs(router, "POST", "/me/tokens", `{"name":"CI","scopes":["tasks:read","projects:admin"]}`, auth.UserHeader, "5")
+	assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.NotContains(t, rr.Body.String(), "token_hash", "хеш токена не должен возвращаться")
+	var created handlers.CreatedAPIToken
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	assert.True(t, strings.HasPrefix(created.Token, "pat_"), "токен должен начинаться с pat_")
+	assert.True(t, strings.HasPrefix(created.Token, created.Prefix), "префикс должен быть началом токена")
+	assert.Equal(t, []string{"tasks:read", "projects:admin"}, created.Scopes)
+	assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), created.ExpiresAt, time.Minute, "по умолчанию токен действует 90 дней")
+
+	stored := tokenRepo.Calls[len(tokenRepo.Calls)-1].Arguments.Get(0).(*models.APIToken)
+	assert.Equal(t, uint(5), stored.UserID, "токен принадлежит вызывающему")
+	assert.Equal(t, hashToken(created.Token), stored.TokenHash, "хранится только хеш токена")
+}
+
+func TestCreateAPIToken_Invalid(t *testing.T) {
+	router, tokenRepo, _ := setupAPITokenRouter()
+
+	for _, body := range []string{
+		`{"name":"CI","scopes":[]}`,
+		`{"name":"CI","scopes":["tasks:delete"]}`,
+		`{"name":"CI","scopes":["billing:read"]}`,
+		`{"scopes":["tasks:read"]}`,
+		`{"name":"CI","scopes":["tasks:read"],"expires_at":"2000-01-01T00:00:00Z"}`,
+		`{"name":"CI","scopes":["tasks:read"],"expires_at":"2999-01-01T00:00:00Z"}`,
+	} {
+		rr := sendAs(router, "POST", "/me/tokens", body, auth.UserHeader, "5")
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+	rr := sendAs(router, "POST", "/me/tokens", `{"name":"CI","scopes":["tasks:read","tasks:delete"]}`, auth.UserHeader, "5")
+	assert.Contains(t, rr.Body.String(), `"field":"scopes[1]"`, "ошибка должна указывать на неверную область")
+	tokenRepo.AssertNotCalled(t, "CreateAPIToken", mock.Anything)
+}
+
+func TestListAndRevokeAPITokens(t *testing.T) {
+	router, tokenRepo, _ := setupAPITokenRouter()
+	tokenRepo.On("GetAPITokensByUser", uint(5)).Return([]models.APIToken{{ID: 1, UserID: 5, Name: "CI", Prefix: "pat_abcdef", TokenHash: "secret"}}, nil)
+	tokenRepo.On("RevokeAPIToken", uint(1), uint(5)).Return(nil)
+	tokenRepo.On("RevokeAPIToken", uint(2), uint(5)).Return(gorm.ErrRecordNotFound)
+
+	rr := sendAs(router, "GET", "/me/tokens", "", auth.UserHeader, "5")
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Contains(t, rr.Body.String(), "pat_abcdef")
+	assert.NotContains(t, rr.Body.String(), "secret", "хеш токена не должен возвращаться")
+
+	rr = sendAs(router, "DELETE", "/me/tokens/1", "", auth.UserHeader, "5")
+	assert.Equal(t, http.StatusNoContent, rr.Code, "статус код не соответствует ожидаемому")
+	rr = sendAs(router, "DELETE", "/me/tokens/2", "", auth.UserHeader, "5")
+	assert.Equal(t, http.StatusNotFound, rr.Code, "чужой или отозванный токен не найден")
+}
+
+func TestAPIToken_Authenticates(t *testing.T) {
+	router, tokenRepo, taskRepo := setupAPITokenRouter()
+	withToken(tokenRepo, "pat_reader", "tasks:read")
+	taskRepo.On("GetAllTasks").Return([]models.Task{}, nil)
+
+	rr := sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer pat_reader")
+	assert.Equal(t, http.StatusOK, rr.Code, "токен должен идентифицировать пользователя")
+	tokenRepo.AssertCalled(t, "TouchAPIToken", mock.AnythingOfType("*models.APIToken"))
+
+	rr = sendAs(router, "POST", "/tasks/", `{}`, "Authorization", "Bearer pat_reader")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "для записи нужна область tasks:write")
+	assert.Contains(t, rr.Body.String(), "tasks:write")
+	taskRepo.AssertNotCalled(t, "CreateTask", mock.Anything)
+
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer pat_revoked")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "отозванный или просроченный токен не принимается")
+
+	rr = sendAs(router, "GET", "/me/tokens", "", "Authorization", "Bearer pat_reader")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "API-токены не могут управлять токенами")
+}
+
+func TestAPIToken_ExportScopes(t *testing.T) {
+	router, tokenRepo, _ := setupAPITokenRouter()
+	withToken(tokenRepo, "pat_projects", "projects:admin", "users:admin")
+
+	for _, route := range [][2]string{
+		{"GET", "/projects/1/export"},
+		{"GET", "/projects/1/archive"},
+		{"GET", "/projects/1/calendar-tokens"},
+		{"POST", "/projects/1/calendar-tokens"},
+		{"POST", "/users/5/calendar-tokens"},
+		{"DELETE", "/users/5/calendar-tokens/1"},
+	} {
+		rr := sendAs(router, route[0], route[1], `{}`, "Authorization", "Bearer pat_projects")
+		assert.Equal(t, http.StatusForbidden, rr.Code, "для выгрузки данных нужна область export: %s %s", route[0], route[1])
+		assert.Contains(t, rr.Body.String(), "export:", route[1])
+	}
+	rr := sendAs(router, "POST", "/projects/import-archive", `{}`, "Authorization", "Bearer pat_projects")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "для импорта архива нужна область import:write")
+	assert.Contains(t, rr.Body.String(), "import:write")
+}
+
+func TestAPIToken_GraphQLScopes(t *testing.T) {
+	router, tokenRepo, _ := setupAPITokenRouter()
+	withToken(tokenRepo, "pat_reader", "graphql:read")
+
+	rr := sendAs(router, "POST", "/graphql", `{"query":"{ users { id } }"}`, "Authorization", "Bearer pat_reader")
+	assert.Equal(t, http.StatusOK, rr.Code, "graphql:read разрешает запросы")
+	rr = sendAs(router, "POST", "/graphql", `{"query":"mutation { deleteTask(id: 1) }"}`, "Authorization", "Bearer pat_reader")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "для мутаций нужна область graphql:write")
+
+	kind, ok := graphql.Parse(graphql.Request{Query: "query A { users { id } } mutation B { deleteTask(id: 1) }", OperationName: "B"}).Kind()
+	assert.True(t, ok)
+	assert.Equal(t, "mutation", kind)
+}
+
+func TestClient_Tokens(t *testing.T) {
+	router, tokenRepo, _ := setupAPITokenRouter()
+	tokenRepo.On("CreateAPIToken", mock.AnythingOfType("*models.APIToken")).Return(nil)
+	tokenRepo.On("RevokeAPIToken", uint(1), uint(5)).Return(nil)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	c := newTestClient(server)
+	c.UserID = 5
+	ctx := context.Background()
+
+	created, err := c.Tokens.Create(ctx, client.TokenInput{Name: "CI", Scopes: []string{"tasks:read"}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.ID)
+	assert.True(t, strings.HasPrefix(created.Token, "pat_"), "токен должен возвращаться при создании")
+	assert.NoError(t, c.Tokens.Revoke(ctx, 1))
+
+	_, err = c.Tokens.Create(ctx, client.TokenInput{Name: "CI", Scopes: []string{"tasks:delete"}})
+	assert.True(t, errors.Is(err, client.ErrValidationFailed), "неверная область должна отклоняться: %v", err)
+}
+
+func TestScopes(t *testing.T) {
+	scopes := []string{"tasks:write", "projects:admin"}
+	assert.True(t, auth.Grants(scopes, "tasks:read"), "write включает read")
+	assert.True(t, auth.Grants(scopes, "tasks:write"))
+	assert.False(t, auth.Grants(scopes, "tasks:admin"))
+	assert.True(t, auth.Grants(scopes, "projects:write"), "admin включает write")
+	assert.False(t, auth.Grants(scopes, "users:read"))
+
+	assert.True(t, auth.ValidScope("recurring-tasks:admin"))
+	assert.False(t, auth.ValidScope("tasks"))
+	assert.False(t, auth.ValidScope("tasks:owner"))
+
+	assert.True(t, auth.Allows(context.Background(), "users:admin"), "сессии не ограничены областями")
+	ctx := auth.NewScopedContext(context.Background(), nil)
+	assert.False(t, auth.Allows(ctx, "tasks:read"), "токен без областей ничего не разрешает")
+}
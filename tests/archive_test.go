@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/archive"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+type MockArchiveRepository struct {
+	mock.Mock
+}
+
+func (m *MockArchiveRepository) WithContext(ctx context.Context) repository.ArchiveRepository {
+	return m
+}
+
+func (m *MockArchiveRepository) LoadProjectArchive(projectID uint) (*archive.Archive, error) {
+	args := m.Called(projectID)
+	if a, ok := args.Get(0).(*archive.Archive); ok {
+		return a, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockArchiveRepository) RestoreProjectArchive(a *archive.Archive) (*repository.RestoreResult, error) {
+	args := m.Called(a)
+	if result, ok := args.Get(0).(*repository.RestoreResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func sampleArchive() *archive.Archive {
+	return &archive.Archive{
+		Project: models.Project{ID: 5, Name: "Alpha", Description: "Pilot", StartDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), ManagerID: 1},
+		Tasks:   []models.Task{{ID: 10, Title: "Report", AssigneeID: 2, ProjectID: 5}},
+		Users: []models.User{
+			{ID: 1, Name: "John Doe", Email: "johndoe@example.com", Role: "manager"},
+			{ID: 2, Name: "Jane Smith", Email: "janesmith@example.com", Role: "developer"},
+		},
+	}
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, archive.Write(&buf, sampleArchive()))
+
+	a, err := archive.Read(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, archive.Version, a.Manifest.Version)
+	assert.Equal(t, 5, a.Manifest.ProjectID)
+	assert.Equal(t, "Alpha", a.Project.Name)
+	assert.Len(t, a.Tasks, 1)
+	assert.Len(t, a.Users, 2)
+	assert.Empty(t, a.RecurringTasks)
+	assert.NoError(t, a.Validate())
+}
+
+func TestArchive_RejectsTamperedMember(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, archive.Write(&buf, sampleArchive()))
+
+	// Rewrite the archive with a modified tasks.json but the original manifest.
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	var tampered bytes.Buffer
+	zw := zip.NewWriter(&tampered)
+	for _, f := range zr.File {
+		r, _ := f.Open()
+		data, _ := io.ReadAll(r)
+		if f.Name == "tasks.json" {
+			data = []byte(`[]`)
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(data)
+	}
+	zw.Close()
+
+	_, err = archive.Read(tampered.Bytes())
+	assert.EqualError(t, err, "checksum mismatch for tasks.json")
+}
+
+func TestArchive_ValidateMissingUser(t *testing.T) {
+	a := sampleArchive()
+	a.Users = a.Users[:1]
+	assert.EqualError(t, a.Validate(), "assignee 2 of task 10 is missing from the archive")
+}
+
+func TestImportProjectArchive(t *testing.T) {
+	repo := new(MockArchiveRepository)
+	handler := handlers.NewArchiveHandler(repo)
+
+	var buf bytes.Buffer
+	assert.NoError(t, archive.Write(&buf, sampleArchive()))
+	repo.On("RestoreProjectArchive", mock.AnythingOfType("*archive.Archive")).Return(&repository.RestoreResult{
+		Project: models.Project{ID: 42, Name: "Alpha"},
+		UserIDs: map[int]int{1: 7, 2: 8},
+		TaskIDs: map[int]int{10: 100},
+	}, nil)
+
+	req, _ := http.NewRequest("POST", "/projects/import-archive", &buf)
+	req.Header.Set("Content-Type", "application/zip")
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.POST("/projects/import-archive", handler.ImportProjectArchive)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Contains(t, rr.Body.String(), `"task_ids":{"10":100}`)
+}
@@ -0,0 +1,464 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/password"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type MockAuthRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuthRepository) WithContext(ctx context.Context) repository.AuthRepository {
+	return m
+}
+
+func (m *MockAuthRepository) FindUserByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockAuthRepository) GetCredential(userID uint) (*models.Credential, error) {
+	args := m.Called(userID)
+	credential, _ := args.Get(0).(*models.Credential)
+	return credential, args.Error(1)
+}
+
+func (m *MockAuthRepository) SetPassword(userID uint, hash string) error {
+	args := m.Called(userID, hash)
+	return args.Error(0)
+}
+
+// AttemptLogin checks the password against the credential set up for the
+// user, locking and counting as the repository does.
+func (m *MockAuthRepository) AttemptLogin(userID uint, verify func(hash string) (bool, error), lockout func(failures int) time.Duration) (*models.Credential, bool, error) {
+	args := m.Called(userID)
+	stored, _ := args.Get(0).(*models.Credential)
+	if stored == nil {
+		return nil, false, args.Error(1)
+	}
+	return attemptLogin(stored, verify, lockout)
+}
+
+func (m *MockAuthRepository) SetEmail(userID uint, email string) error {
+	args := m.Called(userID, email)
+	return args.Error(0)
+}
+
+// attemptLogin checks a password against a copy of a credential the way
+// the repository does once it holds the row lock.
+func attemptLogin(stored *models.Credential, verify func(hash string) (bool, error), lockout func(failures int) time.Duration) (*models.Credential, bool, error) {
+	credential := *stored
+	if credential.LockedUntil != nil && credential.LockedUntil.After(time.Now()) {
+		return &credential, false, repository.ErrLocked
+	}
+	ok, err := verify(credential.PasswordHash)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		credential.FailedAttempts, credential.LockedUntil = 0, nil
+		return &credential, true, nil
+	}
+	credential.FailedAttempts++
+	if d := lockout(credential.FailedAttempts); d > 0 {
+		until := time.Now().Add(d)
+		credential.LockedUntil = &until
+	}
+	return &credential, false, nil
+}
+
+// lockingAuthRepository keeps one credential in memory and serializes the
+// login attempts on it, as the row lock of the repository does.
+type lockingAuthRepository struct {
+	*MockAuthRepository
+	mu         sync.Mutex
+	credential models.Credential
+	verified   int
+}
+
+func (r *lockingAuthRepository) WithContext(ctx context.Context) repository.AuthRepository {
+	return r
+}
+
+func (r *lockingAuthRepository) AttemptLogin(userID uint, verify func(hash string) (bool, error), lockout func(failures int) time.Duration) (*models.Credential, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	credential, ok, err := attemptLogin(&r.credential, func(hash string) (bool, error) {
+		r.verified++
+		return verify(hash)
+	}, lockout)
+	if err == nil {
+		r.credential = *credential
+	}
+	return credential, ok, err
+}
+
+func (m *MockAuthRepository) CreateSession(session *models.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) FindActiveSession(tokenHash string) (*models.Session, error) {
+	args := m.Called(tokenHash)
+	session, _ := args.Get(0).(*models.Session)
+	return session, args.Error(1)
+}
+
+func (m *MockAuthRepository) RevokeSession(tokenHash string) error {
+	args := m.Called(tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) CreatePasswordReset(reset *models.PasswordReset) error {
+	args := m.Called(reset)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ResetPassword(tokenHash, passwordHash string) (*models.PasswordReset, error) {
+	args := m.Called(tokenHash, passwordHash)
+	reset, _ := args.Get(0).(*models.PasswordReset)
+	return reset, args.Error(1)
+}
+
+func (m *MockAuthRepository) CreateOIDCLogin(login *models.OIDCLogin) error {
+	args := m.Called(login)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ClaimOIDCLogin(stateHash string) (*models.OIDCLogin, error) {
+	args := m.Called(stateHash)
+	login, _ := args.Get(0).(*models.OIDCLogin)
+	return login, args.Error(1)
+}
+
+func (m *MockAuthRepository) ProvisionUser(user *models.User, workspaceID uint) error {
+	args := m.Called(user, workspaceID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) RecordEvent(event *models.AuthEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetAuthEvents(userID uint) ([]models.AuthEvent, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.AuthEvent), args.Error(1)
+}
+
+// recordingResetSender keeps the password resets it was asked to send.
+type recordingResetSender struct {
+	messages []password.ResetMessage
+}
+
+func (s *recordingResetSender) SendReset(message password.ResetMessage) error {
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func setupAuthRouter() (*gin.Engine, *handlers.AuthHandler, *MockAuthRepository, *recordingResetSender) {
+	mockRepo := new(MockAuthRepository)
+	mockRepo.On("RecordEvent", mock.AnythingOfType("*models.AuthEvent")).Return(nil).Maybe()
+	sender := &recordingResetSender{}
+	handler := handlers.NewAuthHandler(mockRepo, new(MockUserRepository), sender)
+	router := gin.New()
+	router.POST("/auth/login", handler.Login)
+	router.POST("/auth/password-reset", handler.RequestPasswordReset)
+	router.POST("/auth/password-reset/confirm", handler.ConfirmPasswordReset)
+	authenticated := router.Group("/", auth.Middleware(auth.Bearer(handler.Authenticate)))
+	authenticated.POST("/auth/logout", handler.Logout)
+	authenticated.PUT("/me/email", handler.ChangeEmail)
+	authenticated.GET("/me", func(c *gin.Context) {
+		userID, _ := auth.UserID(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"user_id": userID})
+	})
+	return router, handler, mockRepo, sender
+}
+
+// events returns the kinds of the auth events recorded by mockRepo.
+func events(mockRepo *MockAuthRepository) []string {
+	var kinds []string
+	for _, call := range mockRepo.Calls {
+		if call.Method == "RecordEvent" {
+			kinds = append(kinds, call.Arguments.Get(0).(*models.AuthEvent).Kind)
+		}
+	}
+	return kinds
+}
+
+func TestPassword_HashAndVerify(t *testing.T) {
+	hash, err := password.Hash("correct horse")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$"), "пароль должен хешироваться argon2id")
+	assert.NoError(t, password.Verify(hash, "correct horse"), "верный пароль должен проходить проверку")
+	assert.ErrorIs(t, password.Verify(hash, "wrong horse"), password.ErrMismatch, "неверный пароль должен отклоняться")
+	assert.False(t, password.NeedsRehash(hash), "свежий хеш не нужно пересчитывать")
+
+	other, _ := password.Hash("correct horse")
+	assert.NotEqual(t, hash, other, "у каждого хеша своя соль")
+
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	assert.NoError(t, password.Verify(string(legacy), "correct horse"), "хеши bcrypt должны проверяться")
+	assert.ErrorIs(t, password.Verify(string(legacy), "wrong horse"), password.ErrMismatch)
+	assert.True(t, password.NeedsRehash(string(legacy)), "хеши bcrypt нужно пересчитать")
+}
+
+func TestPassword_Policy(t *testing.T) {
+	trans := i18n.English()
+	assert.Empty(t, password.DefaultPolicy.Check(trans, "long enough"), "политика по умолчанию проверяет только длину")
+	assert.Equal(t, []string{"Password must be at least 8 characters long"}, password.DefaultPolicy.Check(trans, "short"))
+
+	policy := password.Policy{MinLength: 10, MaxLength: 20, Require: []string{password.ClassUpper, password.ClassDigit, password.ClassSymbol}}
+	assert.Equal(t, []string{
+		"Password must contain an upper-case letter",
+		"Password must contain a digit",
+		"Password must contain a symbol",
+	}, policy.Check(trans, "lowercaseonly"), "должны сообщаться все недостающие классы символов")
+	assert.Empty(t, policy.Check(trans, "Upper-case 1"), "пароль соответствует политике")
+	assert.Len(t, policy.Check(trans, "Much-too-long-password-1"), 1, "слишком длинный пароль")
+
+	t.Setenv("PASSWORD_REQUIRE", "upper,emoji")
+	assert.Error(t, (&password.Policy{}).LoadConfig(), "неизвестный класс символов должен отклоняться")
+}
+
+func TestPassword_LockoutBackoff(t *testing.T) {
+	lockout := password.Lockout{Threshold: 3, Base: time.Minute, Max: 10 * time.Minute}
+	assert.Equal(t, time.Duration(0), lockout.Duration(2), "до порога учётная запись не блокируется")
+	assert.Equal(t, time.Minute, lockout.Duration(3))
+	assert.Equal(t, 2*time.Minute, lockout.Duration(4), "каждая следующая ошибка удваивает блокировку")
+	assert.Equal(t, 8*time.Minute, lockout.Duration(6))
+	assert.Equal(t, 10*time.Minute, lockout.Duration(7), "блокировка ограничена максимумом")
+	assert.Equal(t, 10*time.Minute, lockout.Duration(100))
+}
+
+func TestLogin_Success(t *testing.T) {
+	router, _, mockRepo, _ := setupAuthRouter()
+	hash, _ := password.Hash("correct horse")
+	user := &models.User{ID: 4, Name: "John", Email: "john@example.com", Role: "developer"}
+	mockRepo.On("FindUserByEmail", "John@Example.com").Return(user, nil)
+	mockRepo.On("AttemptLogin", uint(4)).Return(&models.Credential{UserID: 4, PasswordHash: hash, FailedAttempts: 2}, nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("*models.Session")).Return(nil)
+
+	rr := sendJSON(router, "POST", "/auth/login", `{"email":"John@Example.com","password":"correct horse"}`)
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	assert.NotContains(t, rr.Body.String(), "argon2id", "хеш пароля не должен возвращаться")
+	assert.NotContains(t, rr.Body.String(), "password", "пароль не должен возвращаться")
+
+	var response handlers.LoginResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.NotEmpty(t, response.Token, "вход должен выдавать токен")
+	assert.Equal(t, *user, response.User)
+
+	session := mockRepo.Calls[2].Arguments.Get(0).(*models.Session)
+	assert.Equal(t, uint(4), session.UserID)
+	assert.Equal(t, hashToken(response.Token), session.TokenHash, "хранится только хеш токена")
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), session.ExpiresAt, time.Minute, "сессия действует сутки")
+	assert.Equal(t, []string{models.AuthLoginSucceeded}, events(mockRepo))
+
+	mockRepo.On("FindActiveSession", session.TokenHash).Return(session, nil)
+	mockRepo.On("FindActiveSession", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+response.Token)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.JSONEq(t, `{"user_id":4}`, rr.Body.String(), "токен сессии должен идентифицировать пользователя")
+
+	req.Header.Set("Authorization", "Bearer forged")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "чужой токен должен отклоняться")
+}
+
+func TestLogin_RehashesBcrypt(t *testing.T) {
+	router, _, mockRepo, _ := setupAuthRouter()
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	mockRepo.On("FindUserByEmail", "john@example.com").Return(&models.User{ID: 4, Email: "john@example.com"}, nil)
+	mockRepo.On("AttemptLogin", uint(4)).Return(&models.Credential{UserID: 4, PasswordHash: string(legacy)}, nil)
+	mockRepo.On("SetPassword", uint(4), mock.AnythingOfType("string")).Return(nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("*models.Session")).Return(nil)
+
+	rr := sendJSON(router, "POST", "/auth/login", `{"email":"john@example.com","password":"correct horse"}`)
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	var rehashed string
+	for _, call := range mockRepo.Calls {
+		if call.Method == "SetPassword" {
+			rehashed = call.Arguments.String(1)
+		}
+	}
+	assert.NoError(t, password.Verify(rehashed, "correct horse"), "пароль должен пересчитываться в argon2id")
+	assert.False(t, password.NeedsRehash(rehashed))
+}
+
+func TestLogin_FailureAndLockout(t *testing.T) {
+	router, handler, mockRepo, _ := setupAuthRouter()
+	hash, _ := password.Hash("correct horse")
+	mockRepo.On("FindUserByEmail", "john@example.com").Return(&models.User{ID: 4, Email: "john@example.com"}, nil)
+	mockRepo.On("FindUserByEmail", "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("AttemptLogin", uint(4)).Return(&models.Credential{UserID: 4, PasswordHash: hash, FailedAttempts: 4}, nil).Once()
+
+	rr := sendJSON(router, "POST", "/auth/login", `{"email":"john@example.com","password":"wrong horse"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "неверный пароль")
+	assert.Equal(t, []string{models.AuthLoginFailed, models.AuthLockedOut}, events(mockRepo), "блокировка должна попадать в журнал")
+
+	until := time.Now().Add(time.Minute)
+	mockRepo.On("AttemptLogin", uint(4)).Return(&models.Credential{UserID: 4, PasswordHash: hash, FailedAttempts: 5, LockedUntil: &until}, nil)
+	rr = sendJSON(router, "POST", "/auth/login", `{"email":"john@example.com","password":"correct horse"}`)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, "заблокированная учётная запись не входит даже с верным паролем")
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"), "должно сообщаться время до разблокировки")
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+
+	rr = sendJSON(router, "POST", "/auth/login", `{"email":"nobody@example.com","password":"whatever"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "неизвестный email отклоняется так же, как неверный пароль")
+	assert.Equal(t, 5, handler.Lockout.Threshold)
+}
+
+func TestLogin_ConcurrentFailuresLockOnce(t *testing.T) {
+	hash, _ := password.Hash("correct horse")
+	mockRepo := new(MockAuthRepository)
+	mockRepo.On("RecordEvent", mock.AnythingOfType("*models.AuthEvent")).Return(nil)
+	mockRepo.On("FindUserByEmail", "john@example.com").Return(&models.User{ID: 4, Email: "john@example.com"}, nil)
+	repo := &lockingAuthRepository{MockAuthRepository: mockRepo, credential: models.Credential{UserID: 4, PasswordHash: hash}}
+	handler := handlers.NewAuthHandler(repo, new(MockUserRepository), &recordingResetSender{})
+	router := gin.New()
+	router.POST("/auth/login", handler.Login)
+
+	codes := make([]int, 4*handler.Lockout.Threshold)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = sendJSON(router, "POST", "/auth/login", `{"email":"john@example.com","password":"wrong horse"}`).Code
+		}(i)
+	}
+	wg.Wait()
+
+	counts := map[int]int{}
+	for _, code := range codes {
+		counts[code]++
+	}
+	assert.Equal(t, handler.Lockout.Threshold, counts[http.StatusUnauthorized], "до блокировки проверяется не больше попыток, чем позволяет порог")
+	assert.Equal(t, len(codes)-handler.Lockout.Threshold, counts[http.StatusTooManyRequests], "остальные одновременные попытки должны видеть блокировку")
+	assert.Equal(t, handler.Lockout.Threshold, repo.verified, "пароль не должен проверяться у заблокированной учётной записи")
+	assert.Equal(t, handler.Lockout.Threshold, repo.credential.FailedAttempts)
+}
+
+func TestChangeEmail(t *testing.T) {
+	router, _, mockRepo, _ := setupAuthRouter()
+	hash, _ := password.Hash("correct horse")
+	mockRepo.On("FindActiveSession", hashToken("session")).Return(&models.Session{ID: 1, UserID: 4}, nil)
+	mockRepo.On("AttemptLogin", uint(4)).Return(&models.Credential{UserID: 4, PasswordHash: hash}, nil)
+	mockRepo.On("SetEmail", uint(4), "new@example.com").Return(nil)
+
+	change := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/me/email", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer session")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := change(`{"email":"new@example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "смена email требует пароль")
+	rr = change(`{"email":"new@example.com","password":"wrong horse"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "неверный пароль")
+	mockRepo.AssertNotCalled(t, "SetEmail", mock.Anything, mock.Anything)
+	assert.Equal(t, []string{models.AuthLoginFailed}, events(mockRepo), "неверный пароль считается неудачным входом")
+
+	rr = change(`{"email":"new@example.com","password":"correct horse"}`)
+	assert.Equal(t, http.StatusNoContent, rr.Code, "статус код не соответствует ожидаемому")
+	mockRepo.AssertCalled(t, "SetEmail", uint(4), "new@example.com")
+	assert.Equal(t, []string{models.AuthLoginFailed, models.AuthEmailChanged}, events(mockRepo))
+}
+
+func TestPasswordReset(t *testing.T) {
+	router, _, mockRepo, sender := setupAuthRouter()
+	user := &models.User{ID: 4, Email: "john@example.com"}
+	mockRepo.On("FindUserByEmail", "john@example.com").Return(user, nil)
+	mockRepo.On("FindUserByEmail", "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("CreatePasswordReset", mock.AnythingOfType("*models.PasswordReset")).Return(nil)
+
+	rr := sendJSON(router, "POST", "/auth/password-reset", `{"email":"nobody@example.com"}`)
+	assert.Equal(t, http.StatusAccepted, rr.Code, "ответ не должен выдавать, есть ли пользователь")
+	assert.Empty(t, sender.messages)
+
+	rr = sendJSON(router, "POST", "/auth/password-reset", `{"email":"john@example.com"}`)
+	assert.Equal(t, http.StatusAccepted, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Len(t, sender.messages, 1, "токен сброса должен быть отправлен")
+	token := sender.messages[0].Token
+	var reset *models.PasswordReset
+	for _, call := range mockRepo.Calls {
+		if call.Method == "CreatePasswordReset" {
+			reset = call.Arguments.Get(0).(*models.PasswordReset)
+		}
+	}
+	assert.Equal(t, hashToken(token), reset.TokenHash, "хранится только хеш токена")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), reset.ExpiresAt, time.Minute, "сброс действует час")
+
+	rr = sendJSON(router, "POST", "/auth/password-reset/confirm", `{"token":"`+token+`","password":"short"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "новый пароль должен соответствовать политике")
+
+	mockRepo.On("ResetPassword", hashToken(token), mock.AnythingOfType("string")).Return(&models.PasswordReset{ID: 1, UserID: 4}, nil)
+	mockRepo.On("ResetPassword", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	rr = sendJSON(router, "POST", "/auth/password-reset/confirm", `{"token":"`+token+`","password":"new correct horse"}`)
+	assert.Equal(t, http.StatusNoContent, rr.Code, "статус код не соответствует ожидаемому")
+
+	rr = sendJSON(router, "POST", "/auth/password-reset/confirm", `{"token":"used","password":"new correct horse"}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "использованный токен должен отклоняться")
+	assert.Equal(t, []string{models.AuthResetRequested, models.AuthPasswordReset}, events(mockRepo))
+}
+
+func TestLogout(t *testing.T) {
+	router, _, mockRepo, _ := setupAuthRouter()
+	mockRepo.On("FindActiveSession", hashToken("session")).Return(&models.Session{ID: 1, UserID: 4}, nil)
+	mockRepo.On("RevokeSession", hashToken("session")).Return(nil)
+
+	req, _ := http.NewRequest("POST", "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer session")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code, "статус код не соответствует ожидаемому")
+	mockRepo.AssertCalled(t, "RevokeSession", hashToken("session"))
+	assert.Equal(t, []string{models.AuthLogout}, events(mockRepo))
+}
+
+func TestGetAuthEvents(t *testing.T) {
+	mockRepo := new(MockAuthRepository)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", uint(5)).Return(&models.User{ID: 5}, nil)
+	userRepo.On("GetUserByID", uint(6)).Return(&models.User{ID: 6}, nil)
+	mockRepo.On("GetAuthEvents", uint(5)).Return([]models.AuthEvent{{ID: 1, Kind: models.AuthLoginSucceeded}}, nil)
+	handler := handlers.NewAuthHandler(mockRepo, userRepo, &recordingResetSender{})
+
+	router := gin.New()
+	router.Use(asMember(models.WorkspaceMember))
+	router.GET("/users/:id/auth-events", handler.GetAuthEvents)
+
+	rr := sendJSON(router, "GET", "/users/5/auth-events", "")
+	assert.Equal(t, http.StatusOK, rr.Code, "пользователь видит свои события")
+	assert.Contains(t, rr.Body.String(), models.AuthLoginSucceeded)
+
+	rr = sendJSON(router, "GET", "/users/6/auth-events", "")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "чужие события видят только администраторы")
+}
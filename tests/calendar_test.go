@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/ical"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+type MockFeedTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockFeedTokenRepository) WithContext(ctx context.Context) repository.FeedTokenRepository {
+	return m
+}
+
+func (m *MockFeedTokenRepository) CreateFeedToken(token *models.FeedToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockFeedTokenRepository) GetFeedTokenByID(id uint) (*models.FeedToken, error) {
+	args := m.Called(id)
+	if token, ok := args.Get(0).(*models.FeedToken); ok {
+		return token, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockFeedTokenRepository) FindActiveFeedToken(tokenHash string) (*models.FeedToken, error) {
+	args := m.Called(tokenHash)
+	if token, ok := args.Get(0).(*models.FeedToken); ok {
+		return token, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockFeedTokenRepository) GetFeedTokensByOwner(ownerType string, ownerID uint) ([]models.FeedToken, error) {
+	args := m.Called(ownerType, ownerID)
+	return args.Get(0).([]models.FeedToken), args.Error(1)
+}
+
+func (m *MockFeedTokenRepository) RevokeFeedToken(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestCalendar_WriteFoldsAndEscapes(t *testing.T) {
+	event := ical.Component{Name: "VEVENT"}
+	event.Text("SUMMARY", "Report; draft, v2")
+	event.Text("DESCRIPTION", strings.Repeat("Қазақстан ", 10))
+	event.Date("DTSTART", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+
+	cal := ical.Calendar{ProdID: "-//test//EN", Components: []ical.Component{event}}
+	var buf bytes.Buffer
+	_, err := cal.WriteTo(&buf)
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "SUMMARY:Report\\; draft\\, v2\r\n")
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20240701\r\n")
+	for _, line := range strings.Split(out, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "строка не свёрнута: %q", line)
+	}
+}
+
+func TestGetUserCalendar_RejectsTokenOfAnotherFeed(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	feedRepo := new(MockFeedTokenRepository)
+	handler := handlers.NewCalendarHandler(userRepo, nil, feedRepo)
+
+	feedRepo.On("FindActiveFeedToken", mock.Anything).Return(&models.FeedToken{ID: 1, OwnerType: models.FeedOwnerUser, OwnerID: 2}, nil)
+
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.GET("/users/:id/calendar.ics", handler.GetUserCalendar)
+	req, _ := http.NewRequest("GET", "/users/1/calendar.ics?token=secret", nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "статус код не соответствует ожидаемому")
+	userRepo.AssertNotCalled(t, "GetTasksByUserID", mock.Anything)
+}
+
+func TestGetUserCalendar_RevokedToken(t *testing.T) {
+	feedRepo := new(MockFeedTokenRepository)
+	handler := handlers.NewCalendarHandler(new(MockUserRepository), nil, feedRepo)
+
+	feedRepo.On("FindActiveFeedToken", mock.Anything).Return(nil, errors.New("record not found"))
+
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.GET("/users/:id/calendar.ics", handler.GetUserCalendar)
+	req, _ := http.NewRequest("GET", "/users/1/calendar.ics?token=revoked", nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "статус код не соответствует ожидаемому")
+}
+
+// workspaceUserRepository serves users and tasks like the scoped user
+// repository: only those of the workspace of its context.
+type workspaceUserRepository struct {
+	*MockUserRepository
+	members     map[uint]uint
+	workspaceID uint
+}
+
+func (r workspaceUserRepository) WithContext(ctx context.Context) repository.UserRepository {
+	r.workspaceID, _ = workspace.ID(ctx)
+	return r
+}
+
+func (r workspaceUserRepository) GetUserByID(id uint) (*models.User, error) {
+	if r.members[id] != r.workspaceID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.User{ID: id}, nil
+}
+
+func TestGetUserCalendar_ReadsWorkspaceOfToken(t *testing.T) {
+	// User 2 now belongs to workspace 2 only; the feed was created in
+	// workspace 1.
+	userRepo := workspaceUserRepository{MockUserRepository: new(MockUserRepository), members: map[uint]uint{2: 2}}
+	feedRepo := new(MockFeedTokenRepository)
+	handler := handlers.NewCalendarHandler(userRepo, nil, feedRepo)
+	feedRepo.On("FindActiveFeedToken", mock.Anything).Return(&models.FeedToken{ID: 1, WorkspaceID: 1, OwnerType: models.FeedOwnerUser, OwnerID: 2}, nil)
+
+	router := gin.New()
+	router.GET("/users/:id/calendar.ics", handler.GetUserCalendar)
+	rr := sendJSON(router, "GET", "/users/2/calendar.ics?token=secret", "")
+
+	assert.Equal(t, http.StatusNotFound, rr.Code, "лента не должна показывать задачи другого рабочего пространства")
+	userRepo.AssertNotCalled(t, "GetTasksByUserID", mock.Anything)
+}
+
+func TestCreateUserFeedToken_OnlyUserOrAdmin(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", mock.Anything).Return(&models.User{ID: 6}, nil)
+	feedRepo := new(MockFeedTokenRepository)
+	feedRepo.On("CreateFeedToken", mock.AnythingOfType("*models.FeedToken")).Return(nil)
+	handler := handlers.NewCalendarHandler(userRepo, nil, feedRepo)
+
+	member := gin.New()
+	member.Use(asMember(models.WorkspaceMember))
+	member.POST("/users/:id/calendar-tokens", handler.CreateUserFeedToken)
+	rr := sendJSON(member, "POST", "/users/6/calendar-tokens", "")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "участник не может выпустить ленту другого пользователя")
+	feedRepo.AssertNotCalled(t, "CreateFeedToken", mock.Anything)
+	rr = sendJSON(member, "POST", "/users/5/calendar-tokens", "")
+	assert.Equal(t, http.StatusCreated, rr.Code, "пользователь может выпустить свою ленту")
+
+	admin := gin.New()
+	admin.Use(asMember(models.WorkspaceAdmin))
+	admin.POST("/users/:id/calendar-tokens", handler.CreateUserFeedToken)
+	rr = sendJSON(admin, "POST", "/users/6/calendar-tokens", "")
+	assert.Equal(t, http.StatusCreated, rr.Code, "администратор может выпустить ленту участника")
+}
@@ -0,0 +1,282 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/togzhanzhakhani/projects/internal/cli"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+)
+
+// runPmctl runs pmctl with a config file of its own, so that neither the
+// user's config nor the environment affects the test.
+func runPmctl(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Setenv("PMCTL_BASE_URL", "")
+	t.Setenv("PMCTL_TOKEN", "")
+	t.Setenv("PMCTL_USER", "")
+	t.Setenv("PMCTL_WORKSPACE", "")
+	if os.Getenv("PMCTL_CONFIG") == "" {
+		t.Setenv("PMCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	}
+
+	cmd := cli.NewRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func cliTasks() []models.Task {
+	due := time.Date(2024, 3, 10, 23, 59, 59, 0, time.UTC)
+	return []models.Task{
+		{ID: 1, Title: "Design schema", Description: "Tables", Priority: "high", Status: "todo", AssigneeID: 1, ProjectID: 1,
+			CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), CompletedAt: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), DueDate: &due},
+		{ID: 2, Title: "Write API", Description: "Handlers", Priority: "medium", Status: "done", AssigneeID: 2, ProjectID: 1,
+			CreatedAt: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), CompletedAt: time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestCLI_TasksList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tasks/", r.URL.Path)
+		json.NewEncoder(w).Encode(cliTasks())
+	}))
+	defer server.Close()
+
+	out, err := runPmctl(t, "", "tasks", "list", "--base-url", server.URL)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, 3, "таблица должна содержать заголовок и две задачи")
+	assert.True(t, strings.HasPrefix(lines[0], "ID"), "первая строка должна быть заголовком")
+	assert.Contains(t, lines[1], "Design schema")
+	assert.Contains(t, lines[1], "2024-03-10T23:59:59Z")
+
+	out, err = runPmctl(t, "", "tasks", "list", "--base-url", server.URL, "--status", "done", "-o", "json")
+	assert.NoError(t, err)
+	var tasks []models.Task
+	assert.NoError(t, json.Unmarshal([]byte(out), &tasks), "вывод должен быть JSON")
+	assert.Len(t, tasks, 1, "должны остаться только задачи с заданным статусом")
+	assert.Equal(t, "Write API", tasks[0].Title)
+
+	out, err = runPmctl(t, "", "tasks", "list", "--base-url", server.URL, "--title", "design", "-o", "yaml")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "- id: 1\n  title: Design schema\n"), "YAML должен сохранять порядок полей: %s", out)
+	assert.NotContains(t, out, "Write API", "фильтр по названию не применен")
+}
+
+func TestCLI_TasksCreateFromFile(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(models.Task{ID: len(bodies), Title: body["title"].(string)})
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "tasks.yaml")
+	os.WriteFile(file, []byte(`title: First
+assignee_id: 1
+project_id: 1
+created_at: 2024-03-01
+completed_at: 2024-03-05
+---
+- title: Second
+  due_date: 2024-03-04T12:00:00+05:00
+- title: Third
+`), 0o600)
+
+	out, err := runPmctl(t, "", "tasks", "create", "-f", file, "--base-url", server.URL, "-o", "json")
+	assert.NoError(t, err)
+	assert.Len(t, bodies, 3, "должны быть созданы все задачи из файла")
+	assert.Equal(t, "2024-03-01", bodies[0]["created_at"], "дата должна отправляться в формате API")
+	assert.Equal(t, float64(1), bodies[0]["project_id"])
+	assert.Equal(t, "2024-03-04T12:00:00+05:00", bodies[1]["due_date"], "время должно сохранять смещение")
+	var created []models.Task
+	assert.NoError(t, json.Unmarshal([]byte(out), &created))
+	assert.Len(t, created, 3)
+
+	bodies = nil
+	_, err = runPmctl(t, `[{"title": "From stdin"}]`, "tasks", "create", "-f", "-", "--base-url", server.URL)
+	assert.NoError(t, err)
+	assert.Len(t, bodies, 1, "задачи должны читаться из стандартного ввода")
+
+	_, err = runPmctl(t, "", "tasks", "create", "-f", file, "--title", "Other", "--base-url", server.URL)
+	assert.Error(t, err, "флаги задачи нельзя совмещать с файлом")
+}
+
+func TestCLI_TasksTransition(t *testing.T) {
+	var put map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tasks/1", r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(cliTasks()[0])
+		case http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&put)
+			task := cliTasks()[0]
+			task.Status = put["status"].(string)
+			json.NewEncoder(w).Encode(task)
+		}
+	}))
+	defer server.Close()
+
+	out, err := runPmctl(t, "", "tasks", "transition", "1", "done", "--base-url", server.URL)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "done")
+	assert.Equal(t, "done", put["status"], "статус должен быть изменен")
+	assert.Equal(t, "Design schema", put["title"], "остальные поля должны сохраниться")
+	assert.Equal(t, "2024-03-01", put["created_at"])
+	assert.Equal(t, "2024-03-05", put["completed_at"])
+	assert.Equal(t, "2024-03-10T23:59:59Z", put["due_date"])
+}
+
+func TestCLI_ProblemError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(problem.Problem{
+			Status: http.StatusUnprocessableEntity,
+			Code:   problem.CodeValidationFailed,
+			Detail: "Validation failed",
+			Errors: []problem.FieldError{{Field: "assignee_id", Message: "assignee_id is required"}},
+		})
+	}))
+	defer server.Close()
+
+	_, err := runPmctl(t, "", "tasks", "create", "--title", "Task", "--base-url", server.URL)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Validation failed (HTTP 422, "+problem.CodeValidationFailed+")")
+	assert.Contains(t, err.Error(), "assignee_id: assignee_id is required", "ошибка должна содержать ошибки полей")
+}
+
+func TestCLI_Profiles(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		assert.Equal(t, "john", r.URL.Query().Get("name"))
+		json.NewEncoder(w).Encode([]models.User{{ID: 3, Name: "John", Email: "john@example.com", Role: "admin"}})
+	}))
+	defer server.Close()
+	t.Setenv("PMCTL_CONFIG", filepath.Join(t.TempDir(), "pmctl", "config.yaml"))
+
+	_, err := runPmctl(t, "", "config", "set-profile", "local", "--base-url", "http://localhost:1")
+	assert.NoError(t, err)
+	_, err = runPmctl(t, "", "config", "set-profile", "test", "--base-url", server.URL, "--token", "secret")
+	assert.NoError(t, err)
+	_, err = runPmctl(t, "", "config", "use-profile", "test")
+	assert.NoError(t, err)
+
+	out, err := runPmctl(t, "", "config", "list")
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "secret", "токен не должен выводиться")
+	assert.Regexp(t, `\*\s+test`, out, "текущий профиль должен быть отмечен")
+
+	out, err = runPmctl(t, "", "users", "search", "--name", "john")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "john@example.com")
+	assert.Equal(t, "Bearer secret", authorization, "должен использоваться токен профиля")
+
+	_, err = runPmctl(t, "", "users", "search", "--name", "john", "-p", "local", "--base-url", server.URL, "--token", "other")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer other", authorization, "флаг должен переопределять профиль")
+
+	_, err = runPmctl(t, "", "users", "search", "--name", "john", "-p", "missing")
+	assert.Error(t, err, "несуществующий профиль должен быть ошибкой")
+}
+
+func TestCLI_Workspace(t *testing.T) {
+	var user, workspace string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, workspace = r.Header.Get("X-User-ID"), r.Header.Get("X-Workspace-ID")
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 2, "name": "Acme", "slug": "acme", "role": "admin"}})
+	}))
+	defer server.Close()
+	t.Setenv("PMCTL_CONFIG", filepath.Join(t.TempDir(), "pmctl", "config.yaml"))
+
+	_, err := runPmctl(t, "", "config", "set-profile", "acme", "--base-url", server.URL, "--user", "5", "--workspace", "2")
+	assert.NoError(t, err)
+
+	out, err := runPmctl(t, "", "workspaces", "list")
+	assert.NoError(t, err)
+	assert.Regexp(t, `2\s+Acme\s+acme\s+admin`, out, "рабочее пространство должно быть в таблице")
+	assert.Equal(t, "5", user, "должен использоваться пользователь профиля")
+	assert.Equal(t, "2", workspace, "должно использоваться рабочее пространство профиля")
+
+	_, err = runPmctl(t, "", "users", "search", "--name", "john", "-w", "3")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", workspace, "флаг должен переопределять профиль")
+}
+
+func TestCLI_ProjectMembers(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"project_id": 7, "user_id": 1, "role": "owner", "name": "John", "email": "john@example.com"},
+			{"project_id": 7, "user_id": 4, "role": "viewer", "name": "Jane", "email": "jane@example.com"},
+		})
+	}))
+	defer server.Close()
+
+	out, err := runPmctl(t, "", "projects", "members", "7", "--base-url", server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "/projects/7/members", path)
+	assert.Regexp(t, `1\s+John\s+john@example.com\s+owner`, out, "владелец должен быть в таблице")
+	assert.Regexp(t, `4\s+Jane\s+jane@example.com\s+viewer`, out, "наблюдатель должен быть в таблице")
+
+	_, err = runPmctl(t, "", "projects", "members", "seven", "--base-url", server.URL)
+	assert.Error(t, err, "неверный ID проекта должен отклоняться")
+}
+
+func TestCLI_UsersInvite(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/invitations/", r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 3, "email": "new@example.com", "role": "manager", "expires_at": "2026-10-26T10:00:00Z"})
+	}))
+	defer server.Close()
+
+	out, err := runPmctl(t, "", "users", "invite", "new@example.com", "--role", "manager", "--project", "7", "--base-url", server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"email": "new@example.com", "role": "manager", "project_id": float64(7)}, body, "приглашение должно отправляться с ролью и проектом")
+	assert.Regexp(t, `3\s+new@example.com\s+manager\s+2026-10-26`, out, "приглашение должно быть в таблице")
+}
+
+func TestCLI_TokensCreate(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/me/tokens", r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 4, "name": "CI", "prefix": "pat_abcdef", "scopes": []string{"tasks:read", "projects:write"},
+			"expires_at": "2026-11-18T10:00:00Z", "token": "pat_abcdefsecret"})
+	}))
+	defer server.Close()
+
+	out, err := runPmctl(t, "", "tokens", "create", "CI", "--scope", "tasks:read,projects:write", "--base-url", server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"tasks:read", "projects:write"}, body["scopes"], "области должны отправляться списком")
+	assert.NotContains(t, body, "expires_at", "без --expires срок выбирает сервер")
+	assert.Regexp(t, `4\s+CI\s+pat_abcdef\s+tasks:read,projects:write\s+2026-11-18\s+never\s+pat_abcdefsecret`, out, "токен должен выводиться один раз")
+
+	_, err = runPmctl(t, "", "tokens", "revoke", "x", "--base-url", server.URL)
+	assert.EqualError(t, err, `invalid token ID "x"`)
+}
@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"github.com/togzhanzhakhani/projects/pkg/client"
+	"gorm.io/gorm"
+)
+
+// newTestClient returns a client of server that retries without waiting.
+func newTestClient(server *httptest.Server) *client.Client {
+	c := client.NewClient(server.URL)
+	c.Retry = client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	return c
+}
+
+func TestClient_Tasks(t *testing.T) {
+	repo := new(MockTaskRepository)
+	server := httptest.NewServer(validatedRouter(t, repo))
+	defer server.Close()
+	c := newTestClient(server)
+	ctx := context.Background()
+
+	var stored *models.Task
+	repo.On("GetProject", 5).Return(&models.Project{ID: 5, EndDate: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)}, nil)
+	repo.On("CreateTask", mock.AnythingOfType("*models.Task")).Return(nil).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.Task)
+		stored.ID = 12
+	})
+	due := time.Date(2024, 7, 10, 18, 0, 0, 0, time.FixedZone("", 5*60*60))
+	task, err := c.Tasks.Create(ctx, &client.Task{
+		Title: "Finish Report", Description: "Quarterly report", Priority: client.PriorityHigh, Status: client.StatusTodo,
+		AssigneeID: 3, ProjectID: 5,
+		CreatedAt:   time.Date(2024, 7, 1, 9, 30, 0, 0, time.UTC),
+		CompletedAt: time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+		DueDate:     &due,
+	})
+	assert.NoError(t, err, "задача должна быть создана")
+	assert.Equal(t, 12, task.ID, "ID задачи не соответствует ожидаемому")
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), stored.CreatedAt, "отправляется только дата")
+	assert.True(t, due.Equal(*stored.DueDate), "срок должен сохранить смещение")
+
+	repo.On("SearchTasksByStatus", "done").Return([]models.Task{{ID: 1, Status: "done"}, {ID: 2, Status: "done"}}, nil)
+	tasks, err := c.Tasks.Search(ctx, client.TaskQuery{Status: client.StatusDone}).All()
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2, "поиск должен вернуть все найденные задачи")
+	repo.AssertNotCalled(t, "DeleteTask", mock.Anything)
+
+	_, err = c.Tasks.Create(ctx, &client.Task{Title: "No dates", Priority: "urgent"})
+	var apiErr *client.Error
+	assert.True(t, errors.As(err, &apiErr), "ошибка должна быть *client.Error")
+	assert.True(t, errors.Is(err, client.ErrValidationFailed), "код ошибки не соответствует ожидаемому: %v", err)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.NotEmpty(t, apiErr.Errors, "ошибка должна содержать ошибки полей")
+	assert.NotEmpty(t, apiErr.TraceID)
+}
+
+func TestClient_Users(t *testing.T) {
+	handler, repo := setupUserHandler(t)
+	router := gin.New()
+	router.GET("/users/:id", handler.GetUserByID)
+	router.DELETE("/users/:id", handler.DeleteUser)
+	router.GET("/users/search", handler.SearchUsersByName)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	c := newTestClient(server)
+	c.AcceptLanguage = "ru"
+	ctx := context.Background()
+
+	repo.On("GetUserByID", uint(1)).Return(&models.User{ID: 1, Name: "John", Email: "john@example.com", Role: "admin"}, nil)
+	repo.On("GetUserByID", uint(2)).Return(&models.User{}, gorm.ErrRecordNotFound)
+	repo.On("FindByName", "jo").Return([]models.User{{ID: 1, Name: "John"}}, nil)
+
+	user, err := c.Users.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", user.Email)
+
+	_, err = c.Users.Get(ctx, 2)
+	assert.True(t, errors.Is(err, client.ErrNotFound), "должна быть ошибка ErrNotFound")
+	assert.False(t, errors.Is(err, client.ErrConflict))
+	assert.Contains(t, err.Error(), "Пользователь не найден", "сообщение должно быть на языке запроса")
+
+	users, err := c.Users.Search(ctx, client.UserQuery{Name: "jo"}).All()
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	_, err = c.Users.Search(ctx, client.UserQuery{}).All()
+	assert.Error(t, err, "пустой запрос не должен отправляться")
+	repo.AssertNumberOfCalls(t, "FindByName", 1)
+}
+
+func TestClient_IteratorFollowsLinks(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `</projects/?page=2>; rel="next", </projects/?page=3>; rel="last"`)
+			json.NewEncoder(w).Encode([]models.Project{{ID: 1}, {ID: 2}})
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/projects/?page=3>; rel="next"`, server.URL))
+			json.NewEncoder(w).Encode([]models.Project{})
+		case "3":
+			json.NewEncoder(w).Encode([]models.Project{{ID: 3}})
+		}
+	}))
+	defer server.Close()
+	c := newTestClient(server)
+
+	var ids []int
+	it := c.Projects.List(context.Background())
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int{1, 2, 3}, ids, "итератор должен пройти все страницы, включая пустые")
+}
+
+func TestClient_Retries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		switch {
+		case r.URL.Path == "/tasks/1" && n == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/tasks/1" && n == 2:
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Path == "/tasks/1":
+			json.NewEncoder(w).Encode(models.Task{ID: 1})
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":500,"code":"internal_error","detail":"Failed to create task"}`))
+		}
+	}))
+	defer server.Close()
+	c := newTestClient(server)
+	ctx := context.Background()
+
+	task, err := c.Tasks.Get(ctx, 1)
+	assert.NoError(t, err, "запрос должен быть повторен после 429 и 5xx")
+	assert.Equal(t, 1, task.ID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	_, err = c.Tasks.Create(ctx, &client.Task{Title: "Task"})
+	assert.True(t, errors.Is(err, client.ErrInternal))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "POST не должен повторяться после 5xx")
+
+	atomic.StoreInt32(&calls, 0)
+	_, err = c.Projects.Get(ctx, 1)
+	assert.True(t, errors.Is(err, client.ErrInternal))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls), "число попыток ограничено политикой")
+
+	c.Retry = client.RetryPolicy{MaxAttempts: 5, MinBackoff: time.Hour, MaxBackoff: time.Hour}
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.Projects.Get(ctx, 1)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second, "отмена контекста должна прерывать ожидание")
+}
+
+func TestClient_ImportReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tasks", r.URL.Query().Get("entity"))
+		assert.Equal(t, "Name:title", r.URL.Query().Get("map"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"entity":"tasks","format":"csv","total_rows":2,"valid_rows":1,"invalid_rows":1,"errors":[{"row":3,"errors":["title is required"]}]}`))
+	}))
+	defer server.Close()
+	c := newTestClient(server)
+
+	_, err := c.Imports.Import(context.Background(), client.ImportRequest{
+		Entity:  "tasks",
+		Format:  client.FormatCSV,
+		File:    strings.NewReader("Name\nTask\n\n"),
+		Mapping: map[string]string{"Name": "title"},
+	})
+	assert.True(t, errors.Is(err, client.ErrValidationFailed), "отклоненный импорт должен быть ошибкой")
+	var apiErr *client.Error
+	assert.True(t, errors.As(err, &apiErr))
+	report, ok := apiErr.Report.(*client.ImportReport)
+	assert.True(t, ok, "ошибка должна содержать отчет импорта")
+	assert.Equal(t, []client.RowError{{Row: 3, Errors: []string{"title is required"}}}, report.Errors)
+}
+
+func TestClient_GraphQL(t *testing.T) {
+	server := httptest.NewServer(graphQLRouter(newFakeGraphRepository()))
+	defer server.Close()
+	c := newTestClient(server)
+
+	var data struct {
+		Task struct {
+			Title string `json:"title"`
+		} `json:"task"`
+	}
+	err := c.GraphQL.Query(context.Background(), `query($id: ID!) { task(id: $id) { title } }`, map[string]interface{}{"id": 1}, &data)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, data.Task.Title, "данные должны быть декодированы")
+
+	err = c.GraphQL.Query(context.Background(), `{ nope }`, nil, nil)
+	var gqlErrs client.GraphQLErrors
+	assert.True(t, errors.As(err, &gqlErrs), "ошибки запроса должны быть GraphQLErrors")
+
+	schema, err := c.GraphQL.Schema(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, schema, "type Query")
+}
+
+func TestClient_Workspaces(t *testing.T) {
+	repo := new(MockWorkspaceRepository)
+	handler := handlers.NewWorkspaceHandler(repo, handlers.NewInvitationHandler(new(MockInvitationRepository), new(MockUserRepository), &recordingSender{}))
+	router := gin.New()
+	router.Use(auth.Middleware(auth.Header))
+	router.GET("/workspaces/", handler.GetWorkspaces)
+	router.POST("/workspaces/:id/members", handler.AddMember)
+	scoped := router.Group("/", workspace.Middleware(fakeMembers{{2, 5}: models.WorkspaceMember}))
+	// The user returned is the caller, in the workspace of the request.
+	scoped.GET("/users/:id", func(c *gin.Context) {
+		membership, _ := workspace.FromContext(c.Request.Context())
+		c.JSON(http.StatusOK, models.User{ID: membership.UserID, Role: membership.Role, Name: strconv.FormatUint(uint64(membership.WorkspaceID), 10)})
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+	c := newTestClient(server)
+	ctx := context.Background()
+
+	_, err := c.Workspaces.List(ctx)
+	assert.True(t, errors.Is(err, client.ErrUnauthorized), "без пользователя запрос должен отклоняться: %v", err)
+
+	c.UserID = 5
+	repo.On("GetWorkspacesByUser", uint(5)).Return([]repository.UserWorkspace{{Workspace: models.Workspace{ID: 2, Name: "Acme", Slug: "acme"}, Role: "member"}}, nil)
+	workspaces, err := c.Workspaces.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.UserWorkspace{{Workspace: client.Workspace{ID: 2, Name: "Acme", Slug: "acme"}, Role: "member"}}, workspaces)
+
+	repo.On("GetMember", uint(2), uint(5)).Return(&models.Membership{WorkspaceID: 2, UserID: 5, Role: models.WorkspaceMember}, nil)
+	_, err = c.Workspaces.AddMember(ctx, 2, "jane@example.com", client.WorkspaceMember)
+	assert.True(t, errors.Is(err, client.ErrForbidden), "только администратор может добавлять участников: %v", err)
+
+	_, err = c.Users.Get(ctx, 5)
+	assert.True(t, errors.Is(err, client.ErrNotFound), "без заголовка используется рабочее пространство по умолчанию: %v", err)
+
+	c.WorkspaceID = 2
+	user, err := c.Users.Get(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", user.Name, "запрос должен выполняться в выбранном рабочем пространстве")
+}
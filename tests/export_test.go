@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/export"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) StreamTasks(ctx context.Context, filter repository.TaskFilter, fn func(repository.TaskExportRow) error) error {
+	args := m.Called(filter)
+	for _, row := range args.Get(0).([]repository.TaskExportRow) {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockExportRepository) StreamProjects(ctx context.Context, fn func(repository.ProjectExportRow) error) error {
+	args := m.Called()
+	for _, row := range args.Get(0).([]repository.ProjectExportRow) {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func TestExportTasks_CSVWithResolvedNames(t *testing.T) {
+	repo := new(MockExportRepository)
+	handler := handlers.NewExportHandler(repo)
+
+	rows := []repository.TaskExportRow{{
+		Task: models.Task{
+			ID: 1, Title: "Report, Q3", Description: "Quarterly", Priority: "high", Status: "todo",
+			AssigneeID: 3, ProjectID: 5,
+			CreatedAt:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			CompletedAt: time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+		},
+		AssigneeName: "John Doe",
+		ProjectName:  "Alpha",
+	}}
+	repo.On("StreamTasks", repository.TaskFilter{Status: "todo", ProjectID: 5}).Return(rows, nil)
+
+	req, _ := http.NewRequest("GET", "/export/tasks?status=todo&project=5", nil)
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.GET("/export/tasks", handler.ExportTasks)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	assert.Equal(t, "id,title,description,priority,status,assignee_id,assignee_name,project_id,project_name,created_at,completed_at,due_date", lines[0])
+	assert.Equal(t, `1,"Report, Q3",Quarterly,high,todo,3,John Doe,5,Alpha,2024-07-01,2024-07-15,`, lines[1])
+}
+
+func TestExportTasks_RejectsUnknownFormat(t *testing.T) {
+	repo := new(MockExportRepository)
+	handler := handlers.NewExportHandler(repo)
+
+	req, _ := http.NewRequest("GET", "/export/tasks?format=pdf", nil)
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.GET("/export/tasks", handler.ExportTasks)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	repo.AssertNotCalled(t, "StreamTasks", mock.Anything)
+}
+
+func TestExportWriter_XLSXIsValidWorkbook(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := export.NewWriter(export.FormatXLSX, &buf, []string{"id", "name", "start_date"})
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteRow([]interface{}{1, "R&D <core>", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)}))
+	assert.NoError(t, writer.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	var names []string
+	var sheet string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, _ := f.Open()
+			data, _ := io.ReadAll(r)
+			sheet = string(data)
+		}
+	}
+	assert.Contains(t, names, "[Content_Types].xml")
+	assert.Contains(t, names, "xl/workbook.xml")
+	assert.Contains(t, sheet, `<c r="A2"><v>1</v></c>`)
+	assert.Contains(t, sheet, "R&amp;D &lt;core&gt;")
+	assert.Contains(t, sheet, `<c r="C2" s="1"><v>45474</v></c>`)
+}
@@ -0,0 +1,466 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/togzhanzhakhani/projects/internal/graph"
+	"github.com/togzhanzhakhani/projects/internal/graphql"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+)
+
+// fakeGraphRepository keeps the records in memory and counts the calls of
+// each method, so that tests can check the batching of the loaders.
+type fakeGraphRepository struct {
+	users    []models.User
+	projects []models.Project
+	tasks    []models.Task
+	calls    map[string]int
+}
+
+func newFakeGraphRepository() *fakeGraphRepository {
+	date := func(s string) time.Time {
+		t, _ := time.Parse("2006-01-02", s)
+		return t
+	}
+	return &fakeGraphRepository{
+		users: []models.User{
+			{ID: 1, Name: "Alice", Email: "alice@example.com", Role: "manager", RegistrationDate: date("2024-01-01")},
+			{ID: 2, Name: "Bob", Email: "bob@example.com", Role: "developer", RegistrationDate: date("2024-02-01")},
+			{ID: 3, Name: "Carol", Email: "carol@example.com", Role: "developer", RegistrationDate: date("2024-03-01")},
+		},
+		projects: []models.Project{
+			{ID: 1, Name: "Website", Description: "Company website", StartDate: date("2024-01-01"), EndDate: date("2024-12-31"), ManagerID: 1},
+			{ID: 2, Name: "Mobile app", Description: "iOS and Android", StartDate: date("2024-02-01"), EndDate: date("2024-06-30"), ManagerID: 1},
+		},
+		tasks: []models.Task{
+			{ID: 1, Title: "Design", Description: "Mockups", Priority: "high", Status: "done", AssigneeID: 2, ProjectID: 1, CreatedAt: date("2024-01-02"), CompletedAt: date("2024-01-10")},
+			{ID: 2, Title: "Backend", Description: "API", Priority: "high", Status: "in_progress", AssigneeID: 2, ProjectID: 1, CreatedAt: date("2024-01-05"), CompletedAt: date("2024-03-01")},
+			{ID: 3, Title: "Frontend", Description: "Pages", Priority: "medium", Status: "todo", AssigneeID: 3, ProjectID: 1, CreatedAt: date("2024-01-05"), CompletedAt: date("2024-03-01")},
+			{ID: 4, Title: "Release", Description: "Store", Priority: "low", Status: "todo", AssigneeID: 3, ProjectID: 2, CreatedAt: date("2024-02-05"), CompletedAt: date("2024-06-01"), Labels: []string{"release"}},
+		},
+		calls: make(map[string]int),
+	}
+}
+
+func contains(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func paged(n int, page repository.Page) (int, int) {
+	from, to := page.Offset, n
+	if from > n {
+		from = n
+	}
+	if page.Limit > 0 && from+page.Limit < n {
+		to = from + page.Limit
+	}
+	return from, to
+}
+
+func matchesTask(task models.Task, filter repository.TaskFilter) bool {
+	return (filter.Title == "" || strings.Contains(task.Title, filter.Title)) &&
+		(filter.Status == "" || task.Status == filter.Status) &&
+		(filter.Priority == "" || task.Priority == filter.Priority) &&
+		(filter.AssigneeID == 0 || task.AssigneeID == int(filter.AssigneeID)) &&
+		(filter.ProjectID == 0 || task.ProjectID == int(filter.ProjectID))
+}
+
+func (f *fakeGraphRepository) WithContext(ctx context.Context) repository.GraphRepository {
+	return f
+}
+
+func (f *fakeGraphRepository) FindUsers(filter repository.UserFilter, page repository.Page) ([]models.User, error) {
+	f.calls["FindUsers"]++
+	var users []models.User
+	for _, user := range f.users {
+		if strings.Contains(user.Name, filter.Name) && strings.Contains(user.Email, filter.Email) && (filter.Role == "" || user.Role == filter.Role) {
+			users = append(users, user)
+		}
+	}
+	from, to := paged(len(users), page)
+	return users[from:to], nil
+}
+
+func (f *fakeGraphRepository) FindProjects(filter repository.ProjectFilter, page repository.Page) ([]models.Project, error) {
+	f.calls["FindProjects"]++
+	var projects []models.Project
+	for _, project := range f.projects {
+		if strings.Contains(project.Name, filter.Name) && (filter.ManagerID == 0 || project.ManagerID == filter.ManagerID) {
+			projects = append(projects, project)
+		}
+	}
+	from, to := paged(len(projects), page)
+	return projects[from:to], nil
+}
+
+func (f *fakeGraphRepository) FindTasks(filter repository.TaskFilter, page repository.Page) ([]models.Task, error) {
+	f.calls["FindTasks"]++
+	var tasks []models.Task
+	for _, task := range f.tasks {
+		if matchesTask(task, filter) {
+			tasks = append(tasks, task)
+		}
+	}
+	from, to := paged(len(tasks), page)
+	return tasks[from:to], nil
+}
+
+func (f *fakeGraphRepository) GetUsersByIDs(ids []int) ([]models.User, error) {
+	f.calls["GetUsersByIDs"]++
+	var users []models.User
+	for _, user := range f.users {
+		if contains(ids, int(user.ID)) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeGraphRepository) GetProjectsByIDs(ids []int) ([]models.Project, error) {
+	f.calls["GetProjectsByIDs"]++
+	var projects []models.Project
+	for _, project := range f.projects {
+		if contains(ids, project.ID) {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
+
+func (f *fakeGraphRepository) GetTasksByIDs(ids []int) ([]models.Task, error) {
+	f.calls["GetTasksByIDs"]++
+	var tasks []models.Task
+	for _, task := range f.tasks {
+		if contains(ids, task.ID) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (f *fakeGraphRepository) GetProjectsByManagers(managerIDs []int) ([]models.Project, error) {
+	f.calls["GetProjectsByManagers"]++
+	var projects []models.Project
+	for _, project := range f.projects {
+		if contains(managerIDs, project.ManagerID) {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
+
+func (f *fakeGraphRepository) GetTasksByAssignees(assigneeIDs []int, filter repository.TaskFilter) ([]models.Task, error) {
+	f.calls["GetTasksByAssignees"]++
+	var tasks []models.Task
+	for _, task := range f.tasks {
+		if contains(assigneeIDs, task.AssigneeID) && matchesTask(task, filter) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (f *fakeGraphRepository) GetTasksByProjects(projectIDs []int, filter repository.TaskFilter) ([]models.Task, error) {
+	f.calls["GetTasksByProjects"]++
+	var tasks []models.Task
+	for _, task := range f.tasks {
+		if contains(projectIDs, task.ProjectID) && matchesTask(task, filter) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (f *fakeGraphRepository) CreateUser(user *models.User) error {
+	f.calls["CreateUser"]++
+	user.ID = uint(len(f.users) + 1)
+	f.users = append(f.users, *user)
+	return nil
+}
+
+func (f *fakeGraphRepository) UpdateUser(user *models.User) error {
+	f.calls["UpdateUser"]++
+	for i := range f.users {
+		if f.users[i].ID == user.ID {
+			f.users[i] = *user
+		}
+	}
+	return nil
+}
+
+func (f *fakeGraphRepository) DeleteUser(id int) error {
+	f.calls["DeleteUser"]++
+	return nil
+}
+
+func (f *fakeGraphRepository) CreateProject(project *models.Project) error {
+	f.calls["CreateProject"]++
+	project.ID = len(f.projects) + 1
+	f.projects = append(f.projects, *project)
+	return nil
+}
+
+func (f *fakeGraphRepository) UpdateProject(project *models.Project) error {
+	f.calls["UpdateProject"]++
+	return nil
+}
+
+func (f *fakeGraphRepository) DeleteProject(id int) error {
+	f.calls["DeleteProject"]++
+	return nil
+}
+
+func (f *fakeGraphRepository) CreateTask(task *models.Task) error {
+	f.calls["CreateTask"]++
+	task.ID = len(f.tasks) + 1
+	f.tasks = append(f.tasks, *task)
+	return nil
+}
+
+func (f *fakeGraphRepository) UpdateTask(task *models.Task) error {
+	f.calls["UpdateTask"]++
+	return nil
+}
+
+func (f *fakeGraphRepository) DeleteTask(id int) error {
+	f.calls["DeleteTask"]++
+	return nil
+}
+
+func graphQLRouter(repo repository.GraphRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.Register(router, routes.Handlers{
+		GraphQL: handlers.NewGraphQLHandler(graph.NewResolver(repo)),
+		Scope:   []gin.HandlerFunc{asMember(models.WorkspaceAdmin)},
+	})
+	return router
+}
+
+type graphQLResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, router *gin.Engine, query string, variables map[string]interface{}) (graphQLResponse, map[string]json.RawMessage) {
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	rr := sendJSON(router, "POST", "/graphql", string(body))
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+
+	var resp graphQLResponse
+	var raw map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp), "ответ должен быть JSON")
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &raw))
+	return resp, raw
+}
+
+func TestGraphQL_NestedQueryIsBatched(t *testing.T) {
+	repo := newFakeGraphRepository()
+	router := graphQLRouter(repo)
+
+	resp, _ := postGraphQL(t, router, `{
+		tasks { id title assignee { name } project { name manager { email } } }
+	}`, nil)
+	assert.Empty(t, resp.Errors, "запрос не должен возвращать ошибок")
+
+	tasks := resp.Data["tasks"].([]interface{})
+	assert.Len(t, tasks, 4, "количество задач не соответствует ожидаемому")
+	first := tasks[0].(map[string]interface{})
+	assert.Equal(t, "1", first["id"], "ID задачи должен быть строкой")
+	assert.Equal(t, "Bob", first["assignee"].(map[string]interface{})["name"])
+	assert.Equal(t, "alice@example.com", first["project"].(map[string]interface{})["manager"].(map[string]interface{})["email"])
+
+	assert.Equal(t, 1, repo.calls["FindTasks"], "задачи должны загружаться одним запросом")
+	assert.Equal(t, 1, repo.calls["GetProjectsByIDs"], "проекты должны загружаться одним запросом")
+	// Assignees and managers are on different levels of the query.
+	assert.Equal(t, 2, repo.calls["GetUsersByIDs"], "пользователи должны загружаться одним запросом на уровень")
+}
+
+func TestGraphQL_RelationsWithFilters(t *testing.T) {
+	repo := newFakeGraphRepository()
+	router := graphQLRouter(repo)
+
+	resp, _ := postGraphQL(t, router, `{
+		users(role: developer) {
+			name
+			open: tasks(status: todo) { title }
+			all: tasks(limit: 1, offset: 1) { title }
+		}
+		user(id: "1") { managedProjects { name tasks(priority: high) { id } } }
+	}`, nil)
+	assert.Empty(t, resp.Errors, "запрос не должен возвращать ошибок")
+
+	users := resp.Data["users"].([]interface{})
+	assert.Len(t, users, 2, "фильтр по роли не применен")
+	carol := users[1].(map[string]interface{})
+	assert.Equal(t, "Carol", carol["name"])
+	assert.Len(t, carol["open"], 2, "фильтр по статусу не применен")
+	assert.Equal(t, []interface{}{map[string]interface{}{"title": "Release"}}, carol["all"], "пагинация не применена")
+	bob := users[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{}, bob["open"], "пустой список должен быть массивом")
+
+	projects := resp.Data["user"].(map[string]interface{})["managedProjects"].([]interface{})
+	assert.Len(t, projects, 2, "количество проектов не соответствует ожидаемому")
+	assert.Len(t, projects[0].(map[string]interface{})["tasks"], 2)
+	assert.Equal(t, 1, repo.calls["GetTasksByProjects"], "задачи проектов должны загружаться одним запросом")
+	assert.Equal(t, 2, repo.calls["GetTasksByAssignees"], "каждый фильтр загружается одним запросом")
+}
+
+func TestGraphQL_VariablesAndFragments(t *testing.T) {
+	router := graphQLRouter(newFakeGraphRepository())
+
+	resp, _ := postGraphQL(t, router, `
+		query Task($id: ID!, $withProject: Boolean = false) {
+			task(id: $id) { ...summary project @include(if: $withProject) { name } __typename }
+		}
+		fragment summary on Task { title status labels }`,
+		map[string]interface{}{"id": "4", "withProject": true})
+	assert.Empty(t, resp.Errors, "запрос не должен возвращать ошибок")
+	assert.Equal(t, map[string]interface{}{
+		"title":      "Release",
+		"status":     "todo",
+		"labels":     []interface{}{"release"},
+		"project":    map[string]interface{}{"name": "Mobile app"},
+		"__typename": "Task",
+	}, resp.Data["task"])
+}
+
+func TestGraphQL_Errors(t *testing.T) {
+	router := graphQLRouter(newFakeGraphRepository())
+
+	resp, raw := postGraphQL(t, router, `{ tasks { nope } }`, nil)
+	assert.Len(t, resp.Errors, 1, "неизвестное поле должно быть ошибкой")
+	assert.Contains(t, resp.Errors[0].Message, "nope")
+	_, hasData := raw["data"]
+	assert.False(t, hasData, "невалидный запрос не выполняется")
+
+	resp, _ = postGraphQL(t, router, `{ tasks { id `, nil)
+	assert.Len(t, resp.Errors, 1, "синтаксическая ошибка должна быть в ответе")
+
+	resp, raw = postGraphQL(t, router, `{ user(id: "42") { name } task(id: "x") { id } }`, nil)
+	assert.Nil(t, resp.Data["user"], "несуществующий пользователь должен быть null")
+	assert.Len(t, resp.Errors, 1, "количество ошибок не соответствует ожидаемому")
+	assert.Equal(t, "Invalid task ID", resp.Errors[0].Message)
+	assert.Equal(t, []interface{}{"task"}, resp.Errors[0].Path)
+	assert.Equal(t, problem.CodeInvalidRequest, resp.Errors[0].Extensions["code"])
+	assert.Contains(t, string(raw["data"]), `"task":null`)
+
+	rr := sendJSON(router, "POST", "/graphql", `{"variables":{}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "запрос без query должен отклоняться")
+}
+
+func TestGraphQL_Limits(t *testing.T) {
+	repo := newFakeGraphRepository()
+	router := graphQLRouter(repo)
+
+	// Eleven levels of fields, five of them spread through fragments.
+	resp, raw := postGraphQL(t, router, `
+		{ tasks { project { manager { ...deep } } } }
+		fragment deep on User { managedProjects { manager { managedProjects { ...deeper } } } }
+		fragment deeper on Project { manager { managedProjects { manager { managedProjects { name } } } } }`, nil)
+	assert.Len(t, resp.Errors, 1, "слишком глубокий запрос должен отклоняться")
+	assert.Contains(t, resp.Errors[0].Message, "nested 11 levels deep")
+	_, hasData := raw["data"]
+	assert.False(t, hasData, "слишком глубокий запрос не выполняется")
+	assert.Zero(t, repo.calls["FindTasks"], "резолверы не должны вызываться")
+
+	resp, _ = postGraphQL(t, router, "{"+strings.Repeat("tasks { ", 100)+"id"+strings.Repeat(" }", 100)+" }", nil)
+	assert.Len(t, resp.Errors, 1, "слишком глубокая вложенность должна отклоняться при разборе")
+	assert.Contains(t, resp.Errors[0].Message, "nested more than")
+
+	resp, _ = postGraphQL(t, router, "{ tasks { id } }"+strings.Repeat(" ", graphql.MaxQuerySize), nil)
+	assert.Len(t, resp.Errors, 1, "слишком длинный запрос должен отклоняться")
+	assert.Contains(t, resp.Errors[0].Message, "longer than")
+}
+
+func TestGraphQL_ErrorLocations(t *testing.T) {
+	schema := graph.NewResolver(newFakeGraphRepository()).Schema()
+	resp := graphql.Execute(context.Background(), schema, graphql.Request{Query: "{ tasks(title: \"ёж\") { id } }\n  { ! }"})
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, []graphql.Location{{Line: 2, Column: 5}}, resp.Errors[0].Locations)
+
+	resp = graphql.Execute(context.Background(), schema, graphql.Request{Query: "{ tasks(title: \"ёжик\") { id } ?"})
+	assert.Equal(t, []graphql.Location{{Line: 1, Column: 31}}, resp.Errors[0].Locations, "колонки считаются в символах, а не в байтах")
+}
+
+func TestGraphQL_Mutations(t *testing.T) {
+	repo := newFakeGraphRepository()
+	router := graphQLRouter(repo)
+
+	resp, _ := postGraphQL(t, router, `mutation($input: TaskInput!) {
+		createTask(input: $input) { id title dueDate assignee { name } }
+	}`, map[string]interface{}{"input": map[string]interface{}{
+		"title": "Docs", "description": "Write docs", "priority": "low", "status": "todo",
+		"assigneeId": "2", "projectId": "1", "createdAt": "2024-03-01", "completedAt": "2024-04-01",
+		"dueDate": "2024-04-01",
+	}})
+	assert.Empty(t, resp.Errors, "мутация не должна возвращать ошибок")
+	assert.Equal(t, map[string]interface{}{
+		"id": "5", "title": "Docs", "dueDate": "2024-04-01T23:59:59Z",
+		"assignee": map[string]interface{}{"name": "Bob"},
+	}, resp.Data["createTask"])
+	assert.Equal(t, 1, repo.calls["CreateTask"], "задача должна быть создана")
+
+	resp, _ = postGraphQL(t, router, `mutation {
+		createTask(input: {title: "", description: "x", priority: low, status: todo,
+			assigneeId: "2", projectId: "1", createdAt: "2024-03-01", completedAt: "2024-02-01"}) { id }
+	}`, nil)
+	assert.Nil(t, resp.Data, "данные должны быть null, так как поле обязательно")
+	assert.Len(t, resp.Errors, 1, "количество ошибок не соответствует ожидаемому")
+	assert.Equal(t, problem.CodeValidationFailed, resp.Errors[0].Extensions["code"])
+	var fields []string
+	for _, fieldError := range resp.Errors[0].Extensions["errors"].([]interface{}) {
+		fields = append(fields, fieldError.(map[string]interface{})["field"].(string))
+	}
+	sort.Strings(fields)
+	assert.Equal(t, []string{"completedAt", "title"}, fields, "поля ошибок должны называться как в схеме")
+	assert.Equal(t, 1, repo.calls["CreateTask"], "невалидная задача не должна создаваться")
+
+	resp, _ = postGraphQL(t, router, `mutation { deleteProject(id: "9") }`, nil)
+	assert.Len(t, resp.Errors, 1, "количество ошибок не соответствует ожидаемому")
+	assert.Equal(t, "Project not found", resp.Errors[0].Message)
+	assert.Equal(t, problem.CodeNotFound, resp.Errors[0].Extensions["code"])
+	assert.Equal(t, 0, repo.calls["DeleteProject"], "несуществующий проект не удаляется")
+
+	resp, _ = postGraphQL(t, router, `mutation {
+		updateUser(id: "3", input: {name: "Caroline", email: "carol@example.com", role: manager}) { name registrationDate }
+	}`, nil)
+	assert.Empty(t, resp.Errors, "мутация не должна возвращать ошибок")
+	assert.Equal(t, map[string]interface{}{"name": "Caroline", "registrationDate": "2024-03-01T00:00:00Z"}, resp.Data["updateUser"])
+}
+
+func TestGraphQL_Schema(t *testing.T) {
+	router := graphQLRouter(newFakeGraphRepository())
+
+	req, _ := http.NewRequest("GET", "/graphql/schema", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	sdl := rr.Body.String()
+	assert.Contains(t, sdl, "type Query {")
+	assert.Contains(t, sdl, "type Mutation {")
+	assert.Contains(t, sdl, "enum Status {")
+	assert.Contains(t, sdl, "input TaskInput {")
+}
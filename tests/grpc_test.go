@@ -0,0 +1,244 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/changes"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+	"github.com/togzhanzhakhani/projects/internal/rpc"
+	pb "github.com/togzhanzhakhani/projects/pkg/pb/projects/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gorm.io/gorm"
+)
+
+// gatewayStatus is the HTTP status grpc-gateway responds with for a code.
+var gatewayStatus = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.Aborted:            http.StatusConflict,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+}
+
+type grpcClients struct {
+	users pb.UserServiceClient
+	tasks pb.TaskServiceClient
+}
+
+func setupGRPC(t *testing.T, userRepo *MockUserRepository, taskRepo *MockTaskRepository, broker *changes.Broker, opts ...grpc.ServerOption) grpcClients {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	rpc.Register(server, rpc.Services{
+		User:    rpc.NewUserService(userRepo),
+		Project: rpc.NewProjectService(nil),
+		Task:    rpc.NewTaskService(taskRepo, broker),
+	})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return grpcClients{users: pb.NewUserServiceClient(conn), tasks: pb.NewTaskServiceClient(conn)}
+}
+
+// asGRPCAdmin scopes the calls of user 5, sent as x-user-id, to the default
+// workspace, which they administer.
+func asGRPCAdmin() []grpc.ServerOption {
+	return rpc.Scope(rpc.UserMetadata, fakeMembers{{1, 5}: models.WorkspaceAdmin})
+}
+
+func errorReason(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestGRPC_CreateUser(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("CreateUser", mock.AnythingOfType("*models.User")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.User).ID = 7
+	})
+	clients := setupGRPC(t, userRepo, new(MockTaskRepository), changes.NewBroker(), asGRPCAdmin()...)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-user-id", "5")
+	user, err := clients.users.CreateUser(ctx, &pb.CreateUserRequest{
+		User: &pb.UserInput{Name: "John", Email: "john@example.com", Role: "admin"},
+	})
+	assert.NoError(t, err, "пользователь должен быть создан")
+	assert.Equal(t, uint32(7), user.Id, "ID пользователя не соответствует ожидаемому")
+	assert.Equal(t, "john@example.com", user.Email)
+
+	_, err = clients.users.CreateUser(ctx, &pb.CreateUserRequest{
+		User: &pb.UserInput{Name: "John", Email: "not an email", Role: "owner"},
+	})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code(), "код ошибки не соответствует ожидаемому")
+	assert.Equal(t, problem.CodeValidationFailed, errorReason(st))
+	var fields []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"email", "role"}, fields, "поля ошибок не соответствуют ожидаемым")
+	userRepo.AssertNumberOfCalls(t, "CreateUser", 1)
+}
+
+func TestGRPC_ErrorsMatchREST(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"not found", gorm.ErrRecordNotFound},
+		{"still referenced", &pgconn.PgError{Code: "23503"}},
+		{"database error", errors.New("connection reset")},
+	}
+
+	for _, tc := range cases {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetUserByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		userRepo.On("DeleteUser", uint(1)).Return(tc.err)
+		clients := setupGRPC(t, userRepo, new(MockTaskRepository), changes.NewBroker(), asGRPCAdmin()...)
+
+		router := gin.New()
+		router.Use(asMember(models.WorkspaceAdmin))
+		router.DELETE("/users/:id", handlers.NewUserHandler(userRepo).DeleteUser)
+		req, _ := http.NewRequest("DELETE", "/users/1", nil)
+		req.Header.Set("Accept-Language", "ru")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		restProblem := decodeProblem(t, rr)
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "accept-language", "ru", "x-user-id", "5")
+		_, err := clients.users.DeleteUser(ctx, &pb.DeleteUserRequest{Id: 1})
+		st := status.Convert(err)
+		assert.Equal(t, rr.Code, gatewayStatus[st.Code()], "%s: статус gRPC не соответствует статусу REST", tc.name)
+		assert.Equal(t, restProblem.Code, errorReason(st), "%s: код проблемы не соответствует REST", tc.name)
+		assert.Equal(t, restProblem.Detail, st.Message(), "%s: сообщение не соответствует REST", tc.name)
+	}
+}
+
+func TestGRPC_GetUserNotFoundIsLocalized(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", uint(9)).Return(&models.User{}, gorm.ErrRecordNotFound)
+	clients := setupGRPC(t, userRepo, new(MockTaskRepository), changes.NewBroker())
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "accept-language", "ru-RU,ru;q=0.9")
+	_, err := clients.users.GetUser(ctx, &pb.GetUserRequest{Id: 9})
+	st := status.Convert(err)
+	assert.Equal(t, codes.NotFound, st.Code(), "код ошибки не соответствует ожидаемому")
+	assert.Equal(t, "Пользователь не найден", st.Message(), "сообщение должно быть на языке запроса")
+}
+
+func TestGRPC_RoutesMatchREST(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.Register(router, routes.Handlers{})
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	files := []protoreflect.FileDescriptor{pb.File_projects_v1_user_proto, pb.File_projects_v1_project_proto, pb.File_projects_v1_task_proto}
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				fullName := "/" + string(services.Get(i).FullName()) + "/" + string(method.Name())
+				route, ok := rpc.Routes[fullName]
+				if method.IsStreamingServer() {
+					assert.False(t, ok, "%s: потоковый метод не имеет маршрута REST", fullName)
+					continue
+				}
+				assert.True(t, ok, "%s: нет соответствующего маршрута REST", fullName)
+				assert.True(t, registered[route], "%s: маршрут %s не зарегистрирован", fullName, route)
+			}
+		}
+	}
+}
+
+func TestGRPC_WatchTasks(t *testing.T) {
+	taskRepo := new(MockTaskRepository)
+	taskRepo.On("GetTaskByID", uint(1)).Return(&models.Task{ID: 1, Title: "Design", ProjectID: 1}, nil)
+	taskRepo.On("GetTaskByID", uint(4)).Return(nil, gorm.ErrRecordNotFound)
+	broker := changes.NewBroker()
+	clients := setupGRPC(t, new(MockUserRepository), taskRepo, broker)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := clients.tasks.WatchTasks(ctx, &pb.WatchTasksRequest{ProjectId: 1})
+	assert.NoError(t, err)
+	_, err = stream.Header()
+	assert.NoError(t, err, "поток должен подписаться на изменения")
+
+	broker.Publish(changes.TaskChange{Op: changes.Inserted, ID: 1, ProjectID: 1})
+	broker.Publish(changes.TaskChange{Op: changes.Updated, ID: 2, ProjectID: 2})
+	broker.Publish(changes.TaskChange{Op: changes.Updated, ID: 4, ProjectID: 1})
+	broker.Publish(changes.TaskChange{Op: changes.Deleted, ID: 3, ProjectID: 1})
+
+	event, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, pb.TaskEvent_TYPE_CREATED, event.Type, "тип события не соответствует ожидаемому")
+	assert.Equal(t, "Design", event.Task.GetTitle(), "событие должно содержать задачу")
+
+	// Task 2 is in another project, and task 4 was deleted before it could be loaded.
+	event, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, pb.TaskEvent_TYPE_DELETED, event.Type, "тип события не соответствует ожидаемому")
+	assert.Equal(t, uint32(3), event.TaskId)
+	assert.Nil(t, event.Task, "удаленная задача не отправляется")
+}
+
+func TestChanges_DropsSlowSubscriber(t *testing.T) {
+	broker := changes.NewBroker()
+	feed, stop := broker.Subscribe(1)
+	defer stop()
+
+	broker.Publish(changes.TaskChange{Op: changes.Inserted, ID: 1})
+	broker.Publish(changes.TaskChange{Op: changes.Inserted, ID: 2})
+
+	change, ok := <-feed
+	assert.True(t, ok)
+	assert.Equal(t, 1, change.ID, "первое изменение должно быть доставлено")
+	_, ok = <-feed
+	assert.False(t, ok, "отставший подписчик должен быть отключен")
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+	"gorm.io/gorm"
+)
+
+func TestTranslations_AreComplete(t *testing.T) {
+	assert.NoError(t, i18n.Check())
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                        "en",
+		"fr-FR, de;q=0.9":         "en",
+		"ru":                      "ru",
+		"ru-RU,ru;q=0.9,en;q=0.8": "ru",
+		"kk-KZ, ru;q=0.8":         "kk",
+		"en;q=0.1, ru;q=0.9":      "ru",
+		"de, kk;q=0.5, *;q=0.1":   "kk",
+		"ru;q=0, en":              "en",
+	}
+	for header, locale := range cases {
+		assert.Equal(t, locale, i18n.Negotiate(header).Locale(), "язык не соответствует ожидаемому для %q", header)
+	}
+}
+
+func TestValidation_LocalizedMessages(t *testing.T) {
+	handler, _ := setupUserHandler(t)
+	router := gin.New()
+	router.Use(asMember(models.WorkspaceAdmin))
+	router.POST("/users", handler.CreateUser)
+
+	for locale, expected := range map[string]string{
+		"en":    "Name is required",
+		"ru-RU": "Имя обязательно",
+		"kk":    "Аты міндетті",
+	} {
+		req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"","email":"johndoe@example.com","role":"admin"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", locale)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+		body := decodeProblem(t, rr)
+		if assert.Len(t, body.Errors, 1) {
+			assert.Equal(t, "name", body.Errors[0].Field)
+			assert.Equal(t, expected, body.Errors[0].Message, "сообщение не соответствует ожидаемому для %s", locale)
+		}
+		assert.Equal(t, i18n.Negotiate(locale).Locale(), rr.Header().Get("Content-Language"))
+	}
+}
+
+func TestValidation_GeneratedFallbackMessages(t *testing.T) {
+	type profile struct {
+		Nickname string `json:"nickname" validate:"required"`
+		Age      int    `json:"age" validate:"lte=150"`
+		Code     string `json:"code" validate:"uuid"`
+		OpensOn  int    `json:"opens_on"`
+		ClosesOn int    `json:"closes_on" validate:"gtfield=OpensOn"`
+	}
+	obj := profile{Age: 200, Code: "x", OpensOn: 2, ClosesOn: 1}
+
+	messages := map[string]string{}
+	fieldErrors, err := validation.ValidateFields(context.Background(), i18n.Negotiate("en"), &obj)
+	assert.NoError(t, err)
+	for _, fieldError := range fieldErrors {
+		messages[fieldError.Field] = fieldError.Message
+	}
+	assert.Equal(t, map[string]string{
+		"nickname":  "nickname is required",
+		"age":       "age must be at most 150",
+		"code":      "code is invalid (uuid)",
+		"closes_on": "closes_on must be after opens_on",
+	}, messages)
+
+	fieldErrors, _ = validation.ValidateFields(context.Background(), i18n.Negotiate("ru"), &obj)
+	for _, fieldError := range fieldErrors {
+		assert.NotEmpty(t, fieldError.Message, "пустое сообщение для %s", fieldError.Field)
+		if fieldError.Field == "nickname" {
+			assert.Equal(t, "Поле nickname обязательно", fieldError.Message)
+		}
+	}
+}
+
+func TestProblem_LocalizedDetail(t *testing.T) {
+	handler, mockRepo := setupUserHandler(t)
+	mockRepo.On("GetUserByID", uint(7)).Return((*models.User)(nil), gorm.ErrRecordNotFound)
+
+	router := gin.New()
+	router.GET("/users/:id", handler.GetUserByID)
+
+	req, _ := http.NewRequest("GET", "/users/7", nil)
+	req.Header.Set("Accept-Language", "ru")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code, "статус код не соответствует ожидаемому")
+	body := decodeProblem(t, rr)
+	assert.Equal(t, problem.CodeNotFound, body.Code, "код не должен переводиться")
+	assert.Equal(t, "Не найдено", body.Title)
+	assert.Equal(t, "Пользователь не найден", body.Detail)
+}
+
+func TestRequestValidation_LocalizedMessages(t *testing.T) {
+	router := validatedRouter(t, new(MockTaskRepository))
+
+	req, _ := http.NewRequest("GET", "/tasks/abc", nil)
+	req.Header.Set("Accept-Language", "kk")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	body := decodeProblem(t, rr)
+	assert.Equal(t, []problem.FieldError{{In: "path", Field: "id", Message: "бүтін сан болуы керек"}}, body.Errors)
+}
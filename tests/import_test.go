@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+)
+
+type MockImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportRepository) WithContext(ctx context.Context) repository.ImportRepository {
+	return m
+}
+
+func (m *MockImportRepository) ExistingUserIDs(ids []int) (map[int]bool, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[int]bool), args.Error(1)
+}
+
+func (m *MockImportRepository) ExistingProjectIDs(ids []int) (map[int]bool, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[int]bool), args.Error(1)
+}
+
+func (m *MockImportRepository) UserIDsByEmail(emails []string) (map[string]int, error) {
+	args := m.Called(emails)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockImportRepository) CreateAll(records []interface{}) error {
+	args := m.Called(records)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) CreateImportJob(job *models.ImportJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) GetImportJob(id string) (*models.ImportJob, error) {
+	args := m.Called(id)
+	if job, ok := args.Get(0).(*models.ImportJob); ok {
+		return job, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockImportRepository) UpdateImportJob(job *models.ImportJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func performImport(handler *handlers.ImportHandler, url, contentType, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.POST("/import", handler.Import)
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestImportUsers_DryRunReportsRowErrors(t *testing.T) {
+	repo := new(MockImportRepository)
+	handler := handlers.NewImportHandler(repo)
+
+	repo.On("UserIDsByEmail", []string{"john@example.com", "taken@example.com", "not-an-email"}).
+		Return(map[string]int{"taken@example.com": 7}, nil)
+
+	csv := "Full Name,Mail,role\n" +
+		"John Doe,john@example.com,developer\n" +
+		"Taken,taken@example.com,manager\n" +
+		"John Again,john@example.com,developer\n" +
+		",not-an-email,owner\n"
+	rr := performImport(handler, "/import?entity=users&dry_run=true&map=Full%20Name:name,Mail:email", "text/csv", csv)
+
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	var report struct {
+		TotalRows   int `json:"total_rows"`
+		ValidRows   int `json:"valid_rows"`
+		InvalidRows int `json:"invalid_rows"`
+		Errors      []struct {
+			Row    int      `json:"row"`
+			Errors []string `json:"errors"`
+		} `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 4, report.TotalRows)
+	assert.Equal(t, 1, report.ValidRows)
+	assert.Equal(t, 3, report.InvalidRows)
+	assert.Equal(t, 2, report.Errors[0].Row)
+	assert.Contains(t, report.Errors[0].Errors, "Email already exists")
+	assert.Equal(t, "Email is duplicated in row 1", report.Errors[1].Errors[0])
+	assert.Len(t, report.Errors[2].Errors, 3)
+	repo.AssertNotCalled(t, "CreateAll", mock.Anything)
+}
+
+func TestImportTasks_AtomicRejectsInvalidFile(t *testing.T) {
+	repo := new(MockImportRepository)
+	handler := handlers.NewImportHandler(repo)
+
+	repo.On("ExistingUserIDs", []int{3}).Return(map[int]bool{3: true}, nil)
+	repo.On("UserIDsByEmail", []string{"ghost@example.com"}).Return(map[string]int{}, nil)
+	repo.On("ExistingProjectIDs", []int{5}).Return(map[int]bool{5: true}, nil)
+
+	ndjson := `{"title":"Report","description":"Quarterly","priority":"high","status":"todo","assignee_id":3,"project_id":5,"created_at":"2024-07-01","completed_at":"2024-07-15"}
+{"title":"Review","description":"Code review","priority":"low","status":"todo","assignee_email":"ghost@example.com","project_id":5,"created_at":"2024-07-01","completed_at":"2024-07-15"}
+`
+	rr := performImport(handler, "/import?entity=tasks", "application/x-ndjson", ndjson)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Contains(t, rr.Body.String(), "Assignee with email ghost@example.com does not exist")
+	repo.AssertNotCalled(t, "CreateAll", mock.Anything)
+}
+
+func TestImportUsers_BatchFailureCanBeResumed(t *testing.T) {
+	repo := new(MockImportRepository)
+	handler := handlers.NewImportHandler(repo)
+
+	csv := "name,email,role\nA,a@example.com,developer\nB,b@example.com,developer\nC,c@example.com,developer\n"
+	repo.On("UserIDsByEmail", mock.Anything).Return(map[string]int{}, nil)
+	var stored *models.ImportJob
+	repo.On("CreateImportJob", mock.AnythingOfType("*models.ImportJob")).Return(nil).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.ImportJob)
+	})
+	repo.On("UpdateImportJob", mock.AnythingOfType("*models.ImportJob")).Return(nil)
+	repo.On("CreateAll", mock.MatchedBy(func(records []interface{}) bool { return len(records) == 2 })).Return(nil).Once()
+	repo.On("CreateAll", mock.MatchedBy(func(records []interface{}) bool { return len(records) == 1 })).Return(errors.New("connection reset")).Once()
+
+	rr := performImport(handler, "/import?entity=users&mode=batch&batch_size=2", "text/csv", csv)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code, "статус код не соответствует ожидаемому")
+
+	var failed struct {
+		Job models.ImportJob `json:"job"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &failed))
+	assert.Equal(t, models.ImportJobFailed, failed.Job.Status)
+	assert.Equal(t, 2, failed.Job.ProcessedRows)
+
+	repo.On("GetImportJob", failed.Job.ID).Return(stored, nil)
+	repo.On("CreateAll", mock.MatchedBy(func(records []interface{}) bool {
+		return len(records) == 1 && records[0].(*models.User).Email == "c@example.com"
+	})).Return(nil).Once()
+
+	rr = performImport(handler, "/import?entity=users&mode=batch&batch_size=2&job_id="+failed.Job.ID, "text/csv", csv)
+	assert.Equal(t, http.StatusCreated, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, models.ImportJobCompleted, stored.Status)
+	assert.Equal(t, 3, stored.CreatedRows)
+}
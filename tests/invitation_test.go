@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/invite"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/password"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"gorm.io/gorm"
+)
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) WithContext(ctx context.Context) repository.InvitationRepository {
+	return m
+}
+
+func (m *MockInvitationRepository) CreateInvitation(invitation *models.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) GetInvitationByID(id uint) (*models.Invitation, error) {
+	args := m.Called(id)
+	invitation, _ := args.Get(0).(*models.Invitation)
+	return invitation, args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetPendingInvitations() ([]models.Invitation, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) FindPendingInvitation(tokenHash string) (*models.Invitation, error) {
+	args := m.Called(tokenHash)
+	invitation, _ := args.Get(0).(*models.Invitation)
+	return invitation, args.Error(1)
+}
+
+func (m *MockInvitationRepository) RenewInvitation(id uint, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(id, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) RevokeInvitation(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) AcceptInvitation(invitation *models.Invitation, user *models.User, passwordHash string) error {
+	args := m.Called(invitation, user, passwordHash)
+	return args.Error(0)
+}
+
+// recordingSender keeps the invitations it was asked to send.
+type recordingSender struct {
+	messages []invite.Message
+}
+
+func (s *recordingSender) Send(message invite.Message) error {
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// setupInvitationRouter serves the invitation routes to user 5, who has
+// userRole and the workspace role.
+func setupInvitationRouter(role, userRole string) (*gin.Engine, *MockInvitationRepository, *MockUserRepository, *recordingSender) {
+	mockRepo := new(MockInvitationRepository)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", uint(5)).Return(&models.User{ID: 5, Role: userRole}, nil).Maybe()
+	sender := &recordingSender{}
+	handler := handlers.NewInvitationHandler(mockRepo, userRepo, sender)
+	router := gin.New()
+	router.POST("/invitations/accept", handler.AcceptInvitation)
+	scoped := router.Group("/", asMember(role))
+	scoped.GET("/invitations", handler.GetPendingInvitations)
+	scoped.POST("/invitations", handler.CreateInvitation)
+	scoped.POST("/invitations/:id/resend", handler.ResendInvitation)
+	scoped.DELETE("/invitations/:id", handler.RevokeInvitation)
+	return router, mockRepo, userRepo, sender
+}
+
+func TestCreateInvitation(t *testing.T) {
+	router, mockRepo, _, sender := setupInvitationRouter(models.WorkspaceMember, "manager")
+	mockRepo.On("CreateInvitation", mock.AnythingOfType("*models.Invitation")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Invitation).ID = 3
+	})
+
+	rr := sendJSON(router, "POST", "/invitations", `{"email":" New@Example.com ","role":"developer","project_id":7,"workspace_role":"admin"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, "менеджер может приглашать разработчиков")
+	assert.NotContains(t, rr.Body.String(), "token", "токен не должен возвращаться в ответе")
+
+	created := mockRepo.Calls[0].Arguments.Get(0).(*models.Invitation)
+	assert.Equal(t, "new@example.com", created.Email, "email должен нормализоваться")
+	assert.Equal(t, uint(5), created.InvitedBy, "приглашающий должен сохраняться")
+	assert.Equal(t, models.ProjectContributor, created.ProjectRole, "роль в проекте по умолчанию contributor")
+	assert.Empty(t, created.WorkspaceRole, "роль в рабочем пространстве задают только его администраторы")
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), created.ExpiresAt, time.Minute, "приглашение должно истекать через неделю")
+
+	assert.Len(t, sender.messages, 1, "приглашение должно быть отправлено")
+	assert.Equal(t, hashToken(sender.messages[0].Token), created.TokenHash, "хранится только хеш токена")
+}
+
+func TestCreateInvitation_Authorization(t *testing.T) {
+	router, mockRepo, _, _ := setupInvitationRouter(models.WorkspaceMember, "manager")
+	rr := sendJSON(router, "POST", "/invitations", `{"email":"boss@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "менеджер не может приглашать администраторов")
+
+	router, mockRepo, _, _ = setupInvitationRouter(models.WorkspaceMember, "developer")
+	rr = sendJSON(router, "POST", "/invitations", `{"email":"new@example.com","role":"developer"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "разработчик не может приглашать")
+	mockRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything)
+
+	router, mockRepo, _, _ = setupInvitationRouter(models.WorkspaceAdmin, "developer")
+	mockRepo.On("CreateInvitation", mock.AnythingOfType("*models.Invitation")).Return(nil)
+	rr = sendJSON(router, "POST", "/invitations", `{"email":"boss@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, "администратор пространства может приглашать администраторов")
+
+	rr = sendJSON(router, "POST", "/invitations", `{"email":"not-an-email","role":"owner"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "неверное приглашение должно отклоняться")
+	mockRepo.AssertNumberOfCalls(t, "CreateInvitation", 1)
+}
+
+func TestResendInvitation(t *testing.T) {
+	router, mockRepo, _, sender := setupInvitationRouter(models.WorkspaceAdmin, "developer")
+	mockRepo.On("GetInvitationByID", uint(3)).Return(&models.Invitation{ID: 3, Email: "new@example.com", Role: "developer", TokenHash: "old"}, nil)
+	mockRepo.On("GetInvitationByID", uint(4)).Return(&models.Invitation{ID: 4, Role: "developer"}, nil)
+	mockRepo.On("GetInvitationByID", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("RenewInvitation", uint(3), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("RenewInvitation", uint(4), mock.Anything, mock.Anything).Return(repository.ErrInvitationClosed)
+
+	rr := sendJSON(router, "POST", "/invitations/3/resend", "")
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Len(t, sender.messages, 1, "приглашение должно быть отправлено повторно")
+	assert.Equal(t, hashToken(sender.messages[0].Token), mockRepo.Calls[1].Arguments.String(1), "новый токен должен заменять старый")
+
+	rr = sendJSON(router, "POST", "/invitations/4/resend", "")
+	assert.Equal(t, http.StatusConflict, rr.Code, "принятое приглашение нельзя отправить повторно")
+
+	rr = sendJSON(router, "POST", "/invitations/9/resend", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code, "несуществующее приглашение")
+	assert.Len(t, sender.messages, 1, "закрытые приглашения не отправляются")
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	router, mockRepo, _, _ := setupInvitationRouter(models.WorkspaceMember, "manager")
+	mockRepo.On("GetInvitationByID", uint(3)).Return(&models.Invitation{ID: 3, Role: "developer"}, nil)
+	mockRepo.On("GetInvitationByID", uint(4)).Return(&models.Invitation{ID: 4, Role: "admin"}, nil)
+	mockRepo.On("RevokeInvitation", uint(3)).Return(nil)
+
+	rr := sendJSON(router, "DELETE", "/invitations/3", "")
+	assert.Equal(t, http.StatusNoContent, rr.Code, "статус код не соответствует ожидаемому")
+
+	rr = sendJSON(router, "DELETE", "/invitations/4", "")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "менеджер не может отзывать приглашения администраторов")
+	mockRepo.AssertNumberOfCalls(t, "RevokeInvitation", 1)
+}
+
+func TestAcceptInvitation_NewUser(t *testing.T) {
+	router, mockRepo, userRepo, _ := setupInvitationRouter(models.WorkspaceMember, "developer")
+	invitation := &models.Invitation{ID: 3, Email: "new@example.com", Role: "manager", ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo.On("FindPendingInvitation", hashToken("secret")).Return(invitation, nil)
+	userRepo.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("AcceptInvitation", invitation, mock.AnythingOfType("*models.User"), mock.AnythingOfType("string")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.User).ID = 12
+	})
+
+	rr := sendJSON(router, "POST", "/invitations/accept", `{"token":"secret","name":"New"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "новому пользователю нужен пароль")
+
+	rr = sendJSON(router, "POST", "/invitations/accept", `{"token":"secret","name":"New","password":"short"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "пароль должен соответствовать политике")
+
+	rr = sendJSON(router, "POST", "/invitations/accept", `{"token":"secret","name":"New","password":"correct horse"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, "статус код не соответствует ожидаемому")
+	var user models.User
+	json.Unmarshal(rr.Body.Bytes(), &user)
+	assert.Equal(t, models.User{ID: 12, Name: "New", Email: "new@example.com", Role: "manager"}, user, "пользователь создаётся по приглашению")
+	assert.NotContains(t, rr.Body.String(), "password", "пароль не должен возвращаться")
+
+	hash := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.String(2)
+	assert.NoError(t, password.Verify(hash, "correct horse"), "пароль должен храниться как хеш")
+}
+
+func TestAcceptInvitation_ExistingUser(t *testing.T) {
+	router, mockRepo, userRepo, _ := setupInvitationRouter(models.WorkspaceMember, "developer")
+	invitation := &models.Invitation{ID: 3, Email: "john@example.com", Role: "admin", ExpiresAt: time.Now().Add(time.Hour)}
+	existing := &models.User{ID: 1, Name: "John", Email: "john@example.com", Role: "developer"}
+	mockRepo.On("FindPendingInvitation", hashToken("secret")).Return(invitation, nil)
+	userRepo.On("FindByEmail", "john@example.com").Return(existing, nil)
+	mockRepo.On("AcceptInvitation", invitation, existing, "").Return(nil)
+
+	rr := sendJSON(router, "POST", "/invitations/accept", `{"token":"secret"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, "существующий пользователь присоединяется без пароля")
+	assert.Equal(t, "developer", existing.Role, "роль существующего пользователя не меняется")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAcceptInvitation_InvalidToken(t *testing.T) {
+	router, mockRepo, userRepo, _ := setupInvitationRouter(models.WorkspaceMember, "developer")
+	expired := &models.Invitation{ID: 4, Email: "late@example.com", Role: "developer", ExpiresAt: time.Now().Add(-time.Minute)}
+	accepted := &models.Invitation{ID: 5, Email: "twice@example.com", Role: "developer", ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo.On("FindPendingInvitation", hashToken("expired")).Return(expired, nil)
+	mockRepo.On("FindPendingInvitation", hashToken("raced")).Return(accepted, nil)
+	mockRepo.On("FindPendingInvitation", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	userRepo.On("FindByEmail", "twice@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("AcceptInvitation", accepted, mock.Anything, mock.Anything).Return(repository.ErrInvitationClosed)
+
+	for _, token := range []string{"unknown", "expired", "raced"} {
+		rr := sendJSON(router, "POST", "/invitations/accept", `{"token":"`+token+`","name":"X","password":"long enough"}`)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "недействительный токен: "+token)
+	}
+	mockRepo.AssertNumberOfCalls(t, "AcceptInvitation", 1)
+
+	rr := sendJSON(router, "POST", "/invitations/accept", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "токен обязателен")
+}
+
+func TestCreateUser_RequiresWorkspaceAdmin(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	handler := handlers.NewUserHandler(userRepo)
+	router := gin.New()
+	router.Use(asMember(models.WorkspaceMember))
+	router.POST("/users", handler.CreateUser)
+
+	rr := sendJSON(router, "POST", "/users", `{"name":"Eve","email":"eve@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "участник не может создавать пользователей напрямую")
+	userRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
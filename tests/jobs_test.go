@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/jobs"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+)
+
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) EnqueueRun(run *models.JobRun) (bool, error) {
+	args := m.Called(run)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) ClaimNextRun(jobNames []string, now time.Time, workerID string) (*models.JobRun, error) {
+	args := m.Called(jobNames, now, workerID)
+	if run, ok := args.Get(0).(*models.JobRun); ok {
+		return run, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockJobRepository) FinishRun(run *models.JobRun) error {
+	args := m.Called(run)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) RequeueStaleRuns(startedBefore time.Time) (int64, error) {
+	args := m.Called(startedBefore)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockJobRepository) GetLastScheduledAt(jobName string) (*time.Time, error) {
+	args := m.Called(jobName)
+	if t, ok := args.Get(0).(*time.Time); ok {
+		return t, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockJobRepository) GetRecentRuns(jobName string, limit int) ([]models.JobRun, error) {
+	args := m.Called(jobName, limit)
+	return args.Get(0).([]models.JobRun), args.Error(1)
+}
+
+type fakeLocker struct {
+	held map[string]bool
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, name string) (bool, error) {
+	return l.held[name], nil
+}
+
+func (l *fakeLocker) Unlock(ctx context.Context, name string) error {
+	return nil
+}
+
+func TestParseSchedule_Next(t *testing.T) {
+	from := time.Date(2024, 7, 10, 9, 17, 30, 0, time.UTC) // Wednesday
+
+	cases := map[string]time.Time{
+		"*/15 * * * *":     time.Date(2024, 7, 10, 9, 30, 0, 0, time.UTC),
+		"0 3 * * *":        time.Date(2024, 7, 11, 3, 0, 0, 0, time.UTC),
+		"30 8 * * MON-FRI": time.Date(2024, 7, 11, 8, 30, 0, 0, time.UTC),
+		"0 0 1 * *":        time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		"@weekly":          time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC),
+		"0 12 13 * 5":      time.Date(2024, 7, 12, 12, 0, 0, 0, time.UTC),
+		"@every 90s":       time.Date(2024, 7, 10, 9, 19, 0, 0, time.UTC),
+		"0 9 * * 1-7":      time.Date(2024, 7, 11, 9, 0, 0, 0, time.UTC),
+		"0 9 * * 7":        time.Date(2024, 7, 14, 9, 0, 0, 0, time.UTC),
+		"0 9 * * 5-7":      time.Date(2024, 7, 12, 9, 0, 0, 0, time.UTC),
+		"0 0 */2 * MON":    time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+	}
+	for spec, expected := range cases {
+		schedule, err := jobs.ParseSchedule(spec)
+		assert.NoError(t, err, spec)
+		assert.Equal(t, expected, schedule.Next(from), spec)
+	}
+
+	for _, spec := range []string{"", "* * * *", "61 * * * *", "* * * * MON-XYZ", "*/0 * * * *", "* * * * 8"} {
+		_, err := jobs.ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestManager_TickEnqueuesOnlyLedJobs(t *testing.T) {
+	repo := new(MockJobRepository)
+	locker := &fakeLocker{held: map[string]bool{"leader-job": true}}
+	manager := jobs.NewManager(repo, locker)
+
+	noop := func(ctx context.Context) error { return nil }
+	assert.NoError(t, manager.Register(jobs.Job{Name: "leader-job", Schedule: "*/5 * * * *", Run: noop}))
+	assert.NoError(t, manager.Register(jobs.Job{Name: "follower-job", Schedule: "*/5 * * * *", Run: noop}))
+
+	last := time.Date(2024, 7, 10, 8, 55, 0, 0, time.UTC)
+	now := time.Date(2024, 7, 10, 9, 2, 0, 0, time.UTC)
+	repo.On("RequeueStaleRuns", mock.Anything).Return(0, nil)
+	repo.On("GetLastScheduledAt", "leader-job").Return(&last, nil)
+	repo.On("EnqueueRun", mock.MatchedBy(func(run *models.JobRun) bool {
+		return run.JobName == "leader-job" && run.ScheduledAt.Equal(time.Date(2024, 7, 10, 9, 0, 0, 0, time.UTC))
+	})).Return(true, nil).Once()
+
+	assert.NoError(t, manager.Tick(context.Background(), now))
+	// The next activation is 09:05, so a second tick in the same minute enqueues nothing.
+	assert.NoError(t, manager.Tick(context.Background(), now))
+	repo.AssertExpectations(t)
+}
+
+func TestManager_RunNextRecordsFailure(t *testing.T) {
+	repo := new(MockJobRepository)
+	manager := jobs.NewManager(repo, &fakeLocker{})
+	assert.NoError(t, manager.Register(jobs.Job{
+		Name:     "failing-job",
+		Schedule: "@hourly",
+		Run:      func(ctx context.Context) error { return errors.New("boom") },
+	}))
+
+	now := time.Now()
+	repo.On("ClaimNextRun", []string{"failing-job"}, now, manager.WorkerID).
+		Return(&models.JobRun{ID: 1, JobName: "failing-job", Status: models.JobRunRunning}, nil)
+	repo.On("FinishRun", mock.MatchedBy(func(run *models.JobRun) bool {
+		return run.Status == models.JobRunFailed && run.Error == "boom" && run.FinishedAt != nil
+	})).Return(nil)
+
+	worked, err := manager.RunNext(context.Background(), now)
+	assert.NoError(t, err)
+	assert.True(t, worked)
+	repo.AssertExpectations(t)
+}
+
+func TestGetJobs_OnlyDefaultWorkspaceAdmins(t *testing.T) {
+	repo := new(MockJobRepository)
+	repo.On("GetRecentRuns", "cleanup", 10).Return([]models.JobRun{}, nil)
+	manager := jobs.NewManager(repo, &fakeLocker{})
+	assert.NoError(t, manager.Register(jobs.Job{Name: "cleanup", Schedule: "@hourly", Run: func(ctx context.Context) error { return nil }}))
+
+	router := gin.New()
+	routes.Register(router, routes.Handlers{
+		Job:          handlers.NewJobHandler(manager),
+		Docs:         handlers.NewDocsHandler([]byte(`{}`)),
+		Authenticate: []gin.HandlerFunc{auth.Middleware(auth.Header)},
+		Scope:        []gin.HandlerFunc{workspace.Middleware(fakeMembers{{1, 5}: models.WorkspaceMember, {1, 6}: models.WorkspaceAdmin, {2, 7}: models.WorkspaceAdmin})},
+	})
+
+	rr := sendJSON(router, "GET", "/admin/jobs", "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "задания не видны без аутентификации")
+	rr = sendAs(router, "GET", "/admin/jobs", "", auth.UserHeader, "5")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "участник не может смотреть задания")
+
+	req := httptest.NewRequest("GET", "/admin/jobs", nil)
+	req.Header.Set(auth.UserHeader, "7")
+	req.Header.Set(workspace.Header, "2")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "администратор другого пространства не может смотреть задания")
+
+	rr = sendAs(router, "GET", "/admin/jobs", "", auth.UserHeader, "6")
+	assert.Equal(t, http.StatusOK, rr.Code, "администратор пространства по умолчанию видит задания")
+	assert.Contains(t, rr.Body.String(), `"name":"cleanup"`)
+}
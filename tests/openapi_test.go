@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/togzhanzhakhani/projects/api"
+	"github.com/togzhanzhakhani/projects/internal/openapi"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+)
+
+func generateSpec(t *testing.T, extra func(*gin.Engine)) (*openapi.Document, error) {
+	router := gin.New()
+	routes.Register(router, routes.Handlers{})
+	if extra != nil {
+		extra(router)
+	}
+	return openapi.Generate(router.Routes())
+}
+
+func TestOpenAPI_SpecMatchesRoutes(t *testing.T) {
+	doc, err := generateSpec(t, nil)
+	assert.NoError(t, err, "маршруты и описания операций расходятся")
+
+	generated, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(api.Spec), string(generated), "api/openapi.json устарел: выполните go generate ./api")
+}
+
+func TestOpenAPI_UndocumentedRouteFails(t *testing.T) {
+	_, err := generateSpec(t, func(router *gin.Engine) {
+		router.GET("/tasks/:id/history", func(c *gin.Context) {})
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "GET /tasks/:id/history")
+}
+
+func TestOpenAPI_SchemasFollowValidateTags(t *testing.T) {
+	doc, err := generateSpec(t, nil)
+	assert.NoError(t, err)
+
+	task := doc.Components.Schemas["Task"]
+	assert.Equal(t, []string{"low", "medium", "high"}, task.Properties["priority"].Enum)
+	assert.Equal(t, []string{"todo", "in_progress", "done"}, task.Properties["status"].Enum)
+	assert.Equal(t, 100, *task.Properties["description"].MaxLength)
+	assert.Contains(t, task.Required, "assignee_id")
+
+	user := doc.Components.Schemas["User"]
+	assert.Equal(t, "email", user.Properties["email"].Format)
+	assert.True(t, user.Properties["registration_date"].ReadOnly, "registration_date задаётся сервером")
+
+	input := doc.Components.Schemas["ProjectInput"]
+	assert.Equal(t, "date", input.Properties["start_date"].Format)
+	assert.NotContains(t, input.Properties, "id")
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+	"gorm.io/gorm"
+)
+
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) problem.Problem {
+	assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"), "тип содержимого не соответствует ожидаемому")
+	var body problem.Problem
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, rr.Code, body.Status, "статус в теле не соответствует статус коду")
+	assert.Equal(t, rr.Header().Get(problem.TraceHeader), body.TraceID, "trace ID не соответствует заголовку")
+	assert.NotEmpty(t, body.TraceID)
+	return body
+}
+
+func TestFromError_MapsRepositoryErrors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{gorm.ErrRecordNotFound, http.StatusNotFound, problem.CodeNotFound},
+		{fmt.Errorf("find task: %w", gorm.ErrRecordNotFound), http.StatusNotFound, problem.CodeNotFound},
+		{gorm.ErrDuplicatedKey, http.StatusConflict, problem.CodeConflict},
+		{&pq.Error{Code: "23505"}, http.StatusConflict, problem.CodeConflict},
+		{&pq.Error{Code: "23503"}, http.StatusConflict, problem.CodeConflict},
+		{context.DeadlineExceeded, http.StatusServiceUnavailable, problem.CodeUnavailable},
+		{errors.New("connection reset"), http.StatusInternalServerError, problem.CodeInternal},
+		{problem.Forbidden("Not yours"), http.StatusForbidden, problem.CodeForbidden},
+	}
+	for _, tc := range cases {
+		p := problem.FromError(tc.err, "Failed to save task")
+		assert.Equal(t, tc.status, p.Status, "статус не соответствует ожидаемому для %v", tc.err)
+		assert.Equal(t, tc.code, p.Code, "код не соответствует ожидаемому для %v", tc.err)
+		assert.True(t, errors.Is(p, tc.err) || p == tc.err, "причина ошибки потеряна для %v", tc.err)
+	}
+
+	p := problem.FromError(errors.New("connection reset"), "Failed to save task")
+	assert.Equal(t, "Failed to save task", p.Detail, "внутренняя ошибка не должна раскрываться клиенту")
+}
+
+func TestProblem_NotFound(t *testing.T) {
+	handler, mockRepo := setupUserHandler(t)
+	mockRepo.On("GetUserByID", uint(7)).Return((*models.User)(nil), gorm.ErrRecordNotFound)
+
+	router := gin.New()
+	router.Use(problem.Trace())
+	router.GET("/users/:id", handler.GetUserByID)
+
+	req, _ := http.NewRequest("GET", "/users/7", nil)
+	req.Header.Set(problem.TraceHeader, "req-42")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code, "статус код не соответствует ожидаемому")
+	body := decodeProblem(t, rr)
+	assert.Equal(t, "req-42", body.TraceID, "trace ID запроса не сохранён")
+	assert.Equal(t, problem.CodeNotFound, body.Code)
+	assert.Equal(t, "https://task-management-api/problems/not_found", body.Type)
+	assert.Equal(t, "User not found", body.Detail)
+	assert.Equal(t, "/users/7", body.Instance)
+}
+
+func TestProblem_ValidationErrors(t *testing.T) {
+	handler, mockRepo := setupUserHandler(t)
+
+	router := gin.New()
+	router.Use(asMember(models.WorkspaceAdmin))
+	router.POST("/users", handler.CreateUser)
+
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"","email":"not-an-email","role":"admin"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	body := decodeProblem(t, rr)
+	assert.Equal(t, problem.CodeValidationFailed, body.Code)
+	var fields []string
+	for _, fieldError := range body.Errors {
+		fields = append(fields, fieldError.Field)
+		assert.NotEmpty(t, fieldError.Message)
+	}
+	assert.ElementsMatch(t, []string{"name", "email"}, fields, "поля с ошибками не соответствуют ожидаемым")
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestProblem_InternalErrorHidesCause(t *testing.T) {
+	handler, mockRepo := setupUserHandler(t)
+	mockRepo.On("GetAllUsers").Return([]models.User(nil), errors.New("pq: password authentication failed"))
+
+	router := gin.New()
+	router.GET("/users", handler.GetAllUsers)
+
+	req, _ := http.NewRequest("GET", "/users", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code, "статус код не соответствует ожидаемому")
+	body := decodeProblem(t, rr)
+	assert.Equal(t, problem.CodeInternal, body.Code)
+	assert.Equal(t, "Failed to retrieve users", body.Detail)
+	assert.NotContains(t, rr.Body.String(), "password")
+}
+
+func TestProblem_EmptySearchIsEmptyList(t *testing.T) {
+	handler, mockRepo := setupUserHandler(t)
+	mockRepo.On("FindByName", "Nobody").Return([]models.User{}, nil)
+
+	router := gin.New()
+	router.GET("/users/search", handler.SearchUsersByName)
+
+	req, _ := http.NewRequest("GET", "/users/search?name=Nobody", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	assert.JSONEq(t, `[]`, rr.Body.String(), "тело ответа не соответствует ожидаемому")
+}
+
+func TestProblem_NoRouteAndPanic(t *testing.T) {
+	router := gin.New()
+	router.Use(gin.CustomRecovery(problem.Recover), problem.Trace())
+	routes.Register(router, routes.Handlers{User: handlers.NewUserHandler(nil)})
+
+	req, _ := http.NewRequest("GET", "/nowhere", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, problem.CodeNotFound, decodeProblem(t, rr).Code)
+
+	// The handler has no repository, so it panics.
+	req, _ = http.NewRequest("GET", "/users/", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, problem.CodeInternal, decodeProblem(t, rr).Code)
+}
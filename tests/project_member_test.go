@@ -0,0 +1,307 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/gorm"
+)
+
+type MockProjectMemberRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectMemberRepository) WithContext(ctx context.Context) repository.ProjectMemberRepository {
+	return m
+}
+
+func (m *MockProjectMemberRepository) GetProject(projectID int) (*models.Project, error) {
+	args := m.Called(projectID)
+	if project := args.Get(0); project != nil {
+		return project.(*models.Project), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) GetProjectMember(projectID int, userID uint) (*models.ProjectMember, error) {
+	args := m.Called(projectID, userID)
+	if member := args.Get(0); member != nil {
+		return member.(*models.ProjectMember), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) GetProjectMembers(projectID int) ([]repository.ProjectMemberInfo, error) {
+	args := m.Called(projectID)
+	return args.Get(0).([]repository.ProjectMemberInfo), args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) AddProjectMember(member *models.ProjectMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockProjectMemberRepository) AddProjectTeam(projectID int, teamID uint, role string) ([]models.ProjectMember, error) {
+	args := m.Called(projectID, teamID, role)
+	if added := args.Get(0); added != nil {
+		return added.([]models.ProjectMember), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockProjectMemberRepository) RemoveProjectMember(projectID int, userID uint) error {
+	args := m.Called(projectID, userID)
+	return args.Error(0)
+}
+
+type MockTeamRepository struct {
+	mock.Mock
+}
+
+func (m *MockTeamRepository) WithContext(ctx context.Context) repository.TeamRepository {
+	return m
+}
+
+func (m *MockTeamRepository) GetAllTeams() ([]models.Team, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Team), args.Error(1)
+}
+
+func (m *MockTeamRepository) GetTeamByID(id uint) (*models.Team, error) {
+	args := m.Called(id)
+	if team := args.Get(0); team != nil {
+		return team.(*models.Team), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTeamRepository) CreateTeam(team *models.Team) error {
+	args := m.Called(team)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) DeleteTeam(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) GetTeamMembers(teamID uint) ([]repository.TeamMemberInfo, error) {
+	args := m.Called(teamID)
+	return args.Get(0).([]repository.TeamMemberInfo), args.Error(1)
+}
+
+func (m *MockTeamRepository) AddTeamMember(member *models.TeamMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) RemoveTeamMember(teamID, userID uint) error {
+	args := m.Called(teamID, userID)
+	return args.Error(0)
+}
+
+// asMember runs the request as user 5 with a role in workspace 1, as
+// workspace.Middleware would.
+func asMember(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := auth.NewContext(c.Request.Context(), 5)
+		ctx = workspace.NewContext(ctx, models.Membership{WorkspaceID: 1, UserID: 5, Role: role})
+		c.Request = c.Request.WithContext(ctx)
+	}
+}
+
+func setupProjectMemberRouter(role string) (*gin.Engine, *MockProjectMemberRepository) {
+	mockRepo := new(MockProjectMemberRepository)
+	mockRepo.On("GetProject", 7).Return(&models.Project{ID: 7}, nil).Maybe()
+	mockRepo.On("GetProject", mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+	handler := handlers.NewProjectMemberHandler(mockRepo)
+	router := gin.New()
+	router.Use(asMember(role))
+	router.GET("/projects/:id/members", handler.GetMembers)
+	router.POST("/projects/:id/members", handler.AddMember)
+	router.DELETE("/projects/:id/members/:userId", handler.RemoveMember)
+	router.POST("/projects/:id/teams", handler.AddTeam)
+	return router, mockRepo
+}
+
+func TestRules_ProjectMember(t *testing.T) {
+	registerLookups(t, &fakeLookups{
+		roles:    map[int]string{3: "developer", 4: "developer"},
+		projects: map[int]bool{5: true},
+		members:  map[int][]int{5: {4}},
+	})
+	repo := new(MockTaskRepository)
+	repo.On("CreateTask", mock.AnythingOfType("*models.Task")).Return(nil)
+	router := gin.New()
+	router.POST("/tasks", handlers.NewTaskHandler(repo).CreateTask)
+
+	body := `{"title":"Finish Report","description":"Quarterly report","priority":"high","status":"todo","assignee_id":%d,"project_id":5,"created_at":"2024-07-01","completed_at":"2024-07-15"}`
+	rr := sendJSON(router, "POST", "/tasks", fmt.Sprintf(body, 3))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "исполнитель вне проекта должен отклоняться")
+	assert.Equal(t, []problem.FieldError{{Field: "assignee_id", Message: "Assignee must be a member of the project"}}, decodeProblem(t, rr).Errors)
+	repo.AssertNotCalled(t, "CreateTask", mock.Anything)
+
+	rr = sendJSON(router, "POST", "/tasks", fmt.Sprintf(body, 4))
+	assert.Equal(t, http.StatusCreated, rr.Code, "участник проекта может быть исполнителем")
+}
+
+func TestRules_ProjectMemberConstraint(t *testing.T) {
+	registerLookups(t, &fakeLookups{roles: map[int]string{3: "developer"}, projects: map[int]bool{5: true}, members: map[int][]int{5: {3}}})
+	repo := new(MockTaskRepository)
+	// The assignee left the project after the rule passed.
+	repo.On("CreateTask", mock.AnythingOfType("*models.Task")).Return(
+		&pgconn.PgError{Code: "23503", ConstraintName: "fk_tasks_assignee_project", TableName: "tasks"})
+	router := gin.New()
+	router.POST("/tasks", handlers.NewTaskHandler(repo).CreateTask)
+
+	rr := sendJSON(router, "POST", "/tasks", `{"title":"Finish Report","description":"Quarterly report","priority":"high","status":"todo","assignee_id":3,"project_id":5,"created_at":"2024-07-01","completed_at":"2024-07-15"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, []problem.FieldError{{Field: "assignee_id", Message: "Assignee must be a member of the project"}}, decodeProblem(t, rr).Errors)
+}
+
+func TestGetProjectMembers_UnknownProject(t *testing.T) {
+	router, _ := setupProjectMemberRouter(models.WorkspaceMember)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/projects/9/members", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code, "статус код не соответствует ожидаемому")
+}
+
+func TestAddProjectMember(t *testing.T) {
+	registerLookups(t, &fakeLookups{roles: map[int]string{8: "developer"}})
+
+	tests := []struct {
+		name   string
+		caller string
+		role   string
+		status int
+	}{
+		{"owner adds an owner", models.ProjectOwner, models.ProjectOwner, http.StatusCreated},
+		{"maintainer adds a contributor", models.ProjectMaintainer, models.ProjectContributor, http.StatusCreated},
+		{"maintainer cannot add an owner", models.ProjectMaintainer, models.ProjectOwner, http.StatusForbidden},
+		{"contributor cannot add members", models.ProjectContributor, models.ProjectViewer, http.StatusForbidden},
+		{"non-member cannot add members", "", models.ProjectViewer, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockRepo := setupProjectMemberRouter(models.WorkspaceMember)
+			mockRepo.On("GetProjectMember", 7, uint(8)).Return(nil, gorm.ErrRecordNotFound)
+			if tt.caller == "" {
+				mockRepo.On("GetProjectMember", 7, uint(5)).Return(nil, gorm.ErrRecordNotFound)
+			} else {
+				mockRepo.On("GetProjectMember", 7, uint(5)).Return(&models.ProjectMember{ProjectID: 7, UserID: 5, Role: tt.caller}, nil)
+			}
+			mockRepo.On("AddProjectMember", &models.ProjectMember{ProjectID: 7, UserID: 8, Role: tt.role}).Return(nil)
+
+			rr := sendJSON(router, "POST", "/projects/7/members", fmt.Sprintf(`{"user_id":8,"role":%q}`, tt.role))
+			assert.Equal(t, tt.status, rr.Code, "статус код не соответствует ожидаемому")
+			if tt.status != http.StatusCreated {
+				mockRepo.AssertNotCalled(t, "AddProjectMember", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAddProjectMember_WorkspaceAdmin(t *testing.T) {
+	registerLookups(t, &fakeLookups{roles: map[int]string{8: "developer"}})
+	router, mockRepo := setupProjectMemberRouter(models.WorkspaceAdmin)
+	// Demoting an owner needs owner rights, which workspace admins have.
+	mockRepo.On("GetProjectMember", 7, uint(8)).Return(&models.ProjectMember{ProjectID: 7, UserID: 8, Role: models.ProjectOwner}, nil)
+	mockRepo.On("AddProjectMember", &models.ProjectMember{ProjectID: 7, UserID: 8, Role: models.ProjectViewer}).Return(repository.ErrLastOwner)
+
+	rr := sendJSON(router, "POST", "/projects/7/members", `{"user_id":8,"role":"viewer"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code, "последнего владельца нельзя понизить")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddProjectMember_InvalidRole(t *testing.T) {
+	registerLookups(t, &fakeLookups{roles: map[int]string{8: "developer"}})
+	router, _ := setupProjectMemberRouter(models.WorkspaceAdmin)
+
+	rr := sendJSON(router, "POST", "/projects/7/members", `{"user_id":8,"role":"admin"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, []problem.FieldError{{Field: "role", Message: "role must be one of: owner, maintainer, contributor, viewer"}}, decodeProblem(t, rr).Errors)
+}
+
+func TestAddProjectTeam_UnknownTeam(t *testing.T) {
+	router, mockRepo := setupProjectMemberRouter(models.WorkspaceAdmin)
+	mockRepo.On("AddProjectTeam", 7, uint(3), models.ProjectContributor).Return(nil, gorm.ErrRecordNotFound)
+
+	rr := sendJSON(router, "POST", "/projects/7/teams", `{"team_id":3,"role":"contributor"}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, "Team not found", decodeProblem(t, rr).Detail)
+}
+
+func TestRemoveProjectMember(t *testing.T) {
+	router, mockRepo := setupProjectMemberRouter(models.WorkspaceMember)
+	mockRepo.On("GetProjectMember", 7, uint(5)).Return(&models.ProjectMember{ProjectID: 7, UserID: 5, Role: models.ProjectMaintainer}, nil)
+	mockRepo.On("GetProjectMember", 7, uint(8)).Return(&models.ProjectMember{ProjectID: 7, UserID: 8, Role: models.ProjectOwner}, nil)
+	mockRepo.On("GetProjectMember", 7, uint(9)).Return(&models.ProjectMember{ProjectID: 7, UserID: 9, Role: models.ProjectContributor}, nil)
+	// Members with assigned tasks are kept by the tasks' foreign key.
+	mockRepo.On("RemoveProjectMember", 7, uint(9)).Return(&pgconn.PgError{Code: "23503", ConstraintName: "fk_tasks_assignee_project", TableName: "tasks"})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/projects/7/members/8", nil))
+	assert.Equal(t, http.StatusForbidden, rr.Code, "сопровождающий не может удалить владельца")
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/projects/7/members/9", nil))
+	assert.Equal(t, http.StatusConflict, rr.Code, "участника с задачами нельзя удалить")
+}
+
+func setupTeamRouter(role string) (*gin.Engine, *MockTeamRepository) {
+	mockRepo := new(MockTeamRepository)
+	handler := handlers.NewTeamHandler(mockRepo)
+	router := gin.New()
+	router.Use(asMember(role))
+	router.GET("/teams", handler.GetAllTeams)
+	router.POST("/teams", handler.CreateTeam)
+	router.POST("/teams/:id/members", handler.AddTeamMember)
+	return router, mockRepo
+}
+
+func TestCreateTeam(t *testing.T) {
+	router, mockRepo := setupTeamRouter(models.WorkspaceMember)
+	rr := sendJSON(router, "POST", "/teams", `{"name":"Backend"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "только администратор может создавать команды")
+
+	router, mockRepo = setupTeamRouter(models.WorkspaceAdmin)
+	mockRepo.On("CreateTeam", &models.Team{Name: "Backend"}).Return(nil)
+	rr = sendJSON(router, "POST", "/teams", `{"name":"Backend"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, "статус код не соответствует ожидаемому")
+
+	rr = sendJSON(router, "POST", "/teams", `{"name":""}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "имя команды обязательно")
+	mockRepo.AssertNumberOfCalls(t, "CreateTeam", 1)
+}
+
+func TestAddTeamMember(t *testing.T) {
+	registerLookups(t, &fakeLookups{roles: map[int]string{8: "developer"}})
+	router, mockRepo := setupTeamRouter(models.WorkspaceAdmin)
+	mockRepo.On("GetTeamByID", uint(2)).Return(&models.Team{ID: 2, Name: "Backend"}, nil)
+	mockRepo.On("GetTeamByID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("AddTeamMember", &models.TeamMember{TeamID: 2, UserID: 8}).Return(nil)
+
+	rr := sendJSON(router, "POST", "/teams/2/members", `{"user_id":8}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, "статус код не соответствует ожидаемому")
+
+	rr = sendJSON(router, "POST", "/teams/2/members", `{"user_id":9}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "пользователь вне рабочего пространства должен отклоняться")
+
+	rr = sendJSON(router, "POST", "/teams/3/members", `{"user_id":8}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "статус код не соответствует ожидаемому")
+	mockRepo.AssertNumberOfCalls(t, "AddTeamMember", 1)
+}
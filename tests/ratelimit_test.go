@@ -0,0 +1,264 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/changes"
+	"github.com/togzhanzhakhani/projects/internal/graph"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/ratelimit"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+	"github.com/togzhanzhakhani/projects/internal/rpc"
+	pb "github.com/togzhanzhakhani/projects/pkg/pb/projects/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// setupRateLimitRouter serves the routes limited by config, with a clock
+// the test moves.
+func setupRateLimitRouter(config ratelimit.Config) (*gin.Engine, *time.Time) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := ratelimit.NewMemoryStore()
+	store.Now = func() time.Time { return now }
+	return setupRateLimitRouterWith(config, store), &now
+}
+
+func setupRateLimitRouterWith(config ratelimit.Config, store ratelimit.Store) *gin.Engine {
+	tokenRepo := new(MockAPITokenRepository)
+	tokenRepo.On("FindActiveAPIToken", hashToken("pat_script")).Return(&models.APIToken{ID: 3, UserID: 5, Scopes: []string{"tasks:read"}}, nil)
+	tokenRepo.On("TouchAPIToken", mock.AnythingOfType("*models.APIToken")).Return(nil)
+	taskRepo := new(MockTaskRepository)
+	taskRepo.On("GetAllTasks").Return([]models.Task{}, nil)
+	taskRepo.On("GetOverdueTasks", mock.Anything).Return([]models.Task{}, nil)
+	tokens := handlers.NewAPITokenHandler(tokenRepo)
+	limiter := ratelimit.NewLimiter(config, store)
+	router := gin.New()
+	router.SetTrustedProxies(config.TrustedProxies)
+	routes.Register(router, routes.Handlers{
+		Task:         handlers.NewTaskHandler(taskRepo),
+		GraphQL:      handlers.NewGraphQLHandler(graph.NewResolver(newFakeGraphRepository())),
+		APIToken:     tokens,
+		Docs:         handlers.NewDocsHandler([]byte(`{}`)),
+		Authenticate: []gin.HandlerFunc{auth.Middleware(auth.Any(auth.BearerScoped(tokens.Authenticate), auth.Bearer(sessionUser), auth.Header))},
+		RateLimitIP:  []gin.HandlerFunc{limiter.IPMiddleware()},
+		RateLimit:    []gin.HandlerFunc{limiter.Middleware()},
+	})
+	return router
+}
+
+// sessionUser accepts the session tokens "session-<user ID>".
+func sessionUser(token string) (uint, bool) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(token, "session-"), 10, 32)
+	return uint(id), err == nil && strings.HasPrefix(token, "session-")
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	router, now := setupRateLimitRouter(ratelimit.Config{Default: &ratelimit.Limit{Requests: 2, Period: time.Minute}})
+
+	rr := sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", rr.Header().Get("RateLimit-Reset"), "один запрос восстанавливается за 30 секунд")
+	assert.Equal(t, "2;w=60", rr.Header().Get("RateLimit-Policy"))
+
+	sendAs(router, "GET", "/tasks/overdue", "", "Authorization", "Bearer session-5")
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, "третий запрос за минуту превышает лимит")
+	assert.Contains(t, rr.Body.String(), `"code":"rate_limited"`)
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-6")
+	assert.Equal(t, http.StatusOK, rr.Code, "у каждого пользователя свой лимит")
+
+	*now = now.Add(30 * time.Second)
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, http.StatusOK, rr.Code, "лимит должен восстанавливаться со временем")
+}
+
+func TestRateLimit_Clients(t *testing.T) {
+	router, _ := setupRateLimitRouter(ratelimit.Config{Default: &ratelimit.Limit{Requests: 1, Period: time.Hour}})
+
+	rr := sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer pat_script")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer pat_script")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, "API-токен ограничивается отдельно")
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, http.StatusOK, rr.Code, "лимит токена не расходует лимит пользователя")
+
+	rr = sendAs(router, "GET", "/graphql/schema", "", "X-Forwarded-For", "203.0.113.7")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = sendAs(router, "GET", "/graphql/schema", "", "X-Forwarded-For", "203.0.113.7")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, "анонимные запросы ограничиваются по IP")
+
+	rr = sendAs(router, "GET", "/openapi.json", "", "Authorization", "Bearer session-5")
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"), "документация не ограничивается")
+}
+
+func TestRateLimit_UnverifiedUserHeaderUsesIP(t *testing.T) {
+	router, _ := setupRateLimitRouter(ratelimit.Config{Default: &ratelimit.Limit{Requests: 1, Period: time.Hour}})
+
+	rr := sendAs(router, "GET", "/tasks/", "", auth.UserHeader, "5")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = sendAs(router, "GET", "/tasks/", "", auth.UserHeader, "6")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, "заголовок X-User-ID не должен давать новый лимит")
+}
+
+// sendForwardedFor sends an anonymous request from 192.0.2.1, the remote
+// address of httptest requests, with an X-Forwarded-For header.
+func sendForwardedFor(router *gin.Engine, forwardedFor string) int {
+	req := httptest.NewRequest("GET", "/graphql/schema", nil)
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr.Code
+}
+
+func TestRateLimit_SpoofedForwardedFor(t *testing.T) {
+	limit := &ratelimit.Limit{Requests: 1, Period: time.Hour}
+	router, _ := setupRateLimitRouter(ratelimit.Config{Default: limit})
+	assert.Equal(t, http.StatusOK, sendForwardedFor(router, "203.0.113.7"))
+	assert.Equal(t, http.StatusTooManyRequests, sendForwardedFor(router, "198.51.100.9"), "без доверенных прокси X-Forwarded-For не должен давать новый лимит")
+
+	router, _ = setupRateLimitRouter(ratelimit.Config{Default: limit, TrustedProxies: []string{"192.0.2.0/24"}})
+	assert.Equal(t, http.StatusOK, sendForwardedFor(router, "203.0.113.7"))
+	assert.Equal(t, http.StatusOK, sendForwardedFor(router, "198.51.100.9"), "за доверенным прокси клиенты различаются по X-Forwarded-For")
+	assert.Equal(t, http.StatusTooManyRequests, sendForwardedFor(router, "198.51.100.9"))
+}
+
+func TestRateLimit_IPBeforeAuthentication(t *testing.T) {
+	router, _ := setupRateLimitRouter(ratelimit.Config{IP: &ratelimit.Limit{Requests: 2, Period: time.Hour}})
+
+	rr := sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer guess-1")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer guess-2")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, "лимит по IP действует до проверки учётных данных")
+
+	rr = sendAs(router, "GET", "/graphql/schema", "", "Authorization", "Bearer guess-3")
+	assert.Equal(t, http.StatusOK, rr.Code, "публичные маршруты не расходуют лимит аутентификации")
+}
+
+func TestRateLimit_GRPC(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := ratelimit.NewMemoryStore()
+	store.Now = func() time.Time { return now }
+	limiter := ratelimit.NewLimiter(ratelimit.Config{Default: &ratelimit.Limit{Requests: 1, Period: time.Minute}}, store)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", uint(1)).Return(&models.User{ID: 1}, nil)
+	clients := setupGRPC(t, userRepo, new(MockTaskRepository), changes.NewBroker(), rpc.RateLimit(limiter)...)
+
+	_, err := clients.users.GetUser(context.Background(), &pb.GetUserRequest{Id: 1})
+	assert.NoError(t, err)
+	var header metadata.MD
+	_, err = clients.users.GetUser(context.Background(), &pb.GetUserRequest{Id: 1}, grpc.Header(&header))
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code(), "второй вызов за минуту превышает лимит")
+	assert.Equal(t, "rate_limited", errorReason(st))
+	assert.Equal(t, []string{"60"}, header.Get("retry-after"))
+}
+
+func TestRateLimit_Routes(t *testing.T) {
+	router, _ := setupRateLimitRouter(ratelimit.Config{
+		Default: &ratelimit.Limit{Requests: 100, Period: time.Minute},
+		Routes: map[string]*ratelimit.Limit{
+			"GET /tasks/":     {Requests: 1, Period: time.Minute},
+			"/graphql/schema": nil,
+		},
+	})
+
+	rr := sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Limit"), "у маршрута свой лимит")
+	rr = sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	rr = sendAs(router, "GET", "/tasks/overdue", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, "100", rr.Header().Get("RateLimit-Limit"), "остальные маршруты используют общий лимит")
+	assert.Equal(t, "99", rr.Header().Get("RateLimit-Remaining"), "лимит маршрута не расходует общий")
+	rr = sendAs(router, "GET", "/graphql/schema", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"), "маршрут без лимита не ограничивается")
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (float64, bool, error) {
+	return 0, false, errors.New("connection refused")
+}
+
+func TestRateLimit_StoreFailure(t *testing.T) {
+	router := setupRateLimitRouterWith(ratelimit.Config{Default: &ratelimit.Limit{Requests: 1, Period: time.Minute}}, failingStore{})
+
+	rr := sendAs(router, "GET", "/tasks/", "", "Authorization", "Bearer session-5")
+	assert.Equal(t, http.StatusOK, rr.Code, "при сбое хранилища запросы пропускаются")
+}
+
+func TestRateLimit_Config(t *testing.T) {
+	limit, err := ratelimit.ParseLimit("5/10s")
+	assert.NoError(t, err)
+	assert.Equal(t, ratelimit.Limit{Requests: 5, Period: 10 * time.Second}, limit)
+	for _, s := range []string{"600", "0/m", "x/m", "5/week", "5/-1s"} {
+		_, err := ratelimit.ParseLimit(s)
+		assert.Error(t, err, s)
+	}
+
+	t.Setenv("RATE_LIMIT", "")
+	t.Setenv("RATE_LIMIT_IP", "")
+	t.Setenv("RATE_LIMIT_ROUTES", "")
+	config, err := ratelimit.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, ratelimit.DefaultConfig.Default, config.Default, "по умолчанию 600 запросов в минуту")
+	assert.Equal(t, ratelimit.DefaultConfig.IP, config.IP)
+
+	t.Setenv("RATE_LIMIT_IP", "50/s")
+	config, err = ratelimit.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, &ratelimit.Limit{Requests: 50, Period: time.Second}, config.IP)
+	t.Setenv("RATE_LIMIT_IP", "")
+
+	t.Setenv("RATE_LIMIT", "off")
+	t.Setenv("RATE_LIMIT_ROUTES", "GET  /tasks/=30/m, POST /auth/login=10/h,/graphql=off")
+	config, err = ratelimit.LoadConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, config.Default)
+	assert.Equal(t, map[string]*ratelimit.Limit{
+		"GET /tasks/":      {Requests: 30, Period: time.Minute},
+		"POST /auth/login": {Requests: 10, Period: time.Hour},
+		"/graphql":         nil,
+	}, config.Routes)
+	assert.Equal(t, time.Hour, config.Longest())
+
+	t.Setenv("RATE_LIMIT_ROUTES", "GET /tasks/")
+	_, err = ratelimit.LoadConfig()
+	assert.Error(t, err)
+	t.Setenv("RATE_LIMIT_ROUTES", "")
+
+	t.Setenv("TRUSTED_PROXIES", "")
+	config, err = ratelimit.LoadConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, config.TrustedProxies, "по умолчанию прокси не доверяют")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1")
+	config, err = ratelimit.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.1"}, config.TrustedProxies)
+	t.Setenv("TRUSTED_PROXIES", "proxy.local")
+	_, err = ratelimit.LoadConfig()
+	assert.Error(t, err)
+}
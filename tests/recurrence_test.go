@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/togzhanzhakhani/projects/internal/recurrence"
+)
+
+func TestRRule_WeeklyByDayWithCount(t *testing.T) {
+	rule, err := recurrence.Parse("RRULE:FREQ=WEEKLY;BYDAY=MO,TH;COUNT=4", time.UTC)
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 7, 4, 9, 0, 0, 0, time.UTC) // Thursday
+	occurrences := rule.Between(start, start, start.AddDate(1, 0, 0))
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 7, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 8, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 11, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 15, 9, 0, 0, 0, time.UTC),
+	}, occurrences, "даты повторений не соответствуют ожидаемым")
+}
+
+func TestRRule_MonthlyLastFridayUntil(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20240930", time.UTC)
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	occurrences := rule.Between(start, start, start.AddDate(1, 0, 0))
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 7, 26, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 8, 30, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 9, 27, 10, 0, 0, 0, time.UTC),
+	}, occurrences, "даты повторений не соответствуют ожидаемым")
+}
+
+func TestRRule_DailyKeepsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	rule, err := recurrence.Parse("FREQ=DAILY;INTERVAL=2", loc)
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 3, 29, 8, 0, 0, 0, loc)
+	occurrences := rule.Between(start, start, start.AddDate(0, 0, 5))
+	assert.Len(t, occurrences, 3)
+	for _, o := range occurrences {
+		assert.Equal(t, 8, o.Hour(), "время повторения не соответствует ожидаемому")
+	}
+}
+
+func TestRRule_CountBeforeAndString(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=DAILY;COUNT=10", time.UTC)
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3, rule.CountBefore(start, time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "FREQ=DAILY;COUNT=10", rule.String())
+}
+
+func TestRRule_InvalidRules(t *testing.T) {
+	for _, value := range []string{"", "FREQ=YEARLY", "FREQ=DAILY;COUNT=2;UNTIL=20240101", "FREQ=WEEKLY;BYDAY=XX", "FREQ=DAILY;BYMONTHDAY=1"} {
+		_, err := recurrence.Parse(value, time.UTC)
+		assert.Error(t, err, value)
+	}
+}
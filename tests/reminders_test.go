@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/reminders"
+)
+
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) HasReminder(taskID int, kind string, offset time.Duration, dueDate time.Time) (bool, error) {
+	args := m.Called(taskID, kind, offset, dueDate)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReminderRepository) CreateReminder(reminder *models.TaskReminder) error {
+	args := m.Called(reminder)
+	return args.Error(0)
+}
+
+type recordingNotifier struct {
+	events []reminders.Event
+}
+
+func (n *recordingNotifier) Notify(event reminders.Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestReminderScheduler_EmitsOnlyUnsentEvents(t *testing.T) {
+	now := time.Date(2024, 7, 10, 9, 0, 0, 0, time.UTC)
+	dueSoon := now.Add(30 * time.Minute)
+	overdue := now.Add(-2 * time.Hour)
+
+	taskRepo := new(MockTaskRepository)
+	reminderRepo := new(MockReminderRepository)
+	notifier := &recordingNotifier{}
+
+	taskRepo.On("GetTasksDueBetween", now, now.Add(time.Hour)).Return([]models.Task{{ID: 1, DueDate: &dueSoon}}, nil)
+	taskRepo.On("GetOverdueTasks", now).Return([]models.Task{{ID: 2, DueDate: &overdue}, {ID: 3, DueDate: &overdue}}, nil)
+	reminderRepo.On("HasReminder", 1, reminders.EventDueSoon, time.Hour, dueSoon).Return(false, nil)
+	reminderRepo.On("HasReminder", 2, reminders.EventOverdue, time.Duration(0), overdue).Return(false, nil)
+	reminderRepo.On("HasReminder", 3, reminders.EventOverdue, time.Duration(0), overdue).Return(true, nil)
+	reminderRepo.On("CreateReminder", mock.AnythingOfType("*models.TaskReminder")).Return(nil)
+
+	scheduler := reminders.NewScheduler(taskRepo, reminderRepo, notifier)
+	scheduler.DueSoonOffsets = []time.Duration{time.Hour}
+	scheduler.OverdueOffsets = []time.Duration{0}
+
+	assert.NoError(t, scheduler.RunOnce(now))
+	assert.Len(t, notifier.events, 2, "количество событий не соответствует ожидаемому")
+	assert.Equal(t, reminders.EventDueSoon, notifier.events[0].Kind)
+	assert.Equal(t, 1, notifier.events[0].Task.ID)
+	assert.Equal(t, reminders.EventOverdue, notifier.events[1].Kind)
+	assert.Equal(t, 2, notifier.events[1].Task.ID)
+	reminderRepo.AssertNumberOfCalls(t, "CreateReminder", 2)
+}
+
+func TestParseOffsets(t *testing.T) {
+	offsets, err := reminders.ParseOffsets("24h, 1h,30m")
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{24 * time.Hour, time.Hour, 30 * time.Minute}, offsets)
+
+	_, err = reminders.ParseOffsets("tomorrow")
+	assert.Error(t, err)
+}
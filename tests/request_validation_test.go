@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/api"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/openapi"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/routes"
+)
+
+func validatedRouter(t *testing.T, taskRepo *MockTaskRepository) *gin.Engine {
+	spec, err := openapi.Load(api.Spec)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(openapi.NewRequestValidator(spec).Middleware())
+	routes.Register(router, routes.Handlers{Task: handlers.NewTaskHandler(taskRepo)})
+	return router
+}
+
+func TestRequestValidation_RejectsInvalidBody(t *testing.T) {
+	repo := new(MockTaskRepository)
+	router := validatedRouter(t, repo)
+
+	taskJSON := `{"title":"","description":"Quarterly report","priority":"High","status":"todo","assignee_id":"3","project_id":5,"completed_at":"15.07.2024"}`
+	req, _ := http.NewRequest("POST", "/tasks/", bytes.NewBufferString(taskJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	var body problem.Problem
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.ElementsMatch(t, []problem.FieldError{
+		{In: "body", Field: "created_at", Message: "is required"},
+		{In: "body", Field: "title", Message: "must not be empty"},
+		{In: "body", Field: "priority", Message: "must be one of: low, medium, high"},
+		{In: "body", Field: "assignee_id", Message: "must be a number"},
+		{In: "body", Field: "completed_at", Message: "must be a date in YYYY-MM-DD format"},
+	}, body.Errors)
+	repo.AssertNotCalled(t, "CreateTask", mock.Anything)
+}
+
+func TestRequestValidation_RejectsInvalidPathAndQuery(t *testing.T) {
+	router := validatedRouter(t, new(MockTaskRepository))
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks/abc", nil)
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Contains(t, rr.Body.String(), `"field":"id"`)
+
+	rr = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/tasks/1?assignee=john", nil)
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Contains(t, rr.Body.String(), `{"in":"query","field":"assignee","message":"must be an integer"}`)
+}
+
+func TestRequestValidation_PassesValidRequestToHandler(t *testing.T) {
+	repo := new(MockTaskRepository)
+	router := validatedRouter(t, repo)
+
+	repo.On("CreateTask", mock.Anything).Return(nil)
+
+	taskJSON := `{"title":"Finish Report","description":"Quarterly report","priority":"high","status":"todo","assignee_id":3,"project_id":5,"created_at":"2024-07-01","completed_at":"2024-07-15"}`
+	req, _ := http.NewRequest("POST", "/tasks/", bytes.NewBufferString(taskJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code, "статус код не соответствует ожидаемому")
+	repo.AssertExpectations(t)
+}
+
+func TestRequestValidation_RejectsUndeclaredMediaType(t *testing.T) {
+	repo := new(MockTaskRepository)
+	router := validatedRouter(t, repo)
+
+	req, _ := http.NewRequest("POST", "/tasks/", bytes.NewBufferString(`title=Finish+Report`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code, "тело не в JSON должно отклоняться")
+	assert.Contains(t, rr.Body.String(), `"code":"unsupported_media_type"`)
+	repo.AssertNotCalled(t, "CreateTask", mock.Anything)
+}
@@ -0,0 +1,411 @@
+package tests
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/oidc"
+	"gorm.io/gorm"
+)
+
+const (
+	ssoClientID    = "projects"
+	ssoSecret      = "client-secret"
+	ssoRedirectURL = "https://api.example.com/auth/oidc/callback"
+)
+
+// authorization is an authorization code the mock identity provider issued.
+type authorization struct {
+	challenge string
+	nonce     string
+	claims    map[string]interface{}
+}
+
+// mockIdP is a local OpenID Connect provider. It signs ID tokens with an RSA
+// key, which rotate replaces, and checks the client secret and PKCE code
+// verifier when codes are redeemed.
+type mockIdP struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	key       *rsa.PrivateKey
+	kid       string
+	codes     map[string]authorization
+	jwksCalls int
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	idp := &mockIdP{codes: make(map[string]authorization)}
+	idp.rotate()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		idp.jwksCalls++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": idp.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		r.ParseForm()
+		idp.mu.Lock()
+		auth, ok := idp.codes[r.PostForm.Get("code")]
+		delete(idp.codes, r.PostForm.Get("code"))
+		idp.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case id != ssoClientID || secret != ssoSecret:
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		case !ok || oidc.Challenge(r.PostForm.Get("code_verifier")) != auth.challenge || r.PostForm.Get("redirect_uri") != ssoRedirectURL:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		default:
+			claims := map[string]interface{}{
+				"iss":   idp.server.URL,
+				"aud":   ssoClientID,
+				"exp":   time.Now().Add(5 * time.Minute).Unix(),
+				"iat":   time.Now().Unix(),
+				"nonce": auth.nonce,
+			}
+			for k, v := range auth.claims {
+				claims[k] = v
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": idp.sign(claims)})
+		}
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// rotate replaces the signing key with a new one.
+func (idp *mockIdP) rotate() {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.key = key
+	idp.kid = base64.RawURLEncoding.EncodeToString(key.N.Bytes()[:6])
+}
+
+func (idp *mockIdP) sign(claims map[string]interface{}) string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return signJWT(map[string]string{"alg": "RS256", "kid": idp.kid}, claims, func(digest []byte) []byte {
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest)
+		return signature
+	})
+}
+
+// authorize logs a user with claims in at the provider, as the browser
+// following location would, and returns the code it redirects back with.
+func (idp *mockIdP) authorize(t *testing.T, location string, claims map[string]interface{}) string {
+	u, err := url.Parse(location)
+	assert.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, idp.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path, "вход должен перенаправлять к провайдеру")
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, ssoClientID, q.Get("client_id"))
+	assert.Equal(t, ssoRedirectURL, q.Get("redirect_uri"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"), "должен использоваться PKCE")
+	assert.Contains(t, q.Get("scope"), "openid")
+	code := "code-" + q.Get("state")
+	idp.mu.Lock()
+	idp.codes[code] = authorization{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), claims: claims}
+	idp.mu.Unlock()
+	return code
+}
+
+func (idp *mockIdP) config() oidc.Config {
+	config := oidc.DefaultConfig
+	config.Issuer = idp.server.URL
+	config.ClientID = ssoClientID
+	config.ClientSecret = ssoSecret
+	config.RedirectURL = ssoRedirectURL
+	config.GroupRoles = map[string]string{"engineering": "developer", "leads": "manager", "it-admins": "admin"}
+	return config
+}
+
+func signJWT(header map[string]string, claims map[string]interface{}, sign func(digest []byte) []byte) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign(digest[:]))
+}
+
+func setupSSORouter(t *testing.T) (*gin.Engine, *handlers.AuthHandler, *MockAuthRepository, *mockIdP) {
+	idp := newMockIdP(t)
+	mockRepo := new(MockAuthRepository)
+	mockRepo.On("RecordEvent", mock.AnythingOfType("*models.AuthEvent")).Return(nil).Maybe()
+	mockRepo.On("CreateOIDCLogin", mock.AnythingOfType("*models.OIDCLogin")).Return(nil).Maybe()
+	mockRepo.On("CreateSession", mock.AnythingOfType("*models.Session")).Return(nil).Maybe()
+	handler := handlers.NewAuthHandler(mockRepo, new(MockUserRepository), &recordingResetSender{})
+	handler.OIDC = oidc.NewProvider(idp.config())
+	router := gin.New()
+	router.GET("/auth/oidc/login", handler.StartSSO)
+	router.GET("/auth/oidc/callback", handler.SSOCallback)
+	return router, handler, mockRepo, idp
+}
+
+// ssoLogin starts a single sign-on login, logs in at the identity provider
+// with claims and returns the response to the callback.
+func ssoLogin(t *testing.T, router *gin.Engine, mockRepo *MockAuthRepository, idp *mockIdP, claims map[string]interface{}) *httptest.ResponseRecorder {
+	rr := sendJSON(router, "GET", "/auth/oidc/login", "")
+	assert.Equal(t, http.StatusFound, rr.Code, "статус код не соответствует ожидаемому")
+	location := rr.Header().Get("Location")
+	state, _ := url.Parse(location)
+	cookie := rr.Result().Cookies()[0]
+	assert.Equal(t, "oidc_state", cookie.Name)
+	assert.Equal(t, state.Query().Get("state"), cookie.Value, "состояние должно быть привязано к браузеру")
+	assert.True(t, cookie.HttpOnly && cookie.Secure, "cookie должна быть HttpOnly и Secure")
+
+	var login *models.OIDCLogin
+	for _, call := range mockRepo.Calls {
+		if call.Method == "CreateOIDCLogin" {
+			login = call.Arguments.Get(0).(*models.OIDCLogin)
+		}
+	}
+	assert.Equal(t, hashToken(cookie.Value), login.StateHash, "хранится только хеш состояния")
+	assert.Equal(t, oidc.Challenge(login.Verifier), state.Query().Get("code_challenge"))
+	mockRepo.On("ClaimOIDCLogin", login.StateHash).Return(login, nil).Once()
+
+	code := idp.authorize(t, location, claims)
+	req, _ := http.NewRequest("GET", "/auth/oidc/callback?"+url.Values{"code": {code}, "state": {cookie.Value}}.Encode(), nil)
+	req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// provisioned returns the user and workspace of the last ProvisionUser call.
+func provisioned(mockRepo *MockAuthRepository) (*models.User, uint) {
+	for i := len(mockRepo.Calls) - 1; i >= 0; i-- {
+		if call := mockRepo.Calls[i]; call.Method == "ProvisionUser" {
+			return call.Arguments.Get(0).(*models.User), call.Arguments.Get(1).(uint)
+		}
+	}
+	return nil, 0
+}
+
+func TestSSO_ProvisionsNewUser(t *testing.T) {
+	router, _, mockRepo, idp := setupSSORouter(t)
+	mockRepo.On("FindUserByEmail", "jane@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("ProvisionUser", mock.AnythingOfType("*models.User"), uint(models.DefaultWorkspaceID)).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.User).ID = 9
+	}).Return(nil)
+
+	rr := ssoLogin(t, router, mockRepo, idp, map[string]interface{}{
+		"sub": "u-1", "email": "Jane@Example.com", "email_verified": true, "name": "Jane Doe",
+		"groups": []string{"engineering", "leads", "unmapped"},
+	})
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var response handlers.LoginResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.NotEmpty(t, response.Token, "вход должен выдавать токен сессии")
+	assert.Equal(t, models.User{ID: 9, Name: "Jane Doe", Email: "jane@example.com", Role: "manager"}, response.User,
+		"пользователь создаётся с наивысшей ролью из его групп")
+	_, workspaceID := provisioned(mockRepo)
+	assert.Equal(t, uint(models.DefaultWorkspaceID), workspaceID, "пользователь вступает в рабочее пространство")
+	assert.Equal(t, []string{models.AuthSSOLogin}, events(mockRepo))
+}
+
+func TestSSO_ExistingUser(t *testing.T) {
+	router, _, mockRepo, idp := setupSSORouter(t)
+	mockRepo.On("FindUserByEmail", "john@example.com").Return(&models.User{ID: 4, Name: "John", Email: "john@example.com", Role: "developer"}, nil)
+	mockRepo.On("ProvisionUser", mock.AnythingOfType("*models.User"), uint(models.DefaultWorkspaceID)).Return(nil)
+
+	rr := ssoLogin(t, router, mockRepo, idp, map[string]interface{}{"email": "john@example.com", "groups": "it-admins"})
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	user, _ := provisioned(mockRepo)
+	assert.Equal(t, uint(4), user.ID, "пользователь находится по email")
+	assert.Equal(t, "admin", user.Role, "роль следует за группами провайдера")
+
+	mockRepo.ExpectedCalls = mockRepo.ExpectedCalls[:0]
+	mockRepo.On("CreateOIDCLogin", mock.AnythingOfType("*models.OIDCLogin")).Return(nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("*models.Session")).Return(nil)
+	mockRepo.On("RecordEvent", mock.AnythingOfType("*models.AuthEvent")).Return(nil)
+	mockRepo.On("FindUserByEmail", "john@example.com").Return(&models.User{ID: 4, Name: "John", Email: "john@example.com", Role: "manager"}, nil)
+	mockRepo.On("ProvisionUser", mock.AnythingOfType("*models.User"), uint(models.DefaultWorkspaceID)).Return(nil)
+	rr = ssoLogin(t, router, mockRepo, idp, map[string]interface{}{"email": "john@example.com"})
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	user, _ = provisioned(mockRepo)
+	assert.Equal(t, "manager", user.Role, "без сопоставленных групп роль не меняется")
+}
+
+func TestSSO_Rejections(t *testing.T) {
+	router, handler, mockRepo, idp := setupSSORouter(t)
+	mockRepo.On("FindUserByEmail", "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	rr := ssoLogin(t, router, mockRepo, idp, map[string]interface{}{"email": "jane@example.com", "email_verified": false})
+	assert.Equal(t, http.StatusForbidden, rr.Code, "неподтверждённый email не принимается")
+
+	rr = ssoLogin(t, router, mockRepo, idp, map[string]interface{}{"email": "jane@example.com", "nonce": "replayed"})
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "токен с чужим nonce не принимается")
+
+	rr = ssoLogin(t, router, mockRepo, idp, map[string]interface{}{"email": "jane@example.com", "aud": "other-client"})
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "токен другого клиента не принимается")
+
+	rr = ssoLogin(t, router, mockRepo, idp, map[string]interface{}{"email": "jane@example.com", "exp": time.Now().Add(-time.Hour).Unix()})
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "просроченный токен не принимается")
+
+	handler.OIDC.Config.DefaultRole = ""
+	rr = ssoLogin(t, router, mockRepo, idp, map[string]interface{}{"email": "nobody@example.com", "groups": []string{"sales"}})
+	assert.Equal(t, http.StatusForbidden, rr.Code, "без роли по умолчанию нужна сопоставленная группа")
+	mockRepo.AssertNotCalled(t, "ProvisionUser", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+
+	rr = sendJSON(router, "GET", "/auth/oidc/login", "")
+	state := rr.Result().Cookies()[0].Value
+	req, _ := http.NewRequest("GET", "/auth/oidc/callback?code=x&state="+state, nil)
+	req.AddCookie(&http.Cookie{Name: "oidc_state", Value: "other"})
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "вход нельзя завершить в другом браузере")
+
+	mockRepo.On("ClaimOIDCLogin", hashToken(state)).Return(nil, gorm.ErrRecordNotFound)
+	req.Header.Del("Cookie")
+	req.AddCookie(&http.Cookie{Name: "oidc_state", Value: state})
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "состояние используется один раз")
+
+	rr = sendJSON(router, "GET", "/auth/oidc/callback?error=access_denied", "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	handler.OIDC = nil
+	rr = sendJSON(router, "GET", "/auth/oidc/login", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code, "без настройки единый вход недоступен")
+}
+
+func TestOIDC_Verify(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := oidc.NewProvider(idp.config())
+	ctx := context.Background()
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"iss": idp.server.URL, "aud": ssoClientID, "exp": time.Now().Add(time.Minute).Unix(), "nonce": "n", "email": "jane@example.com"}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	verified, err := provider.Verify(ctx, idp.sign(claims(map[string]interface{}{"groups": []string{"leads"}})), "n")
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com", verified.Email)
+	assert.Equal(t, []string{"leads"}, verified.Groups)
+
+	_, err = provider.Verify(ctx, idp.sign(claims(map[string]interface{}{"aud": []string{ssoClientID, "other"}})), "n")
+	assert.ErrorIs(t, err, oidc.ErrInvalidToken, "при нескольких получателях нужен azp")
+	_, err = provider.Verify(ctx, idp.sign(claims(map[string]interface{}{"aud": []string{ssoClientID, "other"}, "azp": ssoClientID})), "n")
+	assert.NoError(t, err)
+	_, err = provider.Verify(ctx, idp.sign(claims(map[string]interface{}{"iss": "https://evil.example.com"})), "n")
+	assert.ErrorIs(t, err, oidc.ErrInvalidToken, "токен другого издателя не принимается")
+
+	token := idp.sign(claims(nil))
+	parts := strings.Split(token, ".")
+	forged := signJWT(map[string]string{"alg": "RS256", "kid": idp.kid}, claims(map[string]interface{}{"email": "admin@example.com"}), func([]byte) []byte {
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		return signature
+	})
+	_, err = provider.Verify(ctx, forged, "n")
+	assert.ErrorIs(t, err, oidc.ErrInvalidToken, "подделанные данные не проходят проверку подписи")
+	unsigned := signJWT(map[string]string{"alg": "none"}, claims(nil), func([]byte) []byte { return nil })
+	_, err = provider.Verify(ctx, unsigned, "n")
+	assert.ErrorIs(t, err, oidc.ErrInvalidToken, "неподписанный токен не принимается")
+
+	assert.Equal(t, 1, idp.jwksCalls, "ключи кешируются")
+	idp.rotate()
+	_, err = provider.Verify(ctx, idp.sign(claims(nil)), "n")
+	assert.NoError(t, err, "после смены ключа набор ключей загружается заново")
+	assert.Equal(t, 2, idp.jwksCalls)
+}
+
+func TestOIDC_VerifyES256(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jwks" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kty": "EC", "crv": "P-256", "kid": "ec",
+				"x": base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+				"y": base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+			}}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "authorization_endpoint": server.URL + "/a", "token_endpoint": server.URL + "/t", "jwks_uri": server.URL + "/jwks"})
+	}))
+	defer server.Close()
+	config := oidc.DefaultConfig
+	config.Issuer, config.ClientID = server.URL, ssoClientID
+	provider := oidc.NewProvider(config)
+
+	token := signJWT(map[string]string{"alg": "ES256", "kid": "ec"}, map[string]interface{}{
+		"iss": server.URL, "aud": ssoClientID, "exp": time.Now().Add(time.Minute).Unix(), "nonce": "n", "email": "jane@example.com",
+	}, func(digest []byte) []byte {
+		r, s, _ := ecdsa.Sign(rand.Reader, key, digest)
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	})
+	verified, err := provider.Verify(context.Background(), token, "n")
+	assert.NoError(t, err, "токены ES256 должны проверяться")
+	assert.Equal(t, "jane@example.com", verified.Email)
+}
+
+func TestOIDC_Config(t *testing.T) {
+	config := oidc.DefaultConfig
+	config.GroupRoles = map[string]string{"engineering": "developer", "leads": "manager"}
+	role, ok := config.Role([]string{"leads", "engineering"})
+	assert.True(t, ok)
+	assert.Equal(t, "manager", role, "выбирается наивысшая роль")
+	_, ok = config.Role([]string{"sales"})
+	assert.False(t, ok)
+
+	roles, err := oidc.ParseGroupRoles("it-admins=admin, leads=manager")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"it-admins": "admin", "leads": "manager"}, roles)
+	_, err = oidc.ParseGroupRoles("leads=owner")
+	assert.Error(t, err, "неизвестная роль должна отклоняться")
+
+	t.Setenv("OIDC_ISSUER", "https://idp.example.com")
+	assert.Error(t, (&oidc.Config{}).LoadConfig(), "издателю нужны client id и redirect URL")
+	t.Setenv("OIDC_CLIENT_ID", ssoClientID)
+	t.Setenv("OIDC_REDIRECT_URL", ssoRedirectURL)
+	t.Setenv("OIDC_DEFAULT_ROLE", "none")
+	config = oidc.DefaultConfig
+	assert.NoError(t, config.LoadConfig())
+	assert.True(t, config.Enabled())
+	assert.Empty(t, config.DefaultRole)
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/tracker"
+)
+
+type MockTrackerRepository struct {
+	mock.Mock
+}
+
+func (m *MockTrackerRepository) WithContext(ctx context.Context) repository.TrackerRepository {
+	return m
+}
+
+func (m *MockTrackerRepository) UserIDsByEmail(emails []string) (map[string]int, error) {
+	args := m.Called(emails)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockTrackerRepository) GetProject(projectID int) (*models.Project, error) {
+	args := m.Called(projectID)
+	if project, ok := args.Get(0).(*models.Project); ok {
+		return project, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTrackerRepository) UpsertProject(source, externalID string, project *models.Project) (bool, error) {
+	args := m.Called(source, externalID, project)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTrackerRepository) UpsertTask(source, externalID string, task *models.Task) (bool, error) {
+	args := m.Called(source, externalID, task)
+	return args.Bool(0), args.Error(1)
+}
+
+const githubExport = `[
+  {"number": 7, "title": "Fix login", "body": "Users cannot log in", "state": "closed", "state_reason": "completed",
+   "repository_url": "https://api.github.com/repos/acme/web", "created_at": "2024-03-01T10:00:00Z", "closed_at": "2024-03-04T10:00:00Z",
+   "labels": [{"name": "bug"}, {"name": "priority: high"}], "assignee": {"login": "octocat"}},
+  {"number": 8, "title": "Add dark mode", "body": "", "state": "open",
+   "repository_url": "https://api.github.com/repos/acme/web", "created_at": "2024-03-02T10:00:00Z",
+   "labels": [{"name": "P7"}], "assignee": {"login": "ghost"}},
+  {"number": 9, "title": "Bump deps", "state": "open", "pull_request": {"url": "x"},
+   "repository_url": "https://api.github.com/repos/acme/web", "created_at": "2024-03-03T10:00:00Z"}
+]`
+
+func TestParseGitHub_SkipsPullRequestsAndReadsPriorityLabels(t *testing.T) {
+	issues, pullRequests, err := tracker.ParseGitHub([]byte(githubExport))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pullRequests, "pull request не пропущен")
+	assert.Len(t, issues, 2)
+
+	assert.Equal(t, "acme/web#7", issues[0].ExternalID)
+	assert.Equal(t, "high", issues[0].Priority)
+	assert.Equal(t, []string{"bug"}, issues[0].Labels, "метка приоритета не должна попадать в labels")
+	assert.Equal(t, "closed:completed", issues[0].State)
+}
+
+func TestParseJiraCSV_CollectsRepeatedLabelColumns(t *testing.T) {
+	export := "Summary,Issue key,Status,Priority,Assignee,Labels,Labels,Created,Resolved\n" +
+		"Write docs,DOC-3,In Progress,Major,anna@example.com,docs,q2,12/Mar/24 9:15 AM,\n"
+
+	issues, err := tracker.ParseJiraCSV(strings.NewReader(export))
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "DOC-3", issues[0].ExternalID)
+	assert.Equal(t, "DOC", issues[0].ProjectKey)
+	assert.Equal(t, []string{"docs", "q2"}, issues[0].Labels)
+	assert.Equal(t, time.Date(2024, 3, 12, 9, 15, 0, 0, time.UTC), issues[0].CreatedAt)
+	assert.Nil(t, issues[0].ClosedAt)
+}
+
+func TestParseJiraXML(t *testing.T) {
+	export := `<rss version="0.92"><channel><item>
+<key id="10001">OPS-1</key><summary>Rotate keys</summary><description>Quarterly rotation</description>
+<project id="1" key="OPS">Operations</project><status>Blocked</status><priority>Highest</priority>
+<assignee username="bob@example.com">Bob</assignee><labels><label>security</label></labels>
+<created>Mon, 4 Mar 2024 10:00:00 +0000</created><due>Fri, 29 Mar 2024 00:00:00 +0000</due>
+</item></channel></rss>`
+
+	issues, err := tracker.ParseJiraXML(strings.NewReader(export))
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "Operations", issues[0].ProjectName)
+	assert.Equal(t, "bob@example.com", issues[0].AssigneeLogin)
+	assert.Equal(t, []string{"security"}, issues[0].Labels)
+	assert.NotNil(t, issues[0].DueDate)
+}
+
+func TestTrackerImport_ReportsUnmappedValuesAndUpdatesExisting(t *testing.T) {
+	issues, _, err := tracker.ParseGitHub([]byte(githubExport))
+	assert.NoError(t, err)
+
+	repo := new(MockTrackerRepository)
+	repo.On("UserIDsByEmail", []string{"octo@example.com"}).Return(map[string]int{"octo@example.com": 5}, nil)
+	repo.On("UpsertProject", tracker.SourceGitHub, "acme/web", mock.AnythingOfType("*models.Project")).
+		Run(func(args mock.Arguments) {
+			project := args.Get(2).(*models.Project)
+			assert.True(t, project.EndDate.After(project.StartDate), "даты проекта некорректны")
+			project.ID = 3
+		}).Return(true, nil)
+
+	var saved []models.Task
+	repo.On("UpsertTask", tracker.SourceGitHub, "acme/web#7", mock.Anything).
+		Run(func(args mock.Arguments) { saved = append(saved, *args.Get(2).(*models.Task)) }).Return(false, nil)
+
+	importer := tracker.NewImporter(repo)
+	report, err := importer.Import(context.Background(), tracker.SourceGitHub, issues, tracker.Options{
+		ManagerID: 1,
+		UserMap:   map[string]string{"octocat": "Octo@example.com"},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, report.Updated, "существующая задача должна обновляться")
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 1, report.ProjectsCreated)
+	assert.Equal(t, map[string]int{"p7": 1}, report.Unmapped["priorities"])
+	assert.Equal(t, map[string]int{"ghost": 1}, report.Unmapped["assignees"])
+
+	assert.Len(t, saved, 1)
+	assert.Equal(t, "done", saved[0].Status)
+	assert.Equal(t, "high", saved[0].Priority)
+	assert.Equal(t, 5, saved[0].AssigneeID)
+	assert.Equal(t, 3, saved[0].ProjectID)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/changes"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/rpc"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	pb "github.com/togzhanzhakhani/projects/pkg/pb/projects/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func setupUserRoleRouter(role string) (*gin.Engine, *MockUserRepository) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", uint(5)).Return(&models.User{ID: 5, Name: "Me", Email: "me@example.com", Role: "developer"}, nil)
+	userRepo.On("GetUserByID", uint(6)).Return(&models.User{ID: 6, Name: "Other", Email: "other@example.com", Role: "developer"}, nil)
+	userRepo.On("UpdateUser", mock.AnythingOfType("*models.User")).Return(nil).Maybe()
+	router := gin.New()
+	router.Use(asMember(role))
+	router.PUT("/users/:id", handlers.NewUserHandler(userRepo).UpdateUser)
+	router.DELETE("/users/:id", handlers.NewUserHandler(userRepo).DeleteUser)
+	return router, userRepo
+}
+
+func TestUpdateUser_RoleChangeNeedsWorkspaceAdmin(t *testing.T) {
+	router, userRepo := setupUserRoleRouter(models.WorkspaceMember)
+
+	rr := sendJSON(router, "PUT", "/users/5", `{"name":"Me","email":"me@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "участник не может повысить свою роль")
+	rr = sendJSON(router, "PUT", "/users/6", `{"name":"Other","email":"other@example.com","role":"manager"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "участник не может менять роли других")
+	userRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+
+	rr = sendJSON(router, "PUT", "/users/5", `{"name":"Me Renamed","email":"me@example.com","role":"developer"}`)
+	assert.Equal(t, http.StatusOK, rr.Code, "без смены роли участник может менять свои данные")
+}
+
+func TestUpdateUser_AdminMayNotRaiseOwnRole(t *testing.T) {
+	router, _ := setupUserRoleRouter(models.WorkspaceAdmin)
+
+	rr := sendJSON(router, "PUT", "/users/5", `{"name":"Me","email":"me@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "администратор не может повысить свою роль")
+	rr = sendJSON(router, "PUT", "/users/6", `{"name":"Other","email":"other@example.com","role":"manager"}`)
+	assert.Equal(t, http.StatusOK, rr.Code, "администратор может менять роли других")
+}
+
+func TestUpdateUser_RoleChangeNeedsDefaultWorkspaceAdmin(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", uint(6)).Return(&models.User{ID: 6, Name: "Other", Email: "other@example.com", Role: "developer"}, nil)
+	router := gin.New()
+	// User 5 administers a workspace they created, not the default one.
+	router.Use(func(c *gin.Context) {
+		ctx := auth.NewContext(c.Request.Context(), 5)
+		c.Request = c.Request.WithContext(workspace.NewContext(ctx, models.Membership{WorkspaceID: 2, UserID: 5, Role: models.WorkspaceAdmin}))
+	})
+	router.PUT("/users/:id", handlers.NewUserHandler(userRepo).UpdateUser)
+
+	rr := sendJSON(router, "PUT", "/users/6", `{"name":"Other","email":"other@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code, "роли пользователей меняют только администраторы пространства по умолчанию")
+	userRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+}
+
+func TestAdministers(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, workspace.Administers(ctx), "без участия в пространстве доступ запрещён")
+	assert.False(t, workspace.AdministersDefault(ctx))
+	assert.True(t, workspace.Administers(workspace.NewSystemContext(ctx)), "фоновые задачи действуют как администраторы")
+	assert.True(t, workspace.AdministersDefault(workspace.NewSystemContext(ctx)))
+
+	admin := workspace.NewContext(ctx, models.Membership{WorkspaceID: 2, UserID: 5, Role: models.WorkspaceAdmin})
+	assert.True(t, workspace.Administers(admin))
+	assert.False(t, workspace.AdministersDefault(admin), "администратор другого пространства не администрирует пространство по умолчанию")
+}
+
+func TestUpdateUser_KeepsEmail(t *testing.T) {
+	for _, role := range []string{models.WorkspaceMember, models.WorkspaceAdmin} {
+		router, userRepo := setupUserRoleRouter(role)
+
+		rr := sendJSON(router, "PUT", "/users/6", `{"name":"Other","email":"attacker@example.com","role":"developer"}`)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "%s не может менять чужой email", role)
+		userRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+
+		rr = sendJSON(router, "PUT", "/users/6", `{"name":"Other Renamed","email":"other@example.com","role":"developer"}`)
+		assert.Equal(t, http.StatusOK, rr.Code, "без смены email можно менять данные")
+		rr = sendJSON(router, "PUT", "/users/5", `{"name":"Me","email":"new@example.com","role":"developer"}`)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "свой email меняется только с паролем через /me/email")
+	}
+}
+
+func TestDeleteUser_NeedsWorkspaceAdmin(t *testing.T) {
+	router, userRepo := setupUserRoleRouter(models.WorkspaceMember)
+	rr := sendJSON(router, "DELETE", "/users/6", "")
+	assert.Equal(t, http.StatusForbidden, rr.Code, "участник не может удалять пользователей")
+	userRepo.AssertNotCalled(t, "DeleteUser", mock.Anything)
+
+	router, userRepo = setupUserRoleRouter(models.WorkspaceAdmin)
+	userRepo.On("DeleteUser", uint(5)).Return(repository.ErrLastAdmin)
+	rr = sendJSON(router, "DELETE", "/users/5", "")
+	assert.Equal(t, http.StatusConflict, rr.Code, "последнего администратора нельзя удалить")
+}
+
+func TestGRPC_UpdateUser_RoleChangeNeedsWorkspaceAdmin(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", uint(5)).Return(&models.User{ID: 5, Name: "Me", Email: "me@example.com", Role: "developer"}, nil)
+	members := fakeMembers{{1, 5}: models.WorkspaceMember}
+	clients := setupGRPC(t, userRepo, new(MockTaskRepository), changes.NewBroker(), rpc.Scope(rpc.UserMetadata, members)...)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-user-id", "5")
+	_, err := clients.users.UpdateUser(ctx, &pb.UpdateUserRequest{
+		Id:   5,
+		User: &pb.UserInput{Name: "Me", Email: "me@example.com", Role: "admin"},
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "участник не может повысить свою роль через gRPC")
+	userRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+}
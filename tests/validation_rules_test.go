@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/i18n"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/problem"
+	"github.com/togzhanzhakhani/projects/internal/validation"
+)
+
+type fakeLookups struct {
+	emails   map[string]uint
+	roles    map[int]string
+	projects map[int]bool
+	// members maps projects to their members.
+	members map[int][]int
+	err     error
+}
+
+func (f *fakeLookups) EmailTaken(ctx context.Context, email string, exceptUserID uint) (bool, error) {
+	id, ok := f.emails[email]
+	return ok && id != exceptUserID, f.err
+}
+
+func (f *fakeLookups) UserRole(ctx context.Context, id int) (string, bool, error) {
+	role, ok := f.roles[id]
+	return role, ok, f.err
+}
+
+func (f *fakeLookups) ProjectExists(ctx context.Context, id int) (bool, error) {
+	return f.projects[id], f.err
+}
+
+func (f *fakeLookups) ProjectMember(ctx context.Context, projectID, userID int) (bool, error) {
+	for _, member := range f.members[projectID] {
+		if member == userID {
+			return true, f.err
+		}
+	}
+	return false, f.err
+}
+
+func registerLookups(t *testing.T, lookups validation.Lookups) {
+	validation.RegisterRules(lookups)
+	t.Cleanup(func() { validation.RegisterRules(nil) })
+}
+
+func sendJSON(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRules_UniqueEmail(t *testing.T) {
+	registerLookups(t, &fakeLookups{emails: map[string]uint{"johndoe@example.com": 1}})
+	handler, mockRepo := setupUserHandler(t)
+	mockRepo.On("GetUserByID", uint(1)).Return(&models.User{ID: 1, Email: "johndoe@example.com"}, nil)
+	mockRepo.On("GetUserByID", uint(2)).Return(&models.User{ID: 2, Email: "jane@example.com"}, nil)
+	mockRepo.On("UpdateUser", mock.AnythingOfType("*models.User")).Return(nil)
+
+	router := gin.New()
+	router.Use(asMember(models.WorkspaceAdmin))
+	router.POST("/users", handler.CreateUser)
+	router.PUT("/users/:id", handler.UpdateUser)
+
+	rr := sendJSON(router, "POST", "/users", `{"name":"John","email":"johndoe@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, []problem.FieldError{{Field: "email", Message: "Email already exists"}}, decodeProblem(t, rr).Errors)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+
+	rr = sendJSON(router, "PUT", "/users/1", `{"name":"John Doe","email":"johndoe@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusOK, rr.Code, "пользователь должен сохранять свой email")
+
+	rr = sendJSON(router, "PUT", "/users/2", `{"name":"Jane","email":"johndoe@example.com","role":"admin"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+}
+
+func TestRules_ConstraintViolationIsValidationError(t *testing.T) {
+	registerLookups(t, &fakeLookups{})
+	handler, mockRepo := setupUserHandler(t)
+	// A concurrent request created the same email after the rule passed.
+	mockRepo.On("CreateUser", mock.AnythingOfType("*models.User")).Return(
+		&pgconn.PgError{Code: "23505", ConstraintName: "idx_users_email", TableName: "users"})
+
+	router := gin.New()
+	router.Use(asMember(models.WorkspaceAdmin))
+	router.POST("/users", handler.CreateUser)
+
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"John","email":"johndoe@example.com","role":"admin"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "ru")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	body := decodeProblem(t, rr)
+	assert.Equal(t, problem.CodeValidationFailed, body.Code)
+	assert.Equal(t, []problem.FieldError{{Field: "email", Message: "Такой email уже существует"}}, body.Errors)
+}
+
+func TestRules_TaskReferences(t *testing.T) {
+	registerLookups(t, &fakeLookups{roles: map[int]string{}, projects: map[int]bool{}})
+	repo := new(MockTaskRepository)
+	router := gin.New()
+	router.POST("/tasks", handlers.NewTaskHandler(repo).CreateTask)
+
+	rr := sendJSON(router, "POST", "/tasks", `{"title":"Finish Report","description":"Quarterly report","priority":"high","status":"todo","assignee_id":3,"project_id":5,"created_at":"2024-07-01","completed_at":"2024-07-15"}`)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "статус код не соответствует ожидаемому")
+	assert.ElementsMatch(t, []problem.FieldError{
+		{Field: "assignee_id", Message: "Assignee does not exist"},
+		{Field: "project_id", Message: "Project does not exist"},
+	}, decodeProblem(t, rr).Errors)
+	repo.AssertNotCalled(t, "CreateTask", mock.Anything)
+}
+
+func TestRules_ManagerRole(t *testing.T) {
+	registerLookups(t, &fakeLookups{roles: map[int]string{1: "manager", 2: "developer"}})
+	project := models.Project{
+		Name:        "Alpha",
+		Description: "A project",
+		StartDate:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	for managerID, expected := range map[int]string{
+		1: "",
+		2: "Manager must be an admin or a manager",
+		9: "Manager does not exist",
+	} {
+		project.ManagerID = managerID
+		fieldErrors, err := validation.ValidateFields(context.Background(), i18n.English(), &project)
+		assert.NoError(t, err)
+		if expected == "" {
+			assert.Empty(t, fieldErrors, "менеджер %d должен проходить проверку", managerID)
+			continue
+		}
+		assert.Equal(t, []problem.FieldError{{Field: "manager_id", Message: expected}}, fieldErrors)
+	}
+}
+
+func TestRules_LookupErrorIsInternal(t *testing.T) {
+	registerLookups(t, &fakeLookups{err: errors.New("connection refused")})
+	handler, mockRepo := setupUserHandler(t)
+	router := gin.New()
+	router.Use(asMember(models.WorkspaceAdmin))
+	router.POST("/users", handler.CreateUser)
+
+	rr := sendJSON(router, "POST", "/users", `{"name":"John","email":"johndoe@example.com","role":"admin"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code, "статус код не соответствует ожидаемому")
+	assert.Equal(t, problem.CodeInternal, decodeProblem(t, rr).Code)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestRules_SkippedByValidate(t *testing.T) {
+	registerLookups(t, &fakeLookups{err: errors.New("must not be called")})
+	user := models.User{Name: "John", Email: "johndoe@example.com", Role: "admin"}
+	assert.Empty(t, validation.Validate(&user), "Validate не должен обращаться к базе данных")
+}
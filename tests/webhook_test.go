@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/togzhanzhakhani/projects/internal/commits"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"gorm.io/gorm"
+)
+
+type MockCommitRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommitRepository) WithContext(ctx context.Context) repository.CommitRepository {
+	return m
+}
+
+func (m *MockCommitRepository) ExistingTaskIDs(ids []int) (map[int]bool, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[int]bool), args.Error(1)
+}
+
+func (m *MockCommitRepository) AttachCommit(commit *models.TaskCommit) (bool, error) {
+	args := m.Called(commit)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCommitRepository) CompleteTask(taskID int, at time.Time) (bool, error) {
+	args := m.Called(taskID, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCommitRepository) GetCommitsByTaskID(taskID int) ([]models.TaskCommit, error) {
+	args := m.Called(taskID)
+	return args.Get(0).([]models.TaskCommit), args.Error(1)
+}
+
+func TestParseReferences(t *testing.T) {
+	cases := map[string][]commits.Reference{
+		"Fixes #12":                   {{TaskID: 12, Closes: true}},
+		"closes: #1, #2 and #3":       {{TaskID: 1, Closes: true}, {TaskID: 2, Closes: true}, {TaskID: 3, Closes: true}},
+		"Refactor parser, see #5":     {{TaskID: 5}},
+		"Merge #4; resolved #4":       {{TaskID: 4, Closes: true}},
+		"Fix color &#35; and a/b#9":   nil,
+		"prefix#7 is not a reference": nil,
+	}
+	for message, expected := range cases {
+		assert.Equal(t, expected, commits.ParseReferences(message), message)
+	}
+}
+
+// webhookWorkspaces returns a repository with the default workspace, whose
+// webhook secret is secret.
+func webhookWorkspaces(secret string) *MockWorkspaceRepository {
+	repo := new(MockWorkspaceRepository)
+	repo.On("GetWorkspaceByID", uint(models.DefaultWorkspaceID)).Return(&models.Workspace{ID: models.DefaultWorkspaceID, WebhookSecret: secret}, nil)
+	repo.On("GetWorkspaceByID", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	return repo
+}
+
+func pushRequest(t *testing.T, fixture string, headers map[string]string) *http.Request {
+	body, err := os.ReadFile("testdata/" + fixture)
+	assert.NoError(t, err)
+	req, _ := http.NewRequest("POST", "/webhooks/push", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return req
+}
+
+func TestPushWebhook_GitHubLinksAndCompletesTasks(t *testing.T) {
+	repo := new(MockCommitRepository)
+	handler := handlers.NewWebhookHandler(repo, webhookWorkspaces("s3cret"))
+
+	repo.On("ExistingTaskIDs", []int{12, 40, 12, 999}).Return(map[int]bool{12: true, 40: true}, nil)
+	var attached []*models.TaskCommit
+	repo.On("AttachCommit", mock.AnythingOfType("*models.TaskCommit")).
+		Run(func(args mock.Arguments) { attached = append(attached, args.Get(0).(*models.TaskCommit)) }).Return(true, nil)
+	repo.On("CompleteTask", 12, mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+
+	body, _ := os.ReadFile("testdata/github_push.json")
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.POST("/webhooks/push", handler.Push)
+	router.ServeHTTP(rr, pushRequest(t, "github_push.json", map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+	}))
+
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	var report struct {
+		Linked         []map[string]interface{} `json:"linked"`
+		CompletedTasks []int                    `json:"completed_tasks"`
+		UnknownTasks   []int                    `json:"unknown_tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Len(t, report.Linked, 3)
+	assert.Equal(t, []int{12}, report.CompletedTasks)
+	assert.Equal(t, []int{999}, report.UnknownTasks)
+
+	assert.Equal(t, "acme/web", attached[0].Repository)
+	assert.Equal(t, "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c", attached[0].SHA)
+	assert.Equal(t, "octocat@example.com", attached[0].AuthorEmail)
+	assert.True(t, attached[0].Closes)
+	repo.AssertExpectations(t)
+}
+
+func TestPushWebhook_RejectsBadSignature(t *testing.T) {
+	repo := new(MockCommitRepository)
+	handler := handlers.NewWebhookHandler(repo, webhookWorkspaces("s3cret"))
+
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.POST("/webhooks/push", handler.Push)
+	router.ServeHTTP(rr, pushRequest(t, "gitlab_push.json", map[string]string{"X-Gitlab-Token": "wrong"}))
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "статус код не соответствует ожидаемому")
+	repo.AssertNotCalled(t, "AttachCommit", mock.Anything)
+}
+
+func TestPushWebhook_GitLabFeatureBranchDoesNotComplete(t *testing.T) {
+	repo := new(MockCommitRepository)
+	handler := handlers.NewWebhookHandler(repo, webhookWorkspaces("s3cret"))
+
+	repo.On("ExistingTaskIDs", []int{7}).Return(map[int]bool{7: true}, nil)
+	repo.On("AttachCommit", mock.AnythingOfType("*models.TaskCommit")).Return(true, nil)
+
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.POST("/webhooks/push", handler.Push)
+	router.ServeHTTP(rr, pushRequest(t, "gitlab_push.json", map[string]string{"X-Gitlab-Token": "s3cret"}))
+
+	assert.Equal(t, http.StatusOK, rr.Code, "статус код не соответствует ожидаемому")
+	repo.AssertNotCalled(t, "CompleteTask", mock.Anything, mock.Anything)
+}
+
+func TestPushWebhook_RejectsWorkspaceWithoutSecret(t *testing.T) {
+	repo := new(MockCommitRepository)
+	handler := handlers.NewWebhookHandler(repo, webhookWorkspaces(""))
+
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.POST("/webhooks/push", handler.Push)
+	router.ServeHTTP(rr, pushRequest(t, "gitlab_push.json", map[string]string{"X-Gitlab-Token": ""}))
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "без секрета вебхуки не принимаются")
+	repo.AssertNotCalled(t, "ExistingTaskIDs", mock.Anything)
+}
+
+func TestPushWebhook_SecretOfOtherWorkspace(t *testing.T) {
+	repo := new(MockCommitRepository)
+	handler := handlers.NewWebhookHandler(repo, webhookWorkspaces("s3cret"))
+
+	// The secret of the default workspace does not sign pushes to workspace 2.
+	req := pushRequest(t, "gitlab_push.json", map[string]string{"X-Gitlab-Token": "s3cret"})
+	req.URL.RawQuery = "workspace=2"
+	rr := httptest.NewRecorder()
+	router := gin.Default()
+	router.POST("/webhooks/push", handler.Push)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, "секрет одного пространства не подходит для другого")
+	repo.AssertNotCalled(t, "ExistingTaskIDs", mock.Anything)
+}
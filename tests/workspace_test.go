@@ -0,0 +1,315 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/togzhanzhakhani/projects/internal/auth"
+	"github.com/togzhanzhakhani/projects/internal/handlers"
+	"github.com/togzhanzhakhani/projects/internal/models"
+	"github.com/togzhanzhakhani/projects/internal/repository"
+	"github.com/togzhanzhakhani/projects/internal/workspace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) CreateWorkspace(ws *models.Workspace, ownerID uint) error {
+	args := m.Called(ws, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) GetWorkspaceByID(id uint) (*models.Workspace, error) {
+	args := m.Called(id)
+	if ws, ok := args.Get(0).(*models.Workspace); ok {
+		return ws, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) GetWorkspacesByUser(userID uint) ([]repository.UserWorkspace, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]repository.UserWorkspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) UpdateWorkspace(ws *models.Workspace) error {
+	args := m.Called(ws)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) GetMember(workspaceID, userID uint) (*models.Membership, error) {
+	args := m.Called(workspaceID, userID)
+	if membership, ok := args.Get(0).(*models.Membership); ok {
+		return membership, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) GetMembers(workspaceID uint) ([]repository.Member, error) {
+	args := m.Called(workspaceID)
+	return args.Get(0).([]repository.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) UpdateMember(membership *models.Membership) error {
+	args := m.Called(membership)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) RemoveMember(workspaceID, userID uint) error {
+	args := m.Called(workspaceID, userID)
+	return args.Error(0)
+}
+
+// dryRunDB returns a database that builds statements without running them.
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=dry_run"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(workspace.Plugin{}))
+	return db
+}
+
+func workspaceContext(id uint) context.Context {
+	return workspace.NewContext(context.Background(), models.Membership{WorkspaceID: id, UserID: 1, Role: models.WorkspaceMember})
+}
+
+func TestWorkspacePlugin_ScopesQueries(t *testing.T) {
+	db := dryRunDB(t)
+
+	var tasks []models.Task
+	stmt := db.WithContext(workspaceContext(7)).Where("status = ?", "todo").Find(&tasks).Statement
+	assert.Contains(t, stmt.SQL.String(), `"tasks"."workspace_id" = `, "запрос задач должен быть ограничен рабочим пространством")
+	assert.Contains(t, stmt.Vars, uint(7), "ID рабочего пространства не передан в запрос")
+
+	var users []models.User
+	stmt = db.WithContext(workspaceContext(7)).Find(&users).Statement
+	assert.Contains(t, stmt.SQL.String(), "SELECT user_id FROM workspace_members WHERE workspace_id = ", "пользователи должны быть ограничены участниками")
+
+	stmt = db.WithContext(workspaceContext(7)).Delete(&models.Project{}, 3).Statement
+	assert.Contains(t, stmt.SQL.String(), `"projects"."workspace_id" = `, "удаление должно быть ограничено рабочим пространством")
+}
+
+func TestWorkspacePlugin_LeavesUnscopedStatements(t *testing.T) {
+	db := dryRunDB(t)
+
+	var tasks []models.Task
+	stmt := db.Find(&tasks).Statement
+	assert.NotContains(t, stmt.SQL.String(), "workspace_id", "запрос без рабочего пространства не должен ограничиваться")
+
+	var users []models.User
+	stmt = workspace.Global(db.WithContext(workspaceContext(7))).Find(&users).Statement
+	assert.NotContains(t, stmt.SQL.String(), "workspace_members", "Global должен снимать ограничение")
+}
+
+func TestWorkspacePlugin_AssignsCreatedRows(t *testing.T) {
+	db := dryRunDB(t)
+
+	task := models.Task{Title: "Task", WorkspaceID: 3}
+	db.WithContext(workspaceContext(7)).Create(&task)
+	assert.Equal(t, uint(7), task.WorkspaceID, "задача должна создаваться в рабочем пространстве запроса")
+
+	tasks := []models.Task{{Title: "A"}, {Title: "B", WorkspaceID: 2}}
+	db.WithContext(workspaceContext(7)).Create(&tasks)
+	for _, task := range tasks {
+		assert.Equal(t, uint(7), task.WorkspaceID, "каждая задача должна создаваться в рабочем пространстве запроса")
+	}
+}
+
+type fakeMembers map[[2]uint]string
+
+func (f fakeMembers) GetMember(workspaceID, userID uint) (*models.Membership, error) {
+	role, ok := f[[2]uint{workspaceID, userID}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Membership{WorkspaceID: workspaceID, UserID: userID, Role: role}, nil
+}
+
+func setupScopedRouter() *gin.Engine {
+	members := fakeMembers{{1, 5}: models.WorkspaceMember, {2, 6}: models.WorkspaceAdmin}
+	router := gin.New()
+	router.Use(auth.Middleware(auth.Header), workspace.Middleware(members))
+	router.GET("/scoped", func(c *gin.Context) {
+		id, _ := workspace.ID(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"workspace_id": id})
+	})
+	return router
+}
+
+func TestWorkspaceMiddleware(t *testing.T) {
+	router := setupScopedRouter()
+	tests := []struct {
+		name      string
+		user      string
+		workspace string
+		status    int
+		body      string
+	}{
+		{"без пользователя", "", "", http.StatusUnauthorized, ""},
+		{"рабочее пространство по умолчанию", "5", "", http.StatusOK, `{"workspace_id":1}`},
+		{"выбранное рабочее пространство", "6", "2", http.StatusOK, `{"workspace_id":2}`},
+		{"чужое рабочее пространство", "5", "2", http.StatusNotFound, ""},
+		{"неверный заголовок", "5", "abc", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/scoped", nil)
+			if tt.user != "" {
+				req.Header.Set(auth.UserHeader, tt.user)
+			}
+			if tt.workspace != "" {
+				req.Header.Set(workspace.Header, tt.workspace)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code, "статус код не соответствует ожидаемому")
+			if tt.body != "" {
+				assert.JSONEq(t, tt.body, rr.Body.String(), "рабочее пространство запроса не соответствует ожидаемому")
+			}
+		})
+	}
+}
+
+func setupWorkspaceRouter() (*gin.Engine, *MockWorkspaceRepository) {
+	router, mockRepo, _, _ := setupWorkspaceInvitationRouter()
+	return router, mockRepo
+}
+
+// setupWorkspaceInvitationRouter is setupWorkspaceRouter with the
+// repository and sender of the invitations that add members.
+func setupWorkspaceInvitationRouter() (*gin.Engine, *MockWorkspaceRepository, *MockInvitationRepository, *recordingSender) {
+	mockRepo := new(MockWorkspaceRepository)
+	invitationRepo := new(MockInvitationRepository)
+	sender := &recordingSender{}
+	handler := handlers.NewWorkspaceHandler(mockRepo, handlers.NewInvitationHandler(invitationRepo, new(MockUserRepository), sender))
+	router := gin.New()
+	router.Use(auth.Middleware(auth.Header))
+	router.GET("/workspaces", handler.GetWorkspaces)
+	router.POST("/workspaces", handler.CreateWorkspace)
+	router.POST("/workspaces/:id/members", handler.AddMember)
+	router.PUT("/workspaces/:id/members/:userId", handler.UpdateMember)
+	router.DELETE("/workspaces/:id/members/:userId", handler.RemoveMember)
+	router.POST("/workspaces/:id/webhook-secret", handler.RotateWebhookSecret)
+	return router, mockRepo, invitationRepo, sender
+}
+
+func workspaceRequest(method, path, body string) *http.Request {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.UserHeader, "5")
+	return req
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	router, mockRepo := setupWorkspaceRouter()
+	mockRepo.On("CreateWorkspace", mock.AnythingOfType("*models.Workspace"), uint(5)).Return(nil)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodPost, "/workspaces", `{"name":"Acme","slug":"acme"}`))
+	assert.Equal(t, http.StatusCreated, rr.Code, "статус код не соответствует ожидаемому")
+	mockRepo.AssertExpectations(t)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodPost, "/workspaces", `{"name":"Acme","slug":"Acme Inc"}`))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "неверный slug должен отклоняться")
+	assert.Contains(t, rr.Body.String(), `"field":"slug"`, "ошибка должна относиться к полю slug")
+}
+
+func TestAddMember_RequiresAdmin(t *testing.T) {
+	router, mockRepo, invitationRepo, _ := setupWorkspaceInvitationRouter()
+	mockRepo.On("GetMember", uint(1), uint(5)).Return(&models.Membership{WorkspaceID: 1, UserID: 5, Role: models.WorkspaceMember}, nil)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodPost, "/workspaces/1/members", `{"email":"jane@example.com","role":"member"}`))
+	assert.Equal(t, http.StatusForbidden, rr.Code, "только администратор может добавлять участников")
+	invitationRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything)
+}
+
+func TestAddMember_Invites(t *testing.T) {
+	router, mockRepo, invitationRepo, sender := setupWorkspaceInvitationRouter()
+	mockRepo.On("GetMember", uint(3), uint(5)).Return(&models.Membership{WorkspaceID: 3, UserID: 5, Role: models.WorkspaceAdmin}, nil)
+	invitationRepo.On("CreateInvitation", mock.AnythingOfType("*models.Invitation")).Return(nil)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodPost, "/workspaces/3/members", `{"email":"Jane@Example.com","role":"admin"}`))
+	assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.NotContains(t, rr.Body.String(), "token", "токен не должен возвращаться в ответе")
+
+	created := invitationRepo.Calls[0].Arguments.Get(0).(*models.Invitation)
+	assert.Equal(t, "jane@example.com", created.Email)
+	assert.Equal(t, uint(3), created.WorkspaceID, "приглашение должно быть в рабочее пространство из пути")
+	assert.Equal(t, models.WorkspaceAdmin, created.WorkspaceRole, "роль в рабочем пространстве должна сохраняться")
+	assert.Equal(t, uint(5), created.InvitedBy)
+	assert.Len(t, sender.messages, 1, "пользователь должен получить приглашение, а не быть добавлен без согласия")
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodPost, "/workspaces/3/members", `{"user_id":8,"role":"member"}`))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "пользователей нельзя добавлять по ID")
+	assert.Contains(t, rr.Body.String(), `"field":"email"`)
+}
+
+func TestUpdateMember_LastAdmin(t *testing.T) {
+	router, mockRepo := setupWorkspaceRouter()
+	admin := &models.Membership{WorkspaceID: 1, UserID: 5, Role: models.WorkspaceAdmin}
+	mockRepo.On("GetMember", uint(1), uint(5)).Return(admin, nil)
+	mockRepo.On("UpdateMember", mock.AnythingOfType("*models.Membership")).Return(repository.ErrLastAdmin)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodPut, "/workspaces/1/members/5", `{"role":"member"}`))
+	assert.Equal(t, http.StatusConflict, rr.Code, "последнего администратора нельзя понизить")
+}
+
+func TestRemoveMember_UnknownWorkspace(t *testing.T) {
+	router, mockRepo := setupWorkspaceRouter()
+	mockRepo.On("GetMember", uint(9), uint(5)).Return(nil, gorm.ErrRecordNotFound)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodDelete, "/workspaces/9/members/8", ""))
+	assert.Equal(t, http.StatusNotFound, rr.Code, "чужое рабочее пространство должно выглядеть несуществующим")
+}
+
+func TestRotateWebhookSecret(t *testing.T) {
+	router, mockRepo := setupWorkspaceRouter()
+	mockRepo.On("GetMember", uint(1), uint(5)).Return(&models.Membership{WorkspaceID: 1, UserID: 5, Role: models.WorkspaceAdmin}, nil)
+	mockRepo.On("GetWorkspaceByID", uint(1)).Return(&models.Workspace{ID: 1, WebhookSecret: "old"}, nil)
+	var saved *models.Workspace
+	mockRepo.On("UpdateWorkspace", mock.AnythingOfType("*models.Workspace")).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*models.Workspace) }).Return(nil)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodPost, "/workspaces/1/webhook-secret", ""))
+	assert.Equal(t, http.StatusCreated, rr.Code, "статус код не соответствует ожидаемому")
+	var response struct {
+		Secret string `json:"secret"`
+		URL    string `json:"url"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.NotEqual(t, "old", response.Secret, "секрет должен смениться")
+	assert.Equal(t, response.Secret, saved.WebhookSecret)
+	assert.Equal(t, "/webhooks/push?workspace=1", response.URL)
+}
+
+func TestRotateWebhookSecret_RequiresAdmin(t *testing.T) {
+	router, mockRepo := setupWorkspaceRouter()
+	mockRepo.On("GetMember", uint(1), uint(5)).Return(&models.Membership{WorkspaceID: 1, UserID: 5, Role: models.WorkspaceMember}, nil)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, workspaceRequest(http.MethodPost, "/workspaces/1/webhook-secret", ""))
+	assert.Equal(t, http.StatusForbidden, rr.Code, "только администратор может менять секрет вебхуков")
+	mockRepo.AssertNotCalled(t, "UpdateWorkspace", mock.Anything)
+}